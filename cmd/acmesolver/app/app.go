@@ -65,6 +65,10 @@ func NewACMESolverCommand(stopCh <-chan struct{}) *cobra.Command {
 	cmd.Flags().StringVar(&s.Domain, "domain", "", "the domain name to verify")
 	cmd.Flags().StringVar(&s.Token, "token", "", "the challenge token to verify against")
 	cmd.Flags().StringVar(&s.Key, "key", "", "the challenge key to respond with")
+	cmd.Flags().StringVar(&s.KeyThumbprint, "key-thumbprint", "", "the ACME account key thumbprint. "+
+		"If set, the solver will respond to challenges for any domain and token, and --domain, --token and --key are ignored")
+	cmd.Flags().StringVar(&s.PathPrefix, "path-prefix", "", "the path prefix that challenge requests are made under, "+
+		"prepended to '/.well-known/acme-challenge'")
 
 	return cmd
 }
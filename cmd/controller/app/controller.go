@@ -36,6 +36,7 @@ import (
 
 	"github.com/cert-manager/cert-manager/cmd/controller/app/options"
 	cmdutil "github.com/cert-manager/cert-manager/cmd/util"
+	"github.com/cert-manager/cert-manager/internal/controller/certificates"
 	"github.com/cert-manager/cert-manager/internal/controller/feature"
 	"github.com/cert-manager/cert-manager/pkg/acme/accounts"
 	"github.com/cert-manager/cert-manager/pkg/controller"
@@ -201,11 +202,10 @@ func Run(opts *options.ControllerOptions, stopCh <-chan struct{}) error {
 			return err
 		}
 
+		workers := opts.Workers(n)
 		g.Go(func() error {
-			log.V(logf.InfoLevel).Info("starting controller")
+			log.V(logf.InfoLevel).Info("starting controller", "workers", workers)
 
-			// TODO: make this either a constant or a command line flag
-			workers := 5
 			return iface.Run(workers, rootCtx.Done())
 		})
 	}
@@ -261,6 +261,24 @@ func buildControllerContextFactory(ctx context.Context, opts *options.Controller
 		return nil, fmt.Errorf("error parsing ACMEHTTP01SolverResourceLimitsMemory: %w", err)
 	}
 
+	var issuedBeforeCutoff time.Time
+	if opts.CertificateIssuedBeforeCutoff != "" {
+		issuedBeforeCutoff, err = time.Parse(time.RFC3339, opts.CertificateIssuedBeforeCutoff)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing CertificateIssuedBeforeCutoff: %w", err)
+		}
+	}
+
+	var renewalTimeOfDay *time.Duration
+	if opts.CertificateRenewalTimeOfDay != "" {
+		t, err := time.Parse("15:04", opts.CertificateRenewalTimeOfDay)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing CertificateRenewalTimeOfDay: %w", err)
+		}
+		timeOfDay := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		renewalTimeOfDay = &timeOfDay
+	}
+
 	acmeAccountRegistry := accounts.NewDefaultRegistry()
 
 	ctxFactory, err := controller.NewContextFactory(ctx, controller.ContextOptions{
@@ -282,22 +300,53 @@ func buildControllerContextFactory(ctx context.Context, opts *options.Controller
 			HTTP01SolverImage:                 opts.ACMEHTTP01SolverImage,
 			// Allows specifying a list of custom nameservers to perform HTTP01 checks on.
 			HTTP01SolverNameservers: opts.ACMEHTTP01SolverNameservers,
+			// Allows disabling the HTTP01 self check.
+			HTTP01SolverSkipSelfCheck: opts.ACMEHTTP01SolverSkipSelfCheck,
+			// Allows sharing HTTP01 solver Pods and Services between challenges.
+			HTTP01SolverSharePods: opts.ACMEHTTP01SolverSharePods,
 
 			DNS01Nameservers:        nameservers,
 			DNS01CheckRetryPeriod:   opts.DNS01CheckRetryPeriod,
+			DNS01CheckInitialDelay:  opts.DNS01CheckInitialDelay,
 			DNS01CheckAuthoritative: !opts.DNS01RecursiveNameserversOnly,
+			DNS01ProviderQPS:        opts.DNS01ProviderQPS,
+			DNS01ProviderBurst:      opts.DNS01ProviderBurst,
+
+			// Allows Issuers to run the listed executables using the exec DNS01 provider.
+			DNS01ExecProviderCommands: opts.DNS01ExecProviderCommands,
+
+			// Allows delaying processing of challenges for issuers under maintenance.
+			IssuerMaintenanceRetryPeriod: opts.ACMEIssuerMaintenanceRetryPeriod,
+
+			// Allows alerting on challenges that are stuck processing.
+			ChallengeProcessingWarningThreshold: opts.ACMEChallengeProcessingWarningThreshold,
+
+			// Allows filtering the events recorded by the challenges controller.
+			ChallengesEventComponent: opts.ACMEChallengesEventComponent,
+
+			// Configures the back-off applied to challenges that fail to sync.
+			ChallengeBackoffBaseDelay: opts.ACMEChallengeBackoffBaseDelay,
+			ChallengeBackoffMaxDelay:  opts.ACMEChallengeBackoffMaxDelay,
 
 			AccountRegistry: acmeAccountRegistry,
 		},
 
 		SchedulerOptions: controller.SchedulerOptions{
-			MaxConcurrentChallenges: opts.MaxConcurrentChallenges,
+			MaxConcurrentChallenges:          opts.MaxConcurrentChallenges,
+			MaxConcurrentChallengesPerIssuer: opts.MaxConcurrentChallengesPerIssuer,
+			ReservedMustScheduleChallenges:   opts.ReservedMustScheduleChallenges,
+			StatusUpdateQPS:                  opts.ChallengeScheduleQPS,
+			MaintenanceWindow:                opts.ChallengeMaintenanceWindow,
 		},
 
 		IssuerOptions: controller.IssuerOptions{
 			ClusterIssuerAmbientCredentials: opts.ClusterIssuerAmbientCredentials,
 			IssuerAmbientCredentials:        opts.IssuerAmbientCredentials,
 			ClusterResourceNamespace:        opts.ClusterResourceNamespace,
+			SelfSignedSigningQPS:            opts.SelfSignedSigningQPS,
+			SelfSignedSigningBurst:          opts.SelfSignedSigningBurst,
+			SelfSignedEventComponent:        opts.SelfSignedEventComponent,
+			CALimitDurationToCA:             opts.EnableCAExpiryCheck,
 		},
 
 		IngressShimOptions: controller.IngressShimOptions{
@@ -308,8 +357,33 @@ func buildControllerContextFactory(ctx context.Context, opts *options.Controller
 		},
 
 		CertificateOptions: controller.CertificateOptions{
-			EnableOwnerRef:           opts.EnableCertificateOwnerRef,
-			CopiedAnnotationPrefixes: opts.CopiedAnnotationPrefixes,
+			EnableOwnerRef:                 opts.EnableCertificateOwnerRef,
+			CopiedAnnotationPrefixes:       opts.CopiedAnnotationPrefixes,
+			RenewalJitterWindow:            opts.CertificateRenewalJitterWindow,
+			RenewalGraceTolerance:          opts.CertificateRenewalGraceTolerance,
+			RenewalTimeOfDay:               renewalTimeOfDay,
+			ResyncExpiryMargin:             opts.CertificateResyncExpiryMargin,
+			DurationTolerance:              opts.CertificateDurationTolerance,
+			MinimumDuration:                opts.CertificateMinimumDuration,
+			MinimumRSAKeySize:              opts.CertificateMinimumRSAKeySize,
+			IssuedBeforeCutoff:             issuedBeforeCutoff,
+			DetectPrivateKeyReuse:          opts.EnablePrivateKeyReuseDetection,
+			DetectDuplicateSerials:         opts.EnableDuplicateSerialDetection,
+			ProtectUnmanagedSecrets:        opts.EnableUnmanagedSecretProtection,
+			CompareIssuerProfile:           opts.EnableIssuerProfileCheck,
+			CheckDeniedUsages:              opts.EnableDeniedUsagesCheck,
+			CheckMissingRevision:           opts.EnableMissingRevisionCheck,
+			CheckMultipleLeafCertificates:  opts.EnableMultipleLeafCertificatesCheck,
+			CheckChainVerifies:             opts.EnableChainVerificationCheck,
+			CheckMissingSANs:               opts.EnableMissingSANCheck,
+			CheckUnexpectedCommonName:      opts.EnableUnexpectedCommonNameCheck,
+			IgnoreManagedFieldsParseErrors: opts.IgnoreManagedFieldsParseErrors,
+			SecretUpdateStrategy:           certificates.SecretUpdateStrategy(opts.SecretUpdateStrategy),
+			CompareAuthorityKeyID:          opts.EnableAuthorityKeyIDCheck,
+			CheckIssuerCAExpiry:            opts.EnableCAExpiryCheck,
+			RevokedIntermediatesConfigMap:  opts.RevokedIntermediatesConfigMap,
+			CheckUsages:                    opts.EnableCertificateUsageCheck,
+			DisabledTriggerPolicies:        opts.DisabledTriggerPolicies,
 		},
 	})
 	if err != nil {
@@ -19,16 +19,21 @@ package options
 import (
 	"fmt"
 	"net"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
 
 	cmdutil "github.com/cert-manager/cert-manager/cmd/util"
+	"github.com/cert-manager/cert-manager/internal/controller/certificates"
+	"github.com/cert-manager/cert-manager/internal/controller/certificates/policies"
 	"github.com/cert-manager/cert-manager/internal/controller/feature"
 	cm "github.com/cert-manager/cert-manager/pkg/apis/certmanager"
 	challengescontroller "github.com/cert-manager/cert-manager/pkg/controller/acmechallenges"
+	"github.com/cert-manager/cert-manager/pkg/controller/acmechallenges/scheduler"
 	orderscontroller "github.com/cert-manager/cert-manager/pkg/controller/acmeorders"
 	shimgatewaycontroller "github.com/cert-manager/cert-manager/pkg/controller/certificate-shim/gateways"
 	shimingresscontroller "github.com/cert-manager/cert-manager/pkg/controller/certificate-shim/ingresses"
@@ -55,6 +60,7 @@ import (
 	logf "github.com/cert-manager/cert-manager/pkg/logs"
 	"github.com/cert-manager/cert-manager/pkg/util"
 	utilfeature "github.com/cert-manager/cert-manager/pkg/util/feature"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
 )
 
 type ControllerOptions struct {
@@ -81,10 +87,28 @@ type ControllerOptions struct {
 	ACMEHTTP01SolverResourceLimitsMemory  string
 	// Allows specifying a list of custom nameservers to perform HTTP01 checks on.
 	ACMEHTTP01SolverNameservers []string
+	// Allows disabling the HTTP01 self-check performed before a challenge is
+	// accepted.
+	ACMEHTTP01SolverSkipSelfCheck bool
+	// Allows sharing a single HTTP01 solver Pod and Service between the
+	// challenges of an Order.
+	ACMEHTTP01SolverSharePods bool
 
 	ClusterIssuerAmbientCredentials bool
 	IssuerAmbientCredentials        bool
 
+	// SelfSignedSigningQPS limits the number of CertificateSigningRequests
+	// signed per second by each SelfSigned issuer. A value of 0 disables the
+	// limit.
+	SelfSignedSigningQPS   float32
+	SelfSignedSigningBurst int
+
+	// SelfSignedEventComponent is the source component of events recorded
+	// by the SelfSigned CertificateSigningRequest signer. If empty, the
+	// component of the certificatesigningrequests-issuer-selfsigned
+	// controller is used.
+	SelfSignedEventComponent string
+
 	// Default issuer/certificates details consumed by ingress-shim
 	DefaultIssuerName                 string
 	DefaultIssuerKind                 string
@@ -99,7 +123,153 @@ type ControllerOptions struct {
 
 	EnableCertificateOwnerRef bool
 
+	// CertificateRenewalJitterWindow is the size of the window before a
+	// Certificate's renewal time within which its renewal may be triggered.
+	CertificateRenewalJitterWindow time.Duration
+
+	// CertificateRenewalGraceTolerance is the amount of time that must have
+	// passed since a Certificate's renewal time before it is renewed.
+	CertificateRenewalGraceTolerance time.Duration
+
+	// CertificateRenewalTimeOfDay is a time of day in UTC in the form HH:MM.
+	// If set, the renewal of Certificates is moved to the next occurrence of
+	// this time of day.
+	CertificateRenewalTimeOfDay string
+
+	// CertificateResyncExpiryMargin causes Certificates that would expire
+	// before the next resync, plus this margin, to be renewed straight away.
+	CertificateResyncExpiryMargin time.Duration
+
+	// CertificateDurationTolerance causes Certificates whose issued
+	// certificate is valid for longer than spec.duration plus this tolerance
+	// to be reissued.
+	CertificateDurationTolerance time.Duration
+
+	// CertificateMinimumDuration prevents Certificates whose spec.duration
+	// is shorter than this from being issued, as their issuer would reject
+	// them.
+	CertificateMinimumDuration time.Duration
+
+	// CertificateMinimumRSAKeySize causes Certificates whose stored RSA
+	// private key is smaller than this number of bits to be reissued with a
+	// key of at least this size, regardless of their spec.privateKey.size.
+	CertificateMinimumRSAKeySize int
+
+	// CertificateIssuedBeforeCutoff is an RFC3339 timestamp. If set,
+	// Certificates whose issued certificate has a notBefore earlier than it
+	// are reissued.
+	CertificateIssuedBeforeCutoff string
+
+	// EnableUnmanagedSecretProtection prevents Certificates from being
+	// issued into existing Secrets that were not created by cert-manager.
+	EnableUnmanagedSecretProtection bool
+
+	// EnablePrivateKeyReuseDetection causes Certificates with a private key
+	// rotation policy of Always to be reissued if their private key is the
+	// same as the one used for the previous revision.
+	EnablePrivateKeyReuseDetection bool
+
+	// EnableDuplicateSerialDetection causes Certificates whose issued
+	// certificate has the same serial number as that of another Certificate
+	// in the same namespace to be flagged.
+	EnableDuplicateSerialDetection bool
+
+	// EnableIssuerProfileCheck causes Certificates to be reissued if the
+	// issuer profile annotation recorded on their Secret differs from the
+	// one on the issuer they reference.
+	EnableIssuerProfileCheck bool
+
+	// EnableDeniedUsagesCheck causes Certificates to be reissued if their
+	// issued certificate has an extended key usage that is denied by the
+	// issuer they reference.
+	EnableDeniedUsagesCheck bool
+
+	// EnableMissingRevisionCheck causes Certificates to be reissued if their
+	// Secret does not have the revision annotation, so that it is backfilled.
+	EnableMissingRevisionCheck bool
+
+	// EnableMultipleLeafCertificatesCheck causes Certificates to be reissued
+	// if the tls.crt entry of their Secret contains more than one end-entity
+	// certificate.
+	EnableMultipleLeafCertificatesCheck bool
+
+	// EnableChainVerificationCheck causes Certificates to be reissued if the
+	// certificate in their Secret does not verify against the CA
+	// certificates in its ca.crt entry.
+	EnableChainVerificationCheck bool
+
+	// EnableMissingSANCheck causes non-CA Certificates with a common name to
+	// be reissued if their issued certificate has no subject alternative name
+	// extension.
+	EnableMissingSANCheck bool
+
+	// EnableUnexpectedCommonNameCheck causes Certificates without a common
+	// name to be reissued if their issued certificate has a common name.
+	EnableUnexpectedCommonNameCheck bool
+
+	// IgnoreManagedFieldsParseErrors causes managed fields entries on
+	// Certificates' Secrets that cannot be decoded to be treated as empty,
+	// rather than stopping the Secret from being reconciled.
+	IgnoreManagedFieldsParseErrors bool
+
+	// SecretUpdateStrategy is the strategy used to write the Secrets of
+	// Certificates, either 'Apply' or 'Update'.
+	SecretUpdateStrategy string
+
+	// EnableAuthorityKeyIDCheck causes Certificates to be reissued if the
+	// authority key ID of their issued certificate differs from the subject
+	// key ID of the CA used by the issuer they reference.
+	EnableAuthorityKeyIDCheck bool
+
+	// EnableCAExpiryCheck causes Certificates to be reissued if their issued
+	// certificate expires after the CA used by the issuer they reference, and
+	// limits certificates signed by CA issuers to the notAfter of their CA.
+	EnableCAExpiryCheck bool
+
+	// RevokedIntermediatesConfigMap is the <namespace>/<name> of a ConfigMap
+	// holding the SHA-256 fingerprints of revoked intermediate CA
+	// certificates. Certificates signed by one of them are reissued.
+	RevokedIntermediatesConfigMap string
+
+	// EnableCertificateUsageCheck causes Certificates to be marked as not
+	// ready if the usages of their issued certificate contradict each other.
+	EnableCertificateUsageCheck bool
+
+	// CertificatePolicyWorkers is the number of workers of each of the
+	// controllers that evaluate the Certificate policy chains, i.e. the
+	// number of Certificates whose policies are evaluated concurrently by
+	// each controller.
+	CertificatePolicyWorkers int
+
+	// DisabledTriggerPolicies holds the names of policies that are left out
+	// of the Certificate trigger policy chain.
+	DisabledTriggerPolicies []string
+
 	MaxConcurrentChallenges int
+	// MaxConcurrentChallengesPerIssuer limits the number of challenges that
+	// can be scheduled as 'processing' at once for individual issuers, keyed
+	// by "ClusterIssuer/<name>" or "Issuer/<namespace>/<name>".
+	MaxConcurrentChallengesPerIssuer map[string]int
+	// ReservedMustScheduleChallenges is the number of MaxConcurrentChallenges
+	// reserved for challenges of Certificates marked as must-schedule.
+	ReservedMustScheduleChallenges int
+	// ChallengeScheduleQPS limits the rate at which challenges are marked as
+	// 'processing' by the scheduler. Zero means no limit.
+	ChallengeScheduleQPS float32
+	// ChallengeMaintenanceWindow is a daily window of the form HH:MM-HH:MM,
+	// in UTC, during which only must-schedule challenges are scheduled.
+	// Empty means no maintenance window.
+	ChallengeMaintenanceWindow string
+	// DNS01ProviderQPS limits the rate at which records are presented and
+	// cleaned up with each DNS01 provider. Zero means no limit.
+	DNS01ProviderQPS float32
+	// DNS01ProviderBurst is the number of calls that can be made to a DNS01
+	// provider at once before DNS01ProviderQPS applies.
+	DNS01ProviderBurst int
+	// DNS01ExecProviderCommands is the list of absolute paths of executables
+	// that the exec DNS01 provider may run. The exec provider is disabled if
+	// the list is empty.
+	DNS01ExecProviderCommands []string
 
 	// The host and port address, separated by a ':', that the Prometheus server
 	// should expose metrics on.
@@ -112,6 +282,32 @@ type ControllerOptions struct {
 
 	DNS01CheckRetryPeriod time.Duration
 
+	// DNS01CheckInitialDelay is the time to wait before checking whether a
+	// challenge has propagated for the first time after it was presented.
+	// Subsequent checks wait for DNS01CheckRetryPeriod.
+	DNS01CheckInitialDelay time.Duration
+
+	// ACMEIssuerMaintenanceRetryPeriod is the time to wait before processing
+	// a challenge again if its issuer has been marked as being under
+	// maintenance.
+	ACMEIssuerMaintenanceRetryPeriod time.Duration
+
+	// ACMEChallengeProcessingWarningThreshold is the time a challenge may be
+	// processing for before a Warning event is recorded on it. A value of 0
+	// disables the warning.
+	ACMEChallengeProcessingWarningThreshold time.Duration
+
+	// ACMEChallengesEventComponent is the source component of events
+	// recorded by the challenges controller. If empty, the component of the
+	// challenges controller is used.
+	ACMEChallengesEventComponent string
+
+	// ACMEChallengeBackoffBaseDelay and ACMEChallengeBackoffMaxDelay are the
+	// initial and maximum delays of the exponential back-off applied to ACME
+	// challenges that fail to sync.
+	ACMEChallengeBackoffBaseDelay time.Duration
+	ACMEChallengeBackoffMaxDelay  time.Duration
+
 	// Annotations copied Certificate -> CertificateRequest,
 	// CertificateRequest -> Order. Slice of string literals that are
 	// treated as prefixes for annotation keys.
@@ -130,18 +326,89 @@ const (
 	defaultClusterIssuerAmbientCredentials = true
 	defaultIssuerAmbientCredentials        = false
 
+	defaultSelfSignedSigningQPS   float32 = 0
+	defaultSelfSignedSigningBurst         = 1
+
 	defaultTLSACMEIssuerName         = ""
 	defaultTLSACMEIssuerKind         = "Issuer"
 	defaultTLSACMEIssuerGroup        = cm.GroupName
 	defaultEnableCertificateOwnerRef = false
 
+	defaultCertificateRenewalJitterWindow = time.Duration(0)
+
+	defaultCertificateRenewalGraceTolerance = time.Duration(0)
+
+	defaultCertificateRenewalTimeOfDay = ""
+
+	defaultCertificateResyncExpiryMargin = time.Duration(0)
+
+	defaultCertificateDurationTolerance = time.Duration(0)
+
+	defaultCertificateMinimumDuration = time.Duration(0)
+
+	defaultCertificateMinimumRSAKeySize = 0
+
+	defaultCertificateIssuedBeforeCutoff = ""
+
+	defaultEnableUnmanagedSecretProtection = false
+
+	defaultEnablePrivateKeyReuseDetection = false
+
+	defaultEnableDuplicateSerialDetection = false
+
+	defaultEnableIssuerProfileCheck = false
+
+	defaultEnableDeniedUsagesCheck = false
+
+	defaultEnableMissingRevisionCheck = false
+
+	defaultEnableMultipleLeafCertificatesCheck = false
+
+	defaultEnableChainVerificationCheck = false
+
+	defaultEnableMissingSANCheck = false
+
+	defaultEnableUnexpectedCommonNameCheck = false
+
+	defaultIgnoreManagedFieldsParseErrors = false
+
+	defaultSecretUpdateStrategy = string(certificates.SecretUpdateStrategyApply)
+
+	defaultEnableAuthorityKeyIDCheck = false
+
+	defaultEnableCAExpiryCheck = false
+
+	defaultRevokedIntermediatesConfigMap = ""
+
+	defaultEnableCertificateUsageCheck = false
+
+	// defaultWorkers is the number of workers of each controller.
+	defaultWorkers = 5
+
+	defaultCertificatePolicyWorkers = defaultWorkers
+
 	defaultDNS01RecursiveNameserversOnly = false
 
+	defaultACMEHTTP01SolverSkipSelfCheck = false
+
+	defaultACMEHTTP01SolverSharePods = false
+
 	defaultMaxConcurrentChallenges = 60
 
 	defaultPrometheusMetricsServerAddress = "0.0.0.0:9402"
 
 	defaultDNS01CheckRetryPeriod = 10 * time.Second
+
+	defaultDNS01CheckInitialDelay = 2 * time.Second
+
+	defaultACMEIssuerMaintenanceRetryPeriod = 5 * time.Minute
+
+	defaultACMEChallengeProcessingWarningThreshold = time.Duration(0)
+
+	defaultDNS01ProviderBurst = 1
+
+	defaultACMEChallengeBackoffBaseDelay = 5 * time.Second
+	defaultACMEChallengeBackoffMaxDelay  = 30 * time.Minute
 )
 
 var (
@@ -217,31 +484,67 @@ var (
 
 func NewControllerOptions() *ControllerOptions {
 	return &ControllerOptions{
-		APIServerHost:                     defaultAPIServerHost,
-		ClusterResourceNamespace:          defaultClusterResourceNamespace,
-		KubernetesAPIQPS:                  defaultKubernetesAPIQPS,
-		KubernetesAPIBurst:                defaultKubernetesAPIBurst,
-		Namespace:                         defaultNamespace,
-		LeaderElect:                       cmdutil.DefaultLeaderElect,
-		LeaderElectionNamespace:           cmdutil.DefaultLeaderElectionNamespace,
-		LeaderElectionLeaseDuration:       cmdutil.DefaultLeaderElectionLeaseDuration,
-		LeaderElectionRenewDeadline:       cmdutil.DefaultLeaderElectionRenewDeadline,
-		LeaderElectionRetryPeriod:         cmdutil.DefaultLeaderElectionRetryPeriod,
-		controllers:                       defaultEnabledControllers,
-		ClusterIssuerAmbientCredentials:   defaultClusterIssuerAmbientCredentials,
-		IssuerAmbientCredentials:          defaultIssuerAmbientCredentials,
-		DefaultIssuerName:                 defaultTLSACMEIssuerName,
-		DefaultIssuerKind:                 defaultTLSACMEIssuerKind,
-		DefaultIssuerGroup:                defaultTLSACMEIssuerGroup,
-		DefaultAutoCertificateAnnotations: defaultAutoCertificateAnnotations,
-		ACMEHTTP01SolverNameservers:       []string{},
-		DNS01RecursiveNameservers:         []string{},
-		DNS01RecursiveNameserversOnly:     defaultDNS01RecursiveNameserversOnly,
-		EnableCertificateOwnerRef:         defaultEnableCertificateOwnerRef,
-		MetricsListenAddress:              defaultPrometheusMetricsServerAddress,
-		DNS01CheckRetryPeriod:             defaultDNS01CheckRetryPeriod,
-		EnablePprof:                       cmdutil.DefaultEnableProfiling,
-		PprofAddress:                      cmdutil.DefaultProfilerAddr,
+		APIServerHost:                           defaultAPIServerHost,
+		ClusterResourceNamespace:                defaultClusterResourceNamespace,
+		KubernetesAPIQPS:                        defaultKubernetesAPIQPS,
+		KubernetesAPIBurst:                      defaultKubernetesAPIBurst,
+		Namespace:                               defaultNamespace,
+		LeaderElect:                             cmdutil.DefaultLeaderElect,
+		LeaderElectionNamespace:                 cmdutil.DefaultLeaderElectionNamespace,
+		LeaderElectionLeaseDuration:             cmdutil.DefaultLeaderElectionLeaseDuration,
+		LeaderElectionRenewDeadline:             cmdutil.DefaultLeaderElectionRenewDeadline,
+		LeaderElectionRetryPeriod:               cmdutil.DefaultLeaderElectionRetryPeriod,
+		controllers:                             defaultEnabledControllers,
+		ClusterIssuerAmbientCredentials:         defaultClusterIssuerAmbientCredentials,
+		IssuerAmbientCredentials:                defaultIssuerAmbientCredentials,
+		SelfSignedSigningQPS:                    defaultSelfSignedSigningQPS,
+		SelfSignedSigningBurst:                  defaultSelfSignedSigningBurst,
+		DefaultIssuerName:                       defaultTLSACMEIssuerName,
+		DefaultIssuerKind:                       defaultTLSACMEIssuerKind,
+		DefaultIssuerGroup:                      defaultTLSACMEIssuerGroup,
+		DefaultAutoCertificateAnnotations:       defaultAutoCertificateAnnotations,
+		ACMEHTTP01SolverNameservers:             []string{},
+		ACMEHTTP01SolverSkipSelfCheck:           defaultACMEHTTP01SolverSkipSelfCheck,
+		ACMEHTTP01SolverSharePods:               defaultACMEHTTP01SolverSharePods,
+		DNS01RecursiveNameservers:               []string{},
+		DNS01ExecProviderCommands:               []string{},
+		DNS01RecursiveNameserversOnly:           defaultDNS01RecursiveNameserversOnly,
+		EnableCertificateOwnerRef:               defaultEnableCertificateOwnerRef,
+		CertificateRenewalJitterWindow:          defaultCertificateRenewalJitterWindow,
+		CertificateRenewalGraceTolerance:        defaultCertificateRenewalGraceTolerance,
+		CertificateRenewalTimeOfDay:             defaultCertificateRenewalTimeOfDay,
+		CertificateResyncExpiryMargin:           defaultCertificateResyncExpiryMargin,
+		CertificateDurationTolerance:            defaultCertificateDurationTolerance,
+		CertificateMinimumDuration:              defaultCertificateMinimumDuration,
+		CertificateMinimumRSAKeySize:            defaultCertificateMinimumRSAKeySize,
+		CertificateIssuedBeforeCutoff:           defaultCertificateIssuedBeforeCutoff,
+		EnableUnmanagedSecretProtection:         defaultEnableUnmanagedSecretProtection,
+		EnablePrivateKeyReuseDetection:          defaultEnablePrivateKeyReuseDetection,
+		EnableDuplicateSerialDetection:          defaultEnableDuplicateSerialDetection,
+		EnableIssuerProfileCheck:                defaultEnableIssuerProfileCheck,
+		EnableDeniedUsagesCheck:                 defaultEnableDeniedUsagesCheck,
+		EnableMissingRevisionCheck:              defaultEnableMissingRevisionCheck,
+		EnableMultipleLeafCertificatesCheck:     defaultEnableMultipleLeafCertificatesCheck,
+		EnableChainVerificationCheck:            defaultEnableChainVerificationCheck,
+		EnableMissingSANCheck:                   defaultEnableMissingSANCheck,
+		EnableUnexpectedCommonNameCheck:         defaultEnableUnexpectedCommonNameCheck,
+		IgnoreManagedFieldsParseErrors:          defaultIgnoreManagedFieldsParseErrors,
+		SecretUpdateStrategy:                    defaultSecretUpdateStrategy,
+		EnableAuthorityKeyIDCheck:               defaultEnableAuthorityKeyIDCheck,
+		EnableCAExpiryCheck:                     defaultEnableCAExpiryCheck,
+		RevokedIntermediatesConfigMap:           defaultRevokedIntermediatesConfigMap,
+		EnableCertificateUsageCheck:             defaultEnableCertificateUsageCheck,
+		CertificatePolicyWorkers:                defaultCertificatePolicyWorkers,
+		MetricsListenAddress:                    defaultPrometheusMetricsServerAddress,
+		DNS01CheckRetryPeriod:                   defaultDNS01CheckRetryPeriod,
+		DNS01CheckInitialDelay:                  defaultDNS01CheckInitialDelay,
+		ACMEIssuerMaintenanceRetryPeriod:        defaultACMEIssuerMaintenanceRetryPeriod,
+		ACMEChallengeProcessingWarningThreshold: defaultACMEChallengeProcessingWarningThreshold,
+		ACMEChallengeBackoffBaseDelay:           defaultACMEChallengeBackoffBaseDelay,
+		DNS01ProviderBurst:                      defaultDNS01ProviderBurst,
+		ACMEChallengeBackoffMaxDelay:            defaultACMEChallengeBackoffMaxDelay,
+		EnablePprof:                             cmdutil.DefaultEnableProfiling,
+		PprofAddress:                            cmdutil.DefaultProfilerAddr,
 	}
 }
 
@@ -306,6 +609,26 @@ func (s *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
 			"ACME HTTP01 check requests. This should be a list containing host and "+
 			"port, for example 8.8.8.8:53,8.8.4.4:53")
 
+	fs.BoolVar(&s.ACMEHTTP01SolverSkipSelfCheck, "acme-http01-solver-skip-self-check",
+		defaultACMEHTTP01SolverSkipSelfCheck,
+		"When true, cert-manager will not perform the ACME HTTP01 self check "+
+			"before accepting a challenge, and will instead accept the challenge as "+
+			"soon as the solver resources have been presented. This is useful in "+
+			"environments where the controller cannot reach the challenge URL from "+
+			"inside the cluster (e.g. due to egress restrictions). Disabling the self "+
+			"check means misconfigured solvers will only be detected by the ACME "+
+			"server, which may count failed validations against your rate limits. "+
+			"This option does not affect DNS01 self checks.")
+
+	fs.BoolVar(&s.ACMEHTTP01SolverSharePods, "acme-http01-solver-share-pods",
+		defaultACMEHTTP01SolverSharePods,
+		"When true, ACME HTTP01 challenges that belong to the same Order and use "+
+			"the same solver configuration will share a single solver Pod and Service "+
+			"instead of creating one of each per challenge. The shared resources are "+
+			"deleted once the last challenge using them has been cleaned up. This "+
+			"greatly reduces the number of resources created for certificates with many "+
+			"DNS names.")
+
 	fs.BoolVar(&s.ClusterIssuerAmbientCredentials, "cluster-issuer-ambient-credentials", defaultClusterIssuerAmbientCredentials, ""+
 		"Whether a cluster-issuer may make use of ambient credentials for issuers. 'Ambient Credentials' are credentials drawn from the environment, metadata services, or local files which are not explicitly configured in the ClusterIssuer API object. "+
 		"When this flag is enabled, the following sources for credentials are also used: "+
@@ -314,6 +637,15 @@ func (s *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
 		"Whether an issuer may make use of ambient credentials. 'Ambient Credentials' are credentials drawn from the environment, metadata services, or local files which are not explicitly configured in the Issuer API object. "+
 		"When this flag is enabled, the following sources for credentials are also used: "+
 		"AWS - All sources the Go SDK defaults to, notably including any EC2 IAM roles available via instance metadata.")
+	fs.Float32Var(&s.SelfSignedSigningQPS, "selfsigned-signing-qps", defaultSelfSignedSigningQPS, ""+
+		"The maximum number of CertificateSigningRequests signed per second by each SelfSigned Issuer or ClusterIssuer. "+
+		"Requests exceeding the limit are requeued. A value of 0 disables the limit.")
+	fs.IntVar(&s.SelfSignedSigningBurst, "selfsigned-signing-burst", defaultSelfSignedSigningBurst, ""+
+		"The maximum number of CertificateSigningRequests that may be signed at once by each SelfSigned Issuer or "+
+		"ClusterIssuer when selfsigned-signing-qps is set.")
+	fs.StringVar(&s.SelfSignedEventComponent, "selfsigned-event-component", "", ""+
+		"The source component of events recorded by the SelfSigned CertificateSigningRequest signer, for example "+
+		"cert-manager-selfsigned. If empty, the component of the controller signing the requests is used.")
 	fs.StringSliceVar(&s.DefaultAutoCertificateAnnotations, "auto-certificate-annotations", defaultAutoCertificateAnnotations, ""+
 		"The annotation consumed by the ingress-shim controller to indicate a ingress is requesting a certificate")
 
@@ -338,6 +670,109 @@ func (s *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&s.EnableCertificateOwnerRef, "enable-certificate-owner-ref", defaultEnableCertificateOwnerRef, ""+
 		"Whether to set the certificate resource as an owner of secret where the tls certificate is stored. "+
 		"When this flag is enabled, the secret will be automatically removed when the certificate resource is deleted.")
+	fs.DurationVar(&s.CertificateRenewalJitterWindow, "certificate-renewal-jitter-window", defaultCertificateRenewalJitterWindow, ""+
+		"The size of the window before a Certificate's renewal time within which the renewal will be triggered. "+
+		"Each Certificate is renewed at a fixed point within this window, derived from its UID, so that Certificates "+
+		"created at the same time are not all renewed at once. The jitter is limited to half of the time between "+
+		"the certificate's notBefore and renewal time. Set to 0 (the default) to disable.")
+	fs.DurationVar(&s.CertificateRenewalGraceTolerance, "certificate-renewal-grace-tolerance", defaultCertificateRenewalGraceTolerance, ""+
+		"The amount of time that must have passed since a Certificate's renewal time before the renewal is triggered. "+
+		"A small tolerance, e.g. a few seconds, avoids renewals being triggered early by one controller replica and not "+
+		"another due to clock skew. Set to 0 (the default) to disable.")
+	fs.StringVar(&s.CertificateRenewalTimeOfDay, "certificate-renewal-time-of-day", defaultCertificateRenewalTimeOfDay, ""+
+		"A time of day in UTC in the form HH:MM, e.g. 02:00. If set, the renewal of each Certificate is moved from its "+
+		"renewal time to the next occurrence of this time of day, e.g. to align renewals with a maintenance window. "+
+		"Renewals are never moved past the expiry of the issued certificate. Leave empty (the default) to disable.")
+	fs.DurationVar(&s.CertificateResyncExpiryMargin, "certificate-resync-expiry-margin", defaultCertificateResyncExpiryMargin, ""+
+		"If set, Certificates whose issued certificate would expire within the controller's resync period plus this "+
+		"margin are renewed straight away, regardless of their renewal time. This guards against a renewal window being "+
+		"missed entirely between resyncs. Set to 0 (the default) to disable.")
+	fs.DurationVar(&s.CertificateDurationTolerance, "certificate-duration-tolerance", defaultCertificateDurationTolerance, ""+
+		"If set, Certificates whose issued certificate is valid (notAfter - notBefore) for longer than their spec.duration "+
+		"plus this tolerance are reissued, e.g. after an issuer's maximum duration has been reduced. The tolerance should "+
+		"allow for issuers that backdate notBefore. Set to 0 (the default) to disable.")
+	fs.DurationVar(&s.CertificateMinimumDuration, "certificate-minimum-duration", defaultCertificateMinimumDuration, ""+
+		"If set, Certificates whose spec.duration is shorter than this are not issued, and are instead marked with the "+
+		"DurationTooShort reason on their Issuing condition. Set this to the minimum duration documented by your issuers "+
+		"so that such Certificates fail straight away rather than repeatedly failing issuance. Set to 0 (the default) to disable.")
+	fs.IntVar(&s.CertificateMinimumRSAKeySize, "certificate-minimum-rsa-key-size", defaultCertificateMinimumRSAKeySize, ""+
+		"If set, Certificates whose stored RSA private key is smaller than this number of bits are reissued, and new "+
+		"RSA private keys are generated with at least this size, regardless of the Certificate's spec.privateKey.size. "+
+		"This allows a minimum key size to be enforced on existing Certificates. Set to 0 (the default) to disable.")
+	fs.StringVar(&s.CertificateIssuedBeforeCutoff, "certificate-issued-before-cutoff", defaultCertificateIssuedBeforeCutoff, ""+
+		"An RFC3339 timestamp, e.g. 2022-06-01T00:00:00Z. If set, Certificates whose issued certificate has a notBefore "+
+		"earlier than this time are reissued, e.g. to replace every certificate signed by a compromised CA key. "+
+		"Certificates issued for a CertificateRequest created after this time are not reissued, even if their issuer "+
+		"backdated their notBefore. Leave empty (the default) to disable.")
+	fs.BoolVar(&s.EnableUnmanagedSecretProtection, "enable-unmanaged-secret-protection", defaultEnableUnmanagedSecretProtection, ""+
+		"Whether to refuse to issue Certificates into an existing Secret that contains data but was not created by "+
+		"cert-manager. Such Secrets can be adopted by annotating them with 'cert-manager.io/allow-adoption: \"true\"'.")
+	fs.BoolVar(&s.EnablePrivateKeyReuseDetection, "enable-private-key-reuse-detection", defaultEnablePrivateKeyReuseDetection, ""+
+		"Whether to reissue Certificates with a private key rotationPolicy of Always if the private key stored in their "+
+		"Secret is the same as the one used for the previous revision. This requires the CertificateRequest for the "+
+		"previous revision to still exist.")
+	fs.BoolVar(&s.EnableDuplicateSerialDetection, "enable-duplicate-serial-detection", defaultEnableDuplicateSerialDetection, ""+
+		"Whether to record the serial numbers of issued certificates in memory and flag Certificates whose issued "+
+		"certificate has the same issuer and serial number as that of another Certificate in the same namespace. Flagged "+
+		"Certificates are not reissued.")
+	fs.BoolVar(&s.EnableIssuerProfileCheck, "enable-issuer-profile-check", defaultEnableIssuerProfileCheck, ""+
+		"Whether to reissue Certificates if the 'cert-manager.io/issuer-profile' annotation recorded on their Secret "+
+		"differs from the one on the Issuer or ClusterIssuer they reference.")
+	fs.BoolVar(&s.EnableDeniedUsagesCheck, "enable-denied-usages-check", defaultEnableDeniedUsagesCheck, ""+
+		"Whether to reissue Certificates if their issued certificate has an extended key usage listed in the "+
+		"'cert-manager.io/denied-extended-key-usages' annotation of the Issuer or ClusterIssuer they reference.")
+	fs.BoolVar(&s.EnableMissingRevisionCheck, "enable-missing-revision-check", defaultEnableMissingRevisionCheck, ""+
+		"Whether to reissue Certificates whose Secret does not have the 'cert-manager.io/certificate-revision' "+
+		"annotation, so that it is backfilled. Secrets written by older versions of cert-manager do not have this "+
+		"annotation, so enabling this will cause their Certificates to be reissued.")
+	fs.BoolVar(&s.EnableMultipleLeafCertificatesCheck, "enable-multiple-leaf-certificates-check", defaultEnableMultipleLeafCertificatesCheck, ""+
+		"Whether to reissue Certificates if the 'tls.crt' entry of their Secret contains more than one end-entity "+
+		"certificate, e.g. because several certificates were concatenated by another tool.")
+	fs.BoolVar(&s.EnableChainVerificationCheck, "enable-chain-verification-check", defaultEnableChainVerificationCheck, ""+
+		"Whether to reissue Certificates if the certificate in the 'tls.crt' entry of their Secret does not verify "+
+		"against the CA certificates in its 'ca.crt' entry. Only enable this if all issuers include the full chain "+
+		"up to the certificates in 'ca.crt', otherwise such Certificates may be reissued repeatedly.")
+	fs.BoolVar(&s.EnableMissingSANCheck, "enable-missing-san-check", defaultEnableMissingSANCheck, ""+
+		"Whether to reissue non-CA Certificates with a common name if their issued certificate has no subject "+
+		"alternative name extension. Only enable this if all issuers add a subject alternative name for the common "+
+		"name, otherwise such Certificates will be reissued repeatedly.")
+	fs.BoolVar(&s.EnableUnexpectedCommonNameCheck, "enable-unexpected-common-name-check", defaultEnableUnexpectedCommonNameCheck, ""+
+		"Whether to reissue Certificates without a common name if their issued certificate has a common name. "+
+		"Only enable this if no issuers set the common name to one of the requested DNS names, otherwise such "+
+		"Certificates will be reissued repeatedly.")
+	fs.BoolVar(&s.IgnoreManagedFieldsParseErrors, "ignore-managed-fields-parse-errors", defaultIgnoreManagedFieldsParseErrors, ""+
+		"Whether to treat managed fields entries on Certificates' Secrets that cannot be decoded as empty when checking "+
+		"the Secret against the Certificate's secretTemplate. By default such Secrets are not reconciled until the "+
+		"managed fields are fixed. If enabled, the Secret is reconciled as if the entry did not exist.")
+	fs.StringVar(&s.SecretUpdateStrategy, "secret-update-strategy", defaultSecretUpdateStrategy, ""+
+		"The strategy used to write the Secrets of Certificates, either 'Apply' to use server-side apply or 'Update' "+
+		"to use Create and Update calls. Secrets written with 'Update' do not record which fields are owned by "+
+		"cert-manager, so their managed fields are not checked against the Certificate's secretTemplate.")
+	fs.BoolVar(&s.EnableAuthorityKeyIDCheck, "enable-authority-key-id-check", defaultEnableAuthorityKeyIDCheck, ""+
+		"Whether to reissue Certificates if the authority key ID of their issued certificate differs from the subject "+
+		"key ID of the CA currently used by the Issuer or ClusterIssuer they reference, for example after the CA has "+
+		"been rotated. Only CA issuers expose their CA; Certificates using other issuers are not affected.")
+	fs.BoolVar(&s.EnableCAExpiryCheck, "enable-ca-expiry-check", defaultEnableCAExpiryCheck, ""+
+		"Whether to reissue Certificates whose issued certificate expires after the CA currently used by the Issuer "+
+		"or ClusterIssuer they reference, so that they do not outlive the CA's trust. When enabled, CA issuers also "+
+		"limit the notAfter of the certificates they sign to the notAfter of their CA. Only CA issuers expose their "+
+		"CA; Certificates using other issuers are not affected.")
+	fs.StringVar(&s.RevokedIntermediatesConfigMap, "revoked-intermediates-configmap", defaultRevokedIntermediatesConfigMap, ""+
+		"The <namespace>/<name> of a ConfigMap whose values list the SHA-256 fingerprints of revoked intermediate CA "+
+		"certificates, separated by whitespace. Certificates whose stored chain shows that they were signed by one of "+
+		"these intermediates are reissued. Leave empty (the default) to disable.")
+	fs.StringSliceVar(&s.DisabledTriggerPolicies, "disabled-certificate-trigger-policies", nil, fmt.Sprintf(""+
+		"A list of policies to leave out when deciding whether a Certificate should be issued, for example to "+
+		"temporarily disable a check during a migration. Known policies: %s.",
+		strings.Join(policies.TriggerPolicyNames().List(), ", ")))
+	fs.BoolVar(&s.EnableCertificateUsageCheck, "enable-certificate-usage-check", defaultEnableCertificateUsageCheck, ""+
+		"Whether to mark Certificates as not ready with the reason InvalidUsages if the key usages, extended key usages "+
+		"and basic constraints of their issued certificate contradict each other. Certificates are not reissued, as the "+
+		"spec must be corrected.")
+	fs.IntVar(&s.CertificatePolicyWorkers, "certificate-policy-workers", defaultCertificatePolicyWorkers, ""+
+		"The number of workers of each of the "+trigger.ControllerName+" and "+readiness.ControllerName+" controllers, "+
+		"which evaluate the policies of Certificates. Increase this in clusters with a large number of Certificates if "+
+		"policy evaluation does not keep up with resyncs.")
 	fs.StringSliceVar(&s.CopiedAnnotationPrefixes, "copied-annotation-prefixes", defaultCopiedAnnotationPrefixes, "Specify which annotations should/shouldn't be copied"+
 		"from Certificate to CertificateRequest and Order, as well as from CertificateSigningRequest to Order, by passing a list of annotation key prefixes."+
 		"A prefix starting with a dash(-) specifies an annotation that shouldn't be copied. Example: '*,-kubectl.kuberenetes.io/'- all annotations"+
@@ -345,9 +780,55 @@ func (s *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
 
 	fs.IntVar(&s.MaxConcurrentChallenges, "max-concurrent-challenges", defaultMaxConcurrentChallenges, ""+
 		"The maximum number of challenges that can be scheduled as 'processing' at once.")
+	fs.StringToIntVar(&s.MaxConcurrentChallengesPerIssuer, "max-concurrent-challenges-per-issuer", nil, ""+
+		"The maximum number of challenges that can be scheduled as 'processing' at once for individual issuers, "+
+		"as a comma separated list of key=value pairs. Keys take the form 'ClusterIssuer/<name>' or "+
+		"'Issuer/<namespace>/<name>', for example 'ClusterIssuer/letsencrypt=10'. The limit set by "+
+		"--max-concurrent-challenges applies in addition to these limits.")
+	fs.IntVar(&s.ReservedMustScheduleChallenges, "reserved-must-schedule-challenges", 0, ""+
+		"The number of --max-concurrent-challenges that is reserved for challenges of Certificates annotated with "+
+		"'acme.cert-manager.io/must-schedule: \"true\"'. Other challenges are not scheduled once the rest of the "+
+		"limit is in use, while must-schedule challenges are scheduled first and may use the whole limit.")
+	fs.Float32Var(&s.ChallengeScheduleQPS, "challenge-schedule-qps", 0, ""+
+		"The maximum number of challenges per second that the scheduler marks as 'processing'. Each challenge that "+
+		"is scheduled results in a status update to the Kubernetes apiserver. Zero means no limit.")
+	fs.StringVar(&s.ChallengeMaintenanceWindow, "challenge-maintenance-window", "", ""+
+		"A daily window of the form HH:MM-HH:MM, in UTC, during which only challenges of Certificates annotated with "+
+		"'acme.cert-manager.io/must-schedule: \"true\"' are scheduled, for example '23:00-01:30'. Other challenges "+
+		"are deferred until the window ends. Challenges that are already processing are not affected.")
+	fs.Float32Var(&s.DNS01ProviderQPS, "dns01-provider-qps", 0, ""+
+		"The maximum number of DNS01 records per second that are presented or cleaned up with each DNS provider, "+
+		"to avoid hitting provider rate limits when solving many challenges at once. Zero means no limit.")
+	fs.IntVar(&s.DNS01ProviderBurst, "dns01-provider-burst", defaultDNS01ProviderBurst, ""+
+		"The number of DNS01 records that can be presented or cleaned up with a DNS provider at once before "+
+		"--dns01-provider-qps applies.")
+	fs.StringSliceVar(&s.DNS01ExecProviderCommands, "dns01-exec-provider-commands", []string{}, ""+
+		"A list of absolute paths of executables that Issuers may run on the controller to present and clean up "+
+		"DNS01 records using the exec DNS01 provider. The executables are run with the controller's permissions, "+
+		"so only list executables that are safe for any Issuer to run. The exec provider is disabled if no "+
+		"executables are listed.")
 	fs.DurationVar(&s.DNS01CheckRetryPeriod, "dns01-check-retry-period", defaultDNS01CheckRetryPeriod, ""+
 		"The duration the controller should wait between checking if a ACME dns entry exists."+
 		"This should be a valid duration string, for example 180s or 1h")
+	fs.DurationVar(&s.DNS01CheckInitialDelay, "dns01-check-initial-delay", defaultDNS01CheckInitialDelay, ""+
+		"The duration the controller should wait before checking if a ACME challenge has propagated for the first "+
+		"time after presenting it. Subsequent checks wait for --dns01-check-retry-period. If this is longer than "+
+		"--dns01-check-retry-period, the retry period is used instead. This should be a valid duration string, for example 2s or 1m")
+	fs.DurationVar(&s.ACMEIssuerMaintenanceRetryPeriod, "acme-issuer-maintenance-retry-period", defaultACMEIssuerMaintenanceRetryPeriod, ""+
+		"The duration the controller should wait before processing an ACME challenge again if its issuer has the "+
+		"'acme.cert-manager.io/maintenance: \"true\"' annotation. This should be a valid duration string, for example 180s or 1h")
+	fs.DurationVar(&s.ACMEChallengeProcessingWarningThreshold, "acme-challenge-processing-warning-threshold", defaultACMEChallengeProcessingWarningThreshold, ""+
+		"The duration an ACME challenge may be processing for without reaching a final state before a Warning event "+
+		"is recorded on it. Set to 0 to disable. This should be a valid duration string, for example 30m or 1h")
+	fs.StringVar(&s.ACMEChallengesEventComponent, "acme-challenges-event-component", "", ""+
+		"The source component of events recorded by the ACME challenges controller, for example "+
+		"cert-manager-challenges. If empty, the component of the challenges controller is used.")
+	fs.DurationVar(&s.ACMEChallengeBackoffBaseDelay, "acme-challenge-backoff-base-delay", defaultACMEChallengeBackoffBaseDelay, ""+
+		"The initial delay before retrying an ACME challenge that failed to sync. The delay doubles with each "+
+		"subsequent failure, up to --acme-challenge-backoff-max-delay. This should be a valid duration string, for example 5s or 1m")
+	fs.DurationVar(&s.ACMEChallengeBackoffMaxDelay, "acme-challenge-backoff-max-delay", defaultACMEChallengeBackoffMaxDelay, ""+
+		"The maximum delay before retrying an ACME challenge that failed to sync. This should be a valid duration "+
+		"string, for example 5m or 1h")
 
 	fs.StringVar(&s.MetricsListenAddress, "metrics-listen-address", defaultPrometheusMetricsServerAddress, ""+
 		"The host and port that the metrics endpoint should listen on.")
@@ -365,6 +846,14 @@ func (o *ControllerOptions) Validate() error {
 		return fmt.Errorf("invalid default issuer kind: %v", o.DefaultIssuerKind)
 	}
 
+	switch certificates.SecretUpdateStrategy(o.SecretUpdateStrategy) {
+	case certificates.SecretUpdateStrategyApply:
+	case certificates.SecretUpdateStrategyUpdate:
+	default:
+		return fmt.Errorf("invalid value for secret-update-strategy: %q must be %q or %q", o.SecretUpdateStrategy,
+			certificates.SecretUpdateStrategyApply, certificates.SecretUpdateStrategyUpdate)
+	}
+
 	if o.KubernetesAPIBurst <= 0 {
 		return fmt.Errorf("invalid value for kube-api-burst: %v must be higher than 0", o.KubernetesAPIBurst)
 	}
@@ -377,6 +866,128 @@ func (o *ControllerOptions) Validate() error {
 		return fmt.Errorf("invalid value for kube-api-burst: %v must be higher or equal to kube-api-qps: %v", o.KubernetesAPIQPS, o.KubernetesAPIQPS)
 	}
 
+	if o.SelfSignedSigningQPS < 0 {
+		return fmt.Errorf("invalid value for selfsigned-signing-qps: %v must not be negative", o.SelfSignedSigningQPS)
+	}
+
+	if o.SelfSignedSigningQPS > 0 && o.SelfSignedSigningBurst <= 0 {
+		return fmt.Errorf("invalid value for selfsigned-signing-burst: %v must be higher than 0", o.SelfSignedSigningBurst)
+	}
+
+	if o.CertificateRenewalJitterWindow < 0 {
+		return fmt.Errorf("invalid value for certificate-renewal-jitter-window: %v must not be negative", o.CertificateRenewalJitterWindow)
+	}
+
+	if o.CertificateRenewalGraceTolerance < 0 {
+		return fmt.Errorf("invalid value for certificate-renewal-grace-tolerance: %v must not be negative", o.CertificateRenewalGraceTolerance)
+	}
+
+	if o.CertificateRenewalTimeOfDay != "" {
+		if _, err := time.Parse("15:04", o.CertificateRenewalTimeOfDay); err != nil {
+			return fmt.Errorf("invalid value for certificate-renewal-time-of-day: %v", err)
+		}
+	}
+
+	if o.CertificateResyncExpiryMargin < 0 {
+		return fmt.Errorf("invalid value for certificate-resync-expiry-margin: %v must not be negative", o.CertificateResyncExpiryMargin)
+	}
+
+	if o.CertificateDurationTolerance < 0 {
+		return fmt.Errorf("invalid value for certificate-duration-tolerance: %v must not be negative", o.CertificateDurationTolerance)
+	}
+
+	if o.CertificateMinimumDuration < 0 {
+		return fmt.Errorf("invalid value for certificate-minimum-duration: %v must not be negative", o.CertificateMinimumDuration)
+	}
+
+	if o.CertificateMinimumRSAKeySize != 0 &&
+		(o.CertificateMinimumRSAKeySize < pki.MinRSAKeySize || o.CertificateMinimumRSAKeySize > pki.MaxRSAKeySize) {
+		return fmt.Errorf("invalid value for certificate-minimum-rsa-key-size: %d must be 0 or between %d and %d",
+			o.CertificateMinimumRSAKeySize, pki.MinRSAKeySize, pki.MaxRSAKeySize)
+	}
+
+	if o.CertificateIssuedBeforeCutoff != "" {
+		if _, err := time.Parse(time.RFC3339, o.CertificateIssuedBeforeCutoff); err != nil {
+			return fmt.Errorf("invalid value for certificate-issued-before-cutoff: %v", err)
+		}
+	}
+
+	if o.DNS01CheckInitialDelay < 0 {
+		return fmt.Errorf("invalid value for dns01-check-initial-delay: %v must not be negative", o.DNS01CheckInitialDelay)
+	}
+
+	if o.ACMEIssuerMaintenanceRetryPeriod <= 0 {
+		return fmt.Errorf("invalid value for acme-issuer-maintenance-retry-period: %v must be positive", o.ACMEIssuerMaintenanceRetryPeriod)
+	}
+
+	if o.ACMEChallengeProcessingWarningThreshold < 0 {
+		return fmt.Errorf("invalid value for acme-challenge-processing-warning-threshold: %v must not be negative", o.ACMEChallengeProcessingWarningThreshold)
+	}
+
+	if o.ACMEChallengeBackoffBaseDelay <= 0 {
+		return fmt.Errorf("invalid value for acme-challenge-backoff-base-delay: %v must be positive", o.ACMEChallengeBackoffBaseDelay)
+	}
+
+	if o.ACMEChallengeBackoffMaxDelay < o.ACMEChallengeBackoffBaseDelay {
+		return fmt.Errorf("invalid value for acme-challenge-backoff-max-delay: %v must not be less than acme-challenge-backoff-base-delay (%v)", o.ACMEChallengeBackoffMaxDelay, o.ACMEChallengeBackoffBaseDelay)
+	}
+
+	if o.ChallengeScheduleQPS < 0 {
+		return fmt.Errorf("invalid value for challenge-schedule-qps: %v must not be negative", o.ChallengeScheduleQPS)
+	}
+
+	if o.ChallengeMaintenanceWindow != "" {
+		if _, err := scheduler.ParseMaintenanceWindow(o.ChallengeMaintenanceWindow); err != nil {
+			return fmt.Errorf("invalid value for challenge-maintenance-window: %w", err)
+		}
+	}
+
+	if o.DNS01ProviderQPS < 0 {
+		return fmt.Errorf("invalid value for dns01-provider-qps: %v must not be negative", o.DNS01ProviderQPS)
+	}
+
+	if o.RevokedIntermediatesConfigMap != "" {
+		namespace, name, err := cache.SplitMetaNamespaceKey(o.RevokedIntermediatesConfigMap)
+		if err != nil || namespace == "" || name == "" {
+			return fmt.Errorf("invalid value for revoked-intermediates-configmap: %q must be of the form <namespace>/<name>", o.RevokedIntermediatesConfigMap)
+		}
+	}
+
+	if o.CertificatePolicyWorkers < 1 {
+		return fmt.Errorf("invalid value for certificate-policy-workers: %v must be at least 1", o.CertificatePolicyWorkers)
+	}
+
+	if o.DNS01ProviderBurst < 1 {
+		return fmt.Errorf("invalid value for dns01-provider-burst: %v must be at least 1", o.DNS01ProviderBurst)
+	}
+
+	for _, command := range o.DNS01ExecProviderCommands {
+		if !filepath.IsAbs(command) {
+			return fmt.Errorf("invalid value for dns01-exec-provider-commands: %q must be an absolute path", command)
+		}
+	}
+
+	if o.ReservedMustScheduleChallenges < 0 || o.ReservedMustScheduleChallenges > o.MaxConcurrentChallenges {
+		return fmt.Errorf("invalid value for reserved-must-schedule-challenges: %v must be between 0 and max-concurrent-challenges (%v)", o.ReservedMustScheduleChallenges, o.MaxConcurrentChallenges)
+	}
+
+	for issuer, limit := range o.MaxConcurrentChallengesPerIssuer {
+		if limit < 0 {
+			return fmt.Errorf("invalid value for max-concurrent-challenges-per-issuer: limit %v for %q must not be negative", limit, issuer)
+		}
+	}
+
+	knownTriggerPolicies := policies.TriggerPolicyNames()
+	guardTriggerPolicies := policies.GuardPolicyNames()
+	for _, policy := range o.DisabledTriggerPolicies {
+		if guardTriggerPolicies.Has(policy) {
+			return fmt.Errorf("invalid value for disabled-certificate-trigger-policies: %q cannot be disabled as other policies rely on it", policy)
+		}
+		if !knownTriggerPolicies.Has(policy) {
+			return fmt.Errorf("invalid value for disabled-certificate-trigger-policies: %q is not a known policy", policy)
+		}
+	}
+
 	for _, server := range append(o.DNS01RecursiveNameservers, o.ACMEHTTP01SolverNameservers...) {
 		// ensure all servers have a port number
 		_, _, err := net.SplitHostPort(server)
@@ -405,6 +1016,16 @@ func (o *ControllerOptions) Validate() error {
 	return nil
 }
 
+// Workers returns the number of workers to run for the named controller.
+func (o *ControllerOptions) Workers(controller string) int {
+	switch controller {
+	case trigger.ControllerName, readiness.ControllerName:
+		return o.CertificatePolicyWorkers
+	default:
+		return defaultWorkers
+	}
+}
+
 func (o *ControllerOptions) EnabledControllers() sets.String {
 	var disabled []string
 	enabled := sets.NewString()
@@ -20,6 +20,9 @@ import (
 	"testing"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/cert-manager/cert-manager/pkg/controller/certificates/readiness"
+	"github.com/cert-manager/cert-manager/pkg/controller/certificates/trigger"
 )
 
 func TestEnabledControllers(t *testing.T) {
@@ -63,3 +66,17 @@ func TestEnabledControllers(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkers(t *testing.T) {
+	o := ControllerOptions{CertificatePolicyWorkers: 20}
+
+	for controller, expWorkers := range map[string]int{
+		trigger.ControllerName:   20,
+		readiness.ControllerName: 20,
+		"issuers":                defaultWorkers,
+	} {
+		if got := o.Workers(controller); got != expWorkers {
+			t.Errorf("got unexpected workers for controller %q, exp=%d got=%d", controller, expWorkers, got)
+		}
+	}
+}
@@ -113,6 +113,12 @@ type ChallengeStatus struct {
 	// any more action.
 	Processing bool
 
+	// ProcessingStartTime is the time at which the challenges controller
+	// first observed this challenge with processing set to true.
+	// It is used to detect challenges that have been processing for longer
+	// than expected.
+	ProcessingStartTime *metav1.Time
+
 	// Presented will be set to true if the challenge values for this challenge
 	// are currently 'presented'.
 	// This *does not* imply the self check is passing. Only that the values
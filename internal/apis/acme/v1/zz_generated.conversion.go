@@ -233,6 +233,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1.ACMEIssuerDNS01ProviderExec)(nil), (*acme.ACMEIssuerDNS01ProviderExec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_ACMEIssuerDNS01ProviderExec_To_acme_ACMEIssuerDNS01ProviderExec(a.(*v1.ACMEIssuerDNS01ProviderExec), b.(*acme.ACMEIssuerDNS01ProviderExec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*acme.ACMEIssuerDNS01ProviderExec)(nil), (*v1.ACMEIssuerDNS01ProviderExec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_acme_ACMEIssuerDNS01ProviderExec_To_v1_ACMEIssuerDNS01ProviderExec(a.(*acme.ACMEIssuerDNS01ProviderExec), b.(*v1.ACMEIssuerDNS01ProviderExec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1.ACMEIssuerDNS01ProviderRFC2136)(nil), (*acme.ACMEIssuerDNS01ProviderRFC2136)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_ACMEIssuerDNS01ProviderRFC2136_To_acme_ACMEIssuerDNS01ProviderRFC2136(a.(*v1.ACMEIssuerDNS01ProviderRFC2136), b.(*acme.ACMEIssuerDNS01ProviderRFC2136), scope)
 	}); err != nil {
@@ -480,6 +490,9 @@ func Convert_acme_ACMEChallengeSolver_To_v1_ACMEChallengeSolver(in *acme.ACMECha
 
 func autoConvert_v1_ACMEChallengeSolverDNS01_To_acme_ACMEChallengeSolverDNS01(in *v1.ACMEChallengeSolverDNS01, out *acme.ACMEChallengeSolverDNS01, s conversion.Scope) error {
 	out.CNAMEStrategy = acme.CNAMEStrategy(in.CNAMEStrategy)
+	out.CheckAuthoritativeNameservers = (*bool)(unsafe.Pointer(in.CheckAuthoritativeNameservers))
+	out.RequireAllNameservers = (*bool)(unsafe.Pointer(in.RequireAllNameservers))
+	out.TTL = (*int32)(unsafe.Pointer(in.TTL))
 	if in.Akamai != nil {
 		in, out := &in.Akamai, &out.Akamai
 		*out = new(acme.ACMEIssuerDNS01ProviderAkamai)
@@ -553,6 +566,7 @@ func autoConvert_v1_ACMEChallengeSolverDNS01_To_acme_ACMEChallengeSolverDNS01(in
 		out.RFC2136 = nil
 	}
 	out.Webhook = (*acme.ACMEIssuerDNS01ProviderWebhook)(unsafe.Pointer(in.Webhook))
+	out.Exec = (*acme.ACMEIssuerDNS01ProviderExec)(unsafe.Pointer(in.Exec))
 	return nil
 }
 
@@ -563,6 +577,9 @@ func Convert_v1_ACMEChallengeSolverDNS01_To_acme_ACMEChallengeSolverDNS01(in *v1
 
 func autoConvert_acme_ACMEChallengeSolverDNS01_To_v1_ACMEChallengeSolverDNS01(in *acme.ACMEChallengeSolverDNS01, out *v1.ACMEChallengeSolverDNS01, s conversion.Scope) error {
 	out.CNAMEStrategy = v1.CNAMEStrategy(in.CNAMEStrategy)
+	out.CheckAuthoritativeNameservers = (*bool)(unsafe.Pointer(in.CheckAuthoritativeNameservers))
+	out.RequireAllNameservers = (*bool)(unsafe.Pointer(in.RequireAllNameservers))
+	out.TTL = (*int32)(unsafe.Pointer(in.TTL))
 	if in.Akamai != nil {
 		in, out := &in.Akamai, &out.Akamai
 		*out = new(v1.ACMEIssuerDNS01ProviderAkamai)
@@ -636,6 +653,7 @@ func autoConvert_acme_ACMEChallengeSolverDNS01_To_v1_ACMEChallengeSolverDNS01(in
 		out.RFC2136 = nil
 	}
 	out.Webhook = (*v1.ACMEIssuerDNS01ProviderWebhook)(unsafe.Pointer(in.Webhook))
+	out.Exec = (*v1.ACMEIssuerDNS01ProviderExec)(unsafe.Pointer(in.Exec))
 	return nil
 }
 
@@ -694,6 +712,7 @@ func autoConvert_v1_ACMEChallengeSolverHTTP01Ingress_To_acme_ACMEChallengeSolver
 	out.Name = in.Name
 	out.PodTemplate = (*acme.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
 	out.IngressTemplate = (*acme.ACMEChallengeSolverHTTP01IngressTemplate)(unsafe.Pointer(in.IngressTemplate))
+	out.PathPrefix = in.PathPrefix
 	return nil
 }
 
@@ -708,6 +727,7 @@ func autoConvert_acme_ACMEChallengeSolverHTTP01Ingress_To_v1_ACMEChallengeSolver
 	out.Name = in.Name
 	out.PodTemplate = (*v1.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
 	out.IngressTemplate = (*v1.ACMEChallengeSolverHTTP01IngressTemplate)(unsafe.Pointer(in.IngressTemplate))
+	out.PathPrefix = in.PathPrefix
 	return nil
 }
 
@@ -766,6 +786,8 @@ func autoConvert_v1_ACMEChallengeSolverHTTP01IngressPodSpec_To_acme_ACMEChalleng
 	out.Tolerations = *(*[]corev1.Toleration)(unsafe.Pointer(&in.Tolerations))
 	out.PriorityClassName = in.PriorityClassName
 	out.ServiceAccountName = in.ServiceAccountName
+	out.Resources = (*corev1.ResourceRequirements)(unsafe.Pointer(in.Resources))
+	out.HostNetwork = in.HostNetwork
 	return nil
 }
 
@@ -780,6 +802,8 @@ func autoConvert_acme_ACMEChallengeSolverHTTP01IngressPodSpec_To_v1_ACMEChalleng
 	out.Tolerations = *(*[]corev1.Toleration)(unsafe.Pointer(&in.Tolerations))
 	out.PriorityClassName = in.PriorityClassName
 	out.ServiceAccountName = in.ServiceAccountName
+	out.Resources = (*corev1.ResourceRequirements)(unsafe.Pointer(in.Resources))
+	out.HostNetwork = in.HostNetwork
 	return nil
 }
 
@@ -1170,6 +1194,26 @@ func Convert_acme_ACMEIssuerDNS01ProviderDigitalOcean_To_v1_ACMEIssuerDNS01Provi
 	return autoConvert_acme_ACMEIssuerDNS01ProviderDigitalOcean_To_v1_ACMEIssuerDNS01ProviderDigitalOcean(in, out, s)
 }
 
+func autoConvert_v1_ACMEIssuerDNS01ProviderExec_To_acme_ACMEIssuerDNS01ProviderExec(in *v1.ACMEIssuerDNS01ProviderExec, out *acme.ACMEIssuerDNS01ProviderExec, s conversion.Scope) error {
+	out.Command = in.Command
+	return nil
+}
+
+// Convert_v1_ACMEIssuerDNS01ProviderExec_To_acme_ACMEIssuerDNS01ProviderExec is an autogenerated conversion function.
+func Convert_v1_ACMEIssuerDNS01ProviderExec_To_acme_ACMEIssuerDNS01ProviderExec(in *v1.ACMEIssuerDNS01ProviderExec, out *acme.ACMEIssuerDNS01ProviderExec, s conversion.Scope) error {
+	return autoConvert_v1_ACMEIssuerDNS01ProviderExec_To_acme_ACMEIssuerDNS01ProviderExec(in, out, s)
+}
+
+func autoConvert_acme_ACMEIssuerDNS01ProviderExec_To_v1_ACMEIssuerDNS01ProviderExec(in *acme.ACMEIssuerDNS01ProviderExec, out *v1.ACMEIssuerDNS01ProviderExec, s conversion.Scope) error {
+	out.Command = in.Command
+	return nil
+}
+
+// Convert_acme_ACMEIssuerDNS01ProviderExec_To_v1_ACMEIssuerDNS01ProviderExec is an autogenerated conversion function.
+func Convert_acme_ACMEIssuerDNS01ProviderExec_To_v1_ACMEIssuerDNS01ProviderExec(in *acme.ACMEIssuerDNS01ProviderExec, out *v1.ACMEIssuerDNS01ProviderExec, s conversion.Scope) error {
+	return autoConvert_acme_ACMEIssuerDNS01ProviderExec_To_v1_ACMEIssuerDNS01ProviderExec(in, out, s)
+}
+
 func autoConvert_v1_ACMEIssuerDNS01ProviderRFC2136_To_acme_ACMEIssuerDNS01ProviderRFC2136(in *v1.ACMEIssuerDNS01ProviderRFC2136, out *acme.ACMEIssuerDNS01ProviderRFC2136, s conversion.Scope) error {
 	out.Nameserver = in.Nameserver
 	if err := metav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(&in.TSIGSecret, &out.TSIGSecret, s); err != nil {
@@ -1444,6 +1488,7 @@ func Convert_acme_ChallengeSpec_To_v1_ChallengeSpec(in *acme.ChallengeSpec, out
 
 func autoConvert_v1_ChallengeStatus_To_acme_ChallengeStatus(in *v1.ChallengeStatus, out *acme.ChallengeStatus, s conversion.Scope) error {
 	out.Processing = in.Processing
+	out.ProcessingStartTime = (*pkgapismetav1.Time)(unsafe.Pointer(in.ProcessingStartTime))
 	out.Presented = in.Presented
 	out.Reason = in.Reason
 	out.State = acme.State(in.State)
@@ -1457,6 +1502,7 @@ func Convert_v1_ChallengeStatus_To_acme_ChallengeStatus(in *v1.ChallengeStatus,
 
 func autoConvert_acme_ChallengeStatus_To_v1_ChallengeStatus(in *acme.ChallengeStatus, out *v1.ChallengeStatus, s conversion.Scope) error {
 	out.Processing = in.Processing
+	out.ProcessingStartTime = (*pkgapismetav1.Time)(unsafe.Pointer(in.ProcessingStartTime))
 	out.Presented = in.Presented
 	out.Reason = in.Reason
 	out.State = v1.State(in.State)
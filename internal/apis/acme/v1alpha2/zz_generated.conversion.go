@@ -232,6 +232,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ACMEIssuerDNS01ProviderExec)(nil), (*acme.ACMEIssuerDNS01ProviderExec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_ACMEIssuerDNS01ProviderExec_To_acme_ACMEIssuerDNS01ProviderExec(a.(*ACMEIssuerDNS01ProviderExec), b.(*acme.ACMEIssuerDNS01ProviderExec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*acme.ACMEIssuerDNS01ProviderExec)(nil), (*ACMEIssuerDNS01ProviderExec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_acme_ACMEIssuerDNS01ProviderExec_To_v1alpha2_ACMEIssuerDNS01ProviderExec(a.(*acme.ACMEIssuerDNS01ProviderExec), b.(*ACMEIssuerDNS01ProviderExec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ACMEIssuerDNS01ProviderRFC2136)(nil), (*acme.ACMEIssuerDNS01ProviderRFC2136)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_ACMEIssuerDNS01ProviderRFC2136_To_acme_ACMEIssuerDNS01ProviderRFC2136(a.(*ACMEIssuerDNS01ProviderRFC2136), b.(*acme.ACMEIssuerDNS01ProviderRFC2136), scope)
 	}); err != nil {
@@ -479,6 +489,9 @@ func Convert_acme_ACMEChallengeSolver_To_v1alpha2_ACMEChallengeSolver(in *acme.A
 
 func autoConvert_v1alpha2_ACMEChallengeSolverDNS01_To_acme_ACMEChallengeSolverDNS01(in *ACMEChallengeSolverDNS01, out *acme.ACMEChallengeSolverDNS01, s conversion.Scope) error {
 	out.CNAMEStrategy = acme.CNAMEStrategy(in.CNAMEStrategy)
+	out.CheckAuthoritativeNameservers = (*bool)(unsafe.Pointer(in.CheckAuthoritativeNameservers))
+	out.RequireAllNameservers = (*bool)(unsafe.Pointer(in.RequireAllNameservers))
+	out.TTL = (*int32)(unsafe.Pointer(in.TTL))
 	if in.Akamai != nil {
 		in, out := &in.Akamai, &out.Akamai
 		*out = new(acme.ACMEIssuerDNS01ProviderAkamai)
@@ -552,6 +565,7 @@ func autoConvert_v1alpha2_ACMEChallengeSolverDNS01_To_acme_ACMEChallengeSolverDN
 		out.RFC2136 = nil
 	}
 	out.Webhook = (*acme.ACMEIssuerDNS01ProviderWebhook)(unsafe.Pointer(in.Webhook))
+	out.Exec = (*acme.ACMEIssuerDNS01ProviderExec)(unsafe.Pointer(in.Exec))
 	return nil
 }
 
@@ -562,6 +576,9 @@ func Convert_v1alpha2_ACMEChallengeSolverDNS01_To_acme_ACMEChallengeSolverDNS01(
 
 func autoConvert_acme_ACMEChallengeSolverDNS01_To_v1alpha2_ACMEChallengeSolverDNS01(in *acme.ACMEChallengeSolverDNS01, out *ACMEChallengeSolverDNS01, s conversion.Scope) error {
 	out.CNAMEStrategy = CNAMEStrategy(in.CNAMEStrategy)
+	out.CheckAuthoritativeNameservers = (*bool)(unsafe.Pointer(in.CheckAuthoritativeNameservers))
+	out.RequireAllNameservers = (*bool)(unsafe.Pointer(in.RequireAllNameservers))
+	out.TTL = (*int32)(unsafe.Pointer(in.TTL))
 	if in.Akamai != nil {
 		in, out := &in.Akamai, &out.Akamai
 		*out = new(ACMEIssuerDNS01ProviderAkamai)
@@ -635,6 +652,7 @@ func autoConvert_acme_ACMEChallengeSolverDNS01_To_v1alpha2_ACMEChallengeSolverDN
 		out.RFC2136 = nil
 	}
 	out.Webhook = (*ACMEIssuerDNS01ProviderWebhook)(unsafe.Pointer(in.Webhook))
+	out.Exec = (*ACMEIssuerDNS01ProviderExec)(unsafe.Pointer(in.Exec))
 	return nil
 }
 
@@ -693,6 +711,7 @@ func autoConvert_v1alpha2_ACMEChallengeSolverHTTP01Ingress_To_acme_ACMEChallenge
 	out.Name = in.Name
 	out.PodTemplate = (*acme.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
 	out.IngressTemplate = (*acme.ACMEChallengeSolverHTTP01IngressTemplate)(unsafe.Pointer(in.IngressTemplate))
+	out.PathPrefix = in.PathPrefix
 	return nil
 }
 
@@ -707,6 +726,7 @@ func autoConvert_acme_ACMEChallengeSolverHTTP01Ingress_To_v1alpha2_ACMEChallenge
 	out.Name = in.Name
 	out.PodTemplate = (*ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
 	out.IngressTemplate = (*ACMEChallengeSolverHTTP01IngressTemplate)(unsafe.Pointer(in.IngressTemplate))
+	out.PathPrefix = in.PathPrefix
 	return nil
 }
 
@@ -765,6 +785,8 @@ func autoConvert_v1alpha2_ACMEChallengeSolverHTTP01IngressPodSpec_To_acme_ACMECh
 	out.Tolerations = *(*[]v1.Toleration)(unsafe.Pointer(&in.Tolerations))
 	out.PriorityClassName = in.PriorityClassName
 	out.ServiceAccountName = in.ServiceAccountName
+	out.Resources = (*v1.ResourceRequirements)(unsafe.Pointer(in.Resources))
+	out.HostNetwork = in.HostNetwork
 	return nil
 }
 
@@ -779,6 +801,8 @@ func autoConvert_acme_ACMEChallengeSolverHTTP01IngressPodSpec_To_v1alpha2_ACMECh
 	out.Tolerations = *(*[]v1.Toleration)(unsafe.Pointer(&in.Tolerations))
 	out.PriorityClassName = in.PriorityClassName
 	out.ServiceAccountName = in.ServiceAccountName
+	out.Resources = (*v1.ResourceRequirements)(unsafe.Pointer(in.Resources))
+	out.HostNetwork = in.HostNetwork
 	return nil
 }
 
@@ -1169,6 +1193,26 @@ func Convert_acme_ACMEIssuerDNS01ProviderDigitalOcean_To_v1alpha2_ACMEIssuerDNS0
 	return autoConvert_acme_ACMEIssuerDNS01ProviderDigitalOcean_To_v1alpha2_ACMEIssuerDNS01ProviderDigitalOcean(in, out, s)
 }
 
+func autoConvert_v1alpha2_ACMEIssuerDNS01ProviderExec_To_acme_ACMEIssuerDNS01ProviderExec(in *ACMEIssuerDNS01ProviderExec, out *acme.ACMEIssuerDNS01ProviderExec, s conversion.Scope) error {
+	out.Command = in.Command
+	return nil
+}
+
+// Convert_v1alpha2_ACMEIssuerDNS01ProviderExec_To_acme_ACMEIssuerDNS01ProviderExec is an autogenerated conversion function.
+func Convert_v1alpha2_ACMEIssuerDNS01ProviderExec_To_acme_ACMEIssuerDNS01ProviderExec(in *ACMEIssuerDNS01ProviderExec, out *acme.ACMEIssuerDNS01ProviderExec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ACMEIssuerDNS01ProviderExec_To_acme_ACMEIssuerDNS01ProviderExec(in, out, s)
+}
+
+func autoConvert_acme_ACMEIssuerDNS01ProviderExec_To_v1alpha2_ACMEIssuerDNS01ProviderExec(in *acme.ACMEIssuerDNS01ProviderExec, out *ACMEIssuerDNS01ProviderExec, s conversion.Scope) error {
+	out.Command = in.Command
+	return nil
+}
+
+// Convert_acme_ACMEIssuerDNS01ProviderExec_To_v1alpha2_ACMEIssuerDNS01ProviderExec is an autogenerated conversion function.
+func Convert_acme_ACMEIssuerDNS01ProviderExec_To_v1alpha2_ACMEIssuerDNS01ProviderExec(in *acme.ACMEIssuerDNS01ProviderExec, out *ACMEIssuerDNS01ProviderExec, s conversion.Scope) error {
+	return autoConvert_acme_ACMEIssuerDNS01ProviderExec_To_v1alpha2_ACMEIssuerDNS01ProviderExec(in, out, s)
+}
+
 func autoConvert_v1alpha2_ACMEIssuerDNS01ProviderRFC2136_To_acme_ACMEIssuerDNS01ProviderRFC2136(in *ACMEIssuerDNS01ProviderRFC2136, out *acme.ACMEIssuerDNS01ProviderRFC2136, s conversion.Scope) error {
 	out.Nameserver = in.Nameserver
 	if err := metav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(&in.TSIGSecret, &out.TSIGSecret, s); err != nil {
@@ -1433,6 +1477,7 @@ func autoConvert_acme_ChallengeSpec_To_v1alpha2_ChallengeSpec(in *acme.Challenge
 
 func autoConvert_v1alpha2_ChallengeStatus_To_acme_ChallengeStatus(in *ChallengeStatus, out *acme.ChallengeStatus, s conversion.Scope) error {
 	out.Processing = in.Processing
+	out.ProcessingStartTime = (*pkgapismetav1.Time)(unsafe.Pointer(in.ProcessingStartTime))
 	out.Presented = in.Presented
 	out.Reason = in.Reason
 	out.State = acme.State(in.State)
@@ -1446,6 +1491,7 @@ func Convert_v1alpha2_ChallengeStatus_To_acme_ChallengeStatus(in *ChallengeStatu
 
 func autoConvert_acme_ChallengeStatus_To_v1alpha2_ChallengeStatus(in *acme.ChallengeStatus, out *ChallengeStatus, s conversion.Scope) error {
 	out.Processing = in.Processing
+	out.ProcessingStartTime = (*pkgapismetav1.Time)(unsafe.Pointer(in.ProcessingStartTime))
 	out.Presented = in.Presented
 	out.Reason = in.Reason
 	out.State = State(in.State)
@@ -105,6 +105,21 @@ func (in *ACMEChallengeSolver) DeepCopy() *ACMEChallengeSolver {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ACMEChallengeSolverDNS01) DeepCopyInto(out *ACMEChallengeSolverDNS01) {
 	*out = *in
+	if in.CheckAuthoritativeNameservers != nil {
+		in, out := &in.CheckAuthoritativeNameservers, &out.CheckAuthoritativeNameservers
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RequireAllNameservers != nil {
+		in, out := &in.RequireAllNameservers, &out.RequireAllNameservers
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Akamai != nil {
 		in, out := &in.Akamai, &out.Akamai
 		*out = new(ACMEIssuerDNS01ProviderAkamai)
@@ -150,6 +165,11 @@ func (in *ACMEChallengeSolverDNS01) DeepCopyInto(out *ACMEChallengeSolverDNS01)
 		*out = new(ACMEIssuerDNS01ProviderWebhook)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(ACMEIssuerDNS01ProviderExec)
+		**out = **in
+	}
 	return
 }
 
@@ -325,6 +345,11 @@ func (in *ACMEChallengeSolverHTTP01IngressPodSpec) DeepCopyInto(out *ACMEChallen
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -545,6 +570,22 @@ func (in *ACMEIssuerDNS01ProviderDigitalOcean) DeepCopy() *ACMEIssuerDNS01Provid
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ACMEIssuerDNS01ProviderExec) DeepCopyInto(out *ACMEIssuerDNS01ProviderExec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ACMEIssuerDNS01ProviderExec.
+func (in *ACMEIssuerDNS01ProviderExec) DeepCopy() *ACMEIssuerDNS01ProviderExec {
+	if in == nil {
+		return nil
+	}
+	out := new(ACMEIssuerDNS01ProviderExec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ACMEIssuerDNS01ProviderRFC2136) DeepCopyInto(out *ACMEIssuerDNS01ProviderRFC2136) {
 	*out = *in
@@ -671,7 +712,7 @@ func (in *Challenge) DeepCopyInto(out *Challenge) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -747,6 +788,10 @@ func (in *ChallengeSpec) DeepCopy() *ChallengeSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChallengeStatus) DeepCopyInto(out *ChallengeStatus) {
 	*out = *in
+	if in.ProcessingStartTime != nil {
+		in, out := &in.ProcessingStartTime, &out.ProcessingStartTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -124,6 +124,13 @@ type ChallengeStatus struct {
 	// +optional
 	Processing bool `json:"processing"`
 
+	// processingStartTime is the time at which the challenges controller
+	// first observed this challenge with processing set to true.
+	// It is used to detect challenges that have been processing for longer
+	// than expected.
+	// +optional
+	ProcessingStartTime *metav1.Time `json:"processingStartTime,omitempty"`
+
 	// Presented will be set to true if the challenge values for this challenge
 	// are currently 'presented'.
 	// This *does not* imply the self check is passing. Only that the values
@@ -105,6 +105,13 @@ type CertificateSpec struct {
 	// the way through the certificate's duration.
 	RenewBefore *metav1.Duration
 
+	// RenewBeforePercentage is like RenewBefore, except it is a relative
+	// percentage of the issued certificate's actual duration rather than an
+	// absolute duration. For example, a value of 25 on a certificate that is
+	// valid for 60 minutes will cause it to be renewed 45 minutes after it
+	// was issued. Cannot be set if RenewBefore is set.
+	RenewBeforePercentage *int32
+
 	// DNSNames is a list of DNS subjectAltNames to be set on the Certificate.
 	DNSNames []string
 
@@ -163,6 +163,28 @@ type SelfSignedIssuer struct {
 	// the location of the CRL from which the revocation of this certificate can be checked.
 	// If not set certificate will be issued without CDP. Values are strings.
 	CRLDistributionPoints []string
+
+	// SignatureAlgorithm is the algorithm used to sign certificates issued by
+	// this issuer. It must be compatible with the type of the private key
+	// used for signing. If not set, the default signature algorithm for the
+	// type of private key is used.
+	SignatureAlgorithm string
+
+	// MaxDuration is the maximum duration of certificates issued by this
+	// issuer. Requests for a longer duration are issued with this duration
+	// instead, and a warning event is emitted. If not set, the requested
+	// duration is not limited.
+	MaxDuration *metav1.Duration
+
+	// AdditionalDNSNames are DNS subject alternative names that are added to
+	// every certificate issued by this issuer, in addition to those in the
+	// request. Names that are already requested are not duplicated.
+	AdditionalDNSNames []string
+
+	// AdditionalIPAddresses are IP address subject alternative names that are
+	// added to every certificate issued by this issuer, in addition to those
+	// in the request. Addresses that are already requested are not duplicated.
+	AdditionalIPAddresses []string
 }
 
 // VaultIssuer configures an issuer to sign certificates using a HashiCorp Vault
@@ -810,6 +810,7 @@ func autoConvert_v1_CertificateSpec_To_certmanager_CertificateSpec(in *v1.Certif
 	out.CommonName = in.CommonName
 	out.Duration = (*metav1.Duration)(unsafe.Pointer(in.Duration))
 	out.RenewBefore = (*metav1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.RenewBeforePercentage = (*int32)(unsafe.Pointer(in.RenewBeforePercentage))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	// WARNING: in.URIs requires manual conversion: does not exist in peer-type
@@ -842,6 +843,7 @@ func autoConvert_certmanager_CertificateSpec_To_v1_CertificateSpec(in *certmanag
 	out.CommonName = in.CommonName
 	out.Duration = (*metav1.Duration)(unsafe.Pointer(in.Duration))
 	out.RenewBefore = (*metav1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.RenewBeforePercentage = (*int32)(unsafe.Pointer(in.RenewBeforePercentage))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	// WARNING: in.URISANs requires manual conversion: does not exist in peer-type
@@ -1255,6 +1257,10 @@ func Convert_certmanager_PKCS12Keystore_To_v1_PKCS12Keystore(in *certmanager.PKC
 
 func autoConvert_v1_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *v1.SelfSignedIssuer, out *certmanager.SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.SignatureAlgorithm = in.SignatureAlgorithm
+	out.MaxDuration = (*metav1.Duration)(unsafe.Pointer(in.MaxDuration))
+	out.AdditionalDNSNames = *(*[]string)(unsafe.Pointer(&in.AdditionalDNSNames))
+	out.AdditionalIPAddresses = *(*[]string)(unsafe.Pointer(&in.AdditionalIPAddresses))
 	return nil
 }
 
@@ -1265,6 +1271,10 @@ func Convert_v1_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *v1.SelfSign
 
 func autoConvert_certmanager_SelfSignedIssuer_To_v1_SelfSignedIssuer(in *certmanager.SelfSignedIssuer, out *v1.SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.SignatureAlgorithm = in.SignatureAlgorithm
+	out.MaxDuration = (*metav1.Duration)(unsafe.Pointer(in.MaxDuration))
+	out.AdditionalDNSNames = *(*[]string)(unsafe.Pointer(&in.AdditionalDNSNames))
+	out.AdditionalIPAddresses = *(*[]string)(unsafe.Pointer(&in.AdditionalIPAddresses))
 	return nil
 }
 
@@ -799,6 +799,7 @@ func autoConvert_v1alpha2_CertificateSpec_To_certmanager_CertificateSpec(in *Cer
 	// WARNING: in.Organization requires manual conversion: does not exist in peer-type
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
 	out.RenewBefore = (*v1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.RenewBeforePercentage = (*int32)(unsafe.Pointer(in.RenewBeforePercentage))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
@@ -850,6 +851,7 @@ func autoConvert_certmanager_CertificateSpec_To_v1alpha2_CertificateSpec(in *cer
 	out.CommonName = in.CommonName
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
 	out.RenewBefore = (*v1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.RenewBeforePercentage = (*int32)(unsafe.Pointer(in.RenewBeforePercentage))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
@@ -1271,6 +1273,10 @@ func Convert_certmanager_PKCS12Keystore_To_v1alpha2_PKCS12Keystore(in *certmanag
 
 func autoConvert_v1alpha2_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *SelfSignedIssuer, out *certmanager.SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.SignatureAlgorithm = in.SignatureAlgorithm
+	out.MaxDuration = (*v1.Duration)(unsafe.Pointer(in.MaxDuration))
+	out.AdditionalDNSNames = *(*[]string)(unsafe.Pointer(&in.AdditionalDNSNames))
+	out.AdditionalIPAddresses = *(*[]string)(unsafe.Pointer(&in.AdditionalIPAddresses))
 	return nil
 }
 
@@ -1281,6 +1287,10 @@ func Convert_v1alpha2_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *SelfS
 
 func autoConvert_certmanager_SelfSignedIssuer_To_v1alpha2_SelfSignedIssuer(in *certmanager.SelfSignedIssuer, out *SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.SignatureAlgorithm = in.SignatureAlgorithm
+	out.MaxDuration = (*v1.Duration)(unsafe.Pointer(in.MaxDuration))
+	out.AdditionalDNSNames = *(*[]string)(unsafe.Pointer(&in.AdditionalDNSNames))
+	out.AdditionalIPAddresses = *(*[]string)(unsafe.Pointer(&in.AdditionalIPAddresses))
 	return nil
 }
 
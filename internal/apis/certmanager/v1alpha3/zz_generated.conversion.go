@@ -798,6 +798,7 @@ func autoConvert_v1alpha3_CertificateSpec_To_certmanager_CertificateSpec(in *Cer
 	out.CommonName = in.CommonName
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
 	out.RenewBefore = (*v1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.RenewBeforePercentage = (*int32)(unsafe.Pointer(in.RenewBeforePercentage))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
@@ -849,6 +850,7 @@ func autoConvert_certmanager_CertificateSpec_To_v1alpha3_CertificateSpec(in *cer
 	out.CommonName = in.CommonName
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
 	out.RenewBefore = (*v1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.RenewBeforePercentage = (*int32)(unsafe.Pointer(in.RenewBeforePercentage))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
@@ -1270,6 +1272,10 @@ func Convert_certmanager_PKCS12Keystore_To_v1alpha3_PKCS12Keystore(in *certmanag
 
 func autoConvert_v1alpha3_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *SelfSignedIssuer, out *certmanager.SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.SignatureAlgorithm = in.SignatureAlgorithm
+	out.MaxDuration = (*v1.Duration)(unsafe.Pointer(in.MaxDuration))
+	out.AdditionalDNSNames = *(*[]string)(unsafe.Pointer(&in.AdditionalDNSNames))
+	out.AdditionalIPAddresses = *(*[]string)(unsafe.Pointer(&in.AdditionalIPAddresses))
 	return nil
 }
 
@@ -1280,6 +1286,10 @@ func Convert_v1alpha3_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *SelfS
 
 func autoConvert_certmanager_SelfSignedIssuer_To_v1alpha3_SelfSignedIssuer(in *certmanager.SelfSignedIssuer, out *SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.SignatureAlgorithm = in.SignatureAlgorithm
+	out.MaxDuration = (*v1.Duration)(unsafe.Pointer(in.MaxDuration))
+	out.AdditionalDNSNames = *(*[]string)(unsafe.Pointer(&in.AdditionalDNSNames))
+	out.AdditionalIPAddresses = *(*[]string)(unsafe.Pointer(&in.AdditionalIPAddresses))
 	return nil
 }
 
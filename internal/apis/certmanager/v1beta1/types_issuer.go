@@ -181,6 +181,33 @@ type SelfSignedIssuer struct {
 	// If not set certificate will be issued without CDP. Values are strings.
 	// +optional
 	CRLDistributionPoints []string `json:"crlDistributionPoints,omitempty"`
+
+	// SignatureAlgorithm is the algorithm used to sign certificates issued by
+	// this issuer. It must be compatible with the type of the private key
+	// used for signing. If not set, the default signature algorithm for the
+	// type of private key is used.
+	// +optional
+	// +kubebuilder:validation:Enum=SHA256WithRSA;SHA384WithRSA;SHA512WithRSA;ECDSAWithSHA256;ECDSAWithSHA384;ECDSAWithSHA512;PureEd25519
+	SignatureAlgorithm string `json:"signatureAlgorithm,omitempty"`
+
+	// MaxDuration is the maximum duration of certificates issued by this
+	// issuer. Requests for a longer duration are issued with this duration
+	// instead, and a warning event is emitted. If not set, the requested
+	// duration is not limited.
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+
+	// AdditionalDNSNames are DNS subject alternative names that are added to
+	// every certificate issued by this issuer, in addition to those in the
+	// request. Names that are already requested are not duplicated.
+	// +optional
+	AdditionalDNSNames []string `json:"additionalDNSNames,omitempty"`
+
+	// AdditionalIPAddresses are IP address subject alternative names that are
+	// added to every certificate issued by this issuer, in addition to those
+	// in the request. Addresses that are already requested are not duplicated.
+	// +optional
+	AdditionalIPAddresses []string `json:"additionalIPAddresses,omitempty"`
 }
 
 // Configures an issuer to sign certificates using a HashiCorp Vault
@@ -808,6 +808,7 @@ func autoConvert_v1beta1_CertificateSpec_To_certmanager_CertificateSpec(in *Cert
 	out.CommonName = in.CommonName
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
 	out.RenewBefore = (*v1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.RenewBeforePercentage = (*int32)(unsafe.Pointer(in.RenewBeforePercentage))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
@@ -845,6 +846,7 @@ func autoConvert_certmanager_CertificateSpec_To_v1beta1_CertificateSpec(in *cert
 	out.CommonName = in.CommonName
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
 	out.RenewBefore = (*v1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.RenewBeforePercentage = (*int32)(unsafe.Pointer(in.RenewBeforePercentage))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
@@ -1263,6 +1265,10 @@ func Convert_certmanager_PKCS12Keystore_To_v1beta1_PKCS12Keystore(in *certmanage
 
 func autoConvert_v1beta1_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *SelfSignedIssuer, out *certmanager.SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.SignatureAlgorithm = in.SignatureAlgorithm
+	out.MaxDuration = (*v1.Duration)(unsafe.Pointer(in.MaxDuration))
+	out.AdditionalDNSNames = *(*[]string)(unsafe.Pointer(&in.AdditionalDNSNames))
+	out.AdditionalIPAddresses = *(*[]string)(unsafe.Pointer(&in.AdditionalIPAddresses))
 	return nil
 }
 
@@ -1273,6 +1279,10 @@ func Convert_v1beta1_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *SelfSi
 
 func autoConvert_certmanager_SelfSignedIssuer_To_v1beta1_SelfSignedIssuer(in *certmanager.SelfSignedIssuer, out *SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.SignatureAlgorithm = in.SignatureAlgorithm
+	out.MaxDuration = (*v1.Duration)(unsafe.Pointer(in.MaxDuration))
+	out.AdditionalDNSNames = *(*[]string)(unsafe.Pointer(&in.AdditionalDNSNames))
+	out.AdditionalIPAddresses = *(*[]string)(unsafe.Pointer(&in.AdditionalIPAddresses))
 	return nil
 }
 
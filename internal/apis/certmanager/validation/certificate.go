@@ -81,7 +81,7 @@ func ValidateCertificateSpec(crt *internalcmapi.CertificateSpec, fldPath *field.
 		}
 	}
 
-	if crt.Duration != nil || crt.RenewBefore != nil {
+	if crt.Duration != nil || crt.RenewBefore != nil || crt.RenewBeforePercentage != nil {
 		el = append(el, ValidateDuration(crt, fldPath)...)
 	}
 	if len(crt.Usages) > 0 {
@@ -212,6 +212,15 @@ func ValidateDuration(crt *internalcmapi.CertificateSpec, fldPath *field.Path) f
 	if crt.RenewBefore != nil && crt.RenewBefore.Duration >= duration {
 		el = append(el, field.Invalid(fldPath.Child("renewBefore"), crt.RenewBefore.Duration, fmt.Sprintf("certificate duration %s must be greater than renewBefore %s", duration, crt.RenewBefore.Duration)))
 	}
+	// spec.renewBefore and spec.renewBeforePercentage are mutually exclusive.
+	if crt.RenewBefore != nil && crt.RenewBeforePercentage != nil {
+		el = append(el, field.Invalid(fldPath.Child("renewBeforePercentage"), *crt.RenewBeforePercentage, "renewBefore and renewBeforePercentage are mutually exclusive and cannot both be set"))
+	}
+	// If spec.renewBeforePercentage is set, it must be a percentage in the
+	// range (0,100).
+	if crt.RenewBeforePercentage != nil && (*crt.RenewBeforePercentage <= 0 || *crt.RenewBeforePercentage >= 100) {
+		el = append(el, field.Invalid(fldPath.Child("renewBeforePercentage"), *crt.RenewBeforePercentage, "renewBeforePercentage must be a value between 0 and 100 (exclusive)"))
+	}
 	return el
 }
 
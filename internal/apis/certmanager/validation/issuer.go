@@ -19,6 +19,8 @@ package validation
 import (
 	"crypto/x509"
 	"fmt"
+	"net"
+	"path/filepath"
 	"strings"
 
 	admissionv1 "k8s.io/api/admission/v1"
@@ -30,6 +32,7 @@ import (
 	"github.com/cert-manager/cert-manager/internal/apis/certmanager"
 	"github.com/cert-manager/cert-manager/internal/apis/certmanager/validation/util"
 	cmmeta "github.com/cert-manager/cert-manager/internal/apis/meta"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
 )
 
 // Validation functions for cert-manager Issuer types.
@@ -189,6 +192,9 @@ func ValidateACMEIssuerChallengeSolverHTTP01IngressConfig(ingress *cmacme.ACMECh
 	default:
 		el = append(el, field.Invalid(fldPath.Child("serviceType"), ingress.ServiceType, `must be empty, "ClusterIP" or "NodePort"`))
 	}
+	if len(ingress.PathPrefix) > 0 && (!strings.HasPrefix(ingress.PathPrefix, "/") || strings.HasSuffix(ingress.PathPrefix, "/")) {
+		el = append(el, field.Invalid(fldPath.Child("pathPrefix"), ingress.PathPrefix, "must start with a '/' and must not end with one"))
+	}
 
 	return el
 }
@@ -221,7 +227,29 @@ func ValidateCAIssuerConfig(iss *certmanager.CAIssuer, fldPath *field.Path) fiel
 }
 
 func ValidateSelfSignedIssuerConfig(iss *certmanager.SelfSignedIssuer, fldPath *field.Path) field.ErrorList {
-	return nil
+	el := field.ErrorList{}
+	if len(iss.SignatureAlgorithm) > 0 {
+		supported := pki.SignatureAlgorithmNames()
+		found := false
+		for _, name := range supported {
+			if name == iss.SignatureAlgorithm {
+				found = true
+				break
+			}
+		}
+		if !found {
+			el = append(el, field.NotSupported(fldPath.Child("signatureAlgorithm"), iss.SignatureAlgorithm, supported))
+		}
+	}
+	if iss.MaxDuration != nil && iss.MaxDuration.Duration <= 0 {
+		el = append(el, field.Invalid(fldPath.Child("maxDuration"), iss.MaxDuration.Duration.String(), "must be greater than zero"))
+	}
+	for i, ip := range iss.AdditionalIPAddresses {
+		if net.ParseIP(ip) == nil {
+			el = append(el, field.Invalid(fldPath.Child("additionalIPAddresses").Index(i), ip, "must be a valid IP address"))
+		}
+	}
+	return el
 }
 
 func ValidateVaultIssuerConfig(iss *certmanager.VaultIssuer, fldPath *field.Path) field.ErrorList {
@@ -303,6 +331,9 @@ func ValidateACMEChallengeSolverDNS01(p *cmacme.ACMEChallengeSolverDNS01, fldPat
 			el = append(el, field.Invalid(fldPath.Child("cnameStrategy"), p.CNAMEStrategy, fmt.Sprintf("must be one of %q or %q", cmacme.NoneStrategy, cmacme.FollowStrategy)))
 		}
 	}
+	if p.TTL != nil && *p.TTL <= 0 {
+		el = append(el, field.Invalid(fldPath.Child("ttl"), *p.TTL, "must be greater than zero"))
+	}
 	numProviders := 0
 	if p.Akamai != nil {
 		numProviders++
@@ -468,6 +499,18 @@ func ValidateACMEChallengeSolverDNS01(p *cmacme.ACMEChallengeSolverDNS01, fldPat
 			}
 		}
 	}
+	if p.Exec != nil {
+		if numProviders > 0 {
+			el = append(el, field.Forbidden(fldPath.Child("exec"), "may not specify more than one provider type"))
+		} else {
+			numProviders++
+			if len(p.Exec.Command) == 0 {
+				el = append(el, field.Required(fldPath.Child("exec", "command"), ""))
+			} else if !filepath.IsAbs(p.Exec.Command) {
+				el = append(el, field.Invalid(fldPath.Child("exec", "command"), p.Exec.Command, "must be an absolute path"))
+			}
+		}
+	}
 	if numProviders == 0 {
 		el = append(el, field.Required(fldPath, "no DNS01 provider configured"))
 	}
@@ -19,11 +19,14 @@ package validation
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 
 	cmacme "github.com/cert-manager/cert-manager/internal/apis/acme"
 	cmapi "github.com/cert-manager/cert-manager/internal/apis/certmanager"
@@ -431,6 +434,65 @@ func TestValidateIssuerSpec(t *testing.T) {
 			},
 			errs: []*field.Error{},
 		},
+		"valid self signed issuer with signature algorithm": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{SignatureAlgorithm: "SHA384WithRSA"},
+				},
+			},
+			errs: []*field.Error{},
+		},
+		"self signed issuer with unsupported signature algorithm": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{SignatureAlgorithm: "MD5WithRSA"},
+				},
+			},
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("selfSigned", "signatureAlgorithm"), "MD5WithRSA", []string{
+					"ECDSAWithSHA256", "ECDSAWithSHA384", "ECDSAWithSHA512", "PureEd25519", "SHA256WithRSA", "SHA384WithRSA", "SHA512WithRSA",
+				}),
+			},
+		},
+		"valid self signed issuer with max duration": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{MaxDuration: &metav1.Duration{Duration: time.Hour}},
+				},
+			},
+			errs: []*field.Error{},
+		},
+		"self signed issuer with non-positive max duration": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{MaxDuration: &metav1.Duration{}},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("selfSigned", "maxDuration"), "0s", "must be greater than zero"),
+			},
+		},
+		"valid self signed issuer with additional SANs": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{
+						AdditionalDNSNames:    []string{"pod.internal"},
+						AdditionalIPAddresses: []string{"10.0.0.1", "::1"},
+					},
+				},
+			},
+			errs: []*field.Error{},
+		},
+		"self signed issuer with an invalid additional IP address": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{AdditionalIPAddresses: []string{"10.0.0.1", "not-an-ip"}},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("selfSigned", "additionalIPAddresses").Index(1), "not-an-ip", "must be a valid IP address"),
+			},
+		},
 		"valid acme issuer": {
 			spec: &cmapi.IssuerSpec{
 				IssuerConfig: cmapi.IssuerConfig{
@@ -572,6 +634,33 @@ func TestValidateACMEIssuerHTTP01Config(t *testing.T) {
 				field.Invalid(fldPath.Child("ingress", "serviceType"), corev1.ServiceType("InvalidServiceType"), `must be empty, "ClusterIP" or "NodePort"`),
 			},
 		},
+		"acme issuer with valid http01 ingress path prefix": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{
+					PathPrefix: "/prefix",
+				},
+			},
+		},
+		"acme issuer with http01 ingress path prefix not starting with a slash": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{
+					PathPrefix: "prefix",
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("ingress", "pathPrefix"), "prefix", "must start with a '/' and must not end with one"),
+			},
+		},
+		"acme issuer with http01 ingress path prefix ending with a slash": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{
+					PathPrefix: "/prefix/",
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("ingress", "pathPrefix"), "/prefix/", "must start with a '/' and must not end with one"),
+			},
+		},
 	}
 	for n, s := range scenarios {
 		t.Run(n, func(t *testing.T) {
@@ -1113,6 +1202,50 @@ func TestValidateACMEIssuerDNS01Config(t *testing.T) {
 				field.Forbidden(fldPath.Child("cloudflare"), "may not specify more than one provider type"),
 			},
 		},
+		"valid exec provider": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				Exec: &cmacme.ACMEIssuerDNS01ProviderExec{
+					Command: "/usr/local/bin/dns-hook",
+				},
+			},
+		},
+		"exec provider missing command": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				Exec: &cmacme.ACMEIssuerDNS01ProviderExec{},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("exec", "command"), ""),
+			},
+		},
+		"exec provider command is not an absolute path": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				Exec: &cmacme.ACMEIssuerDNS01ProviderExec{
+					Command: "dns-hook",
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("exec", "command"), "dns-hook", "must be an absolute path"),
+			},
+		},
+		"valid ttl": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				TTL: pointer.Int32(30),
+				CloudDNS: &cmacme.ACMEIssuerDNS01ProviderCloudDNS{
+					Project: "valid",
+				},
+			},
+		},
+		"ttl must be greater than zero": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				TTL: pointer.Int32(0),
+				CloudDNS: &cmacme.ACMEIssuerDNS01ProviderCloudDNS{
+					Project: "valid",
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("ttl"), int32(0), "must be greater than zero"),
+			},
+		},
 	}
 	for n, s := range scenarios {
 		t.Run(n, func(t *testing.T) {
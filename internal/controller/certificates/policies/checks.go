@@ -18,25 +18,83 @@ package policies
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/utils/clock"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
 
 	internalcertificates "github.com/cert-manager/cert-manager/internal/controller/certificates"
+	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	"github.com/cert-manager/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/cert-manager/cert-manager/pkg/controller/certificates"
+	"github.com/cert-manager/cert-manager/pkg/issuer"
 	"github.com/cert-manager/cert-manager/pkg/util/pki"
 )
 
+// IssuerDoesNotExist returns a policy function that checks whether the Issuer
+// or ClusterIssuer referenced by the Certificate's spec.issuerRef exists.
+// References to issuers in other API groups (i.e. external issuers) are not
+// checked, as cert-manager has no way to look these up.
+func IssuerDoesNotExist(helper issuer.Helper) Func {
+	return func(input Input) (string, string, bool) {
+		ref := input.Certificate.Spec.IssuerRef
+		if ref.Group != "" && ref.Group != certmanager.GroupName {
+			return "", "", false
+		}
+
+		_, err := helper.GetGenericIssuer(ref, input.Certificate.Namespace)
+		if !apierrors.IsNotFound(err) {
+			// Any other error (e.g. an invalid kind) is left to be surfaced
+			// by the controllers that actually use the issuer.
+			return "", "", false
+		}
+
+		kind := ref.Kind
+		if kind == "" {
+			kind = cmapi.IssuerKind
+		}
+		return IssuerNotFound, fmt.Sprintf("Referenced %s %q does not exist", kind, ref.Name), true
+	}
+}
+
+// DurationBelowMinimum returns a policy function that checks whether the
+// Certificate's spec.duration is shorter than opts.MinimumDuration. Issuers
+// reject such requests, so rather than repeatedly failing issuance the
+// violation tells the user how to correct the spec.
+func DurationBelowMinimum(opts TriggerPolicyOptions) Func {
+	return func(input Input) (string, string, bool) {
+		duration := cmapi.DefaultCertificateDuration
+		if input.Certificate.Spec.Duration != nil {
+			duration = input.Certificate.Spec.Duration.Duration
+		}
+		if duration >= opts.MinimumDuration {
+			return "", "", false
+		}
+
+		return DurationTooShort, fmt.Sprintf("spec.duration of %s is shorter than the minimum duration of %s accepted by the issuer; increase spec.duration to at least %s",
+			duration, opts.MinimumDuration, opts.MinimumDuration), true
+	}
+}
+
 func SecretDoesNotExist(input Input) (string, string, bool) {
 	if input.Secret == nil {
 		return DoesNotExist, "Issuing certificate as Secret does not exist", true
@@ -44,6 +102,68 @@ func SecretDoesNotExist(input Input) (string, string, bool) {
 	return "", "", false
 }
 
+// SecretIsTerminating reports a SecretTerminating violation if the Secret has
+// a deletionTimestamp set. Writing a newly issued certificate to the Secret
+// would be lost once the Secret is removed, so issuance should wait until the
+// Secret has been deleted, at which point SecretDoesNotExist applies.
+func SecretIsTerminating(input Input) (string, string, bool) {
+	if input.Secret.DeletionTimestamp != nil {
+		return SecretTerminating, fmt.Sprintf("Secret %q is being deleted, waiting for it to be removed before issuing", input.Secret.Name), true
+	}
+	return "", "", false
+}
+
+// SecretIsImmutable reports a SecretImmutable violation if the Secret is
+// immutable. Its data can never be updated, so issuing a certificate would
+// only have it signed and thrown away; the Secret has to be deleted or
+// spec.secretName changed first.
+func SecretIsImmutable(input Input) (string, string, bool) {
+	if input.Secret.Immutable != nil && *input.Secret.Immutable {
+		return SecretImmutable, fmt.Sprintf("Secret %q is immutable and cannot be updated with an issued certificate. "+
+			"Delete the Secret so that it can be recreated, or set spec.secretName to a different Secret", input.Secret.Name), true
+	}
+	return "", "", false
+}
+
+// SecretIsNotManaged reports a SecretNotManaged violation if the Secret
+// contains data but was not created by cert-manager, i.e. it has none of the
+// annotations that cert-manager sets on the Secrets it manages. This prevents
+// a Certificate whose spec.secretName collides with an unrelated Secret from
+// overwriting it. Users can allow the Secret to be adopted by annotating it
+// with cert-manager.io/allow-adoption: "true".
+func SecretIsNotManaged(input Input) (string, string, bool) {
+	if len(input.Secret.Data) == 0 {
+		return "", "", false
+	}
+	annotations := input.Secret.Annotations
+	if annotations[cmapi.AllowSecretAdoptionAnnotationKey] == "true" {
+		return "", "", false
+	}
+	for _, key := range []string{cmapi.CertificateNameKey, cmapi.IssuerNameAnnotationKey} {
+		if _, ok := annotations[key]; ok {
+			return "", "", false
+		}
+	}
+	return SecretNotManaged, fmt.Sprintf("Secret %q was not created by cert-manager, refusing to overwrite it. "+
+		"Annotate the Secret with %s: \"true\" to allow it to be adopted", input.Secret.Name, cmapi.AllowSecretAdoptionAnnotationKey), true
+}
+
+// SecretHasWrongType reports a WrongSecretType violation if the Secret is not
+// of type kubernetes.io/tls and is missing the private key or certificate.
+// This is most likely caused by the Secret having been created beforehand,
+// and gives a clearer reason than MissingData. Secrets of another type which
+// do contain a private key and certificate are supported, as cert-manager
+// preserves the type of existing Secrets.
+func SecretHasWrongType(input Input) (string, string, bool) {
+	if input.Secret.Type == "" || input.Secret.Type == corev1.SecretTypeTLS {
+		return "", "", false
+	}
+	if len(input.Secret.Data[corev1.TLSPrivateKeyKey]) > 0 && len(input.Secret.Data[corev1.TLSCertKey]) > 0 {
+		return "", "", false
+	}
+	return WrongSecretType, fmt.Sprintf("Issuing certificate as Secret is of type %q rather than %q and does not contain a private key and certificate", input.Secret.Type, corev1.SecretTypeTLS), true
+}
+
 func SecretIsMissingData(input Input) (string, string, bool) {
 	if input.Secret.Data == nil {
 		return MissingData, "Issuing certificate as Secret does not contain any data", true
@@ -56,9 +176,127 @@ func SecretIsMissingData(input Input) (string, string, bool) {
 	if len(certData) == 0 {
 		return MissingData, "Issuing certificate as Secret does not contain a certificate", true
 	}
+	for _, key := range requiredSecretKeys(input.Certificate) {
+		if len(input.Secret.Data[key]) == 0 {
+			return MissingData, fmt.Sprintf("Issuing certificate as Secret does not contain required key %q", key), true
+		}
+	}
+	return "", "", false
+}
+
+// requiredSecretKeys returns the additional Secret data keys listed in the
+// Certificate's required-secret-keys annotation, if any.
+func requiredSecretKeys(crt *cmapi.Certificate) []string {
+	if crt == nil {
+		return nil
+	}
+	value, ok := crt.Annotations[cmapi.RequiredSecretKeysAnnotation]
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); len(key) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// SecretHasMultipleLeafCertificates is violated when the tls.crt entry of the
+// Secret contains more than one end-entity (non-CA) certificate, for example
+// because a tool concatenated several leaf certificates. Only the first
+// certificate is compared against the Certificate by the other policies, so
+// such a Secret is reissued. A single leaf certificate followed by its CA
+// chain is valid. Data that cannot be decoded is reported by
+// SecretPublicKeysDiffer.
+func SecretHasMultipleLeafCertificates(input Input) (string, string, bool) {
+	certs, err := pki.DecodeX509CertificateChainBytes(input.Secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return "", "", false
+	}
+	leaves := 0
+	for _, cert := range certs {
+		if !cert.IsCA {
+			leaves++
+		}
+	}
+	if leaves > 1 {
+		return InvalidCertificate, fmt.Sprintf("Issuing certificate as Secret %q contains %d end-entity certificates rather than a single certificate optionally followed by its CA chain", corev1.TLSCertKey, leaves), true
+	}
 	return "", "", false
 }
 
+// SecretChainDoesNotVerify reports an IncompleteChain violation if the
+// certificate in the Secret's tls.crt cannot be verified against the CA
+// certificates in its ca.crt, using the remaining certificates in tls.crt as
+// intermediates. Clients trusting ca.crt would otherwise fail to establish
+// TLS connections, e.g. after one of the keys was edited by hand. The policy
+// only applies when ca.crt is present. The chain is verified at the time the
+// certificate became valid, as expiry is checked by other policies.
+// Failures that do not show the Secret to be broken are ignored, see
+// chainVerificationIsInconclusive.
+func SecretChainDoesNotVerify(input Input) (string, string, bool) {
+	caData := input.Secret.Data[cmmeta.TLSCAKey]
+	if len(caData) == 0 {
+		return "", "", false
+	}
+	certs, err := pki.DecodeX509CertificateChainBytes(input.Secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return "", "", false
+	}
+	cas, err := pki.DecodeX509CertificateChainBytes(caData)
+	if err != nil {
+		return IncompleteChain, fmt.Sprintf("Issuing certificate as Secret %q could not be decoded: %v", cmmeta.TLSCAKey, err), true
+	}
+
+	roots := x509.NewCertPool()
+	for _, ca := range cas {
+		roots.AddCert(ca)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   certs[0].NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil && !chainVerificationIsInconclusive(err, certs, cas) {
+		return IncompleteChain, fmt.Sprintf("Issuing certificate as Secret %q does not verify against %q: %v", corev1.TLSCertKey, cmmeta.TLSCAKey, err), true
+	}
+	return "", "", false
+}
+
+// chainVerificationIsInconclusive returns true if err, returned when
+// verifying the first of certs against cas, is not caused by the Secret being
+// broken. This is the case if tls.crt only holds a certificate that is not
+// self-signed and ca.crt does not contain its issuer, e.g. because ca.crt only
+// holds the root CA and the intermediates are distributed separately, or if
+// any of the CA certificates does not have the basic constraints extension, as
+// is the case for v1 and other legacy CA certificates.
+func chainVerificationIsInconclusive(err error, certs, cas []*x509.Certificate) bool {
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	if !errors.As(err, &unknownAuthorityErr) {
+		return false
+	}
+	for _, ca := range append(append([]*x509.Certificate{}, certs[1:]...), cas...) {
+		if !ca.BasicConstraintsValid {
+			return true
+		}
+	}
+	if len(certs) > 1 || bytes.Equal(certs[0].RawIssuer, certs[0].RawSubject) {
+		return false
+	}
+	for _, ca := range cas {
+		if bytes.Equal(certs[0].RawIssuer, ca.RawSubject) {
+			return false
+		}
+	}
+	return true
+}
+
 func SecretPublicKeysDiffer(input Input) (string, string, bool) {
 	pkData := input.Secret.Data[corev1.TLSPrivateKeyKey]
 	certData := input.Secret.Data[corev1.TLSCertKey]
@@ -71,6 +309,42 @@ func SecretPublicKeysDiffer(input Input) (string, string, bool) {
 	return "", "", false
 }
 
+// issuedCertificate decodes the certificate stored in the tls.crt key of the
+// Secret for policies that inspect it. It returns false if the data cannot be
+// decoded, in which case the policy should not be violated: missing or
+// invalid certificate data is reported by SecretIsMissingData and
+// SecretPublicKeysDiffer earlier in the chain.
+func issuedCertificate(input Input) (*x509.Certificate, bool) {
+	cert, err := pki.DecodeX509CertificateBytes(input.Secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, false
+	}
+	return cert, true
+}
+
+// SecretAdditionalOutputFormatsMismatch reports a MissingData violation if the
+// Secret does not contain the data for each of the Certificate's
+// spec.additionalOutputFormats, or if that data was not derived from the
+// private key and certificate currently stored in the Secret.
+func SecretAdditionalOutputFormatsMismatch(input Input) (string, string, bool) {
+	for _, format := range input.Certificate.Spec.AdditionalOutputFormats {
+		key, expected, err := internalcertificates.AdditionalOutputFormatData(format.Type, input.Secret.Data[corev1.TLSPrivateKeyKey], input.Secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			// An invalid key pair is reported by SecretPublicKeysDiffer, and
+			// unknown formats are rejected by validation.
+			continue
+		}
+		actual, ok := input.Secret.Data[key]
+		if !ok {
+			return MissingData, fmt.Sprintf("Issuing certificate as Secret does not contain %s output format data under key %q", format.Type, key), true
+		}
+		if !bytes.Equal(actual, expected) {
+			return MissingData, fmt.Sprintf("Issuing certificate as Secret %s output format data under key %q does not match the stored certificate and private key", format.Type, key), true
+		}
+	}
+	return "", "", false
+}
+
 func SecretPrivateKeyMatchesSpec(input Input) (string, string, bool) {
 	if input.Secret.Data == nil || len(input.Secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
 		return SecretMismatch, "Existing issued Secret does not contain private key data", true
@@ -86,12 +360,167 @@ func SecretPrivateKeyMatchesSpec(input Input) (string, string, bool) {
 	if err != nil {
 		return SecretMismatch, fmt.Sprintf("Failed to check private key is up to date: %v", err), true
 	}
+	if !privateKeyEncodingMatchesSpec(pkBytes, input.Certificate.Spec) {
+		violations = append(violations, "spec.privateKey.encoding")
+	}
 	if len(violations) > 0 {
 		return SecretMismatch, fmt.Sprintf("Existing private key is not up to date for spec: %v", violations), true
 	}
 	return "", "", false
 }
 
+// SecretPrivateKeyBelowMinimumSize returns a policy function that is violated
+// when the RSA private key stored in the Secret is smaller than
+// opts.MinimumRSAKeySize, regardless of the Certificate's
+// spec.privateKey.size. This allows a minimum key size to be enforced on
+// existing certificates whose spec has not been updated. Other key
+// algorithms are not checked.
+func SecretPrivateKeyBelowMinimumSize(opts TriggerPolicyOptions) Func {
+	return func(input Input) (string, string, bool) {
+		pk, err := pki.DecodePrivateKeyBytes(input.Secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			// Invalid private key data is handled by other policies.
+			return "", "", false
+		}
+		rsaPk, ok := pk.(*rsa.PrivateKey)
+		if !ok {
+			return "", "", false
+		}
+		if size := rsaPk.N.BitLen(); size < opts.MinimumRSAKeySize {
+			return SecretMismatch, fmt.Sprintf("Issuing certificate as the existing RSA private key size of %d bits is below the minimum of %d bits", size, opts.MinimumRSAKeySize), true
+		}
+		return "", "", false
+	}
+}
+
+// privateKeyEncodingMatchesSpec returns false if the Certificate requests
+// PKCS#8 encoding but the given PEM encoded private key is not stored in
+// PKCS#8 form, for example because it was issued before spec.privateKey.encoding
+// was changed.
+func privateKeyEncodingMatchesSpec(pkBytes []byte, spec cmapi.CertificateSpec) bool {
+	if spec.PrivateKey == nil || spec.PrivateKey.Encoding != cmapi.PKCS8 {
+		return true
+	}
+	block, _ := pem.Decode(pkBytes)
+	return block != nil && block.Type == "PRIVATE KEY"
+}
+
+// SecretPrivateKeyReused is violated when the Certificate's
+// spec.privateKey.rotationPolicy is Always but the private key stored in the
+// Secret is the same as the one used by the previous revision's
+// CertificateRequest, for example because the Secret's contents were copied
+// from elsewhere. Keys are compared using a hash of their public key.
+// Certificates whose previous revision was issued before the rotation policy
+// was changed to Always will also be reissued, as their key was never rotated.
+func SecretPrivateKeyReused(input Input) (string, string, bool) {
+	if input.Certificate == nil || input.Secret == nil || input.PreviousRevisionRequest == nil {
+		return "", "", false
+	}
+	spec := input.Certificate.Spec.PrivateKey
+	if spec == nil || spec.RotationPolicy != cmapi.RotationPolicyAlways {
+		return "", "", false
+	}
+
+	// Invalid private key data is handled by SecretPrivateKeyMatchesSpec.
+	pk, err := pki.DecodePrivateKeyBytes(input.Secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return "", "", false
+	}
+	csr, err := pki.DecodeX509CertificateRequestBytes(input.PreviousRevisionRequest.Spec.Request)
+	if err != nil {
+		return "", "", false
+	}
+
+	secretKeyHash, err := publicKeyHash(pk.Public())
+	if err != nil {
+		return "", "", false
+	}
+	previousKeyHash, err := publicKeyHash(csr.PublicKey)
+	if err != nil {
+		return "", "", false
+	}
+	if secretKeyHash != previousKeyHash {
+		return "", "", false
+	}
+
+	return PrivateKeyReused, fmt.Sprintf("Issuing certificate as Secret contains the private key used by the previous revision (public key SHA-256 %s) but spec.privateKey.rotationPolicy is %s", secretKeyHash, cmapi.RotationPolicyAlways), true
+}
+
+// CurrentCertificateRequestPublicKeyMismatch is violated when the public key
+// of the certificate stored in the Secret is not the public key of the
+// "current" CertificateRequest's CSR, for example because a private key
+// rotation was only partially applied. Keys are compared using a hash of
+// their public key.
+func CurrentCertificateRequestPublicKeyMismatch(input Input) (string, string, bool) {
+	if input.Secret == nil || input.CurrentRevisionRequest == nil {
+		return "", "", false
+	}
+
+	cert, ok := issuedCertificate(input)
+	if !ok {
+		return "", "", false
+	}
+	// Invalid request data is handled by other policies.
+	csr, err := pki.DecodeX509CertificateRequestBytes(input.CurrentRevisionRequest.Spec.Request)
+	if err != nil {
+		return "", "", false
+	}
+
+	certKeyHash, err := publicKeyHash(cert.PublicKey)
+	if err != nil {
+		return "", "", false
+	}
+	requestKeyHash, err := publicKeyHash(csr.PublicKey)
+	if err != nil {
+		return "", "", false
+	}
+	if certKeyHash == requestKeyHash {
+		return "", "", false
+	}
+
+	return RequestChanged, fmt.Sprintf("Issuing certificate as the public key of the issued certificate (SHA-256 %s) does not match the public key of the current CertificateRequest %q (SHA-256 %s)",
+		certKeyHash, input.CurrentRevisionRequest.Name, requestKeyHash), true
+}
+
+// SecretCertificateMismatchesCurrentRequest is violated when the certificate
+// stored in the Secret is not the certificate issued by the "current"
+// CertificateRequest, for example because status.revision was advanced but
+// the Secret was never updated with the newly issued certificate. Only the
+// leaf certificates are compared, so that a change to the CA chain stored
+// alongside it does not cause a reissue. Requests that have not issued a
+// certificate are skipped.
+func SecretCertificateMismatchesCurrentRequest(input Input) (string, string, bool) {
+	if input.Secret == nil || input.CurrentRevisionRequest == nil || len(input.CurrentRevisionRequest.Status.Certificate) == 0 {
+		return "", "", false
+	}
+
+	cert, ok := issuedCertificate(input)
+	if !ok {
+		return "", "", false
+	}
+	requestCert, err := pki.DecodeX509CertificateBytes(input.CurrentRevisionRequest.Status.Certificate)
+	if err != nil {
+		return "", "", false
+	}
+	if bytes.Equal(cert.Raw, requestCert.Raw) {
+		return "", "", false
+	}
+
+	return StaleSecret, fmt.Sprintf("Issuing certificate as the issued certificate (serial number %s) is not the certificate issued by the current CertificateRequest %q (serial number %s)",
+		cert.SerialNumber.Text(16), input.CurrentRevisionRequest.Name, requestCert.SerialNumber.Text(16)), true
+}
+
+// publicKeyHash returns the hex encoded SHA-256 hash of the DER encoded
+// public key.
+func publicKeyHash(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 func SecretIssuerAnnotationsNotUpToDate(input Input) (string, string, bool) {
 	name := input.Secret.Annotations[cmapi.IssuerNameAnnotationKey]
 	kind := input.Secret.Annotations[cmapi.IssuerKindAnnotationKey]
@@ -104,6 +533,276 @@ func SecretIssuerAnnotationsNotUpToDate(input Input) (string, string, bool) {
 	return "", "", false
 }
 
+// SecretIssuerProfileNotUpToDate returns a policy function that checks the
+// issuer profile annotation stored on the Secret against the profile
+// annotation of the issuer currently referenced by the Certificate. Issuers
+// that are not cert-manager issuers, or cannot be found, are skipped.
+func SecretIssuerProfileNotUpToDate(helper issuer.Helper) Func {
+	return func(input Input) (string, string, bool) {
+		ref := input.Certificate.Spec.IssuerRef
+		if ref.Group != "" && ref.Group != certmanager.GroupName {
+			return "", "", false
+		}
+
+		iss, err := helper.GetGenericIssuer(ref, input.Certificate.Namespace)
+		if err != nil {
+			return "", "", false
+		}
+
+		issuerProfile := iss.GetObjectMeta().Annotations[cmapi.IssuerProfileAnnotationKey]
+		secretProfile := input.Secret.Annotations[cmapi.IssuerProfileAnnotationKey]
+		if issuerProfile != secretProfile {
+			return IncorrectIssuer, fmt.Sprintf("Issuing certificate as Secret was previously issued with issuer profile %q but the issuer now has profile %q", secretProfile, issuerProfile), true
+		}
+		return "", "", false
+	}
+}
+
+// SecretCertificateHasDeniedUsages returns a policy function that checks the
+// extended key usages of the issued certificate against those listed in the
+// DeniedExtendedKeyUsagesAnnotationKey annotation of the issuer referenced by
+// the Certificate. This allows Certificates to converge after an issuer stops
+// granting an extended key usage. Usages that are still requested in the
+// Certificate's spec.usages are ignored, as reissuing would not remove them.
+// Issuers that are not cert-manager issuers, or cannot be found, are skipped.
+func SecretCertificateHasDeniedUsages(helper issuer.Helper) Func {
+	return func(input Input) (string, string, bool) {
+		ref := input.Certificate.Spec.IssuerRef
+		if ref.Group != "" && ref.Group != certmanager.GroupName {
+			return "", "", false
+		}
+
+		iss, err := helper.GetGenericIssuer(ref, input.Certificate.Namespace)
+		if err != nil {
+			return "", "", false
+		}
+		denied := iss.GetObjectMeta().Annotations[cmapi.DeniedExtendedKeyUsagesAnnotationKey]
+		if len(denied) == 0 {
+			return "", "", false
+		}
+
+		cert, ok := issuedCertificate(input)
+		if !ok {
+			return "", "", false
+		}
+
+		requested := make(map[cmapi.KeyUsage]bool, len(input.Certificate.Spec.Usages))
+		for _, usage := range input.Certificate.Spec.Usages {
+			requested[usage] = true
+		}
+		for _, name := range strings.Split(denied, ",") {
+			usage := cmapi.KeyUsage(strings.TrimSpace(name))
+			eku, ok := apiutil.ExtKeyUsageType(usage)
+			if !ok || requested[usage] {
+				continue
+			}
+			for _, certEKU := range cert.ExtKeyUsage {
+				if certEKU == eku {
+					return DeniedUsage, fmt.Sprintf("Issuing certificate as the existing certificate has the extended key usage %q which is denied by the issuer", usage), true
+				}
+			}
+		}
+		return "", "", false
+	}
+}
+
+// IssuerCAFunc returns the CA certificate that the given issuer currently
+// signs certificates with, or nil if the issuer does not expose one.
+type IssuerCAFunc func(iss cmapi.GenericIssuer) (*x509.Certificate, error)
+
+// CAIssuerSigningCertificate returns an IssuerCAFunc that reads the signing
+// certificate of CA issuers from the first certificate in the tls.crt key of
+// their spec.ca.secretName Secret. The Secret is looked up in the namespace
+// returned by resourceNamespace. Other issuer types do not expose their CA.
+func CAIssuerSigningCertificate(secretLister corelisters.SecretLister, resourceNamespace func(cmapi.GenericIssuer) string) IssuerCAFunc {
+	return func(iss cmapi.GenericIssuer) (*x509.Certificate, error) {
+		if iss.GetSpec().CA == nil {
+			return nil, nil
+		}
+		secret, err := secretLister.Secrets(resourceNamespace(iss)).Get(iss.GetSpec().CA.SecretName)
+		if err != nil {
+			return nil, err
+		}
+		return pki.DecodeX509CertificateBytes(secret.Data[corev1.TLSCertKey])
+	}
+}
+
+// SecretAuthorityKeyIDMismatch returns a policy function that checks whether
+// the authority key identifier of the issued certificate matches the subject
+// key identifier of the CA currently used by the issuer referenced by the
+// Certificate, as returned by issuerCA. This detects the issuer's CA being
+// rotated more precisely than comparing CA bundles. The check is skipped if
+// the issuer cannot be found, does not expose its CA, or if either key
+// identifier is not available.
+func SecretAuthorityKeyIDMismatch(helper issuer.Helper, issuerCA IssuerCAFunc) Func {
+	return func(input Input) (string, string, bool) {
+		iss, err := helper.GetGenericIssuer(input.Certificate.Spec.IssuerRef, input.Certificate.Namespace)
+		if err != nil {
+			return "", "", false
+		}
+		ca, err := issuerCA(iss)
+		if err != nil || ca == nil || len(ca.SubjectKeyId) == 0 {
+			return "", "", false
+		}
+
+		cert, ok := issuedCertificate(input)
+		if !ok || len(cert.AuthorityKeyId) == 0 {
+			return "", "", false
+		}
+
+		if !bytes.Equal(cert.AuthorityKeyId, ca.SubjectKeyId) {
+			return CAChanged, fmt.Sprintf("Issuing certificate as the authority key ID of the issued certificate (%s) does not match the subject key ID of the issuer's CA (%s)",
+				hex.EncodeToString(cert.AuthorityKeyId), hex.EncodeToString(ca.SubjectKeyId)), true
+		}
+		return "", "", false
+	}
+}
+
+// SecretCertificateOutlivesIssuerCA returns a policy function that checks
+// whether the issued certificate expires after the CA certificate currently
+// used by the issuer referenced by the Certificate, as returned by issuerCA.
+// Such a certificate can no longer be verified once the CA has expired, so it
+// is reissued early. The issuer must limit the notAfter of the reissued
+// certificate to that of its CA, otherwise the certificate would be reissued
+// repeatedly. The check is skipped if the issuer cannot be found or does not
+// expose its CA.
+func SecretCertificateOutlivesIssuerCA(helper issuer.Helper, issuerCA IssuerCAFunc) Func {
+	return func(input Input) (string, string, bool) {
+		iss, err := helper.GetGenericIssuer(input.Certificate.Spec.IssuerRef, input.Certificate.Namespace)
+		if err != nil {
+			return "", "", false
+		}
+		ca, err := issuerCA(iss)
+		if err != nil || ca == nil {
+			return "", "", false
+		}
+
+		cert, ok := issuedCertificate(input)
+		if !ok {
+			return "", "", false
+		}
+
+		if cert.NotAfter.After(ca.NotAfter) {
+			return OutlivesCA, fmt.Sprintf("Issuing certificate as the issued certificate expires at %s, after the issuer's CA certificate which expires at %s",
+				cert.NotAfter.UTC().Format(time.RFC3339), ca.NotAfter.UTC().Format(time.RFC3339)), true
+		}
+		return "", "", false
+	}
+}
+
+// RevokedIntermediatesFunc returns the set of SHA-256 fingerprints of
+// intermediate CA certificates that have been revoked. Fingerprints are lower
+// case hex encoded, without separators.
+type RevokedIntermediatesFunc func() (sets.String, error)
+
+// RevokedIntermediatesFromConfigMap returns a RevokedIntermediatesFunc that
+// reads the fingerprints of revoked intermediates from the values of the
+// named ConfigMap. Each value may contain any number of fingerprints,
+// separated by whitespace. Fingerprints may be written in upper or lower case
+// and may contain colons, e.g. as printed by openssl. A ConfigMap that does
+// not exist is treated as containing no fingerprints.
+func RevokedIntermediatesFromConfigMap(configMapLister corelisters.ConfigMapLister, namespace, name string) RevokedIntermediatesFunc {
+	return func() (sets.String, error) {
+		configMap, err := configMapLister.ConfigMaps(namespace).Get(name)
+		if apierrors.IsNotFound(err) {
+			return sets.NewString(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		revoked := sets.NewString()
+		for _, value := range configMap.Data {
+			for _, fingerprint := range strings.Fields(value) {
+				revoked.Insert(normalizeFingerprint(fingerprint))
+			}
+		}
+		return revoked, nil
+	}
+}
+
+// normalizeFingerprint returns the given hex encoded fingerprint in lower
+// case with any colons removed.
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+}
+
+// SecretIssuedByRevokedIntermediate returns a policy function that checks
+// whether the certificate stored in the Secret was signed by an intermediate
+// CA whose SHA-256 fingerprint is in the set returned by revoked. The issuing
+// certificate is looked up in the chain stored in the tls.crt key of the
+// Secret. The check is skipped if the stored chain does not contain the
+// issuing certificate or if the revoked intermediates cannot be loaded.
+func SecretIssuedByRevokedIntermediate(revoked RevokedIntermediatesFunc) Func {
+	return func(input Input) (string, string, bool) {
+		// Invalid certificate data is handled by other policies.
+		chain, err := pki.DecodeX509CertificateChainBytes(input.Secret.Data[corev1.TLSCertKey])
+		if err != nil || len(chain) < 2 {
+			return "", "", false
+		}
+
+		issuing := issuingCertificate(chain[0], chain[1:])
+		if issuing == nil {
+			return "", "", false
+		}
+
+		revokedFingerprints, err := revoked()
+		if err != nil {
+			return "", "", false
+		}
+		sum := sha256.Sum256(issuing.Raw)
+		fingerprint := hex.EncodeToString(sum[:])
+		if !revokedFingerprints.Has(fingerprint) {
+			return "", "", false
+		}
+
+		return RevokedIntermediate, fmt.Sprintf("Issuing certificate as it was signed by the revoked intermediate %q (SHA-256 fingerprint %s)",
+			issuing.Subject.String(), fingerprint), true
+	}
+}
+
+// issuingCertificate returns the certificate among candidates that signed
+// cert, or nil if there is none.
+func issuingCertificate(cert *x509.Certificate, candidates []*x509.Certificate) *x509.Certificate {
+	for _, candidate := range candidates {
+		if bytes.Equal(cert.RawIssuer, candidate.RawSubject) && cert.CheckSignatureFrom(candidate) == nil {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// SecretRevisionInvalid checks that the cert-manager.io/certificate-revision
+// annotation of the Secret can be parsed and matches the Certificate's
+// status.revision, i.e. that the Secret holds the revision the Certificate
+// last issued. A Secret with an inconsistent revision, e.g. one restored from
+// a backup, causes issuance to be triggered so that the revision history is
+// corrected. Secrets without the annotation are left to
+// SecretIsMissingRevision.
+func SecretRevisionInvalid(input Input) (string, string, bool) {
+	value, ok := input.Secret.Annotations[cmapi.CertificateRequestRevisionAnnotationKey]
+	if !ok || input.Certificate.Status.Revision == nil {
+		return "", "", false
+	}
+
+	revision := *input.Certificate.Status.Revision
+	if revision < 1 {
+		return InvalidRevision, fmt.Sprintf("Issuing certificate as the Certificate's revision %d is not a positive number", revision), true
+	}
+
+	secretRevision, err := strconv.Atoi(value)
+	if err != nil {
+		return InvalidRevision, fmt.Sprintf("Issuing certificate as the %s annotation of the Secret is not a number: %q",
+			cmapi.CertificateRequestRevisionAnnotationKey, value), true
+	}
+	if secretRevision != revision {
+		return InvalidRevision, fmt.Sprintf("Issuing certificate as the revision of the Secret (%d) does not match the Certificate's revision (%d)",
+			secretRevision, revision), true
+	}
+
+	return "", "", false
+}
+
 func CurrentCertificateRequestNotValidForSpec(input Input) (string, string, bool) {
 	if input.CurrentRevisionRequest == nil {
 		// Fallback to comparing the Certificate spec with the issued certificate.
@@ -147,10 +846,233 @@ func currentSecretValidForSpec(input Input) (string, string, bool) {
 	return "", "", false
 }
 
+// UnlessExternallyIssued wraps the given policy so that it is never violated
+// for Certificates marked as externally issued using the
+// cert-manager.io/externally-issued annotation. The key pair and x509 fields
+// of certificates imported into the Secret are not expected to match what
+// cert-manager would have requested, so they must not cause re-issuance.
+func UnlessExternallyIssued(policy Func) Func {
+	return func(input Input) (string, string, bool) {
+		if input.Certificate.Annotations[cmapi.ExternallyIssuedAnnotation] == "true" {
+			return "", "", false
+		}
+		return policy(input)
+	}
+}
+
+// SecretOCSPMustStapleMismatch is violated when the Certificate expresses a
+// preference for the OCSP Must-Staple extension using the
+// cert-manager.io/ocsp-must-staple annotation and the certificate stored in
+// the Secret does not match it, for example because the certificate was
+// issued before the annotation was added. Certificates without the
+// annotation are never considered to be mismatched, as some issuers add the
+// extension of their own accord.
+func SecretOCSPMustStapleMismatch(input Input) (string, string, bool) {
+	mustStaple, set := pki.OCSPMustStapleForCertificate(input.Certificate)
+	if !set {
+		return "", "", false
+	}
+
+	x509cert, ok := issuedCertificate(input)
+	if !ok {
+		return "", "", false
+	}
+	if pki.HasOCSPMustStaple(x509cert.Extensions) == mustStaple {
+		return "", "", false
+	}
+
+	return SecretMismatch, fmt.Sprintf("Existing issued Secret is not up to date for spec: [metadata.annotations[%s]]", cmapi.OCSPMustStapleAnnotation), true
+}
+
+// SecretEmbeddedSCTsMismatch is violated when the Certificate expresses an
+// expectation for embedded Signed Certificate Timestamps using the
+// cert-manager.io/embedded-scts annotation and the certificate stored in the
+// Secret does not match it, for example because the issuer failed to submit
+// the certificate to certificate transparency logs. Certificates without the
+// annotation are never considered to be mismatched.
+func SecretEmbeddedSCTsMismatch(input Input) (string, string, bool) {
+	embedded, set := pki.EmbeddedSCTsForCertificate(input.Certificate)
+	if !set {
+		return "", "", false
+	}
+
+	x509cert, ok := issuedCertificate(input)
+	if !ok {
+		return "", "", false
+	}
+	if pki.HasEmbeddedSCTs(x509cert.Extensions) == embedded {
+		return "", "", false
+	}
+
+	return SecretMismatch, fmt.Sprintf("Existing issued Secret is not up to date for spec: [metadata.annotations[%s]]", cmapi.EmbeddedSCTsAnnotation), true
+}
+
+// SecretCertificatePoliciesMissing is violated when the Certificate requires
+// certificate policies using the cert-manager.io/certificate-policies
+// annotation and the certificate stored in the Secret does not contain all of
+// them, for example because the certificate was issued before the annotation
+// was added.
+func SecretCertificatePoliciesMissing(input Input) (string, string, bool) {
+	required, err := pki.CertificatePoliciesForCertificate(input.Certificate)
+	if err != nil || len(required) == 0 {
+		// An invalid annotation cannot be satisfied by reissuing, so it is
+		// reported when generating the CSR instead.
+		return "", "", false
+	}
+
+	x509cert, ok := issuedCertificate(input)
+	if !ok {
+		return "", "", false
+	}
+	missing := pki.MissingCertificatePolicies(required, x509cert.PolicyIdentifiers)
+	if len(missing) == 0 {
+		return "", "", false
+	}
+
+	oids := make([]string, len(missing))
+	for i, oid := range missing {
+		oids[i] = oid.String()
+	}
+	return MissingCertificatePolicy, fmt.Sprintf("Issuing certificate as the existing certificate does not contain the required certificate policies: %s", strings.Join(oids, ", ")), true
+}
+
+// oidExtensionSubjectAltName is the OID of the subject alternative name
+// extension.
+var oidExtensionSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+
+// SecretCertificateMissingSANs is violated when the certificate stored in the
+// Secret of a non-CA Certificate with a common name has no subject
+// alternative name extension at all. Clients that ignore the common name,
+// such as modern browsers, reject these certificates, which were produced by
+// some older issuers.
+// Reissuing only helps if the issuer adds a subject alternative name for the
+// common name, as the CSR for a Certificate without spec.dnsNames does not
+// request one, so the policy is only enabled when all issuers do so.
+func SecretCertificateMissingSANs(input Input) (string, string, bool) {
+	if input.Certificate.Spec.IsCA || input.Certificate.Spec.CommonName == "" {
+		return "", "", false
+	}
+
+	x509cert, ok := issuedCertificate(input)
+	if !ok {
+		return "", "", false
+	}
+	for _, ext := range x509cert.Extensions {
+		if ext.Id.Equal(oidExtensionSubjectAltName) {
+			return "", "", false
+		}
+	}
+	return SecretMismatch, "Existing issued Secret is not up to date for spec: [spec.dnsNames]", true
+}
+
+// SecretCertificateHasUnexpectedCommonName is violated when a Certificate
+// has no spec.commonName but the certificate stored in its Secret has a
+// common name, for example because spec.commonName was removed to move to
+// certificates that only use subject alternative names. The comparison of the
+// Secret with the spec allows the common name to be any of spec.dnsNames, as
+// some issuers promote a DNS name to be the common name, so the policy is
+// only enabled when no issuers do so.
+func SecretCertificateHasUnexpectedCommonName(input Input) (string, string, bool) {
+	if input.Certificate.Spec.CommonName != "" {
+		return "", "", false
+	}
+
+	x509cert, ok := issuedCertificate(input)
+	if !ok {
+		return "", "", false
+	}
+	if x509cert.Subject.CommonName == "" {
+		return "", "", false
+	}
+	return SecretMismatch, "Existing issued Secret is not up to date for spec: [spec.commonName]", true
+}
+
+// SecretDurationExceedsSpec returns a policy function that is violated when
+// the total validity of the certificate stored in the Secret exceeds the
+// Certificate's spec.duration (or the default duration if unset) by more than
+// opts.DurationTolerance. This allows certificates issued before an issuer's
+// maximum duration was reduced to be reissued without waiting for their
+// renewal time. The tolerance accounts for issuers that backdate notBefore or
+// round notAfter.
+func SecretDurationExceedsSpec(opts TriggerPolicyOptions) Func {
+	return func(input Input) (string, string, bool) {
+		x509cert, ok := issuedCertificate(input)
+		if !ok {
+			return "", "", false
+		}
+
+		duration := cmapi.DefaultCertificateDuration
+		if input.Certificate.Spec.Duration != nil {
+			duration = input.Certificate.Spec.Duration.Duration
+		}
+		if x509cert.NotAfter.Sub(x509cert.NotBefore) <= duration+opts.DurationTolerance {
+			return "", "", false
+		}
+
+		return SecretMismatch, "Existing issued Secret is not up to date for spec: [spec.duration]", true
+	}
+}
+
+// SecretSerialNumberDuplicated returns a policy function that is violated
+// when the given index holds the issuer and serial number of the certificate
+// stored in the Secret for a different Certificate in the same namespace. A CA
+// should never issue two certificates with the same serial number, so this
+// indicates a broken CA rather than a problem that reissuing would fix.
+// The policy only looks serial numbers up; callers record them with
+// SerialIndex.Record once the chain has been evaluated.
+func SecretSerialNumberDuplicated(index *SerialIndex) Func {
+	return func(input Input) (string, string, bool) {
+		x509cert, ok := issuedCertificate(input)
+		if !ok {
+			return "", "", false
+		}
+
+		other, duplicated := index.Lookup(input.Certificate.Namespace, input.Certificate.Name, x509cert)
+		if !duplicated {
+			return "", "", false
+		}
+
+		return DuplicateSerial, fmt.Sprintf("Issued certificate has serial number %s, which is also used by the issued certificate of Certificate %q from the same issuer", x509cert.SerialNumber, other), true
+	}
+}
+
+// SecretIssuedBeforeCutoff returns a policy function that is violated when
+// the certificate stored in the Secret has a notBefore earlier than
+// opts.IssuedBeforeCutoff. This allows every certificate issued before a
+// point in time, e.g. before a CA key was compromised, to be reissued.
+// Certificates issued exactly at the cutoff are not reissued.
+// As issuers may backdate notBefore, a certificate that was issued by a
+// "current" CertificateRequest created at or after the cutoff is never
+// reissued, as it would otherwise be reissued over and over again.
+func SecretIssuedBeforeCutoff(opts TriggerPolicyOptions) Func {
+	return func(input Input) (string, string, bool) {
+		x509cert, ok := issuedCertificate(input)
+		if !ok {
+			return "", "", false
+		}
+
+		if !x509cert.NotBefore.Before(opts.IssuedBeforeCutoff) {
+			return "", "", false
+		}
+
+		if req := input.CurrentRevisionRequest; req != nil && !req.CreationTimestamp.Time.Before(opts.IssuedBeforeCutoff) {
+			requestCert, err := pki.DecodeX509CertificateBytes(req.Status.Certificate)
+			if err == nil && bytes.Equal(x509cert.Raw, requestCert.Raw) {
+				return "", "", false
+			}
+		}
+
+		return IssuedBeforeCutoff, fmt.Sprintf("Issued certificate has a notBefore of %s, which is before the cutoff of %s",
+			x509cert.NotBefore.UTC().Format(time.RFC3339), opts.IssuedBeforeCutoff.UTC().Format(time.RFC3339)), true
+	}
+}
+
 // CurrentCertificateNearingExpiry returns a policy function that can be used to
 // check whether an X.509 cert currently issued for a Certificate should be
-// renewed.
-func CurrentCertificateNearingExpiry(c clock.Clock) Func {
+// renewed. If renewalJitterWindow is greater than zero, renewal is brought
+// forward by a per-Certificate jitter within that window (see
+// JitteredRenewalTime).
+func CurrentCertificateNearingExpiry(c clock.Clock, opts TriggerPolicyOptions) Func {
 
 	return func(input Input) (string, string, bool) {
 
@@ -165,12 +1087,10 @@ func CurrentCertificateNearingExpiry(c clock.Clock) Func {
 			return InvalidCertificate, fmt.Sprintf("Failed to decode stored certificate: %v", err), true
 		}
 
-		notBefore := metav1.NewTime(x509cert.NotBefore)
-		notAfter := metav1.NewTime(x509cert.NotAfter)
-		crt := input.Certificate
-		renewalTime := certificates.RenewalTime(notBefore.Time, notAfter.Time, crt.Spec.RenewBefore)
+		renewalTime := ExplainRenewalTime(input.Certificate, x509cert).RenewalTime
+		triggerTime := RenewalTriggerTime(input.Certificate, x509cert.NotBefore, x509cert.NotAfter, renewalTime, opts)
 
-		renewIn := renewalTime.Time.Sub(c.Now())
+		renewIn := triggerTime.Sub(c.Now())
 		if renewIn > 0 {
 			//renewal time is in future, no need to renew
 			return "", "", false
@@ -180,6 +1100,79 @@ func CurrentCertificateNearingExpiry(c clock.Clock) Func {
 	}
 }
 
+// notYetValidTolerance is how far in the future the notBefore of a certificate
+// may be before CurrentCertificateOutsideValidityWindow is violated. It
+// allows for clock skew between the issuer and the controller, so that
+// freshly issued certificates are not reissued straight away.
+const notYetValidTolerance = 5 * time.Minute
+
+// CurrentCertificateOutsideValidityWindow is a policy function that is
+// violated when the current time is not within the validity window of the
+// certificate stored in the Secret, i.e. when the certificate has expired or
+// its notBefore is in the future, e.g. due to an issuer bug. In either case
+// the certificate is useless and must be reissued, regardless of its renewal
+// time.
+func CurrentCertificateOutsideValidityWindow(c clock.Clock) Func {
+	return func(input Input) (string, string, bool) {
+		x509cert, err := pki.DecodeX509CertificateBytes(input.Secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			// This case should never happen as it should always be caught by the
+			// secretPublicKeysMatch function beforehand, but handle it just in case.
+			return InvalidCertificate, fmt.Sprintf("Failed to decode stored certificate: %v", err), true
+		}
+
+		now := c.Now()
+		if x509cert.NotBefore.After(now.Add(notYetValidTolerance)) {
+			return NotYetValid, fmt.Sprintf("Issuing certificate as the existing certificate is not valid until %s", x509cert.NotBefore.Format(time.RFC1123)), true
+		}
+		if now.After(x509cert.NotAfter) {
+			return Expired, fmt.Sprintf("Issuing certificate as the existing certificate expired on %s", x509cert.NotAfter.Format(time.RFC1123)), true
+		}
+		return "", "", false
+	}
+}
+
+// CurrentCertificateExpiresBeforeResync is a policy function that triggers
+// renewal of the current certificate if it would expire within the resync
+// period plus the configured margin, regardless of its renewal time. This
+// guards against the renewal window being missed entirely between resyncs.
+// Certificates whose total duration is no longer than the resync period plus
+// the margin are ignored, as even a freshly issued certificate would
+// immediately violate the policy again.
+func CurrentCertificateExpiresBeforeResync(c clock.Clock, opts TriggerPolicyOptions) Func {
+	return func(input Input) (string, string, bool) {
+		x509cert, err := pki.DecodeX509CertificateBytes(input.Secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			// This case should never happen as it should always be caught by the
+			// secretPublicKeysMatch function beforehand, but handle it just in case.
+			return InvalidCertificate, fmt.Sprintf("Failed to decode stored certificate: %v", err), true
+		}
+
+		window := opts.ResyncPeriod + opts.ResyncExpiryMargin
+		if x509cert.NotAfter.Sub(x509cert.NotBefore) <= window {
+			return "", "", false
+		}
+		if x509cert.NotAfter.Sub(c.Now()) >= window {
+			return "", "", false
+		}
+
+		return ExpiresBeforeResync, fmt.Sprintf("Renewing certificate as it expires at %s, which is within the resync period of %s plus a margin of %s",
+			x509cert.NotAfter.UTC().Format(time.RFC3339), opts.ResyncPeriod, opts.ResyncExpiryMargin), true
+	}
+}
+
+// SecretIsMissingRevision is a policy function that triggers reissuance if
+// the Secret does not have the revision annotation, e.g. because it was
+// written by an older version of cert-manager. Reissuing the certificate
+// backfills the annotation. It is evaluated after all other policies, so
+// that it is only reported for otherwise valid Secrets.
+func SecretIsMissingRevision(input Input) (string, string, bool) {
+	if _, ok := input.Secret.Annotations[cmapi.CertificateRequestRevisionAnnotationKey]; ok {
+		return "", "", false
+	}
+	return MissingRevision, fmt.Sprintf("Issuing certificate as Secret does not have the %q annotation", cmapi.CertificateRequestRevisionAnnotationKey), true
+}
+
 // CurrentCertificateHasExpired is used exclusively to check if the current
 // issued certificate has actually expired rather than just nearing expiry.
 func CurrentCertificateHasExpired(c clock.Clock) Func {
@@ -204,6 +1197,74 @@ func CurrentCertificateHasExpired(c clock.Clock) Func {
 	}
 }
 
+// CurrentCertificateHasInvalidUsages checks the key usages and basic
+// constraints of the issued certificate against its extended key usages for
+// common contradictions, such as a certificate that may sign certificates but
+// is not a CA. This is only a readiness check, as reissuing the certificate
+// with the same spec would not correct it.
+func CurrentCertificateHasInvalidUsages(input Input) (string, string, bool) {
+	cert, err := pki.DecodeX509CertificateBytes(input.Secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		// This case should never happen as it should always be caught by the
+		// secretPublicKeysMatch function beforehand, but handle it just in case.
+		return InvalidCertificate, fmt.Sprintf("Failed to decode stored certificate: %v", err), true
+	}
+
+	if contradictions := usageContradictions(cert); len(contradictions) > 0 {
+		return InvalidUsages, fmt.Sprintf("Issued certificate has contradictory usages, check spec.usages and spec.isCA: %s", strings.Join(contradictions, "; ")), true
+	}
+	return "", "", false
+}
+
+// usageContradictions returns a description of each contradiction between
+// the key usages, extended key usages and basic constraints of the given
+// certificate. Key usages are only checked against extended key usages if
+// the certificate has a key usage extension, as otherwise the key is not
+// restricted.
+func usageContradictions(cert *x509.Certificate) []string {
+	var contradictions []string
+	hasKeyUsage := cert.KeyUsage != 0
+	has := func(usages ...x509.KeyUsage) bool {
+		for _, usage := range usages {
+			if cert.KeyUsage&usage != 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	if cert.IsCA && hasKeyUsage && !has(x509.KeyUsageCertSign) {
+		contradictions = append(contradictions, "certificate is a CA but does not have the cert sign key usage")
+	}
+	if !cert.IsCA && has(x509.KeyUsageCertSign) {
+		contradictions = append(contradictions, "certificate has the cert sign key usage but is not a CA")
+	}
+	if !hasKeyUsage {
+		return contradictions
+	}
+	for _, eku := range cert.ExtKeyUsage {
+		switch eku {
+		case x509.ExtKeyUsageServerAuth:
+			if !has(x509.KeyUsageDigitalSignature, x509.KeyUsageKeyEncipherment, x509.KeyUsageKeyAgreement) {
+				contradictions = append(contradictions, "server auth extended key usage requires the digital signature, key encipherment or key agreement key usage")
+			}
+		case x509.ExtKeyUsageClientAuth:
+			if !has(x509.KeyUsageDigitalSignature, x509.KeyUsageKeyAgreement) {
+				contradictions = append(contradictions, "client auth extended key usage requires the digital signature or key agreement key usage")
+			}
+		case x509.ExtKeyUsageCodeSigning:
+			if !has(x509.KeyUsageDigitalSignature) {
+				contradictions = append(contradictions, "code signing extended key usage requires the digital signature key usage")
+			}
+		case x509.ExtKeyUsageEmailProtection:
+			if !has(x509.KeyUsageDigitalSignature, x509.KeyUsageContentCommitment, x509.KeyUsageKeyEncipherment, x509.KeyUsageKeyAgreement) {
+				contradictions = append(contradictions, "email protection extended key usage requires the digital signature, content commitment, key encipherment or key agreement key usage")
+			}
+		}
+	}
+	return contradictions
+}
+
 func formatIssuerRef(name, kind, group string) string {
 	if group == "" {
 		group = "cert-manager.io"
@@ -264,15 +1325,48 @@ func SecretTemplateMismatchesSecret(input Input) (string, string, bool) {
 	return "", "", false
 }
 
+// Path elements used to extract the labels and annotations from the managed
+// fields of a Secret.
+var (
+	metadataPathElement    = fieldpath.PathElement{FieldName: pointer.String("metadata")}
+	labelsPathElement      = fieldpath.PathElement{FieldName: pointer.String("labels")}
+	annotationsPathElement = fieldpath.PathElement{FieldName: pointer.String("annotations")}
+)
+
+// hasManagedFieldsFor returns true if the given Secret has a managed fields
+// entry owned by the given field manager.
+func hasManagedFieldsFor(secret *corev1.Secret, fieldManager string) bool {
+	for _, managedField := range secret.ManagedFields {
+		if managedField.Manager == fieldManager && managedField.FieldsV1 != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // SecretTemplateMismatchesSecretManagedFields will inspect the given Secret's
 // managed fields for its Annotations and Labels, and compare this against the
 // SecretTemplate on the given Certificate. Returns false if Annotations and
 // Labels match on both the Certificate's SecretTemplate and the Secret's
 // managed fields, true otherwise.
 // Also returns true if the managed fields or signed certificate were not able
-// to be decoded.
-func SecretTemplateMismatchesSecretManagedFields(fieldManager string) Func {
+// to be decoded, unless opts.IgnoreManagedFieldsParseErrors is set, in which
+// case managed fields entries that cannot be decoded are treated as empty.
+// The check is skipped if Secrets are written using the Update strategy, as
+// their managed fields then do not reflect the SecretTemplate.
+func SecretTemplateMismatchesSecretManagedFields(fieldManager string, opts PostIssuancePolicyOptions) Func {
 	return func(input Input) (string, string, bool) {
+		if opts.SecretUpdateStrategy == internalcertificates.SecretUpdateStrategyUpdate {
+			return "", "", false
+		}
+
+		// If the SecretTemplate is nil and none of the managed fields are owned
+		// by the cert-manager controller, there is nothing to compare so avoid
+		// decoding the certificate and managed fields altogether.
+		if input.Certificate.Spec.SecretTemplate == nil && !hasManagedFieldsFor(input.Secret, fieldManager) {
+			return "", "", false
+		}
+
 		// Only attempt to decode the signed certificate, if one is available.
 		var x509cert *x509.Certificate
 		if len(input.Secret.Data[corev1.TLSCertKey]) > 0 {
@@ -289,6 +1383,12 @@ func SecretTemplateMismatchesSecretManagedFields(fieldManager string) Func {
 
 		managedLabels, managedAnnotations := sets.NewString(), sets.NewString()
 
+		// The decoded field set and reader are reused between entries to avoid
+		// allocating them for every managed field.
+		var (
+			fieldset fieldpath.Set
+			reader   bytes.Reader
+		)
 		for _, managedField := range input.Secret.ManagedFields {
 			// If the managed field isn't owned by the cert-manager controller, ignore.
 			if managedField.Manager != fieldManager || managedField.FieldsV1 == nil {
@@ -296,21 +1396,18 @@ func SecretTemplateMismatchesSecretManagedFields(fieldManager string) Func {
 			}
 
 			// Decode the managed field.
-			var fieldset fieldpath.Set
-			if err := fieldset.FromJSON(bytes.NewReader(managedField.FieldsV1.Raw)); err != nil {
+			reader.Reset(managedField.FieldsV1.Raw)
+			if err := fieldset.FromJSON(&reader); err != nil {
+				if opts.IgnoreManagedFieldsParseErrors {
+					continue
+				}
 				return ManagedFieldsParseError, fmt.Sprintf("failed to decode managed fields on Secret: %s", err), true
 			}
 
 			// Extract the labels and annotations of the managed fields.
-			metadata := fieldset.Children.Descend(fieldpath.PathElement{
-				FieldName: pointer.String("metadata"),
-			})
-			labels := metadata.Children.Descend(fieldpath.PathElement{
-				FieldName: pointer.String("labels"),
-			})
-			annotations := metadata.Children.Descend(fieldpath.PathElement{
-				FieldName: pointer.String("annotations"),
-			})
+			metadata := fieldset.Children.Descend(metadataPathElement)
+			labels := metadata.Children.Descend(labelsPathElement)
+			annotations := metadata.Children.Descend(annotationsPathElement)
 
 			// Gather the annotations and labels on the managed fields. Remove the '.'
 			// prefix which appears on managed field keys.
@@ -324,9 +1421,13 @@ func SecretTemplateMismatchesSecretManagedFields(fieldManager string) Func {
 
 		// Remove the base Annotations from the managed Annotations so we can compare
 		// 1 to 1 against the SecretTemplate.
+		// The issuer profile and revision annotations are also managed by
+		// cert-manager, but are not part of the base Annotations as they are
+		// only known at issuance time.
 		for k := range baseAnnotations {
 			managedAnnotations = managedAnnotations.Delete(k)
 		}
+		managedAnnotations = managedAnnotations.Delete(cmapi.IssuerProfileAnnotationKey, cmapi.CertificateRequestRevisionAnnotationKey)
 
 		// Check early for Secret Template being nil, and whether managed
 		// labels/annotations are not.
@@ -368,3 +1469,39 @@ func SecretTemplateMismatchesSecretManagedFields(fieldManager string) Func {
 		return "", "", false
 	}
 }
+
+// SecretKeystorePasswordMismatch returns a KeystorePasswordMismatch violation
+// if the PKCS12 or JKS keystore in the Secret was built with a different
+// password Secret reference than the one currently configured on the
+// Certificate, as recorded by the keystore password annotations. Keystores
+// stored before these annotations were introduced are treated as mismatched so
+// that they are rebuilt with the configured password once.
+func SecretKeystorePasswordMismatch(input Input) (string, string, bool) {
+	ks := input.Certificate.Spec.Keystores
+	if ks == nil {
+		return "", "", false
+	}
+	if ks.PKCS12 != nil && ks.PKCS12.Create {
+		if message, mismatch := keystorePasswordMismatch(input.Secret, "PKCS12", cmapi.PKCS12PasswordSecretRefAnnotationKey, ks.PKCS12.PasswordSecretRef); mismatch {
+			return KeystorePasswordMismatch, message, true
+		}
+	}
+	if ks.JKS != nil && ks.JKS.Create {
+		if message, mismatch := keystorePasswordMismatch(input.Secret, "JKS", cmapi.JKSPasswordSecretRefAnnotationKey, ks.JKS.PasswordSecretRef); mismatch {
+			return KeystorePasswordMismatch, message, true
+		}
+	}
+	return "", "", false
+}
+
+func keystorePasswordMismatch(secret *corev1.Secret, keystore, annotationKey string, ref cmmeta.SecretKeySelector) (string, bool) {
+	want := internalcertificates.KeystorePasswordSecretRef(ref)
+	got, ok := secret.Annotations[annotationKey]
+	if !ok {
+		return fmt.Sprintf("%s keystore password Secret reference is not recorded on the Secret, rebuilding keystore using %q", keystore, want), true
+	}
+	if got != want {
+		return fmt.Sprintf("%s keystore was built using password Secret reference %q, but %q is configured", keystore, got, want), true
+	}
+	return "", false
+}
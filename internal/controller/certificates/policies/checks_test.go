@@ -17,17 +17,45 @@ limitations under the License.
 package policies
 
 import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	fakeclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
 
+	internalcertificates "github.com/cert-manager/cert-manager/internal/controller/certificates"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	testpkg "github.com/cert-manager/cert-manager/pkg/controller/test"
+	issuerfake "github.com/cert-manager/cert-manager/pkg/issuer/fake"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
 	testcrypto "github.com/cert-manager/cert-manager/test/unit/crypto"
 	"github.com/cert-manager/cert-manager/test/unit/gen"
+	testlisters "github.com/cert-manager/cert-manager/test/unit/listers"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -55,6 +83,24 @@ func Test_NewTriggerPolicyChain(t *testing.T) {
 			message:     "Issuing certificate as Secret does not exist",
 			reissue:     true,
 		},
+		"do not trigger issuance as Secret is being deleted": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretName: "something"}},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:              "something",
+				DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				Finalizers:        []string{"example.com/finalizer"},
+			}},
+			reason:  SecretTerminating,
+			message: `Secret "something" is being deleted, waiting for it to be removed before issuing`,
+			reissue: true,
+		},
+		"do not trigger issuance as Secret is immutable": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretName: "something"}},
+			secret:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"}, Immutable: pointer.Bool(true)},
+			reason:      SecretImmutable,
+			message:     `Secret "something" is immutable and cannot be updated with an issued certificate. Delete the Secret so that it can be recreated, or set spec.secretName to a different Secret`,
+			reissue:     true,
+		},
 		"trigger issuance as Secret does not contain any data": {
 			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretName: "something"}},
 			secret:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"}},
@@ -62,6 +108,13 @@ func Test_NewTriggerPolicyChain(t *testing.T) {
 			message:     "Issuing certificate as Secret does not contain any data",
 			reissue:     true,
 		},
+		"trigger issuance as Secret is of the wrong type and does not contain any data": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretName: "something"}},
+			secret:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"}, Type: corev1.SecretTypeOpaque},
+			reason:      WrongSecretType,
+			message:     `Issuing certificate as Secret is of type "Opaque" rather than "kubernetes.io/tls" and does not contain a private key and certificate`,
+			reissue:     true,
+		},
 		"trigger issuance as Secret is missing private key": {
 			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretName: "something"}},
 			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"},
@@ -80,6 +133,44 @@ func Test_NewTriggerPolicyChain(t *testing.T) {
 			message: "Issuing certificate as Secret does not contain a certificate",
 			reissue: true,
 		},
+		"trigger issuance as Secret is missing an additional required key": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					cmapi.RequiredSecretKeysAnnotation: "ca.crt, tls-combined.pem",
+				}},
+				Spec: cmapi.CertificateSpec{SecretName: "something"},
+			},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: []byte("test"),
+					corev1.TLSCertKey:       []byte("test"),
+					cmmeta.TLSCAKey:         []byte("test"),
+				},
+			},
+			reason:  MissingData,
+			message: `Issuing certificate as Secret does not contain required key "tls-combined.pem"`,
+			reissue: true,
+		},
+		"do not report missing data if Secret contains all additional required keys": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					cmapi.RequiredSecretKeysAnnotation: "ca.crt,tls-combined.pem",
+				}},
+				Spec: cmapi.CertificateSpec{SecretName: "something"},
+			},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: []byte("test"),
+					corev1.TLSCertKey:       []byte("test"),
+					cmmeta.TLSCAKey:         []byte("test"),
+					"tls-combined.pem":      []byte("test"),
+				},
+			},
+			// MissingData passes, so the next policy in the chain fails.
+			reason:  InvalidKeyPair,
+			message: "Issuing certificate as Secret contains an invalid key-pair: tls: failed to find any PEM data in certificate input",
+			reissue: true,
+		},
 		"trigger issuance as Secret contains corrupt private key and certificate data": {
 			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretName: "something"}},
 			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"},
@@ -272,11 +363,12 @@ func Test_NewTriggerPolicyChain(t *testing.T) {
 				},
 				Data: map[string][]byte{
 					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
-					corev1.TLSCertKey: testcrypto.MustCreateCert(t, staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
 						// It does not matter what certificate data is stored in the Secret
 						// as the CertificateRequest will be used to determine whether a
 						// re-issuance is required.
 						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "does-not-matter.example.com"}},
+						clock.Now(), clock.Now().Add(time.Hour*24*90),
 					),
 				},
 			},
@@ -319,9 +411,136 @@ func Test_NewTriggerPolicyChain(t *testing.T) {
 			message: "Existing issued Secret is not up to date for spec: [spec.commonName]",
 			reissue: true,
 		},
-		"do nothing if signed x509 certificate in Secret matches spec (when request does not exist)": {
+		"compare signed x509 certificate in Secret with spec if CertificateRequest does not exist (organization changed)": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				CommonName: "example.com",
+				Subject:    &cmapi.X509Subject{Organizations: []string{"new-org"}},
+				IssuerRef: cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				},
+			}},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCert(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{
+							CommonName: "example.com",
+							Subject:    &cmapi.X509Subject{Organizations: []string{"old-org"}},
+						}},
+					),
+				},
+			},
+			reason:  SecretMismatch,
+			message: "Existing issued Secret is not up to date for spec: [spec.subject.organizations]",
+			reissue: true,
+		},
+		"compare signed x509 certificate in Secret with spec if CertificateRequest does not exist (isCA changed from false to true)": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				CommonName: "example.com",
+				IsCA:       true,
+				IssuerRef: cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				},
+			}},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCert(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{
+							CommonName: "example.com",
+						}},
+					),
+				},
+			},
+			reason:  SecretMismatch,
+			message: "Existing issued Secret is not up to date for spec: [spec.isCA]",
+			reissue: true,
+		},
+		"compare signed x509 certificate in Secret with spec if CertificateRequest does not exist (isCA changed from true to false)": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				CommonName: "example.com",
+				IssuerRef: cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				},
+			}},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCert(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{
+							CommonName: "example.com",
+							IsCA:       true,
+						}},
+					),
+				},
+			},
+			reason:  SecretMismatch,
+			message: "Existing issued Secret is not up to date for spec: [spec.isCA]",
+			reissue: true,
+		},
+		"compare signed x509 certificate in Secret with spec if CertificateRequest does not exist (country changed)": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				CommonName: "example.com",
+				Subject:    &cmapi.X509Subject{Countries: []string{"US"}},
+				IssuerRef: cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				},
+			}},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCert(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{
+							CommonName: "example.com",
+							Subject:    &cmapi.X509Subject{Countries: []string{"GB"}},
+						}},
+					),
+				},
+			},
+			reason:  SecretMismatch,
+			message: "Existing issued Secret is not up to date for spec: [spec.subject.countries]",
+			reissue: true,
+		},
+		"compare signed x509 certificate in Secret with spec if CertificateRequest does not exist (subject serialNumber changed)": {
 			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
 				CommonName: "example.com",
+				Subject:    &cmapi.X509Subject{SerialNumber: "device-2"},
 				IssuerRef: cmmeta.ObjectReference{
 					Name:  "testissuer",
 					Kind:  "IssuerKind",
@@ -339,10 +558,117 @@ func Test_NewTriggerPolicyChain(t *testing.T) {
 				Data: map[string][]byte{
 					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
 					corev1.TLSCertKey: testcrypto.MustCreateCert(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{
+							CommonName: "example.com",
+							Subject:    &cmapi.X509Subject{SerialNumber: "device-1"},
+						}},
+					),
+				},
+			},
+			reason:  SecretMismatch,
+			message: "Existing issued Secret is not up to date for spec: [spec.subject.serialNumber]",
+			reissue: true,
+		},
+		"do nothing if signed x509 certificate in Secret matches spec (when request does not exist)": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				CommonName: "example.com",
+				IssuerRef: cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				},
+			}},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+						clock.Now(), clock.Now().Add(time.Hour*24*90),
+					),
+				},
+			},
+		},
+		"do nothing if the key-pair and x509 certificate in Secret do not match spec for an externally issued Certificate": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					cmapi.ExternallyIssuedAnnotation: "true",
+				}},
+				Spec: cmapi.CertificateSpec{
+					CommonName: "example.com",
+					PrivateKey: &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm},
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+				},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: testcrypto.MustCreatePEMPrivateKey(t),
+					corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "imported.example.com"}},
+						clock.Now(), clock.Now().Add(time.Hour*24*90),
+					),
+				},
+			},
+		},
+		"trigger issuance as Secret is missing certificate for an externally issued Certificate": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					cmapi.ExternallyIssuedAnnotation: "true",
+				}},
+				Spec: cmapi.CertificateSpec{SecretName: "something"},
+			},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"},
+				Data: map[string][]byte{corev1.TLSPrivateKeyKey: []byte("test")},
+			},
+			reason:  MissingData,
+			message: "Issuing certificate as Secret does not contain a certificate",
+			reissue: true,
+		},
+		"trigger issuance if the certificate in the Secret is not valid yet": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				CommonName: "example.com",
+				IssuerRef: cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				},
+			}},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
 						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+						clock.Now().Add(time.Hour), clock.Now().Add(time.Hour*24*90),
 					),
 				},
 			},
+			reason:  NotYetValid,
+			message: "Issuing certificate as the existing certificate is not valid until " + clock.Now().Add(time.Hour).Format(time.RFC1123),
+			reissue: true,
 		},
 		"trigger renewal if renewalTime is right now": {
 			certificate: &cmapi.Certificate{
@@ -486,545 +812,3356 @@ func Test_NewTriggerPolicyChain(t *testing.T) {
 				},
 			},
 		},
-	}
-	policyChain := NewTriggerPolicyChain(clock)
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			reason, message, reissue := policyChain.Evaluate(Input{
-				Certificate:            test.certificate,
-				CurrentRevisionRequest: test.request,
-				Secret:                 test.secret,
-			})
-
-			if test.reason != reason {
-				t.Errorf("unexpected 'reason' exp=%s, got=%s", test.reason, reason)
-			}
-			if test.message != message {
-				t.Errorf("unexpected 'message' exp=%s, got=%s", test.message, message)
-			}
-			if test.reissue != reissue {
-				t.Errorf("unexpected 'reissue' exp=%v, got=%v", test.reissue, reissue)
-			}
-		})
-	}
-}
-
-func Test_SecretTemplateMismatchesSecret(t *testing.T) {
-	tests := map[string]struct {
-		tmpl         *cmapi.CertificateSecretTemplate
-		secret       *corev1.Secret
-		expViolation bool
-		expReason    string
-		expMessage   string
-	}{
-		"if SecretTemplate is nil, Secret Annotations and Labels are nil, return false": {
-			tmpl:         nil,
-			secret:       &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: nil, Labels: nil}},
-			expViolation: false,
-			expReason:    "",
-			expMessage:   "",
-		},
-		"if SecretTemplate is nil, Secret Annotations are nil, Labels are non-nil, return false": {
-			tmpl:         nil,
-			secret:       &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: nil, Labels: map[string]string{"foo": "bar"}}},
-			expViolation: false,
-			expReason:    "",
-			expMessage:   "",
-		},
-		"if SecretTemplate is nil, Secret Annotations are non-nil, Labels are nil, return false": {
-			tmpl:         nil,
-			secret:       &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"foo": "bar"}, Labels: nil}},
-			expViolation: false,
-			expReason:    "",
-			expMessage:   "",
-		},
-		"if SecretTemplate is nil, Secret Annotations and Labels are non-nil, return false": {
-			tmpl: nil,
-			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
-				Annotations: map[string]string{"foo": "bar"},
-				Labels:      map[string]string{"bar": "foo"},
-			}},
-			expViolation: false,
-			expReason:    "",
-			expMessage:   "",
-		},
-		"if SecretTemplate is non-nil, Secret Annotations match but Labels are nil, return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
+		"trigger renewal if renewBeforePercentage results in a renewal time of right now": {
+			certificate: &cmapi.Certificate{
+				Spec: cmapi.CertificateSpec{
+					CommonName: "example.com",
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+					RenewBeforePercentage: pointer.Int32(60),
+				},
+				Status: cmapi.CertificateStatus{
+					RenewalTime: &metav1.Time{Time: clock.Now()},
+				},
 			},
-			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      nil,
-			}},
-			expViolation: true,
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate Labels missing or incorrect value on Secret",
-		},
-		"if SecretTemplate is non-nil, Secret Labels match but Annotations are nil, return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+						clock.Now().Add(time.Minute*-40),
+						// expires in 60 minutes time, i.e. a duration of 100 minutes
+						clock.Now().Add(time.Minute*60),
+					),
+				},
 			},
-			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
-				Annotations: nil,
-				Labels:      map[string]string{"abc": "123", "def": "456"},
-			}},
-			expViolation: true,
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate Annotations missing or incorrect value on Secret",
+			reason:  Renewing,
+			message: "Renewing certificate as renewal was scheduled at 0001-01-01 00:00:00 +0000 UTC",
+			reissue: true,
 		},
-		"if SecretTemplate is non-nil, Secret Labels match but Annotations don't match keys, return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
+		"does not trigger renewal if renewBeforePercentage results in a renewal time in 1 minute": {
+			certificate: &cmapi.Certificate{
+				Spec: cmapi.CertificateSpec{
+					CommonName: "example.com",
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+					RenewBeforePercentage: pointer.Int32(59),
+				},
+				Status: cmapi.CertificateStatus{
+					RenewalTime: &metav1.Time{Time: clock.Now()},
+				},
 			},
-			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
-				Annotations: map[string]string{"foo2": "bar1", "foo1": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
-			}},
-			expViolation: true,
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate Annotations missing or incorrect value on Secret",
-		},
-		"if SecretTemplate is non-nil, Secret Annoations match but Labels don't match keys, return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+						clock.Now().Add(time.Minute*-40),
+						// expires in 60 minutes time, i.e. a duration of 100 minutes
+						clock.Now().Add(time.Minute*60),
+					),
+				},
 			},
-			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"def": "123", "abc": "456"},
-			}},
-			expViolation: true,
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate Labels missing or incorrect value on Secret",
 		},
-		"if SecretTemplate is non-nil, Secret Labels match but Annotations don't match values, return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
+		"does not trigger issuance if an Opaque Secret contains valid tls data": {
+			certificate: &cmapi.Certificate{
+				Spec: cmapi.CertificateSpec{
+					CommonName: "example.com",
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+				},
 			},
-			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
-				Annotations: map[string]string{"foo1": "bar2", "foo2": "bar1"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
-			}},
-			expViolation: true,
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate Annotations missing or incorrect value on Secret",
-		},
-		"if SecretTemplate is non-nil, Secret Annotations match but Labels don't match values, return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+						clock.Now().Add(time.Minute*-40),
+						// expires in 60 minutes time, i.e. a duration of 100 minutes
+						clock.Now().Add(time.Minute*60),
+					),
+				},
+				Type: corev1.SecretTypeOpaque,
 			},
-			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "456", "def": "123"},
-			}},
-			expViolation: true,
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate Labels missing or incorrect value on Secret",
 		},
-		"if SecretTemplate is non-nil, Secret Annotations and Labels match, return false": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
+		"renewBeforePercentage is ignored if it is out of range": {
+			certificate: &cmapi.Certificate{
+				Spec: cmapi.CertificateSpec{
+					CommonName: "example.com",
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+					RenewBeforePercentage: pointer.Int32(100),
+				},
+				Status: cmapi.CertificateStatus{
+					RenewalTime: &metav1.Time{Time: clock.Now()},
+				},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+						clock.Now().Add(time.Minute*-40),
+						// expires in 60 minutes time, i.e. a duration of 100 minutes
+						clock.Now().Add(time.Minute*60),
+					),
+				},
 			},
-			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
-			}},
-			expViolation: false,
-			expReason:    "",
-			expMessage:   "",
 		},
 	}
-
+	// All referenced issuers exist for the purposes of these tests.
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(cmmeta.ObjectReference, string) (cmapi.GenericIssuer, error) {
+			return &cmapi.Issuer{}, nil
+		},
+	}
+	policyChain := NewTriggerPolicyChain(clock, helper, TriggerPolicyOptions{})
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			gotReason, gotMessage, gotViolation := SecretTemplateMismatchesSecret(Input{
-				Certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretTemplate: test.tmpl}},
-				Secret:      test.secret,
+			reason, message, reissue := policyChain.Evaluate(Input{
+				Certificate:            test.certificate,
+				CurrentRevisionRequest: test.request,
+				Secret:                 test.secret,
 			})
 
-			assert.Equal(t, test.expReason, gotReason, "unexpected reason")
-			assert.Equal(t, test.expMessage, gotMessage, "unexpected message")
-			assert.Equal(t, test.expViolation, gotViolation, "unexpected violation")
+			if test.reason != reason {
+				t.Errorf("unexpected 'reason' exp=%s, got=%s", test.reason, reason)
+			}
+			if test.message != message {
+				t.Errorf("unexpected 'message' exp=%s, got=%s", test.message, message)
+			}
+			if test.reissue != reissue {
+				t.Errorf("unexpected 'reissue' exp=%v, got=%v", test.reissue, reissue)
+			}
 		})
 	}
 }
 
-func Test_SecretTemplateMismatchesSecretManagedFields(t *testing.T) {
-	const fieldManager = "cert-manager-unit-test"
-
-	var (
-		fixedClockStart = time.Now()
-		fixedClock      = fakeclock.NewFakeClock(fixedClockStart)
-		baseCertBundle  = testcrypto.MustCreateCryptoBundle(t,
-			gen.Certificate("test-certificate", gen.SetCertificateCommonName("cert-manager")), fixedClock)
+// Benchmark_NewTriggerPolicyChain_Workers evaluates the trigger policy chain
+// for an up to date Certificate using a varying number of concurrent workers,
+// as the certificates-trigger controller does, to show how the throughput of
+// policy evaluation scales with the number of workers.
+func Benchmark_NewTriggerPolicyChain_Workers(b *testing.B) {
+	fixedClock := fakeclock.NewFakeClock(time.Now())
+	crt := gen.Certificate("test",
+		gen.SetCertificateNamespace("testns"),
+		gen.SetCertificateCommonName("example.com"),
+		gen.SetCertificateDNSNames("example.com"),
+		gen.SetCertificateSecretName("test-tls"),
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "testissuer", Kind: "Issuer", Group: "cert-manager.io"}),
+		gen.SetCertificateRevision(1),
 	)
+	bundle := testcrypto.MustCreateCryptoBundle(b, crt, fixedClock)
+	input := Input{
+		Certificate: bundle.Certificate,
+		Secret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test-tls",
+				Annotations: map[string]string{
+					cmapi.IssuerNameAnnotationKey:  "testissuer",
+					cmapi.IssuerKindAnnotationKey:  "Issuer",
+					cmapi.IssuerGroupAnnotationKey: "cert-manager.io",
+				},
+			},
+			Data: map[string][]byte{
+				corev1.TLSPrivateKeyKey: bundle.PrivateKeyBytes,
+				corev1.TLSCertKey:       bundle.CertBytes,
+			},
+		},
+		CurrentRevisionRequest: bundle.CertificateRequestReady,
+	}
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(cmmeta.ObjectReference, string) (cmapi.GenericIssuer, error) {
+			return &cmapi.Issuer{}, nil
+		},
+	}
+	chain := NewTriggerPolicyChain(fixedClock, helper, TriggerPolicyOptions{})
+	if reason, message, reissue := chain.Evaluate(input); reissue {
+		b.Fatalf("unexpected violation %s: %s", reason, message)
+	}
 
-	tests := map[string]struct {
-		tmpl                *cmapi.CertificateSecretTemplate
-		secretManagedFields []metav1.ManagedFieldsEntry
-		secretData          map[string][]byte
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			var next int64
+			var wg sync.WaitGroup
+			b.ReportAllocs()
+			b.ResetTimer()
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for atomic.AddInt64(&next, 1) <= int64(b.N) {
+						chain.Evaluate(input)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
 
-		expReason    string
-		expMessage   string
-		expViolation bool
-	}{
-		"if template is nil and no managed fields, should return false": {
-			tmpl:                nil,
-			secretManagedFields: nil,
-			expReason:           "",
-			expMessage:          "",
-			expViolation:        false,
-		},
-		"if template is nil, managed fields is not nil but not managed by cert-manager, should return false": {
-			tmpl: nil,
-			secretManagedFields: []metav1.ManagedFieldsEntry{{
-				Manager: "not-cert-manager", FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:bar": {}
-							},
-							"f:labels": {
-								"f:123": {}
-							}
-						}}`),
-				}},
+func Test_NewTriggerPolicyChain_DisabledPolicies(t *testing.T) {
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	crt := &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+		SecretName: "something",
+		CommonName: "example.com",
+		IssuerRef:  cmmeta.ObjectReference{Name: "testissuer"},
+	}}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "something",
+			Annotations: map[string]string{
+				cmapi.IssuerNameAnnotationKey: "oldissuer",
 			},
-			expReason:    "",
-			expMessage:   "",
-			expViolation: false,
 		},
-		"if template is nil, managed fields is not nil but fields are nil, should return false": {
-			tmpl:                nil,
-			secretManagedFields: []metav1.ManagedFieldsEntry{{Manager: fieldManager, FieldsV1: nil}},
-			expReason:           "",
-			expMessage:          "",
-			expViolation:        false,
+		Data: map[string][]byte{
+			corev1.TLSPrivateKeyKey: pk,
+			corev1.TLSCertKey:       testcrypto.MustCreateCert(t, pk, crt),
 		},
-		"if template is not-nil but managed fields is nil, should return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo": "bar"},
-				Labels:      map[string]string{"abc": "123"},
-			},
-			secretManagedFields: nil,
-			expReason:           SecretTemplateMismatch,
-			expMessage:          "Certificate's SecretTemplate doesn't match Secret",
-			expViolation:        true,
+	}
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(cmmeta.ObjectReference, string) (cmapi.GenericIssuer, error) {
+			return &cmapi.Issuer{}, nil
 		},
-		"if template is nil but managed fields is not nil, should return true": {
-			tmpl: nil,
-			secretManagedFields: []metav1.ManagedFieldsEntry{{
-				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo": {}
-							},
-							"f:labels": {
-								"f:abc": {}
-							}
-						}}`),
-				}},
+	}
+	clock := fakeclock.NewFakeClock(time.Now())
+
+	reason, _, reissue := NewTriggerPolicyChain(clock, helper, TriggerPolicyOptions{}).Evaluate(Input{Certificate: crt, Secret: secret})
+	assert.True(t, reissue, "expected the outdated issuer annotation to trigger issuance")
+	assert.Equal(t, IncorrectIssuer, reason)
+
+	opts := TriggerPolicyOptions{DisabledPolicies: sets.NewString(SecretIssuerAnnotationsNotUpToDatePolicy)}
+	reason, message, reissue := NewTriggerPolicyChain(clock, helper, opts).Evaluate(Input{Certificate: crt, Secret: secret})
+	assert.False(t, reissue, "expected the disabled policy not to trigger issuance, got %s: %s", reason, message)
+}
+
+func Test_NewTriggerPolicyChain_Names(t *testing.T) {
+	helper := &issuerfake.Helper{}
+	clock := fakeclock.NewFakeClock(time.Now())
+
+	allOpts := TriggerPolicyOptions{
+		ResyncExpiryMargin:            time.Minute,
+		DurationTolerance:             time.Minute,
+		MinimumDuration:               time.Hour,
+		IssuedBeforeCutoff:            clock.Now(),
+		ProtectUnmanagedSecrets:       true,
+		DetectPrivateKeyReuse:         true,
+		CompareIssuerProfile:          true,
+		CheckDeniedUsages:             true,
+		CheckMissingRevision:          true,
+		CheckMultipleLeafCertificates: true,
+		CheckChainVerifies:            true,
+		CheckMissingSANs:              true,
+		CheckUnexpectedCommonName:     true,
+		MinimumRSAKeySize:             2048,
+		IssuerCA:                      func(cmapi.GenericIssuer) (*x509.Certificate, error) { return nil, nil },
+		IssuerCAExpiry:                func(cmapi.GenericIssuer) (*x509.Certificate, error) { return nil, nil },
+		RevokedIntermediates:          func() (sets.String, error) { return sets.NewString(), nil },
+		CheckOutputFormats:            true,
+		SerialIndex:                   NewSerialIndex(DefaultSerialIndexSize),
+	}
+	allNames := []string{
+		IssuerDoesNotExistPolicy,
+		DurationBelowMinimumPolicy,
+		SecretDoesNotExistPolicy,
+		SecretIsTerminatingPolicy,
+		SecretIsImmutablePolicy,
+		SecretIsNotManagedPolicy,
+		SecretHasWrongTypePolicy,
+		SecretIsMissingDataPolicy,
+		SecretHasMultipleLeafCertificatesPolicy,
+		SecretPublicKeysDifferPolicy,
+		SecretChainDoesNotVerifyPolicy,
+		SecretAdditionalOutputFormatsMismatchPolicy,
+		SecretPrivateKeyBelowMinimumSizePolicy,
+		SecretPrivateKeyMatchesSpecPolicy,
+		SecretPrivateKeyReusedPolicy,
+		SecretIssuerAnnotationsNotUpToDatePolicy,
+		SecretIssuerProfileNotUpToDatePolicy,
+		SecretCertificateHasDeniedUsagesPolicy,
+		SecretAuthorityKeyIDMismatchPolicy,
+		SecretCertificateOutlivesIssuerCAPolicy,
+		SecretIssuedByRevokedIntermediatePolicy,
+		SecretRevisionInvalidPolicy,
+		CurrentCertificateRequestPublicKeyMismatchPolicy,
+		SecretCertificateMismatchesCurrentRequestPolicy,
+		CurrentCertificateRequestNotValidForSpecPolicy,
+		SecretOCSPMustStapleMismatchPolicy,
+		SecretEmbeddedSCTsMismatchPolicy,
+		SecretCertificatePoliciesMissingPolicy,
+		SecretCertificateMissingSANsPolicy,
+		SecretCertificateHasUnexpectedCommonNamePolicy,
+		SecretDurationExceedsSpecPolicy,
+		SecretIssuedBeforeCutoffPolicy,
+		CurrentCertificateOutsideValidityWindowPolicy,
+		CurrentCertificateNearingExpiryPolicy,
+		CurrentCertificateExpiresBeforeResyncPolicy,
+		SecretIsMissingRevisionPolicy,
+		SecretSerialNumberDuplicatedPolicy,
+	}
+
+	tests := map[string]struct {
+		opts     TriggerPolicyOptions
+		expNames []string
+	}{
+		"only the default policies are included if no options are set": {
+			opts: TriggerPolicyOptions{},
+			expNames: []string{
+				IssuerDoesNotExistPolicy,
+				SecretDoesNotExistPolicy,
+				SecretIsTerminatingPolicy,
+				SecretIsImmutablePolicy,
+				SecretHasWrongTypePolicy,
+				SecretIsMissingDataPolicy,
+				SecretPublicKeysDifferPolicy,
+				SecretPrivateKeyMatchesSpecPolicy,
+				SecretIssuerAnnotationsNotUpToDatePolicy,
+				SecretRevisionInvalidPolicy,
+				CurrentCertificateRequestPublicKeyMismatchPolicy,
+				SecretCertificateMismatchesCurrentRequestPolicy,
+				CurrentCertificateRequestNotValidForSpecPolicy,
+				SecretOCSPMustStapleMismatchPolicy,
+				SecretEmbeddedSCTsMismatchPolicy,
+				SecretCertificatePoliciesMissingPolicy,
+				CurrentCertificateOutsideValidityWindowPolicy,
+				CurrentCertificateNearingExpiryPolicy,
 			},
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "SecretTemplate is nil, but Secret contains extra managed entries",
-			expViolation: true,
 		},
-		"if template annotations do not match managed fields, should return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
+		"all policies are included if all options are set": {
+			opts:     allOpts,
+			expNames: allNames,
+		},
+		"disabled policies are not included": {
+			opts: func() TriggerPolicyOptions {
+				opts := allOpts
+				opts.DisabledPolicies = sets.NewString(IssuerDoesNotExistPolicy, SecretSerialNumberDuplicatedPolicy)
+				return opts
+			}(),
+			expNames: allNames[1 : len(allNames)-1],
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expNames, NewTriggerPolicyChain(clock, helper, tc.opts).Names())
+		})
+	}
+
+	// Every policy that may be included in the chain must be known by name,
+	// so that it can be disabled, except for the guard policies.
+	assert.Equal(t, TriggerPolicyNames(), sets.NewString(allNames...).Difference(GuardPolicyNames()))
+	assert.True(t, sets.NewString(allNames...).IsSuperset(GuardPolicyNames()))
+}
+
+func Test_NewTriggerPolicyChain_GuardPoliciesCannotBeDisabled(t *testing.T) {
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(cmmeta.ObjectReference, string) (cmapi.GenericIssuer, error) {
+			return &cmapi.Issuer{}, nil
+		},
+	}
+	clock := fakeclock.NewFakeClock(time.Now())
+	crt := gen.Certificate("test", gen.SetCertificateCommonName("example.com"))
+
+	tests := map[string]struct {
+		secret    *corev1.Secret
+		expReason string
+	}{
+		"the Secret does not exist": {
+			expReason: DoesNotExist,
+		},
+		"the Secret is missing data": {
+			secret:    &corev1.Secret{Data: map[string][]byte{}},
+			expReason: MissingData,
+		},
+	}
+	for _, guard := range GuardPolicyNames().List() {
+		for name, tc := range tests {
+			t.Run(guard+" is disabled and "+name, func(t *testing.T) {
+				opts := TriggerPolicyOptions{
+					ProtectUnmanagedSecrets: true,
+					DisabledPolicies:        sets.NewString(guard),
+				}
+				chain := NewTriggerPolicyChain(clock, helper, opts)
+				assert.Subset(t, chain.Names(), GuardPolicyNames().List())
+
+				var (
+					reason  string
+					reissue bool
+				)
+				assert.NotPanics(t, func() {
+					reason, _, reissue = chain.Evaluate(Input{Certificate: crt, Secret: tc.secret})
+				})
+				assert.True(t, reissue)
+				assert.Equal(t, tc.expReason, reason)
+			})
+		}
+	}
+}
+
+func Test_CurrentCertificateNearingExpiry_RenewalJitter(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, pk,
+				&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+				clock.Now().Add(time.Minute*-40),
+				// expires in 60 minutes time, i.e. renewal is due in 26m40s
+				clock.Now().Add(time.Minute*60),
+			),
+		},
+	}
+
+	tests := map[string]struct {
+		uid    types.UID
+		window time.Duration
+
+		reissue bool
+	}{
+		"does not trigger renewal without a jitter window": {
+			uid:     "uid-b",
+			reissue: false,
+		},
+		"does not trigger renewal if the jitter does not bring the renewal time into the past": {
+			// jitter of 25m27s
+			uid:     "uid-a",
+			window:  time.Hour,
+			reissue: false,
+		},
+		"triggers renewal if the jitter brings the renewal time into the past": {
+			// jitter of 43m47s, limited to 33m20s
+			uid:     "uid-b",
+			window:  time.Hour,
+			reissue: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			crt := &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{UID: test.uid}}
+			_, _, reissue := CurrentCertificateNearingExpiry(clock, TriggerPolicyOptions{RenewalJitterWindow: test.window})(Input{Certificate: crt, Secret: secret})
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_CurrentCertificateNearingExpiry_RenewalGraceTolerance(t *testing.T) {
+	// The certificate has a duration of 60 minutes, so with the default
+	// renewBefore of 1/3 its renewal time is exactly renewalTime.
+	renewalTime := time.Now().Truncate(time.Second)
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, pk,
+				&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+				renewalTime.Add(time.Minute*-40),
+				renewalTime.Add(time.Minute*20),
+			),
+		},
+	}
+
+	tests := map[string]struct {
+		now       time.Time
+		tolerance time.Duration
+
+		reissue bool
+	}{
+		"triggers renewal at the renewal time without a tolerance": {
+			now:     renewalTime,
+			reissue: true,
+		},
+		"does not trigger renewal at the renewal time with a tolerance": {
+			now:       renewalTime,
+			tolerance: time.Second * 5,
+			reissue:   false,
+		},
+		"does not trigger renewal before the tolerance has passed": {
+			now:       renewalTime.Add(time.Second * 4),
+			tolerance: time.Second * 5,
+			reissue:   false,
+		},
+		"triggers renewal once the tolerance has passed": {
+			now:       renewalTime.Add(time.Second * 5),
+			tolerance: time.Second * 5,
+			reissue:   true,
+		},
+		"triggers renewal after the tolerance has passed": {
+			now:       renewalTime.Add(time.Second * 6),
+			tolerance: time.Second * 5,
+			reissue:   true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := CurrentCertificateNearingExpiry(fakeclock.NewFakeClock(test.now), TriggerPolicyOptions{RenewalGraceTolerance: test.tolerance})
+			_, _, reissue := policy(Input{Certificate: &cmapi.Certificate{}, Secret: secret})
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_CurrentCertificateOutsideValidityWindow(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	secretWithWindow := func(notBefore, notAfter time.Time) *corev1.Secret {
+		return &corev1.Secret{
+			Data: map[string][]byte{
+				corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, pk,
+					&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+					notBefore,
+					notAfter,
+				),
 			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{{
-				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo3": {}
-							},
-							"f:labels": {
-								"f:abc": {},
-								"f:def": {}
-							}
-						}}`),
-				}},
+		}
+	}
+
+	tests := map[string]struct {
+		secret *corev1.Secret
+
+		reason  string
+		message string
+		reissue bool
+	}{
+		"does not trigger issuance if the validity window includes now": {
+			secret:  secretWithWindow(now.Add(-time.Hour), now.Add(time.Hour)),
+			reissue: false,
+		},
+		"triggers issuance if notBefore is in the future": {
+			secret:  secretWithWindow(now.Add(time.Hour), now.Add(time.Hour*2)),
+			reason:  NotYetValid,
+			message: "Issuing certificate as the existing certificate is not valid until " + now.Add(time.Hour).UTC().Format(time.RFC1123),
+			reissue: true,
+		},
+		"does not trigger issuance if notBefore is in the future by less than the clock skew tolerance": {
+			secret:  secretWithWindow(now.Add(notYetValidTolerance), now.Add(time.Hour)),
+			reissue: false,
+		},
+		"triggers issuance if notBefore is in the future by more than the clock skew tolerance": {
+			secret:  secretWithWindow(now.Add(notYetValidTolerance+time.Second), now.Add(time.Hour)),
+			reason:  NotYetValid,
+			message: "Issuing certificate as the existing certificate is not valid until " + now.Add(notYetValidTolerance+time.Second).UTC().Format(time.RFC1123),
+			reissue: true,
+		},
+		"triggers issuance if the whole validity window is in the past": {
+			secret:  secretWithWindow(now.Add(-time.Hour*2), now.Add(-time.Hour)),
+			reason:  Expired,
+			message: "Issuing certificate as the existing certificate expired on " + now.Add(-time.Hour).UTC().Format(time.RFC1123),
+			reissue: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := CurrentCertificateOutsideValidityWindow(fakeclock.NewFakeClock(now))
+			reason, message, reissue := policy(Input{Certificate: &cmapi.Certificate{}, Secret: test.secret})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_CurrentCertificateExpiresBeforeResync(t *testing.T) {
+	notAfter := time.Now().Truncate(time.Second)
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	secretWithDuration := func(duration time.Duration) *corev1.Secret {
+		return &corev1.Secret{
+			Data: map[string][]byte{
+				corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, pk,
+					&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+					notAfter.Add(-duration),
+					notAfter,
+				),
 			},
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
-			expViolation: true,
+		}
+	}
+	// The resync period plus the margin is 11 hours.
+	opts := TriggerPolicyOptions{ResyncPeriod: time.Hour * 10, ResyncExpiryMargin: time.Hour}
+
+	tests := map[string]struct {
+		now    time.Time
+		secret *corev1.Secret
+
+		reason  string
+		reissue bool
+	}{
+		"does not trigger renewal if the certificate expires after the next resync and margin": {
+			now:     notAfter.Add(-time.Hour*11 - time.Second),
+			secret:  secretWithDuration(time.Hour * 24 * 30),
+			reissue: false,
 		},
-		"if template labels do not match managed fields, should return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
+		"does not trigger renewal if the certificate expires exactly at the end of the next resync and margin": {
+			now:     notAfter.Add(-time.Hour * 11),
+			secret:  secretWithDuration(time.Hour * 24 * 30),
+			reissue: false,
+		},
+		"triggers renewal if the certificate expires before the next resync and margin": {
+			now:     notAfter.Add(-time.Hour*11 + time.Second),
+			secret:  secretWithDuration(time.Hour * 24 * 30),
+			reason:  ExpiresBeforeResync,
+			reissue: true,
+		},
+		"does not trigger renewal if the certificate's duration is shorter than the resync period and margin": {
+			now:     notAfter.Add(-time.Hour),
+			secret:  secretWithDuration(time.Hour * 10),
+			reissue: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := CurrentCertificateExpiresBeforeResync(fakeclock.NewFakeClock(test.now), opts)
+			reason, _, reissue := policy(Input{Certificate: &cmapi.Certificate{}, Secret: test.secret})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretDurationExceedsSpec(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	secretWithDuration := func(duration time.Duration) *corev1.Secret {
+		return &corev1.Secret{
+			Data: map[string][]byte{
+				corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, pk,
+					&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+					now,
+					now.Add(duration),
+				),
 			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{{
-				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo2": {}
-							},
-							"f:labels": {
-								"f:abc": {},
-								"f:erg": {}
-							}
-						}}`),
-				}},
+		}
+	}
+	policy := SecretDurationExceedsSpec(TriggerPolicyOptions{DurationTolerance: time.Hour})
+
+	tests := map[string]struct {
+		duration *metav1.Duration
+		secret   *corev1.Secret
+
+		reason  string
+		message string
+		reissue bool
+	}{
+		"does not trigger issuance if the certificate's validity matches spec.duration": {
+			duration: &metav1.Duration{Duration: time.Hour * 24 * 30},
+			secret:   secretWithDuration(time.Hour * 24 * 30),
+		},
+		"does not trigger issuance if the certificate's validity exceeds spec.duration by no more than the tolerance": {
+			duration: &metav1.Duration{Duration: time.Hour * 24 * 30},
+			secret:   secretWithDuration(time.Hour*24*30 + time.Hour),
+		},
+		"triggers issuance if the certificate's validity exceeds spec.duration by more than the tolerance": {
+			duration: &metav1.Duration{Duration: time.Hour * 24 * 30},
+			secret:   secretWithDuration(time.Hour * 24 * 365),
+			reason:   SecretMismatch,
+			message:  "Existing issued Secret is not up to date for spec: [spec.duration]",
+			reissue:  true,
+		},
+		"compares against the default duration if spec.duration is not set": {
+			secret:  secretWithDuration(cmapi.DefaultCertificateDuration + time.Hour*2),
+			reason:  SecretMismatch,
+			message: "Existing issued Secret is not up to date for spec: [spec.duration]",
+			reissue: true,
+		},
+		"does not trigger issuance if the certificate is shorter than spec.duration": {
+			duration: &metav1.Duration{Duration: time.Hour * 24 * 30},
+			secret:   secretWithDuration(time.Hour * 24),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			crt := &cmapi.Certificate{Spec: cmapi.CertificateSpec{Duration: test.duration}}
+			reason, message, reissue := policy(Input{Certificate: crt, Secret: test.secret})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretIsNotManaged(t *testing.T) {
+	data := map[string][]byte{"password": []byte("hunter2")}
+	tests := map[string]struct {
+		secret *corev1.Secret
+
+		reason  string
+		message string
+		blocked bool
+	}{
+		"blocks issuance into an unmanaged Secret containing data": {
+			secret:  &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"}, Data: data},
+			reason:  SecretNotManaged,
+			message: `Secret "something" was not created by cert-manager, refusing to overwrite it. Annotate the Secret with cert-manager.io/allow-adoption: "true" to allow it to be adopted`,
+			blocked: true,
+		},
+		"blocks issuance if the adoption annotation is not set to true": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something", Annotations: map[string]string{
+				cmapi.AllowSecretAdoptionAnnotationKey: "false",
+			}}, Data: data},
+			reason:  SecretNotManaged,
+			message: `Secret "something" was not created by cert-manager, refusing to overwrite it. Annotate the Secret with cert-manager.io/allow-adoption: "true" to allow it to be adopted`,
+			blocked: true,
+		},
+		"does not block issuance into an unmanaged Secret with the adoption annotation": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something", Annotations: map[string]string{
+				cmapi.AllowSecretAdoptionAnnotationKey: "true",
+			}}, Data: data},
+		},
+		"does not block issuance into a Secret managed by cert-manager": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something", Annotations: map[string]string{
+				cmapi.CertificateNameKey: "something",
+			}}, Data: data},
+		},
+		"does not block issuance into a Secret with a cert-manager issuer annotation": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something", Annotations: map[string]string{
+				cmapi.IssuerNameAnnotationKey: "testissuer",
+			}}, Data: data},
+		},
+		"does not block issuance into an empty unmanaged Secret": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, blocked := SecretIsNotManaged(Input{Certificate: &cmapi.Certificate{}, Secret: test.secret})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.blocked, blocked)
+		})
+	}
+}
+
+func Test_SecretIssuedBeforeCutoff(t *testing.T) {
+	cutoff := time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC)
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	secretWithNotBefore := func(notBefore time.Time) *corev1.Secret {
+		return &corev1.Secret{
+			Data: map[string][]byte{
+				corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, pk,
+					&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+					notBefore,
+					notBefore.Add(time.Hour*24*90),
+				),
 			},
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
-			expViolation: true,
+		}
+	}
+	policy := SecretIssuedBeforeCutoff(TriggerPolicyOptions{IssuedBeforeCutoff: cutoff})
+
+	// A certificate whose notBefore was backdated to before the cutoff by
+	// its issuer, and the request it was issued for.
+	backdatedSecret := secretWithNotBefore(cutoff.Add(-time.Hour))
+	requestCreatedAt := func(created time.Time, cert []byte) *cmapi.CertificateRequest {
+		return gen.CertificateRequest("test-2",
+			gen.SetCertificateRequestCertificate(cert),
+			func(cr *cmapi.CertificateRequest) { cr.CreationTimestamp = metav1.NewTime(created) },
+		)
+	}
+
+	tests := map[string]struct {
+		secret         *corev1.Secret
+		currentRequest *cmapi.CertificateRequest
+
+		reason  string
+		message string
+		reissue bool
+	}{
+		"triggers issuance if the certificate was issued well before the cutoff": {
+			secret:  secretWithNotBefore(cutoff.Add(-time.Hour * 24 * 30)),
+			reason:  IssuedBeforeCutoff,
+			message: "Issued certificate has a notBefore of 2022-05-02T12:00:00Z, which is before the cutoff of 2022-06-01T12:00:00Z",
+			reissue: true,
+		},
+		"triggers issuance if the certificate was issued one second before the cutoff": {
+			secret:  secretWithNotBefore(cutoff.Add(-time.Second)),
+			reason:  IssuedBeforeCutoff,
+			message: "Issued certificate has a notBefore of 2022-06-01T11:59:59Z, which is before the cutoff of 2022-06-01T12:00:00Z",
+			reissue: true,
+		},
+		"does not trigger issuance if the certificate was issued exactly at the cutoff": {
+			secret: secretWithNotBefore(cutoff),
+		},
+		"does not trigger issuance if the certificate was issued one second after the cutoff": {
+			secret: secretWithNotBefore(cutoff.Add(time.Second)),
+		},
+		"does not trigger issuance if the certificate cannot be parsed": {
+			secret: &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: []byte("invalid")}},
+		},
+		"does not trigger issuance if a backdated certificate was issued by a request created after the cutoff": {
+			secret:         backdatedSecret,
+			currentRequest: requestCreatedAt(cutoff.Add(time.Minute), backdatedSecret.Data[corev1.TLSCertKey]),
+		},
+		"triggers issuance if the certificate was issued by a request created before the cutoff": {
+			secret:         backdatedSecret,
+			currentRequest: requestCreatedAt(cutoff.Add(-time.Minute), backdatedSecret.Data[corev1.TLSCertKey]),
+			reason:         IssuedBeforeCutoff,
+			message:        "Issued certificate has a notBefore of 2022-06-01T11:00:00Z, which is before the cutoff of 2022-06-01T12:00:00Z",
+			reissue:        true,
+		},
+		"triggers issuance if the certificate was not issued by the request created after the cutoff": {
+			secret:         backdatedSecret,
+			currentRequest: requestCreatedAt(cutoff.Add(time.Minute), secretWithNotBefore(cutoff).Data[corev1.TLSCertKey]),
+			reason:         IssuedBeforeCutoff,
+			message:        "Issued certificate has a notBefore of 2022-06-01T11:00:00Z, which is before the cutoff of 2022-06-01T12:00:00Z",
+			reissue:        true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, reissue := policy(Input{Certificate: &cmapi.Certificate{}, Secret: test.secret, CurrentRevisionRequest: test.currentRequest})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretSerialNumberDuplicated(t *testing.T) {
+	pk, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secretWithSerial := func(issuer string, serial int64) *corev1.Secret {
+		template, err := pki.GenerateTemplate(&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: issuer}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.SerialNumber = big.NewInt(serial)
+		template.PublicKey = pk.Public()
+		certPEM, _, err := pki.SignCertificate(template, template, pk.Public(), pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: certPEM}}
+	}
+	index := NewSerialIndex(DefaultSerialIndexSize)
+	policy := SecretSerialNumberDuplicated(index)
+
+	// Evaluating the policy alone does not record the serial number.
+	for _, name := range []string{"a", "b"} {
+		_, _, failed := policy(Input{
+			Certificate: gen.Certificate(name, gen.SetCertificateNamespace("ns")),
+			Secret:      secretWithSerial("ca-1", 1),
+		})
+		assert.False(t, failed)
+	}
+
+	// Steps are evaluated in order against the same index, recording the
+	// serial number after each evaluation as the trigger controller does.
+	steps := []struct {
+		name      string
+		namespace string
+		secret    *corev1.Secret
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		{name: "a", namespace: "ns", secret: secretWithSerial("ca-1", 1)},
+		{name: "a", namespace: "ns", secret: secretWithSerial("ca-1", 1)},
+		{name: "b", namespace: "ns", secret: secretWithSerial("ca-1", 2)},
+		{
+			name:      "b",
+			namespace: "ns",
+			secret:    secretWithSerial("ca-1", 1),
+			reason:    DuplicateSerial,
+			message:   `Issued certificate has serial number 1, which is also used by the issued certificate of Certificate "a" from the same issuer`,
+			failed:    true,
+		},
+		// serial numbers are only unique per issuer
+		{name: "c", namespace: "ns", secret: secretWithSerial("ca-2", 1)},
+		{name: "c", namespace: "other-ns", secret: secretWithSerial("ca-1", 1)},
+		// once "a" has a new serial number, its previous one is dropped
+		{name: "a", namespace: "ns", secret: secretWithSerial("ca-1", 3)},
+		{name: "b", namespace: "ns", secret: secretWithSerial("ca-1", 1)},
+		{name: "d", namespace: "ns", secret: &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: []byte("invalid")}}},
+	}
+	for i, step := range steps {
+		input := Input{
+			Certificate: gen.Certificate(step.name, gen.SetCertificateNamespace(step.namespace)),
+			Secret:      step.secret,
+		}
+		reason, message, failed := policy(input)
+		assert.Equal(t, step.reason, reason, "step %d", i)
+		assert.Equal(t, step.message, message, "step %d", i)
+		assert.Equal(t, step.failed, failed, "step %d", i)
+
+		if x509cert, err := pki.DecodeX509CertificateBytes(step.secret.Data[corev1.TLSCertKey]); err == nil {
+			index.Record(step.namespace, step.name, x509cert)
+		}
+	}
+}
+
+func Test_SecretAdditionalOutputFormatsMismatch(t *testing.T) {
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	cert := testcrypto.MustCreateCert(t, pk, gen.Certificate("test", gen.SetCertificateCommonName("example.com")))
+	otherCert := testcrypto.MustCreateCert(t, pk, gen.Certificate("test", gen.SetCertificateCommonName("other.example.com")))
+	block, _ := pem.Decode(pk)
+
+	withFormats := func(formats ...cmapi.CertificateOutputFormatType) gen.CertificateModifier {
+		return func(crt *cmapi.Certificate) {
+			for _, format := range formats {
+				crt.Spec.AdditionalOutputFormats = append(crt.Spec.AdditionalOutputFormats, cmapi.CertificateAdditionalOutputFormat{Type: format})
+			}
+		}
+	}
+	secretData := func(extra map[string][]byte) map[string][]byte {
+		data := map[string][]byte{corev1.TLSPrivateKeyKey: pk, corev1.TLSCertKey: cert}
+		for k, v := range extra {
+			data[k] = v
+		}
+		return data
+	}
+
+	tests := map[string]struct {
+		certificate *cmapi.Certificate
+		data        map[string][]byte
+
+		reason  string
+		message string
+		reissue bool
+	}{
+		"do nothing if no additional output formats are requested": {
+			certificate: gen.Certificate("test"),
+			data:        secretData(nil),
+		},
+		"trigger issuance if CombinedPEM is requested but missing": {
+			certificate: gen.Certificate("test", withFormats(cmapi.CertificateOutputFormatCombinedPEM)),
+			data:        secretData(nil),
+			reason:      MissingData,
+			message:     `Issuing certificate as Secret does not contain CombinedPEM output format data under key "tls-combined.pem"`,
+			reissue:     true,
+		},
+		"do nothing if CombinedPEM is requested and present": {
+			certificate: gen.Certificate("test", withFormats(cmapi.CertificateOutputFormatCombinedPEM)),
+			data: secretData(map[string][]byte{
+				cmapi.CertificateOutputFormatCombinedPEMKey: bytes.Join([][]byte{pk, cert}, []byte("\n")),
+			}),
+		},
+		"trigger issuance if CombinedPEM was derived from a different certificate": {
+			certificate: gen.Certificate("test", withFormats(cmapi.CertificateOutputFormatCombinedPEM)),
+			data: secretData(map[string][]byte{
+				cmapi.CertificateOutputFormatCombinedPEMKey: bytes.Join([][]byte{pk, otherCert}, []byte("\n")),
+			}),
+			reason:  MissingData,
+			message: `Issuing certificate as Secret CombinedPEM output format data under key "tls-combined.pem" does not match the stored certificate and private key`,
+			reissue: true,
+		},
+		"trigger issuance if DER is requested but missing": {
+			certificate: gen.Certificate("test", withFormats(cmapi.CertificateOutputFormatCombinedPEM, cmapi.CertificateOutputFormatDER)),
+			data: secretData(map[string][]byte{
+				cmapi.CertificateOutputFormatCombinedPEMKey: bytes.Join([][]byte{pk, cert}, []byte("\n")),
+			}),
+			reason:  MissingData,
+			message: `Issuing certificate as Secret does not contain DER output format data under key "key.der"`,
+			reissue: true,
+		},
+		"do nothing if DER is requested and present": {
+			certificate: gen.Certificate("test", withFormats(cmapi.CertificateOutputFormatDER)),
+			data:        secretData(map[string][]byte{cmapi.CertificateOutputFormatDERKey: block.Bytes}),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, reissue := SecretAdditionalOutputFormatsMismatch(Input{
+				Certificate: test.certificate,
+				Secret:      &corev1.Secret{Data: test.data},
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretCertificatePoliciesMissing(t *testing.T) {
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	withPolicies := func(value string) gen.CertificateModifier {
+		return gen.AddCertificateAnnotations(map[string]string{cmapi.CertificatePoliciesAnnotation: value})
+	}
+	certWithoutPolicy := testcrypto.MustCreateCert(t, pk, gen.Certificate("test", gen.SetCertificateCommonName("example.com")))
+	certWithPolicy := testcrypto.MustCreateCert(t, pk, gen.Certificate("test", gen.SetCertificateCommonName("example.com"), withPolicies("2.23.140.1.2.1")))
+
+	tests := map[string]struct {
+		certificate *cmapi.Certificate
+		certData    []byte
+
+		reason  string
+		message string
+		reissue bool
+	}{
+		"trigger issuance if a required policy is absent from the certificate": {
+			certificate: gen.Certificate("test", withPolicies("2.23.140.1.2.1")),
+			certData:    certWithoutPolicy,
+			reason:      MissingCertificatePolicy,
+			message:     "Issuing certificate as the existing certificate does not contain the required certificate policies: 2.23.140.1.2.1",
+			reissue:     true,
+		},
+		"trigger issuance if only some of the required policies are present in the certificate": {
+			certificate: gen.Certificate("test", withPolicies("2.23.140.1.2.1,2.23.140.1.2.2")),
+			certData:    certWithPolicy,
+			reason:      MissingCertificatePolicy,
+			message:     "Issuing certificate as the existing certificate does not contain the required certificate policies: 2.23.140.1.2.2",
+			reissue:     true,
+		},
+		"do nothing if the required policy is present in the certificate": {
+			certificate: gen.Certificate("test", withPolicies("2.23.140.1.2.1")),
+			certData:    certWithPolicy,
+		},
+		"do nothing if no policies are required": {
+			certificate: gen.Certificate("test"),
+			certData:    certWithoutPolicy,
+		},
+		"do nothing if the annotation is invalid": {
+			certificate: gen.Certificate("test", withPolicies("not-an-oid")),
+			certData:    certWithoutPolicy,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, reissue := SecretCertificatePoliciesMissing(Input{
+				Certificate: test.certificate,
+				Secret:      &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: test.certData}},
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretPrivateKeyBelowMinimumSize(t *testing.T) {
+	mustEncodeRSA := func(size int) []byte {
+		pk, err := pki.GenerateRSAPrivateKey(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pki.EncodePKCS1PrivateKey(pk)
+	}
+	rsa2048 := mustEncodeRSA(2048)
+	rsa3072 := mustEncodeRSA(3072)
+	ecdsaPK, err := pki.GenerateECPrivateKey(pki.ECCurve256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsa256, err := pki.EncodeECPrivateKey(ecdsaPK)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		minSize int
+		keyData []byte
+
+		reason  string
+		message string
+		reissue bool
+	}{
+		"trigger issuance if the RSA key is below the minimum size": {
+			minSize: 3072,
+			keyData: rsa2048,
+			reason:  SecretMismatch,
+			message: "Issuing certificate as the existing RSA private key size of 2048 bits is below the minimum of 3072 bits",
+			reissue: true,
+		},
+		"trigger issuance if the RSA key is one bit below the minimum size": {
+			minSize: 3073,
+			keyData: rsa3072,
+			reason:  SecretMismatch,
+			message: "Issuing certificate as the existing RSA private key size of 3072 bits is below the minimum of 3073 bits",
+			reissue: true,
+		},
+		"do nothing if the RSA key is exactly the minimum size": {
+			minSize: 3072,
+			keyData: rsa3072,
+		},
+		"do nothing if the RSA key is above the minimum size": {
+			minSize: 2048,
+			keyData: rsa3072,
+		},
+		"do nothing if the key is not an RSA key": {
+			minSize: 3072,
+			keyData: ecdsa256,
+		},
+		"do nothing if the key cannot be decoded": {
+			minSize: 3072,
+			keyData: []byte("invalid"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// The Certificate's own spec.privateKey.size is deliberately
+			// set to a smaller size, as the policy must ignore it.
+			reason, message, reissue := SecretPrivateKeyBelowMinimumSize(TriggerPolicyOptions{MinimumRSAKeySize: test.minSize})(Input{
+				Certificate: gen.Certificate("test", gen.SetCertificateKeyAlgorithm(cmapi.RSAKeyAlgorithm), gen.SetCertificateKeySize(2048)),
+				Secret:      &corev1.Secret{Data: map[string][]byte{corev1.TLSPrivateKeyKey: test.keyData}},
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_DurationBelowMinimum(t *testing.T) {
+	tests := map[string]struct {
+		certificate *cmapi.Certificate
+
+		reason    string
+		message   string
+		violation bool
+	}{
+		"violation if spec.duration is shorter than the minimum": {
+			certificate: gen.Certificate("test", gen.SetCertificateDuration(time.Hour)),
+			reason:      DurationTooShort,
+			message:     "spec.duration of 1h0m0s is shorter than the minimum duration of 24h0m0s accepted by the issuer; increase spec.duration to at least 24h0m0s",
+			violation:   true,
+		},
+		"no violation if spec.duration is equal to the minimum": {
+			certificate: gen.Certificate("test", gen.SetCertificateDuration(24*time.Hour)),
+		},
+		"no violation if spec.duration is longer than the minimum": {
+			certificate: gen.Certificate("test", gen.SetCertificateDuration(48*time.Hour)),
+		},
+		"no violation if spec.duration is not set and the default is longer than the minimum": {
+			certificate: gen.Certificate("test"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, violation := DurationBelowMinimum(TriggerPolicyOptions{MinimumDuration: 24 * time.Hour})(Input{
+				Certificate: test.certificate,
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.violation, violation)
+		})
+	}
+}
+
+func Test_SecretCertificateMissingSANs(t *testing.T) {
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	commonNameOnlyCert := testcrypto.MustCreateCert(t, pk, gen.Certificate("test", gen.SetCertificateCommonName("example.com")))
+	certWithSANs := testcrypto.MustCreateCert(t, pk, gen.Certificate("test",
+		gen.SetCertificateCommonName("example.com"),
+		gen.SetCertificateDNSNames("example.com"),
+	))
+
+	tests := map[string]struct {
+		certificate *cmapi.Certificate
+		certData    []byte
+
+		reason  string
+		message string
+		reissue bool
+	}{
+		"trigger issuance if the certificate only has a common name": {
+			certificate: gen.Certificate("test", gen.SetCertificateCommonName("example.com")),
+			certData:    commonNameOnlyCert,
+			reason:      SecretMismatch,
+			message:     "Existing issued Secret is not up to date for spec: [spec.dnsNames]",
+			reissue:     true,
+		},
+		"do nothing if the certificate has a subject alternative name extension": {
+			certificate: gen.Certificate("test", gen.SetCertificateCommonName("example.com")),
+			certData:    certWithSANs,
+		},
+		"do nothing if the Certificate is a CA": {
+			certificate: gen.Certificate("test", gen.SetCertificateCommonName("example.com"), gen.SetCertificateIsCA(true)),
+			certData:    commonNameOnlyCert,
+		},
+		"do nothing if the Certificate has no common name": {
+			certificate: gen.Certificate("test"),
+			certData:    commonNameOnlyCert,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, reissue := SecretCertificateMissingSANs(Input{
+				Certificate: test.certificate,
+				Secret:      &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: test.certData}},
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretCertificateHasUnexpectedCommonName(t *testing.T) {
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	certWithCommonName := testcrypto.MustCreateCert(t, pk, gen.Certificate("test",
+		gen.SetCertificateCommonName("example.com"),
+		gen.SetCertificateDNSNames("example.com"),
+	))
+	certWithoutCommonName := testcrypto.MustCreateCert(t, pk, gen.Certificate("test", gen.SetCertificateDNSNames("example.com")))
+
+	tests := map[string]struct {
+		certificate *cmapi.Certificate
+		certData    []byte
+
+		reason  string
+		message string
+		reissue bool
+	}{
+		"trigger issuance if the Certificate has no common name but the certificate does": {
+			certificate: gen.Certificate("test", gen.SetCertificateDNSNames("example.com")),
+			certData:    certWithCommonName,
+			reason:      SecretMismatch,
+			message:     "Existing issued Secret is not up to date for spec: [spec.commonName]",
+			reissue:     true,
+		},
+		"do nothing if neither the Certificate nor the certificate have a common name": {
+			certificate: gen.Certificate("test", gen.SetCertificateDNSNames("example.com")),
+			certData:    certWithoutCommonName,
+		},
+		"do nothing if the Certificate has a common name": {
+			certificate: gen.Certificate("test", gen.SetCertificateCommonName("example.com"), gen.SetCertificateDNSNames("example.com")),
+			certData:    certWithCommonName,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, reissue := SecretCertificateHasUnexpectedCommonName(Input{
+				Certificate: test.certificate,
+				Secret:      &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: test.certData}},
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretOCSPMustStapleMismatch(t *testing.T) {
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	withMustStaple := func(value string) gen.CertificateModifier {
+		return gen.AddCertificateAnnotations(map[string]string{cmapi.OCSPMustStapleAnnotation: value})
+	}
+	certWithoutMustStaple := testcrypto.MustCreateCert(t, pk, gen.Certificate("test", gen.SetCertificateCommonName("example.com")))
+	certWithMustStaple := testcrypto.MustCreateCert(t, pk, gen.Certificate("test", gen.SetCertificateCommonName("example.com"), withMustStaple("true")))
+
+	tests := map[string]struct {
+		certificate *cmapi.Certificate
+		certData    []byte
+
+		reason  string
+		reissue bool
+	}{
+		"trigger issuance if must-staple is requested but the certificate does not have it": {
+			certificate: gen.Certificate("test", withMustStaple("true")),
+			certData:    certWithoutMustStaple,
+			reason:      SecretMismatch,
+			reissue:     true,
+		},
+		"do nothing if must-staple is requested and the certificate has it": {
+			certificate: gen.Certificate("test", withMustStaple("true")),
+			certData:    certWithMustStaple,
+		},
+		"trigger issuance if must-staple is not wanted but the certificate has it": {
+			certificate: gen.Certificate("test", withMustStaple("false")),
+			certData:    certWithMustStaple,
+			reason:      SecretMismatch,
+			reissue:     true,
+		},
+		"do nothing if must-staple is not wanted and the certificate does not have it": {
+			certificate: gen.Certificate("test", withMustStaple("false")),
+			certData:    certWithoutMustStaple,
+		},
+		"do nothing if no preference is expressed and the certificate has must-staple": {
+			certificate: gen.Certificate("test"),
+			certData:    certWithMustStaple,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, _, reissue := SecretOCSPMustStapleMismatch(Input{
+				Certificate: test.certificate,
+				Secret:      &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: test.certData}},
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretEmbeddedSCTsMismatch(t *testing.T) {
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	withEmbeddedSCTs := func(value string) gen.CertificateModifier {
+		return gen.AddCertificateAnnotations(map[string]string{cmapi.EmbeddedSCTsAnnotation: value})
+	}
+	// mustCreateCert signs a certificate with the given extra extensions, as
+	// cert-manager never requests SCTs itself.
+	mustCreateCert := func(extensions ...pkix.Extension) []byte {
+		signer, err := pki.DecodePrivateKeyBytes(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template, err := pki.GenerateTemplate(gen.Certificate("test", gen.SetCertificateCommonName("example.com")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.ExtraExtensions = extensions
+		certData, _, err := pki.SignCertificate(template, template, signer.Public(), signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return certData
+	}
+	// A Signed Certificate Timestamp list holding a single 3 byte timestamp.
+	sctList, err := asn1.Marshal([]byte{0x00, 0x05, 0x00, 0x03, 0x01, 0x02, 0x03})
+	if err != nil {
+		t.Fatal(err)
+	}
+	certWithoutSCTs := mustCreateCert()
+	certWithSCTs := mustCreateCert(pkix.Extension{Id: pki.OIDExtensionSCTList, Value: sctList})
+
+	tests := map[string]struct {
+		certificate *cmapi.Certificate
+		certData    []byte
+
+		reason  string
+		message string
+		reissue bool
+	}{
+		"trigger issuance if SCTs are expected but the certificate does not have them": {
+			certificate: gen.Certificate("test", withEmbeddedSCTs("true")),
+			certData:    certWithoutSCTs,
+			reason:      SecretMismatch,
+			message:     "Existing issued Secret is not up to date for spec: [metadata.annotations[cert-manager.io/embedded-scts]]",
+			reissue:     true,
+		},
+		"do nothing if SCTs are expected and the certificate has them": {
+			certificate: gen.Certificate("test", withEmbeddedSCTs("true")),
+			certData:    certWithSCTs,
+		},
+		"trigger issuance if SCTs are not wanted but the certificate has them": {
+			certificate: gen.Certificate("test", withEmbeddedSCTs("false")),
+			certData:    certWithSCTs,
+			reason:      SecretMismatch,
+			message:     "Existing issued Secret is not up to date for spec: [metadata.annotations[cert-manager.io/embedded-scts]]",
+			reissue:     true,
+		},
+		"do nothing if SCTs are not wanted and the certificate does not have them": {
+			certificate: gen.Certificate("test", withEmbeddedSCTs("false")),
+			certData:    certWithoutSCTs,
+		},
+		"do nothing if no expectation is expressed and the certificate has SCTs": {
+			certificate: gen.Certificate("test"),
+			certData:    certWithSCTs,
+		},
+		"do nothing if no expectation is expressed and the certificate does not have SCTs": {
+			certificate: gen.Certificate("test"),
+			certData:    certWithoutSCTs,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, reissue := SecretEmbeddedSCTsMismatch(Input{
+				Certificate: tc.certificate,
+				Secret:      &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: tc.certData}},
+			})
+			assert.Equal(t, tc.reason, reason)
+			assert.Equal(t, tc.message, message)
+			assert.Equal(t, tc.reissue, reissue)
+		})
+	}
+}
+
+func Test_UnlessExternallyIssued(t *testing.T) {
+	key := testcrypto.MustCreatePEMPrivateKey(t)
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			corev1.TLSPrivateKeyKey: testcrypto.MustCreatePEMPrivateKey(t),
+			corev1.TLSCertKey: testcrypto.MustCreateCert(t, key,
+				&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+			),
+		},
+	}
+
+	tests := map[string]struct {
+		annotations map[string]string
+		expViolated bool
+	}{
+		"the policy is evaluated for a Certificate without the annotation": {
+			expViolated: true,
+		},
+		"the policy is evaluated for a Certificate not marked as externally issued": {
+			annotations: map[string]string{cmapi.ExternallyIssuedAnnotation: "false"},
+			expViolated: true,
+		},
+		"the policy is skipped for an externally issued Certificate": {
+			annotations: map[string]string{cmapi.ExternallyIssuedAnnotation: "true"},
+			expViolated: false,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := Input{
+				Certificate: &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}},
+				Secret:      secret,
+			}
+			reason, _, violated := UnlessExternallyIssued(SecretPublicKeysDiffer)(input)
+			assert.Equal(t, tc.expViolated, violated)
+			if tc.expViolated {
+				assert.Equal(t, InvalidKeyPair, reason)
+			}
+		})
+	}
+}
+
+func Test_SecretTemplateMismatchesSecret_ExternallyIssued(t *testing.T) {
+	chain := NewSecretPostIssuancePolicyChain("cert-manager-test", PostIssuancePolicyOptions{})
+	reason, _, violated := chain.Evaluate(Input{
+		Certificate: &cmapi.Certificate{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				cmapi.ExternallyIssuedAnnotation: "true",
+			}},
+			Spec: cmapi.CertificateSpec{SecretTemplate: &cmapi.CertificateSecretTemplate{
+				Labels: map[string]string{"foo": "bar"},
+			}},
+		},
+		Secret: &corev1.Secret{},
+	})
+	assert.True(t, violated, "SecretTemplate should still be managed for externally issued Certificates")
+	assert.Equal(t, SecretTemplateMismatch, reason)
+}
+
+func Test_SecretPrivateKeyMatchesSpec(t *testing.T) {
+	rsaKey, err := pki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1Key := pki.EncodePKCS1PrivateKey(rsaKey)
+	pkcs8Key, err := pki.EncodePKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		encoding  cmapi.PrivateKeyEncoding
+		size      int
+		secretKey []byte
+
+		reason  string
+		message string
+		reissue bool
+	}{
+		"trigger issuance if a PKCS1 key is stored but PKCS8 is requested": {
+			encoding:  cmapi.PKCS8,
+			secretKey: pkcs1Key,
+			reason:    SecretMismatch,
+			message:   "Existing private key is not up to date for spec: [spec.privateKey.encoding]",
+			reissue:   true,
+		},
+		"do nothing if a PKCS8 key is stored and PKCS8 is requested": {
+			encoding:  cmapi.PKCS8,
+			secretKey: pkcs8Key,
+		},
+		"do nothing if a PKCS1 key is stored and PKCS1 is requested": {
+			encoding:  cmapi.PKCS1,
+			secretKey: pkcs1Key,
+		},
+		"do nothing if a PKCS1 key is stored and no encoding is requested": {
+			secretKey: pkcs1Key,
+		},
+		"trigger issuance if the stored RSA key is smaller than requested": {
+			size:      4096,
+			secretKey: pkcs1Key,
+			reason:    SecretMismatch,
+			message:   "Existing private key is not up to date for spec: [spec.privateKey.size]",
+			reissue:   true,
+		},
+		"do nothing if the stored RSA key is the requested size": {
+			size:      2048,
+			secretKey: pkcs1Key,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := Input{
+				Certificate: gen.Certificate("test",
+					gen.SetCertificateCommonName("example.com"),
+					gen.SetCertificateKeyAlgorithm(cmapi.RSAKeyAlgorithm),
+					gen.SetCertificateKeyEncoding(tc.encoding),
+					gen.SetCertificateKeySize(tc.size),
+				),
+				Secret: &corev1.Secret{Data: map[string][]byte{corev1.TLSPrivateKeyKey: tc.secretKey}},
+			}
+			reason, message, reissue := SecretPrivateKeyMatchesSpec(input)
+			assert.Equal(t, tc.reason, reason)
+			assert.Equal(t, tc.message, message)
+			assert.Equal(t, tc.reissue, reissue)
+		})
+	}
+}
+
+func Test_CurrentCertificateRequestPublicKeyMismatch(t *testing.T) {
+	crt := gen.Certificate("test", gen.SetCertificateCommonName("example.com"))
+	currentKey := testcrypto.MustCreatePEMPrivateKey(t)
+	otherKey := testcrypto.MustCreatePEMPrivateKey(t)
+	currentRequest := gen.CertificateRequest("test-1",
+		gen.SetCertificateRequestCSR(testcrypto.MustGenerateCSRImpl(t, currentKey, crt)),
+	)
+
+	tests := map[string]struct {
+		cert           []byte
+		currentRequest *cmapi.CertificateRequest
+
+		reason  string
+		reissue bool
+	}{
+		"do nothing if the certificate was issued for the current request's key": {
+			cert:           testcrypto.MustCreateCert(t, currentKey, crt),
+			currentRequest: currentRequest,
+		},
+		"trigger issuance if the certificate was issued for a different key": {
+			cert:           testcrypto.MustCreateCert(t, otherKey, crt),
+			currentRequest: currentRequest,
+			reason:         RequestChanged,
+			reissue:        true,
+		},
+		"do nothing if the current request does not exist": {
+			cert: testcrypto.MustCreateCert(t, otherKey, crt),
+		},
+		"do nothing if the certificate cannot be decoded": {
+			cert:           []byte("garbage"),
+			currentRequest: currentRequest,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, _, reissue := CurrentCertificateRequestPublicKeyMismatch(Input{
+				Certificate:            crt,
+				Secret:                 &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: test.cert}},
+				CurrentRevisionRequest: test.currentRequest,
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretCertificateMismatchesCurrentRequest(t *testing.T) {
+	crt := gen.Certificate("test", gen.SetCertificateCommonName("example.com"))
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	storedCert := testcrypto.MustCreateCert(t, pk, crt)
+	newerCert := testcrypto.MustCreateCert(t, pk, crt)
+	currentRequest := func(cert []byte) *cmapi.CertificateRequest {
+		return gen.CertificateRequest("test-2", gen.SetCertificateRequestCertificate(cert))
+	}
+
+	tests := map[string]struct {
+		cert           []byte
+		currentRequest *cmapi.CertificateRequest
+
+		reason  string
+		reissue bool
+	}{
+		"do nothing if the Secret holds the certificate issued by the current request": {
+			cert:           storedCert,
+			currentRequest: currentRequest(storedCert),
+		},
+		"do nothing if only the CA chain differs from the current request's": {
+			cert:           append(append([]byte{}, storedCert...), testcrypto.MustCreateCert(t, pk, gen.Certificate("ca", gen.SetCertificateCommonName("ca"), gen.SetCertificateIsCA(true)))...),
+			currentRequest: currentRequest(storedCert),
+		},
+		"trigger issuance if the current request holds a newer certificate than the Secret": {
+			cert:           storedCert,
+			currentRequest: currentRequest(newerCert),
+			reason:         StaleSecret,
+			reissue:        true,
+		},
+		"do nothing if the current request does not exist": {
+			cert: storedCert,
+		},
+		"do nothing if the current request has not issued a certificate": {
+			cert:           storedCert,
+			currentRequest: currentRequest(nil),
+		},
+		"do nothing if the certificate cannot be decoded": {
+			cert:           []byte("garbage"),
+			currentRequest: currentRequest(newerCert),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, _, reissue := SecretCertificateMismatchesCurrentRequest(Input{
+				Certificate:            crt,
+				Secret:                 &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: test.cert}},
+				CurrentRevisionRequest: test.currentRequest,
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretPrivateKeyReused(t *testing.T) {
+	previousKey := testcrypto.MustCreatePEMPrivateKey(t)
+	rotatedKey := testcrypto.MustCreatePEMPrivateKey(t)
+	crtWithRotationPolicy := func(policy cmapi.PrivateKeyRotationPolicy) *cmapi.Certificate {
+		return gen.Certificate("test", gen.SetCertificateCommonName("example.com"), gen.SetCertificateKeyRotationPolicy(policy))
+	}
+	previousRequest := gen.CertificateRequest("test-1",
+		gen.SetCertificateRequestCSR(testcrypto.MustGenerateCSRImpl(t, previousKey, crtWithRotationPolicy(cmapi.RotationPolicyAlways))),
+	)
+
+	tests := map[string]struct {
+		certificate     *cmapi.Certificate
+		secretKey       []byte
+		noSecret        bool
+		previousRequest *cmapi.CertificateRequest
+
+		reason  string
+		reissue bool
+	}{
+		"trigger issuance if the private key is reused and rotationPolicy is Always": {
+			certificate:     crtWithRotationPolicy(cmapi.RotationPolicyAlways),
+			secretKey:       previousKey,
+			previousRequest: previousRequest,
+			reason:          PrivateKeyReused,
+			reissue:         true,
+		},
+		"do nothing if the private key has been rotated": {
+			certificate:     crtWithRotationPolicy(cmapi.RotationPolicyAlways),
+			secretKey:       rotatedKey,
+			previousRequest: previousRequest,
+		},
+		"do nothing if the private key is reused and rotationPolicy is Never": {
+			certificate:     crtWithRotationPolicy(cmapi.RotationPolicyNever),
+			secretKey:       previousKey,
+			previousRequest: previousRequest,
+		},
+		"do nothing if the previous request does not exist": {
+			certificate: crtWithRotationPolicy(cmapi.RotationPolicyAlways),
+			secretKey:   previousKey,
+		},
+		"do nothing if the Secret does not exist": {
+			certificate:     crtWithRotationPolicy(cmapi.RotationPolicyAlways),
+			noSecret:        true,
+			previousRequest: previousRequest,
+		},
+		"do nothing if the Certificate is not known": {
+			secretKey:       previousKey,
+			previousRequest: previousRequest,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := Input{
+				Certificate:             test.certificate,
+				Secret:                  &corev1.Secret{Data: map[string][]byte{corev1.TLSPrivateKeyKey: test.secretKey}},
+				PreviousRevisionRequest: test.previousRequest,
+			}
+			if test.noSecret {
+				input.Secret = nil
+			}
+			reason, _, reissue := SecretPrivateKeyReused(input)
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.reissue, reissue)
+		})
+	}
+}
+
+func Test_SecretKeystorePasswordMismatch(t *testing.T) {
+	passwordRef := func(name string) cmmeta.SecretKeySelector {
+		return cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: name}, Key: "password"}
+	}
+	withPKCS12 := gen.SetCertificateKeystore(&cmapi.CertificateKeystores{
+		PKCS12: &cmapi.PKCS12Keystore{Create: true, PasswordSecretRef: passwordRef("new-password")},
+	})
+	withJKS := gen.SetCertificateKeystore(&cmapi.CertificateKeystores{
+		JKS: &cmapi.JKSKeystore{Create: true, PasswordSecretRef: passwordRef("jks-password")},
+	})
+	secretWithAnnotations := func(annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something", Annotations: annotations}}
+	}
+
+	tests := map[string]struct {
+		certificate *cmapi.Certificate
+		secret      *corev1.Secret
+
+		reason  string
+		message string
+		violate bool
+	}{
+		"no keystores configured, do nothing": {
+			certificate: gen.Certificate("test"),
+			secret:      secretWithAnnotations(nil),
+		},
+		"keystore not created, do nothing": {
+			certificate: gen.Certificate("test", gen.SetCertificateKeystore(&cmapi.CertificateKeystores{
+				PKCS12: &cmapi.PKCS12Keystore{Create: false, PasswordSecretRef: passwordRef("new-password")},
+			})),
+			secret: secretWithAnnotations(nil),
+		},
+		"PKCS12 password Secret reference matches, do nothing": {
+			certificate: gen.Certificate("test", withPKCS12),
+			secret:      secretWithAnnotations(map[string]string{cmapi.PKCS12PasswordSecretRefAnnotationKey: "new-password/password"}),
+		},
+		"PKCS12 password Secret reference has changed, rebuild keystore": {
+			certificate: gen.Certificate("test", withPKCS12),
+			secret:      secretWithAnnotations(map[string]string{cmapi.PKCS12PasswordSecretRefAnnotationKey: "old-password/password"}),
+			reason:      KeystorePasswordMismatch,
+			message:     `PKCS12 keystore was built using password Secret reference "old-password/password", but "new-password/password" is configured`,
+			violate:     true,
+		},
+		"PKCS12 password Secret reference is not recorded, rebuild keystore": {
+			certificate: gen.Certificate("test", withPKCS12),
+			secret:      secretWithAnnotations(nil),
+			reason:      KeystorePasswordMismatch,
+			message:     `PKCS12 keystore password Secret reference is not recorded on the Secret, rebuilding keystore using "new-password/password"`,
+			violate:     true,
+		},
+		"JKS password Secret reference has changed, rebuild keystore": {
+			certificate: gen.Certificate("test", withJKS),
+			secret:      secretWithAnnotations(map[string]string{cmapi.JKSPasswordSecretRefAnnotationKey: "jks-password/other-key"}),
+			reason:      KeystorePasswordMismatch,
+			message:     `JKS keystore was built using password Secret reference "jks-password/other-key", but "jks-password/password" is configured`,
+			violate:     true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, violate := SecretKeystorePasswordMismatch(Input{Certificate: test.certificate, Secret: test.secret})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.violate, violate)
+		})
+	}
+}
+
+func Test_SecretTemplateMismatchesSecret(t *testing.T) {
+	tests := map[string]struct {
+		tmpl         *cmapi.CertificateSecretTemplate
+		secret       *corev1.Secret
+		expViolation bool
+		expReason    string
+		expMessage   string
+	}{
+		"if SecretTemplate is nil, Secret Annotations and Labels are nil, return false": {
+			tmpl:         nil,
+			secret:       &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: nil, Labels: nil}},
+			expViolation: false,
+			expReason:    "",
+			expMessage:   "",
+		},
+		"if SecretTemplate is nil, Secret Annotations are nil, Labels are non-nil, return false": {
+			tmpl:         nil,
+			secret:       &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: nil, Labels: map[string]string{"foo": "bar"}}},
+			expViolation: false,
+			expReason:    "",
+			expMessage:   "",
+		},
+		"if SecretTemplate is nil, Secret Annotations are non-nil, Labels are nil, return false": {
+			tmpl:         nil,
+			secret:       &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"foo": "bar"}, Labels: nil}},
+			expViolation: false,
+			expReason:    "",
+			expMessage:   "",
+		},
+		"if SecretTemplate is nil, Secret Annotations and Labels are non-nil, return false": {
+			tmpl: nil,
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"foo": "bar"},
+				Labels:      map[string]string{"bar": "foo"},
+			}},
+			expViolation: false,
+			expReason:    "",
+			expMessage:   "",
+		},
+		"if SecretTemplate is non-nil, Secret Annotations match but Labels are nil, return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      nil,
+			}},
+			expViolation: true,
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate Labels missing or incorrect value on Secret",
+		},
+		"if SecretTemplate is non-nil, Secret Labels match but Annotations are nil, return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Annotations: nil,
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			}},
+			expViolation: true,
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate Annotations missing or incorrect value on Secret",
+		},
+		"if SecretTemplate is non-nil, Secret Labels match but Annotations don't match keys, return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"foo2": "bar1", "foo1": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			}},
+			expViolation: true,
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate Annotations missing or incorrect value on Secret",
+		},
+		"if SecretTemplate is non-nil, Secret Annoations match but Labels don't match keys, return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"def": "123", "abc": "456"},
+			}},
+			expViolation: true,
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate Labels missing or incorrect value on Secret",
+		},
+		"if SecretTemplate is non-nil, Secret Labels match but Annotations don't match values, return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"foo1": "bar2", "foo2": "bar1"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			}},
+			expViolation: true,
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate Annotations missing or incorrect value on Secret",
+		},
+		"if SecretTemplate is non-nil, Secret Annotations match but Labels don't match values, return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "456", "def": "123"},
+			}},
+			expViolation: true,
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate Labels missing or incorrect value on Secret",
+		},
+		"if SecretTemplate is non-nil, Secret Annotations and Labels match, return false": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			}},
+			expViolation: false,
+			expReason:    "",
+			expMessage:   "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotReason, gotMessage, gotViolation := SecretTemplateMismatchesSecret(Input{
+				Certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretTemplate: test.tmpl}},
+				Secret:      test.secret,
+			})
+
+			assert.Equal(t, test.expReason, gotReason, "unexpected reason")
+			assert.Equal(t, test.expMessage, gotMessage, "unexpected message")
+			assert.Equal(t, test.expViolation, gotViolation, "unexpected violation")
+		})
+	}
+}
+
+func Test_SecretTemplateMismatchesSecretManagedFields(t *testing.T) {
+	const fieldManager = "cert-manager-unit-test"
+
+	var (
+		fixedClockStart = time.Now()
+		fixedClock      = fakeclock.NewFakeClock(fixedClockStart)
+		baseCertBundle  = testcrypto.MustCreateCryptoBundle(t,
+			gen.Certificate("test-certificate", gen.SetCertificateCommonName("cert-manager")), fixedClock)
+	)
+
+	tests := map[string]struct {
+		tmpl                *cmapi.CertificateSecretTemplate
+		secretManagedFields []metav1.ManagedFieldsEntry
+		secretData          map[string][]byte
+		opts                PostIssuancePolicyOptions
+
+		expReason    string
+		expMessage   string
+		expViolation bool
+	}{
+		"if template is nil and no managed fields, should return false": {
+			tmpl:                nil,
+			secretManagedFields: nil,
+			expReason:           "",
+			expMessage:          "",
+			expViolation:        false,
+		},
+		"if template is nil, managed fields is not nil but not managed by cert-manager, should return false": {
+			tmpl: nil,
+			secretManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: "not-cert-manager", FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:bar": {}
+							},
+							"f:labels": {
+								"f:123": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    "",
+			expMessage:   "",
+			expViolation: false,
+		},
+		"if template is nil, managed fields is not nil but fields are nil, should return false": {
+			tmpl:                nil,
+			secretManagedFields: []metav1.ManagedFieldsEntry{{Manager: fieldManager, FieldsV1: nil}},
+			expReason:           "",
+			expMessage:          "",
+			expViolation:        false,
+		},
+		"if template is not-nil but managed fields is nil, should return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo": "bar"},
+				Labels:      map[string]string{"abc": "123"},
+			},
+			secretManagedFields: nil,
+			expReason:           SecretTemplateMismatch,
+			expMessage:          "Certificate's SecretTemplate doesn't match Secret",
+			expViolation:        true,
+		},
+		"if template is nil but managed fields is not nil, should return true": {
+			tmpl: nil,
+			secretManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo": {}
+							},
+							"f:labels": {
+								"f:abc": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "SecretTemplate is nil, but Secret contains extra managed entries",
+			expViolation: true,
+		},
+		"if template annotations do not match managed fields, should return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo3": {}
+							},
+							"f:labels": {
+								"f:abc": {},
+								"f:def": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
+			expViolation: true,
+		},
+		"if template labels do not match managed fields, should return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo2": {}
+							},
+							"f:labels": {
+								"f:abc": {},
+								"f:erg": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
+			expViolation: true,
+		},
+		"if template annotations and labels match managed fields, should return false": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo2": {}
+							},
+							"f:labels": {
+								"f:abc": {},
+								"f:def": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    "",
+			expMessage:   "",
+			expViolation: false,
+		},
+		"if template annotations is a subset of managed fields, return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo2": {},
+								"f:foo3": {}
+							},
+							"f:labels": {
+								"f:abc": {},
+								"f:def": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
+			expViolation: true,
+		},
+		"if template labels is a subset of managed fields, return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo2": {}
+							},
+							"f:labels": {
+								"f:abc": {},
+								"f:def": {},
+								"f:ghi": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
+			expViolation: true,
+		},
+		"if managed fields annotations is a subset of template, return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2", "foo3": "bar3"},
+				Labels:      map[string]string{"abc": "123", "def": "456"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo2": {}
+							},
+							"f:labels": {
+								"f:abc": {},
+								"f:def": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
+			expViolation: true,
+		},
+		"if managed fields labels is a subset of template, return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+				Labels:      map[string]string{"abc": "123", "def": "456", "ghi": "789"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo2": {}
+							},
+							"f:labels": {
+								"f:abc": {},
+								"f:def": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
+			expViolation: true,
+		},
+		"if managed fields matches template but is split across multiple managed fields, should return false": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2", "foo3": "bar3"},
+				Labels:      map[string]string{"abc": "123", "def": "456", "ghi": "789"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:labels": {
+								"f:ghi": {}
+							}
+						}}`),
+				}},
+				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo3": {}
+							},
+							"f:labels": {
+								"f:abc": {},
+								"f:def": {}
+							}
+						}}`),
+				}},
+				{Manager: fieldManager,
+					FieldsV1: &metav1.FieldsV1{
+						Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo2": {}
+							},
+							"f:labels": {
+								"f:abc": {},
+								"f:def": {}
+							}
+						}}`),
+					}},
+			},
+			expReason:    "",
+			expMessage:   "",
+			expViolation: false,
+		},
+		"if managed fields matches template and base cert-manager annotations are present with no certificate data, should return false": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo2": {},
+								"f:cert-manager.io/certificate-name": {},
+								"f:cert-manager.io/issuer-name": {},
+								"f:cert-manager.io/issuer-kind": {},
+								"f:cert-manager.io/issuer-group": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    "",
+			expMessage:   "",
+			expViolation: false,
+		},
+		"if managed fields matches template and base cert-manager annotations are present with certificate data, should return false": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo2": {},
+								"f:cert-manager.io/certificate-name": {},
+								"f:cert-manager.io/issuer-name": {},
+								"f:cert-manager.io/issuer-kind": {},
+								"f:cert-manager.io/issuer-group": {},
+								"f:cert-manager.io/common-name": {},
+								"f:cert-manager.io/alt-names":  {},
+								"f:cert-manager.io/ip-sans": {},
+								"f:cert-manager.io/uri-sans": {}
+							}
+						}}`),
+				}},
+			},
+			secretData:   map[string][]byte{corev1.TLSCertKey: baseCertBundle.CertBytes},
+			expViolation: false,
+		},
+		"if managed fields matches template and base cert-manager annotations are present with certificate data but certificate data is nil, should return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": {
+							"f:annotations": {
+								"f:foo1": {},
+								"f:foo2": {},
+								"f:cert-manager.io/certificate-name": {},
+								"f:cert-manager.io/issuer-name": {},
+								"f:cert-manager.io/issuer-kind": {},
+								"f:cert-manager.io/issuer-group": {},
+								"f:cert-manager.io/common-name": {},
+								"f:cert-manager.io/alt-names":  {},
+								"f:cert-manager.io/ip-sans": {},
+								"f:cert-manager.io/uri-sans": {}
+							}
+						}}`),
+				}},
+			},
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
+			expViolation: true,
+		},
+		"if managed fields cannot be decoded, should return a parse error by default": {
+			tmpl: nil,
+			secretManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": 1}`),
+				}},
+			},
+			expReason:    ManagedFieldsParseError,
+			expMessage:   `failed to decode managed fields on Secret: ReadMapCB: expect { or n, but found 1, error found in #10 byte of ...|tadata": 1}|..., bigger context ...|{"f:metadata": 1}|...`,
+			expViolation: true,
+		},
+		"if managed fields cannot be decoded and parse errors are ignored, should treat them as empty": {
+			tmpl: nil,
+			secretManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": 1}`),
+				}},
+			},
+			opts:         PostIssuancePolicyOptions{IgnoreManagedFieldsParseErrors: true},
+			expReason:    "",
+			expMessage:   "",
+			expViolation: false,
+		},
+		"if managed fields cannot be decoded and parse errors are ignored, should compare the remaining entries against the template": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo": "bar"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:metadata": 1}`),
+				}},
+			},
+			opts:         PostIssuancePolicyOptions{IgnoreManagedFieldsParseErrors: true},
+			expReason:    SecretTemplateMismatch,
+			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
+			expViolation: true,
+		},
+		"if Secrets are applied and the template is not reflected in the managed fields, should return true": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo": "bar"},
+			},
+			secretManagedFields: nil,
+			opts:                PostIssuancePolicyOptions{SecretUpdateStrategy: internalcertificates.SecretUpdateStrategyApply},
+			expReason:           SecretTemplateMismatch,
+			expMessage:          "Certificate's SecretTemplate doesn't match Secret",
+			expViolation:        true,
+		},
+		"if Secrets are updated, should return false even if the template is not reflected in the managed fields": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo": "bar"},
+			},
+			secretManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: fieldManager, Operation: metav1.ManagedFieldsOperationUpdate, FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(`{"f:data": {"f:tls.crt": {}, "f:tls.key": {}}}`),
+				}},
+			},
+			opts:         PostIssuancePolicyOptions{SecretUpdateStrategy: internalcertificates.SecretUpdateStrategyUpdate},
+			expReason:    "",
+			expMessage:   "",
+			expViolation: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotReason, gotMessage, gotViolation := SecretTemplateMismatchesSecretManagedFields(fieldManager, test.opts)(Input{
+				Certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretTemplate: test.tmpl}},
+				Secret:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{ManagedFields: test.secretManagedFields}, Data: test.secretData},
+			})
+
+			assert.Equal(t, test.expReason, gotReason, "unexpected reason")
+			assert.Equal(t, test.expMessage, gotMessage, "unexpected message")
+			assert.Equal(t, test.expViolation, gotViolation, "unexpected violation")
+		})
+	}
+}
+
+func Test_IssuerDoesNotExist(t *testing.T) {
+	// The helper only knows about the Issuer "testns/existing-issuer" and the
+	// ClusterIssuer "existing-clusterissuer".
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(ref cmmeta.ObjectReference, ns string) (cmapi.GenericIssuer, error) {
+			switch {
+			case (ref.Kind == "" || ref.Kind == cmapi.IssuerKind) && ns == "testns" && ref.Name == "existing-issuer":
+				return &cmapi.Issuer{}, nil
+			case ref.Kind == cmapi.ClusterIssuerKind && ref.Name == "existing-clusterissuer":
+				return &cmapi.ClusterIssuer{}, nil
+			case ref.Kind == "" || ref.Kind == cmapi.IssuerKind:
+				return nil, apierrors.NewNotFound(cmapi.Resource("issuers"), ref.Name)
+			case ref.Kind == cmapi.ClusterIssuerKind:
+				return nil, apierrors.NewNotFound(cmapi.Resource("clusterissuers"), ref.Name)
+			default:
+				return nil, fmt.Errorf("invalid value %q for issuerRef.kind", ref.Kind)
+			}
+		},
+	}
+
+	tests := map[string]struct {
+		issuerRef cmmeta.ObjectReference
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"Issuer exists": {
+			issuerRef: cmmeta.ObjectReference{Name: "existing-issuer"},
+		},
+		"Issuer exists and is referenced with kind and group": {
+			issuerRef: cmmeta.ObjectReference{Name: "existing-issuer", Kind: "Issuer", Group: "cert-manager.io"},
+		},
+		"ClusterIssuer exists": {
+			issuerRef: cmmeta.ObjectReference{Name: "existing-clusterissuer", Kind: "ClusterIssuer"},
+		},
+		"Issuer does not exist": {
+			issuerRef: cmmeta.ObjectReference{Name: "missing-issuer"},
+			reason:    IssuerNotFound,
+			message:   `Referenced Issuer "missing-issuer" does not exist`,
+			failed:    true,
+		},
+		"ClusterIssuer does not exist": {
+			issuerRef: cmmeta.ObjectReference{Name: "missing-clusterissuer", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+			reason:    IssuerNotFound,
+			message:   `Referenced ClusterIssuer "missing-clusterissuer" does not exist`,
+			failed:    true,
+		},
+		"external issuers are not checked": {
+			issuerRef: cmmeta.ObjectReference{Name: "missing-issuer", Kind: "Issuer", Group: "example.com"},
+		},
+		"errors other than not found are ignored": {
+			issuerRef: cmmeta.ObjectReference{Name: "missing-issuer", Kind: "UnknownKind"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, failed := IssuerDoesNotExist(helper)(Input{
+				Certificate: gen.Certificate("test", gen.SetCertificateNamespace("testns"),
+					gen.SetCertificateIssuer(test.issuerRef),
+				),
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.failed, failed)
+		})
+	}
+}
+
+func Test_SecretIssuerProfileNotUpToDate(t *testing.T) {
+	// The helper knows about the Issuer "testns/gold-issuer" which has the
+	// profile "gold", and the Issuer "testns/plain-issuer" which has no
+	// profile.
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(ref cmmeta.ObjectReference, ns string) (cmapi.GenericIssuer, error) {
+			switch {
+			case ns == "testns" && ref.Name == "gold-issuer":
+				return &cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{cmapi.IssuerProfileAnnotationKey: "gold"},
+				}}, nil
+			case ns == "testns" && ref.Name == "plain-issuer":
+				return &cmapi.Issuer{}, nil
+			default:
+				return nil, apierrors.NewNotFound(cmapi.Resource("issuers"), ref.Name)
+			}
+		},
+	}
+
+	tests := map[string]struct {
+		issuerRef     cmmeta.ObjectReference
+		secretProfile string
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"do nothing if the Secret profile matches the issuer profile": {
+			issuerRef:     cmmeta.ObjectReference{Name: "gold-issuer"},
+			secretProfile: "gold",
+		},
+		"do nothing if neither the Secret nor the issuer has a profile": {
+			issuerRef: cmmeta.ObjectReference{Name: "plain-issuer"},
+		},
+		"trigger issuance as Secret has old/incorrect 'issuer profile' annotation": {
+			issuerRef:     cmmeta.ObjectReference{Name: "gold-issuer"},
+			secretProfile: "silver",
+			reason:        IncorrectIssuer,
+			message:       `Issuing certificate as Secret was previously issued with issuer profile "silver" but the issuer now has profile "gold"`,
+			failed:        true,
+		},
+		"trigger issuance as Secret has no 'issuer profile' annotation": {
+			issuerRef: cmmeta.ObjectReference{Name: "gold-issuer"},
+			reason:    IncorrectIssuer,
+			message:   `Issuing certificate as Secret was previously issued with issuer profile "" but the issuer now has profile "gold"`,
+			failed:    true,
+		},
+		"trigger issuance as the issuer no longer has a profile": {
+			issuerRef:     cmmeta.ObjectReference{Name: "plain-issuer"},
+			secretProfile: "gold",
+			reason:        IncorrectIssuer,
+			message:       `Issuing certificate as Secret was previously issued with issuer profile "gold" but the issuer now has profile ""`,
+			failed:        true,
+		},
+		"external issuers are not checked": {
+			issuerRef:     cmmeta.ObjectReference{Name: "gold-issuer", Kind: "Issuer", Group: "example.com"},
+			secretProfile: "silver",
+		},
+		"issuers that cannot be found are not checked": {
+			issuerRef:     cmmeta.ObjectReference{Name: "missing-issuer"},
+			secretProfile: "silver",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something", Annotations: map[string]string{}}}
+			if test.secretProfile != "" {
+				secret.Annotations[cmapi.IssuerProfileAnnotationKey] = test.secretProfile
+			}
+			reason, message, failed := SecretIssuerProfileNotUpToDate(helper)(Input{
+				Certificate: gen.Certificate("test", gen.SetCertificateNamespace("testns"),
+					gen.SetCertificateIssuer(test.issuerRef),
+				),
+				Secret: secret,
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.failed, failed)
+		})
+	}
+}
+
+func Benchmark_SecretTemplateMismatchesSecretManagedFields(b *testing.B) {
+	const fieldManager = "cert-manager-unit-test"
+
+	baseCertBundle := testcrypto.MustCreateCryptoBundle(b,
+		gen.Certificate("test-certificate", gen.SetCertificateCommonName("cert-manager")), fakeclock.NewFakeClock(time.Now()))
+
+	// Simulate a Secret which has been modified by many other field managers
+	// in addition to cert-manager.
+	var managedFields []metav1.ManagedFieldsEntry
+	for i := 0; i < 50; i++ {
+		managedFields = append(managedFields, metav1.ManagedFieldsEntry{
+			Manager: fmt.Sprintf("not-cert-manager-%d", i), FieldsV1: &metav1.FieldsV1{
+				Raw: []byte(fmt.Sprintf(`{"f:metadata": {"f:annotations": {"f:bar-%d": {}}, "f:labels": {"f:123-%d": {}}}}`, i, i)),
+			},
+		})
+	}
+	ownedManagedFields := append(managedFields, metav1.ManagedFieldsEntry{
+		Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
+			Raw: []byte(`{"f:metadata": {"f:annotations": {"f:foo": {}}, "f:labels": {"f:abc": {}}}}`),
+		},
+	})
+
+	benchmarks := map[string]struct {
+		tmpl          *cmapi.CertificateSecretTemplate
+		managedFields []metav1.ManagedFieldsEntry
+	}{
+		"template is nil and no entries are owned by cert-manager": {
+			managedFields: managedFields,
+		},
+		"template matches the entries owned by cert-manager": {
+			tmpl: &cmapi.CertificateSecretTemplate{
+				Annotations: map[string]string{"foo": "bar"},
+				Labels:      map[string]string{"abc": "123"},
+			},
+			managedFields: ownedManagedFields,
+		},
+	}
+	for name, bm := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			policy := SecretTemplateMismatchesSecretManagedFields(fieldManager, PostIssuancePolicyOptions{})
+			input := Input{
+				Certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretTemplate: bm.tmpl}},
+				Secret: &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{ManagedFields: bm.managedFields},
+					Data:       map[string][]byte{corev1.TLSCertKey: baseCertBundle.CertBytes},
+				},
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, violation := policy(input); violation {
+					b.Fatal("unexpected violation")
+				}
+			}
+		})
+	}
+}
+
+func Test_SecretCertificateHasDeniedUsages(t *testing.T) {
+	// The helper knows about the Issuer "testns/restricted-issuer" which
+	// denies the 'server auth' extended key usage, and the Issuer
+	// "testns/plain-issuer" which denies nothing.
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(ref cmmeta.ObjectReference, ns string) (cmapi.GenericIssuer, error) {
+			switch {
+			case ns == "testns" && ref.Name == "restricted-issuer":
+				return &cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{cmapi.DeniedExtendedKeyUsagesAnnotationKey: "code signing, server auth"},
+				}}, nil
+			case ns == "testns" && ref.Name == "plain-issuer":
+				return &cmapi.Issuer{}, nil
+			default:
+				return nil, apierrors.NewNotFound(cmapi.Resource("issuers"), ref.Name)
+			}
+		},
+	}
+
+	staticFixedPrivateKey := testcrypto.MustCreatePEMPrivateKey(t)
+	serverCert := testcrypto.MustCreateCert(t, staticFixedPrivateKey, gen.Certificate("test",
+		gen.SetCertificateCommonName("example.com"),
+		gen.SetCertificateKeyUsages(cmapi.UsageDigitalSignature, cmapi.UsageServerAuth),
+	))
+	clientCert := testcrypto.MustCreateCert(t, staticFixedPrivateKey, gen.Certificate("test",
+		gen.SetCertificateCommonName("example.com"),
+		gen.SetCertificateKeyUsages(cmapi.UsageDigitalSignature, cmapi.UsageClientAuth),
+	))
+
+	tests := map[string]struct {
+		issuerRef cmmeta.ObjectReference
+		usages    []cmapi.KeyUsage
+		cert      []byte
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"trigger issuance if the certificate has an extended key usage denied by the issuer": {
+			issuerRef: cmmeta.ObjectReference{Name: "restricted-issuer"},
+			cert:      serverCert,
+			reason:    DeniedUsage,
+			message:   `Issuing certificate as the existing certificate has the extended key usage "server auth" which is denied by the issuer`,
+			failed:    true,
+		},
+		"do nothing if the certificate has no extended key usage denied by the issuer": {
+			issuerRef: cmmeta.ObjectReference{Name: "restricted-issuer"},
+			cert:      clientCert,
+		},
+		"do nothing if the denied extended key usage is still requested by the Certificate": {
+			issuerRef: cmmeta.ObjectReference{Name: "restricted-issuer"},
+			usages:    []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageServerAuth},
+			cert:      serverCert,
+		},
+		"do nothing if the issuer denies no extended key usages": {
+			issuerRef: cmmeta.ObjectReference{Name: "plain-issuer"},
+			cert:      serverCert,
+		},
+		"external issuers are not checked": {
+			issuerRef: cmmeta.ObjectReference{Name: "restricted-issuer", Kind: "Issuer", Group: "example.com"},
+			cert:      serverCert,
+		},
+		"issuers that cannot be found are not checked": {
+			issuerRef: cmmeta.ObjectReference{Name: "missing-issuer"},
+			cert:      serverCert,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, failed := SecretCertificateHasDeniedUsages(helper)(Input{
+				Certificate: gen.Certificate("test", gen.SetCertificateNamespace("testns"),
+					gen.SetCertificateIssuer(tc.issuerRef),
+					gen.SetCertificateKeyUsages(tc.usages...),
+				),
+				Secret: &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: tc.cert}},
+			})
+			assert.Equal(t, tc.reason, reason)
+			assert.Equal(t, tc.message, message)
+			assert.Equal(t, tc.failed, failed)
+		})
+	}
+}
+
+func Test_SecretHasMultipleLeafCertificates(t *testing.T) {
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	leafA := testcrypto.MustCreateCert(t, pk, gen.Certificate("a", gen.SetCertificateCommonName("a.example.com")))
+	leafB := testcrypto.MustCreateCert(t, pk, gen.Certificate("b", gen.SetCertificateCommonName("b.example.com")))
+	ca := testcrypto.MustCreateCert(t, pk, gen.Certificate("ca", gen.SetCertificateCommonName("ca"), gen.SetCertificateIsCA(true)))
+
+	tests := map[string]struct {
+		certData []byte
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"do nothing if tls.crt contains a single leaf certificate": {
+			certData: leafA,
+		},
+		"do nothing if tls.crt contains a leaf certificate followed by its chain": {
+			certData: append(append([]byte{}, leafA...), ca...),
+		},
+		"do nothing if tls.crt cannot be decoded": {
+			certData: []byte("invalid"),
+		},
+		"trigger issuance if tls.crt contains two concatenated leaf certificates": {
+			certData: append(append([]byte{}, leafA...), leafB...),
+			reason:   InvalidCertificate,
+			message:  `Issuing certificate as Secret "tls.crt" contains 2 end-entity certificates rather than a single certificate optionally followed by its CA chain`,
+			failed:   true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, failed := SecretHasMultipleLeafCertificates(Input{
+				Secret: &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: tc.certData}},
+			})
+			assert.Equal(t, tc.reason, reason)
+			assert.Equal(t, tc.message, message)
+			assert.Equal(t, tc.failed, failed)
+		})
+	}
+}
+
+func Test_SecretChainDoesNotVerify(t *testing.T) {
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	selfSigned := testcrypto.MustCreateCert(t, pk, gen.Certificate("a", gen.SetCertificateCommonName("a.example.com")))
+	otherSelfSigned := testcrypto.MustCreateCert(t, pk, gen.Certificate("b", gen.SetCertificateCommonName("b.example.com")))
+
+	// Create a CA and a leaf certificate signed by it.
+	signer, err := pki.DecodePrivateKeyBytes(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate, err := pki.GenerateTemplate(gen.Certificate("ca", gen.SetCertificateCommonName("ca"), gen.SetCertificateIsCA(true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, caCert, err := pki.SignCertificate(caTemplate, caTemplate, signer.Public(), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate, err := pki.GenerateTemplate(gen.Certificate("leaf", gen.SetCertificateDNSNames("leaf.example.com")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _, err := pki.SignCertificate(leafTemplate, caCert, signer.Public(), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a CA with the same subject but a different key, which did not
+	// sign the leaf certificate.
+	otherSigner, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCA, _, err := pki.SignCertificate(caTemplate, caTemplate, otherSigner.Public(), otherSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a chain of root -> intermediate -> leaf, as well as a chain
+	// whose intermediate is a legacy CA without the basic constraints
+	// extension.
+	root, rootKey, rootPEM := mustCreateChainCert(t, "root", nil, nil)
+	intermediate, intermediateKey, _ := mustCreateChainCert(t, "intermediate", root, rootKey)
+	_, _, chainLeafPEM := mustCreateChainCert(t, "leaf", intermediate, intermediateKey)
+	legacyIntermediate, legacyIntermediateKey, legacyIntermediatePEM := mustCreateLegacyCACert(t, "legacy-intermediate", root, rootKey)
+	_, _, legacyLeafPEM := mustCreateChainCert(t, "leaf", legacyIntermediate, legacyIntermediateKey)
+
+	tests := map[string]struct {
+		certData []byte
+		caData   []byte
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"do nothing if the Secret has no ca.crt": {
+			certData: leaf,
+		},
+		"do nothing if tls.crt cannot be decoded": {
+			certData: []byte("invalid"),
+			caData:   ca,
+		},
+		"do nothing if a self-signed certificate matches its ca.crt": {
+			certData: selfSigned,
+			caData:   selfSigned,
+		},
+		"do nothing if a certificate verifies against the CA in ca.crt": {
+			certData: leaf,
+			caData:   ca,
+		},
+		"do nothing if a certificate verifies against the CA in ca.crt through the chain in tls.crt": {
+			certData: append(append([]byte{}, leaf...), ca...),
+			caData:   ca,
+		},
+		"trigger issuance if ca.crt cannot be decoded": {
+			certData: leaf,
+			caData:   []byte("invalid"),
+			reason:   IncompleteChain,
+			message:  `Issuing certificate as Secret "ca.crt" could not be decoded: `,
+			failed:   true,
+		},
+		"trigger issuance if the chain in tls.crt does not lead to the CA in ca.crt": {
+			certData: append(append([]byte{}, leaf...), ca...),
+			caData:   otherSelfSigned,
+			reason:   IncompleteChain,
+			message:  `Issuing certificate as Secret "tls.crt" does not verify against "ca.crt": x509: certificate signed by unknown authority`,
+			failed:   true,
+		},
+		"trigger issuance if a self-signed certificate does not match its ca.crt": {
+			certData: selfSigned,
+			caData:   otherSelfSigned,
+			reason:   IncompleteChain,
+			message:  `Issuing certificate as Secret "tls.crt" does not verify against "ca.crt": x509: certificate signed by unknown authority`,
+			failed:   true,
+		},
+		"do nothing if tls.crt only holds a certificate issued by an intermediate of the root in ca.crt": {
+			certData: chainLeafPEM,
+			caData:   rootPEM,
+		},
+		"do nothing if the chain in tls.crt has a legacy intermediate without basic constraints": {
+			certData: append(append([]byte{}, legacyLeafPEM...), legacyIntermediatePEM...),
+			caData:   rootPEM,
+		},
+		"trigger issuance if a certificate does not verify against the CA in ca.crt": {
+			certData: leaf,
+			caData:   otherCA,
+			reason:   IncompleteChain,
+			message:  `Issuing certificate as Secret "tls.crt" does not verify against "ca.crt": x509: certificate signed by unknown authority`,
+			failed:   true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := map[string][]byte{corev1.TLSCertKey: tc.certData}
+			if tc.caData != nil {
+				data[cmmeta.TLSCAKey] = tc.caData
+			}
+			reason, message, failed := SecretChainDoesNotVerify(Input{
+				Secret: &corev1.Secret{Data: data},
+			})
+			assert.Equal(t, tc.reason, reason)
+			// The error returned by the x509 package may include details
+			// about the candidate authorities, so only the prefix is
+			// compared.
+			if tc.failed {
+				assert.True(t, strings.HasPrefix(message, tc.message), "unexpected message %q", message)
+			} else {
+				assert.Empty(t, message)
+			}
+			assert.Equal(t, tc.failed, failed)
+		})
+	}
+}
+
+func Test_SecretIsMissingRevision(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"do nothing if the Secret has the revision annotation": {
+			annotations: map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: "1"},
+		},
+		"trigger issuance if the Secret does not have the revision annotation": {
+			annotations: map[string]string{cmapi.CertificateNameKey: "test"},
+			reason:      MissingRevision,
+			message:     `Issuing certificate as Secret does not have the "cert-manager.io/certificate-revision" annotation`,
+			failed:      true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, failed := SecretIsMissingRevision(Input{
+				Secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}},
+			})
+			assert.Equal(t, tc.reason, reason)
+			assert.Equal(t, tc.message, message)
+			assert.Equal(t, tc.failed, failed)
+		})
+	}
+}
+
+func Test_CurrentCertificateHasInvalidUsages(t *testing.T) {
+	staticFixedPrivateKey := testcrypto.MustCreatePEMPrivateKey(t)
+
+	// mustCreateCert signs a certificate with the given usages directly, as
+	// some of the contradictory combinations cannot be requested through a
+	// Certificate spec.
+	mustCreateCert := func(isCA bool, keyUsage x509.KeyUsage, extKeyUsage ...x509.ExtKeyUsage) []byte {
+		pk, err := pki.DecodePrivateKeyBytes(staticFixedPrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template, err := pki.GenerateTemplate(gen.Certificate("test", gen.SetCertificateCommonName("example.com")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.IsCA = isCA
+		template.KeyUsage = keyUsage
+		template.ExtKeyUsage = extKeyUsage
+		certData, _, err := pki.SignCertificate(template, template, pk.Public(), pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return certData
+	}
+
+	tests := map[string]struct {
+		cert []byte
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"do nothing for a server certificate with the default usages": {
+			cert: mustCreateCert(false, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment, x509.ExtKeyUsageServerAuth),
+		},
+		"do nothing for a CA certificate with the cert sign usage": {
+			cert: mustCreateCert(true, x509.KeyUsageCertSign|x509.KeyUsageDigitalSignature),
+		},
+		"do nothing for a certificate without a key usage extension": {
+			cert: mustCreateCert(false, 0, x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth),
+		},
+		"mark as invalid a CA certificate without the cert sign usage": {
+			cert:    mustCreateCert(true, x509.KeyUsageDigitalSignature),
+			reason:  InvalidUsages,
+			message: "Issued certificate has contradictory usages, check spec.usages and spec.isCA: certificate is a CA but does not have the cert sign key usage",
+			failed:  true,
+		},
+		"mark as invalid a leaf certificate with the cert sign usage": {
+			cert:    mustCreateCert(false, x509.KeyUsageCertSign|x509.KeyUsageDigitalSignature, x509.ExtKeyUsageServerAuth),
+			reason:  InvalidUsages,
+			message: "Issued certificate has contradictory usages, check spec.usages and spec.isCA: certificate has the cert sign key usage but is not a CA",
+			failed:  true,
+		},
+		"mark as invalid a server auth certificate which can only sign CRLs": {
+			cert:    mustCreateCert(false, x509.KeyUsageCRLSign, x509.ExtKeyUsageServerAuth),
+			reason:  InvalidUsages,
+			message: "Issued certificate has contradictory usages, check spec.usages and spec.isCA: server auth extended key usage requires the digital signature, key encipherment or key agreement key usage",
+			failed:  true,
+		},
+		"mark as invalid a client auth and code signing certificate which can only encipher keys": {
+			cert:    mustCreateCert(false, x509.KeyUsageKeyEncipherment, x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageCodeSigning),
+			reason:  InvalidUsages,
+			message: "Issued certificate has contradictory usages, check spec.usages and spec.isCA: client auth extended key usage requires the digital signature or key agreement key usage; code signing extended key usage requires the digital signature key usage",
+			failed:  true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, failed := CurrentCertificateHasInvalidUsages(Input{
+				Secret: &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: test.cert}},
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.failed, failed)
+		})
+	}
+}
+
+// mustCreateKeyIDCert returns a self-signed certificate with the given
+// subject and authority key IDs, along with its PEM encoding.
+func mustCreateKeyIDCert(t *testing.T, subjectKeyID, authorityKeyID []byte) (*x509.Certificate, []byte) {
+	pk, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:   big.NewInt(1),
+		Subject:        pkix.Name{CommonName: "example.com"},
+		NotBefore:      time.Now(),
+		NotAfter:       time.Now().Add(time.Hour),
+		SubjectKeyId:   subjectKeyID,
+		AuthorityKeyId: authorityKeyID,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pk.Public(), pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func Test_SecretAuthorityKeyIDMismatch(t *testing.T) {
+	caWithKeyID, _ := mustCreateKeyIDCert(t, []byte{1, 2, 3}, nil)
+	caWithoutKeyID, _ := mustCreateKeyIDCert(t, nil, nil)
+	_, matchingCert := mustCreateKeyIDCert(t, nil, []byte{1, 2, 3})
+	_, mismatchingCert := mustCreateKeyIDCert(t, nil, []byte{4, 5, 6})
+	_, certWithoutKeyID := mustCreateKeyIDCert(t, nil, nil)
+
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(ref cmmeta.ObjectReference, ns string) (cmapi.GenericIssuer, error) {
+			if ref.Name == "missing-issuer" {
+				return nil, apierrors.NewNotFound(cmapi.Resource("issuers"), ref.Name)
+			}
+			return &cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{Name: ref.Name}}, nil
+		},
+	}
+	issuerCA := func(iss cmapi.GenericIssuer) (*x509.Certificate, error) {
+		switch iss.GetObjectMeta().Name {
+		case "ca-issuer":
+			return caWithKeyID, nil
+		case "ca-issuer-without-key-id":
+			return caWithoutKeyID, nil
+		default:
+			return nil, nil
+		}
+	}
+
+	tests := map[string]struct {
+		issuerName string
+		cert       []byte
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"do nothing if the authority key ID matches the CA's subject key ID": {
+			issuerName: "ca-issuer",
+			cert:       matchingCert,
+		},
+		"trigger issuance if the authority key ID does not match the CA's subject key ID": {
+			issuerName: "ca-issuer",
+			cert:       mismatchingCert,
+			reason:     CAChanged,
+			message:    "Issuing certificate as the authority key ID of the issued certificate (040506) does not match the subject key ID of the issuer's CA (010203)",
+			failed:     true,
+		},
+		"do nothing if the issued certificate has no authority key ID": {
+			issuerName: "ca-issuer",
+			cert:       certWithoutKeyID,
+		},
+		"do nothing if the CA has no subject key ID": {
+			issuerName: "ca-issuer-without-key-id",
+			cert:       mismatchingCert,
+		},
+		"do nothing if the issuer does not expose its CA": {
+			issuerName: "acme-issuer",
+			cert:       mismatchingCert,
+		},
+		"do nothing if the issuer cannot be found": {
+			issuerName: "missing-issuer",
+			cert:       mismatchingCert,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, failed := SecretAuthorityKeyIDMismatch(helper, issuerCA)(Input{
+				Certificate: gen.Certificate("test", gen.SetCertificateNamespace("testns"),
+					gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: test.issuerName}),
+				),
+				Secret: &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: test.cert}},
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.failed, failed)
+		})
+	}
+}
+
+func Test_CAIssuerSigningCertificate(t *testing.T) {
+	ca, caPEM := mustCreateKeyIDCert(t, []byte{1, 2, 3}, nil)
+	secretLister := testlisters.FakeSecretListerFrom(testlisters.NewFakeSecretLister(),
+		testlisters.SetFakeSecretListerSecret(func(namespace string) corelisters.SecretNamespaceLister {
+			return testlisters.NewFakeSecretNamespaceLister(func(f *testlisters.FakeSecretNamespaceLister) {
+				f.GetFn = func(name string) (*corev1.Secret, error) {
+					if namespace != "resource-ns" || name != "ca-key-pair" {
+						return nil, apierrors.NewNotFound(corev1.Resource("secrets"), name)
+					}
+					return &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: caPEM}}, nil
+				}
+			})
+		}),
+	)
+	issuerCA := CAIssuerSigningCertificate(secretLister, func(cmapi.GenericIssuer) string { return "resource-ns" })
+
+	got, err := issuerCA(&cmapi.ClusterIssuer{Spec: cmapi.IssuerSpec{IssuerConfig: cmapi.IssuerConfig{
+		CA: &cmapi.CAIssuer{SecretName: "ca-key-pair"},
+	}}})
+	assert.NoError(t, err)
+	assert.Equal(t, ca.SubjectKeyId, got.SubjectKeyId)
+
+	got, err = issuerCA(&cmapi.ClusterIssuer{Spec: cmapi.IssuerSpec{IssuerConfig: cmapi.IssuerConfig{
+		SelfSigned: &cmapi.SelfSignedIssuer{},
+	}}})
+	assert.NoError(t, err)
+	assert.Nil(t, got, "only CA issuers expose their CA")
+}
+
+func Test_SecretRevisionInvalid(t *testing.T) {
+	tests := map[string]struct {
+		revision          *int
+		secretAnnotations map[string]string
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"do nothing if the Secret's revision matches the Certificate's revision": {
+			revision:          pointer.Int(2),
+			secretAnnotations: map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: "2"},
+		},
+		"do nothing if the Certificate has no revision": {
+			secretAnnotations: map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: "garbage"},
+		},
+		"do nothing if the Secret has no revision annotation": {
+			revision: pointer.Int(2),
+		},
+		"trigger issuance if the Secret's revision is not a number": {
+			revision:          pointer.Int(2),
+			secretAnnotations: map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: "garbage"},
+			reason:            InvalidRevision,
+			message:           `Issuing certificate as the cert-manager.io/certificate-revision annotation of the Secret is not a number: "garbage"`,
+			failed:            true,
+		},
+		"trigger issuance if the Secret's revision does not match the Certificate's revision": {
+			revision:          pointer.Int(2),
+			secretAnnotations: map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: "1"},
+			reason:            InvalidRevision,
+			message:           "Issuing certificate as the revision of the Secret (1) does not match the Certificate's revision (2)",
+			failed:            true,
+		},
+		"trigger issuance if the Certificate's revision is not positive": {
+			revision:          pointer.Int(0),
+			secretAnnotations: map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: "0"},
+			reason:            InvalidRevision,
+			message:           "Issuing certificate as the Certificate's revision 0 is not a positive number",
+			failed:            true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			crt := gen.Certificate("cert-1", gen.SetCertificateNamespace("ns-1"),
+				gen.SetCertificateUID("cert-1-uid"),
+				gen.SetCertificateSecretName("secret-1"),
+			)
+			crt.Status.Revision = test.revision
+
+			// Build the input using the gatherer, so that the policy is
+			// evaluated against what the trigger controller would see: the
+			// current CertificateRequest is only found for the Certificate's
+			// revision, however the Secret is always returned.
+			sec := secret("secret-1", "ns-1", map[string][]byte{corev1.TLSCertKey: []byte("cert")})
+			sec.Annotations = test.secretAnnotations
+			builder := &testpkg.Builder{
+				KubeObjects: []runtime.Object{sec},
+				CertManagerObjects: []runtime.Object{
+					cr("cr-1", "ns-1", "cert-1-uid", map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: "1"}),
+					cr("cr-2", "ns-1", "cert-1-uid", map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: "2"}),
+				},
+			}
+			builder.T = t
+			builder.Init()
+			noop := cache.ResourceEventHandlerFuncs{AddFunc: func(obj interface{}) {}}
+			builder.SharedInformerFactory.Certmanager().V1().CertificateRequests().Informer().AddEventHandler(noop)
+			builder.KubeSharedInformerFactory.Core().V1().Secrets().Informer().AddEventHandler(noop)
+			builder.Start()
+			defer builder.Stop()
+
+			g := &Gatherer{
+				CertificateRequestLister: builder.SharedInformerFactory.Certmanager().V1().CertificateRequests().Lister(),
+				SecretLister:             builder.KubeSharedInformerFactory.Core().V1().Secrets().Lister(),
+			}
+			input, err := g.DataForCertificate(context.Background(), crt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reason, message, failed := SecretRevisionInvalid(input)
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.failed, failed)
+		})
+	}
+}
+
+// mustCreateChainCert creates a certificate for the given subject signed by
+// parent and parentKey, or a self signed CA certificate if parent is nil.
+func mustCreateChainCert(t *testing.T, subject string, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer, []byte) {
+	pk, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: subject},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  parent == nil || subject != "leaf",
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	return mustSignChainCert(t, template, pk, parent, parentKey)
+}
+
+// mustCreateLegacyCACert creates a CA certificate for the given subject
+// signed by parent and parentKey that, like v1 certificates, does not have
+// the basic constraints extension.
+func mustCreateLegacyCACert(t *testing.T, subject string, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer, []byte) {
+	pk, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: subject},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	return mustSignChainCert(t, template, pk, parent, parentKey)
+}
+
+func mustSignChainCert(t *testing.T, template *x509.Certificate, pk crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer, []byte) {
+	if parent == nil {
+		parent, parentKey = template, pk
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pk.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, pk, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func Test_SecretIssuedByRevokedIntermediate(t *testing.T) {
+	root, rootKey, _ := mustCreateChainCert(t, "root", nil, nil)
+	revokedIntermediate, revokedKey, revokedPEM := mustCreateChainCert(t, "revoked-intermediate", root, rootKey)
+	allowedIntermediate, allowedKey, allowedPEM := mustCreateChainCert(t, "allowed-intermediate", root, rootKey)
+	_, _, revokedLeafPEM := mustCreateChainCert(t, "leaf", revokedIntermediate, revokedKey)
+	_, _, allowedLeafPEM := mustCreateChainCert(t, "leaf", allowedIntermediate, allowedKey)
+
+	sum := sha256.Sum256(revokedIntermediate.Raw)
+	revokedFingerprint := hex.EncodeToString(sum[:])
+	revoked := func() (sets.String, error) {
+		return sets.NewString(revokedFingerprint), nil
+	}
+
+	tests := map[string]struct {
+		chain   []byte
+		revoked RevokedIntermediatesFunc
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"trigger issuance if the certificate was signed by a revoked intermediate": {
+			chain:   append(revokedLeafPEM, revokedPEM...),
+			revoked: revoked,
+			reason:  RevokedIntermediate,
+			message: fmt.Sprintf("Issuing certificate as it was signed by the revoked intermediate %q (SHA-256 fingerprint %s)", "CN=revoked-intermediate", revokedFingerprint),
+			failed:  true,
 		},
-		"if template annotations and labels match managed fields, should return false": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
-			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{{
-				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo2": {}
-							},
-							"f:labels": {
-								"f:abc": {},
-								"f:def": {}
-							}
-						}}`),
-				}},
-			},
-			expReason:    "",
-			expMessage:   "",
-			expViolation: false,
+		"do nothing if the certificate was signed by an allowed intermediate": {
+			chain:   append(allowedLeafPEM, allowedPEM...),
+			revoked: revoked,
 		},
-		"if template annotations is a subset of managed fields, return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
-			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{{
-				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo2": {},
-								"f:foo3": {}
-							},
-							"f:labels": {
-								"f:abc": {},
-								"f:def": {}
-							}
-						}}`),
-				}},
-			},
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
-			expViolation: true,
+		"do nothing if the stored chain does not contain the issuing certificate": {
+			chain:   append(revokedLeafPEM, allowedPEM...),
+			revoked: revoked,
 		},
-		"if template labels is a subset of managed fields, return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
-			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{{
-				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo2": {}
-							},
-							"f:labels": {
-								"f:abc": {},
-								"f:def": {},
-								"f:ghi": {}
-							}
-						}}`),
-				}},
-			},
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
-			expViolation: true,
+		"do nothing if the stored chain only contains the certificate": {
+			chain:   revokedLeafPEM,
+			revoked: revoked,
 		},
-		"if managed fields annotations is a subset of template, return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2", "foo3": "bar3"},
-				Labels:      map[string]string{"abc": "123", "def": "456"},
-			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{{
-				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo2": {}
-							},
-							"f:labels": {
-								"f:abc": {},
-								"f:def": {}
-							}
-						}}`),
-				}},
+		"do nothing if the revoked intermediates cannot be loaded": {
+			chain: append(revokedLeafPEM, revokedPEM...),
+			revoked: func() (sets.String, error) {
+				return nil, errors.New("not synced")
 			},
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
-			expViolation: true,
 		},
-		"if managed fields labels is a subset of template, return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-				Labels:      map[string]string{"abc": "123", "def": "456", "ghi": "789"},
-			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{{
-				Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo2": {}
-							},
-							"f:labels": {
-								"f:abc": {},
-								"f:def": {}
-							}
-						}}`),
-				}},
-			},
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
-			expViolation: true,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, failed := SecretIssuedByRevokedIntermediate(test.revoked)(Input{
+				Secret: &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: test.chain}},
+			})
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.failed, failed)
+		})
+	}
+}
+
+func Test_RevokedIntermediatesFromConfigMap(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	lister := corelisters.NewConfigMapLister(indexer)
+
+	revoked, err := RevokedIntermediatesFromConfigMap(lister, "cert-manager", "revoked")()
+	assert.NoError(t, err)
+	assert.Empty(t, revoked.List(), "expected a missing ConfigMap to contain no fingerprints")
+
+	err = indexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cert-manager", Name: "revoked"},
+		Data: map[string]string{
+			"ca-1": "AB:CD:EF\n0123\n",
+			"ca-2": "4567",
 		},
-		"if managed fields matches template but is split across multiple managed fields, should return false": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2", "foo3": "bar3"},
-				Labels:      map[string]string{"abc": "123", "def": "456", "ghi": "789"},
-			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{
-				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:labels": {
-								"f:ghi": {}
-							}
-						}}`),
-				}},
-				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo3": {}
-							},
-							"f:labels": {
-								"f:abc": {},
-								"f:def": {}
-							}
-						}}`),
-				}},
-				{Manager: fieldManager,
-					FieldsV1: &metav1.FieldsV1{
-						Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo2": {}
-							},
-							"f:labels": {
-								"f:abc": {},
-								"f:def": {}
-							}
-						}}`),
-					}},
-			},
-			expReason:    "",
-			expMessage:   "",
-			expViolation: false,
+	})
+	assert.NoError(t, err)
+
+	revoked, err = RevokedIntermediatesFromConfigMap(lister, "cert-manager", "revoked")()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0123", "4567", "abcdef"}, revoked.List())
+}
+
+func Test_SecretCertificateOutlivesIssuerCA(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	mustCreateCertExpiringAt := func(notAfter time.Time) (*x509.Certificate, []byte) {
+		pk, err := pki.GenerateECPrivateKey(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "example.com"},
+			NotBefore:    now.Add(-time.Hour),
+			NotAfter:     notAfter,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, pk.Public(), pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	ca, _ := mustCreateCertExpiringAt(now.Add(24 * time.Hour))
+	_, certExpiringBeforeCA := mustCreateCertExpiringAt(now.Add(12 * time.Hour))
+	_, certExpiringWithCA := mustCreateCertExpiringAt(now.Add(24 * time.Hour))
+	_, certOutlivingCA := mustCreateCertExpiringAt(now.Add(48 * time.Hour))
+
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(ref cmmeta.ObjectReference, ns string) (cmapi.GenericIssuer, error) {
+			if ref.Name == "missing-issuer" {
+				return nil, apierrors.NewNotFound(cmapi.Resource("issuers"), ref.Name)
+			}
+			return &cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{Name: ref.Name}}, nil
 		},
-		"if managed fields matches template and base cert-manager annotations are present with no certificate data, should return false": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{
-				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo2": {},
-								"f:cert-manager.io/certificate-name": {},
-								"f:cert-manager.io/issuer-name": {},
-								"f:cert-manager.io/issuer-kind": {},
-								"f:cert-manager.io/issuer-group": {}
-							}
-						}}`),
-				}},
-			},
-			expReason:    "",
-			expMessage:   "",
-			expViolation: false,
+	}
+	issuerCA := func(iss cmapi.GenericIssuer) (*x509.Certificate, error) {
+		if iss.GetObjectMeta().Name == "ca-issuer" {
+			return ca, nil
+		}
+		return nil, nil
+	}
+
+	tests := map[string]struct {
+		issuerName string
+		cert       []byte
+
+		reason  string
+		message string
+		failed  bool
+	}{
+		"do nothing if the issued certificate expires before the CA": {
+			issuerName: "ca-issuer",
+			cert:       certExpiringBeforeCA,
 		},
-		"if managed fields matches template and base cert-manager annotations are present with certificate data, should return false": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{
-				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo2": {},
-								"f:cert-manager.io/certificate-name": {},
-								"f:cert-manager.io/issuer-name": {},
-								"f:cert-manager.io/issuer-kind": {},
-								"f:cert-manager.io/issuer-group": {},
-								"f:cert-manager.io/common-name": {},
-								"f:cert-manager.io/alt-names":  {},
-								"f:cert-manager.io/ip-sans": {},
-								"f:cert-manager.io/uri-sans": {}
-							}
-						}}`),
-				}},
-			},
-			secretData:   map[string][]byte{corev1.TLSCertKey: baseCertBundle.CertBytes},
-			expViolation: false,
+		"do nothing if the issued certificate expires at the same time as the CA": {
+			issuerName: "ca-issuer",
+			cert:       certExpiringWithCA,
 		},
-		"if managed fields matches template and base cert-manager annotations are present with certificate data but certificate data is nil, should return true": {
-			tmpl: &cmapi.CertificateSecretTemplate{
-				Annotations: map[string]string{"foo1": "bar1", "foo2": "bar2"},
-			},
-			secretManagedFields: []metav1.ManagedFieldsEntry{
-				{Manager: fieldManager, FieldsV1: &metav1.FieldsV1{
-					Raw: []byte(`{"f:metadata": {
-							"f:annotations": {
-								"f:foo1": {},
-								"f:foo2": {},
-								"f:cert-manager.io/certificate-name": {},
-								"f:cert-manager.io/issuer-name": {},
-								"f:cert-manager.io/issuer-kind": {},
-								"f:cert-manager.io/issuer-group": {},
-								"f:cert-manager.io/common-name": {},
-								"f:cert-manager.io/alt-names":  {},
-								"f:cert-manager.io/ip-sans": {},
-								"f:cert-manager.io/uri-sans": {}
-							}
-						}}`),
-				}},
-			},
-			expReason:    SecretTemplateMismatch,
-			expMessage:   "Certificate's SecretTemplate doesn't match Secret",
-			expViolation: true,
+		"trigger issuance if the CA expires before the issued certificate": {
+			issuerName: "ca-issuer",
+			cert:       certOutlivingCA,
+			reason:     OutlivesCA,
+			message: fmt.Sprintf("Issuing certificate as the issued certificate expires at %s, after the issuer's CA certificate which expires at %s",
+				now.Add(48*time.Hour).UTC().Format(time.RFC3339), now.Add(24*time.Hour).UTC().Format(time.RFC3339)),
+			failed: true,
+		},
+		"do nothing if the issuer does not expose its CA": {
+			issuerName: "acme-issuer",
+			cert:       certOutlivingCA,
+		},
+		"do nothing if the issuer cannot be found": {
+			issuerName: "missing-issuer",
+			cert:       certOutlivingCA,
 		},
 	}
-
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			gotReason, gotMessage, gotViolation := SecretTemplateMismatchesSecretManagedFields(fieldManager)(Input{
-				Certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretTemplate: test.tmpl}},
-				Secret:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{ManagedFields: test.secretManagedFields}, Data: test.secretData},
+			reason, message, failed := SecretCertificateOutlivesIssuerCA(helper, issuerCA)(Input{
+				Certificate: gen.Certificate("test", gen.SetCertificateNamespace("testns"),
+					gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: test.issuerName}),
+				),
+				Secret: &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: test.cert}},
 			})
-
-			assert.Equal(t, test.expReason, gotReason, "unexpected reason")
-			assert.Equal(t, test.expMessage, gotMessage, "unexpected message")
-			assert.Equal(t, test.expViolation, gotViolation, "unexpected violation")
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.failed, failed)
 		})
 	}
 }
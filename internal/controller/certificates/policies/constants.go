@@ -23,27 +23,60 @@ const (
 	// MissingData is a policy violation reason for a scenario where
 	// Certificate's spec.secretName secret has missing data.
 	MissingData string = "MissingData"
+	// WrongSecretType is a policy violation reason for a scenario where
+	// Certificate's spec.secretName secret is not of type kubernetes.io/tls
+	// and does not contain a private key and certificate.
+	WrongSecretType string = "WrongSecretType"
 	// InvalidKeyPair is a policy violation reason for a scenario where public
 	// key of certificate does not match private key.
 	InvalidKeyPair string = "InvalidKeyPair"
 	// InvalidCertificate is a policy violation whereby the signed certificate in
 	// the Input Secret could not be parsed or decoded.
 	InvalidCertificate string = "InvalidCertificate"
+	// IncompleteChain is a policy violation reason for a scenario where the
+	// signed certificate in Certificate's spec.secretName secret cannot be
+	// verified against the CA certificate stored alongside it.
+	IncompleteChain string = "IncompleteChain"
 	// SecretMismatch is a policy violation reason for a scenario where Secret's
 	// private key does not match spec.
 	SecretMismatch string = "SecretMismatch"
+	// PrivateKeyReused is a policy violation reason for a scenario where
+	// Secret's private key is the same as the one used for the previous
+	// revision even though the Certificate's private key rotation policy is
+	// Always.
+	PrivateKeyReused string = "PrivateKeyReused"
 	// IncorrectIssuer is a policy violation reason for a scenario where
 	// Certificate has been issued by incorrect Issuer.
 	IncorrectIssuer string = "IncorrectIssuer"
+	// CAChanged is a policy violation reason for a scenario where the CA
+	// that signed Certificate's issued certificate is no longer the CA used
+	// by the Issuer.
+	CAChanged string = "CAChanged"
 	// RequestChanged is a policy violation reason for a scenario where
 	// CertificateRequest not valid for Certificate's spec.
 	RequestChanged string = "RequestChanged"
 	// Renewing is a policy violation reason for a scenario where
 	// Certificate's renewal time is now or in past.
 	Renewing string = "Renewing"
+	// IssuedBeforeCutoff is a policy violation reason for a scenario where
+	// Certificate's issued certificate was issued before the configured
+	// cutoff time, e.g. because the key of the CA that signed it may have
+	// been compromised.
+	IssuedBeforeCutoff string = "IssuedBeforeCutoff"
+	// ExpiresBeforeResync is a policy violation reason for a scenario where
+	// Certificate's issued certificate would expire before the next resync.
+	ExpiresBeforeResync string = "ExpiresBeforeResync"
+	// MissingCertificatePolicy is a policy violation reason for a scenario
+	// where Certificate's issued certificate does not contain a certificate
+	// policy that is required by the Certificate.
+	MissingCertificatePolicy string = "MissingCertificatePolicy"
 	// Expired is a policy violation reason for a scenario where Certificate has
 	// expired.
 	Expired string = "Expired"
+	// NotYetValid is a policy violation reason for a scenario where
+	// Certificate's issued certificate has a notBefore in the future, e.g.
+	// due to an issuer bug, so cannot be used yet.
+	NotYetValid string = "NotYetValid"
 	// SecretTemplateMisMatch is a policy violation whereby the Certificate's
 	// SecretTemplate is not reflected on the target Secret, either by having
 	// extra, missing, or wrong Annotations or Labels.
@@ -51,4 +84,169 @@ const (
 	// ManagedFieldsParseError is a policy violation whereby cert-manager was
 	// unable to decode the managed fields on a resource.
 	ManagedFieldsParseError string = "ManagedFieldsParseError"
+	// InvalidUsages is a policy violation reason for a scenario where the
+	// issued certificate's key usages, extended key usages and basic
+	// constraints contradict each other.
+	InvalidUsages string = "InvalidUsages"
+	// SecretImmutable is a policy violation reason for a scenario where
+	// Certificate's spec.secretName secret is immutable, so cannot be updated
+	// by cert-manager.
+	SecretImmutable string = "SecretImmutable"
+	// SecretTerminating is a policy violation reason for a scenario where
+	// Certificate's spec.secretName secret is being deleted but has not yet
+	// been removed, for example because it is held in place by a finalizer.
+	SecretTerminating string = "SecretTerminating"
+	// SecretNotManaged is a policy violation reason for a scenario where
+	// Certificate's spec.secretName secret already exists and contains data,
+	// but was not created by cert-manager and has not been annotated to allow
+	// cert-manager to adopt it.
+	SecretNotManaged string = "SecretNotManaged"
+	// DuplicateSerial is a policy violation reason for a scenario where
+	// Certificate's issued certificate has the same serial number as the
+	// issued certificate of another Certificate in the same namespace.
+	DuplicateSerial string = "DuplicateSerial"
+	// KeystorePasswordMismatch is a policy violation reason for a scenario
+	// where the keystores in Certificate's spec.secretName secret were built
+	// with a different password Secret reference than the one currently
+	// configured on the Certificate.
+	KeystorePasswordMismatch string = "KeystorePasswordMismatch"
+	// IssuerNotFound is a policy violation reason for a scenario where the
+	// Issuer or ClusterIssuer referenced by Certificate's spec.issuerRef does
+	// not exist.
+	IssuerNotFound string = "IssuerNotFound"
+	// InvalidRevision is a policy violation reason for a scenario where the
+	// revision annotation of Certificate's Secret cannot be parsed or does
+	// not match the Certificate's status.revision.
+	InvalidRevision string = "InvalidRevision"
+	// RevokedIntermediate is a policy violation reason for a scenario where
+	// Certificate's issued certificate was signed by an intermediate CA that
+	// has been revoked.
+	RevokedIntermediate string = "RevokedIntermediate"
+	// DeniedUsage is a policy violation reason for a scenario where
+	// Certificate's issued certificate has an extended key usage that the
+	// Issuer it references no longer grants.
+	DeniedUsage string = "DeniedUsage"
+	// MissingRevision is a policy violation reason for a scenario where
+	// Certificate's spec.secretName secret does not have the revision
+	// annotation, e.g. because it was written by an older version of
+	// cert-manager.
+	MissingRevision string = "MissingRevision"
+	// DurationTooShort is a policy violation reason for a scenario where
+	// Certificate's spec.duration is shorter than the minimum duration that
+	// its issuer will accept.
+	DurationTooShort string = "DurationTooShort"
+	// OutlivesCA is a policy violation reason for a scenario where
+	// Certificate's issued certificate expires after the CA certificate of
+	// the issuer it references.
+	OutlivesCA string = "OutlivesCA"
+	// StaleSecret is a policy violation reason for a scenario where
+	// Certificate's spec.secretName secret does not contain the certificate
+	// issued by the CertificateRequest of the Certificate's current revision.
+	StaleSecret string = "StaleSecret"
 )
+
+// Reason is a typed representation of a policy violation reason, allowing
+// callers to switch on the outcome of a policy check without comparing
+// strings. Each Reason corresponds to one of the string reason constants
+// above, which remain the values returned by policy Funcs.
+type Reason int
+
+const (
+	// ReasonUnknown is returned by ParseReason for strings that are not a
+	// known policy violation reason, including the empty string.
+	ReasonUnknown Reason = iota
+	ReasonDoesNotExist
+	ReasonMissingData
+	ReasonWrongSecretType
+	ReasonInvalidKeyPair
+	ReasonInvalidCertificate
+	ReasonIncompleteChain
+	ReasonSecretMismatch
+	ReasonPrivateKeyReused
+	ReasonIncorrectIssuer
+	ReasonCAChanged
+	ReasonRequestChanged
+	ReasonRenewing
+	ReasonIssuedBeforeCutoff
+	ReasonExpiresBeforeResync
+	ReasonMissingCertificatePolicy
+	ReasonExpired
+	ReasonNotYetValid
+	ReasonSecretTemplateMismatch
+	ReasonManagedFieldsParseError
+	ReasonInvalidUsages
+	ReasonSecretImmutable
+	ReasonSecretTerminating
+	ReasonSecretNotManaged
+	ReasonDuplicateSerial
+	ReasonKeystorePasswordMismatch
+	ReasonIssuerNotFound
+	ReasonInvalidRevision
+	ReasonRevokedIntermediate
+	ReasonDeniedUsage
+	ReasonMissingRevision
+	ReasonDurationTooShort
+	ReasonOutlivesCA
+	ReasonStaleSecret
+)
+
+// reasonStrings maps each Reason to its string reason constant.
+var reasonStrings = map[Reason]string{
+	ReasonDoesNotExist:             DoesNotExist,
+	ReasonMissingData:              MissingData,
+	ReasonWrongSecretType:          WrongSecretType,
+	ReasonInvalidKeyPair:           InvalidKeyPair,
+	ReasonInvalidCertificate:       InvalidCertificate,
+	ReasonIncompleteChain:          IncompleteChain,
+	ReasonSecretMismatch:           SecretMismatch,
+	ReasonPrivateKeyReused:         PrivateKeyReused,
+	ReasonIncorrectIssuer:          IncorrectIssuer,
+	ReasonCAChanged:                CAChanged,
+	ReasonRequestChanged:           RequestChanged,
+	ReasonRenewing:                 Renewing,
+	ReasonIssuedBeforeCutoff:       IssuedBeforeCutoff,
+	ReasonExpiresBeforeResync:      ExpiresBeforeResync,
+	ReasonMissingCertificatePolicy: MissingCertificatePolicy,
+	ReasonExpired:                  Expired,
+	ReasonNotYetValid:              NotYetValid,
+	ReasonSecretTemplateMismatch:   SecretTemplateMismatch,
+	ReasonManagedFieldsParseError:  ManagedFieldsParseError,
+	ReasonInvalidUsages:            InvalidUsages,
+	ReasonSecretImmutable:          SecretImmutable,
+	ReasonSecretTerminating:        SecretTerminating,
+	ReasonSecretNotManaged:         SecretNotManaged,
+	ReasonDuplicateSerial:          DuplicateSerial,
+	ReasonKeystorePasswordMismatch: KeystorePasswordMismatch,
+	ReasonIssuerNotFound:           IssuerNotFound,
+	ReasonInvalidRevision:          InvalidRevision,
+	ReasonRevokedIntermediate:      RevokedIntermediate,
+	ReasonDeniedUsage:              DeniedUsage,
+	ReasonMissingRevision:          MissingRevision,
+	ReasonDurationTooShort:         DurationTooShort,
+	ReasonOutlivesCA:               OutlivesCA,
+	ReasonStaleSecret:              StaleSecret,
+}
+
+// reasonsByString maps each string reason constant to its Reason.
+var reasonsByString = func() map[string]Reason {
+	m := make(map[string]Reason, len(reasonStrings))
+	for r, s := range reasonStrings {
+		m[s] = r
+	}
+	return m
+}()
+
+// ParseReason returns the Reason for the given policy violation reason
+// string, or ReasonUnknown if the string is not a known reason.
+func ParseReason(s string) Reason {
+	return reasonsByString[s]
+}
+
+// String returns the string reason constant for the Reason, or "Unknown" if
+// the Reason is not known.
+func (r Reason) String() string {
+	if s, ok := reasonStrings[r]; ok {
+		return s
+	}
+	return "Unknown"
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Reason ensures that every string reason constant declared in
+// constants.go has a corresponding Reason, and that the two round trip.
+func Test_Reason(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "constants.go", nil, 0)
+	require.NoError(t, err)
+
+	var reasons []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if typ, ok := value.Type.(*ast.Ident); !ok || typ.Name != "string" {
+				continue
+			}
+			for _, v := range value.Values {
+				reason, err := strconv.Unquote(v.(*ast.BasicLit).Value)
+				require.NoError(t, err)
+				reasons = append(reasons, reason)
+			}
+		}
+	}
+	require.NotEmpty(t, reasons)
+	assert.Len(t, reasonStrings, len(reasons), "every Reason should map to exactly one string reason constant")
+
+	for _, reason := range reasons {
+		r := ParseReason(reason)
+		assert.NotEqual(t, ReasonUnknown, r, "string reason constant %q has no corresponding Reason", reason)
+		assert.Equal(t, reason, r.String())
+	}
+
+	assert.Equal(t, ReasonUnknown, ParseReason(""))
+	assert.Equal(t, ReasonUnknown, ParseReason("NotARealReason"))
+	assert.Equal(t, "Unknown", ReasonUnknown.String())
+	assert.Equal(t, ReasonSecretMismatch, ParseReason(SecretMismatch))
+}
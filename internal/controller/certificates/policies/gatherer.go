@@ -211,6 +211,7 @@ import (
 	"context"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	corelisters "k8s.io/client-go/listers/core/v1"
@@ -278,6 +279,29 @@ func (g *Gatherer) DataForCertificate(ctx context.Context, crt *cmapi.Certificat
 		}
 	}
 
+	// Attempt to fetch the CertificateRequest for the revision preceding the
+	// current status.revision. It may have been garbage collected according
+	// to the Certificate's spec.revisionHistoryLimit, and it is only used for
+	// informational checks, so it is not an error if it cannot be found or if
+	// duplicates exist.
+	var prevCR *cmapi.CertificateRequest
+	if crt.Status.Revision != nil && *crt.Status.Revision > 1 {
+		prevCRRevision := *crt.Status.Revision - 1
+		reqs, err := certificates.ListCertificateRequestsMatchingPredicates(g.CertificateRequestLister.CertificateRequests(crt.Namespace),
+			labels.Everything(),
+			predicate.ResourceOwnedBy(crt),
+			predicate.CertificateRequestRevision(prevCRRevision),
+		)
+		if err != nil {
+			return Input{}, err
+		}
+		if len(reqs) == 1 {
+			prevCR = reqs[0]
+		} else {
+			log.V(logf.DebugLevel).Info("Found no single CertificateRequest owned by this Certificate for the previous revision", "revision", prevCRRevision, "found", len(reqs))
+		}
+	}
+
 	// Attempt fetching the CertificateRequest for the next status.revision.
 	var nextCR *cmapi.CertificateRequest
 	nextCRRevision := 1
@@ -313,9 +337,11 @@ func (g *Gatherer) DataForCertificate(ctx context.Context, crt *cmapi.Certificat
 	}
 
 	return Input{
-		Certificate:            crt,
-		Secret:                 secret,
-		CurrentRevisionRequest: curCR,
-		NextRevisionRequest:    nextCR,
+		Certificate:             crt,
+		Secret:                  secret,
+		CurrentRevisionRequest:  curCR,
+		NextRevisionRequest:     nextCR,
+		PreviousRevisionRequest: prevCR,
+		IsInitialIssuance:       secret == nil || len(secret.Data[corev1.TLSCertKey]) == 0,
 	}, nil
 }
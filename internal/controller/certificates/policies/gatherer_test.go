@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/cache"
@@ -45,8 +46,13 @@ func TestDataForCertificate(t *testing.T) {
 		givenCert  *cmapi.Certificate
 		wantCurCR  *cmapi.CertificateRequest
 		wantNextCR *cmapi.CertificateRequest
+		wantPrevCR *cmapi.CertificateRequest
 		wantSecret *corev1.Secret
 		wantErr    string
+
+		// wantRenewal is true when the Secret already holds a certificate,
+		// i.e. when the input is not for the initial issuance.
+		wantRenewal bool
 	}{
 		"when no secret is found, the returned secret is nil": {
 			givenCert: gen.Certificate("cert-1", gen.SetCertificateNamespace("default-unit-test-ns"),
@@ -114,6 +120,52 @@ func TestDataForCertificate(t *testing.T) {
 			wantCurCR:  cr("cr-1-rev1", "ns-1", "cert-1-uid", map[string]string{"cert-manager.io/certificate-revision": "1"}),
 			wantNextCR: cr("cr-1-rev2", "ns-1", "cert-1-uid", map[string]string{"cert-manager.io/certificate-revision": "2"}),
 		},
+		"when cert revision=2, should return the previous CR with revision=1": {
+			givenCert: gen.Certificate("cert-1", gen.SetCertificateNamespace("ns-1"),
+				gen.SetCertificateUID("cert-1-uid"),
+				gen.SetCertificateRevision(2),
+			),
+			builder: &testpkg.Builder{CertManagerObjects: []runtime.Object{
+				cr("cr-1-rev1", "ns-1", "cert-1-uid", map[string]string{"cert-manager.io/certificate-revision": "1"}),
+				cr("cr-1-rev2", "ns-1", "cert-1-uid", map[string]string{"cert-manager.io/certificate-revision": "2"}),
+				cr("cr-unrelated-rev1", "ns-1", "cert-unrelated-uid", map[string]string{"cert-manager.io/certificate-revision": "1"}),
+			}},
+			wantPrevCR: cr("cr-1-rev1", "ns-1", "cert-1-uid", map[string]string{"cert-manager.io/certificate-revision": "1"}),
+			wantCurCR:  cr("cr-1-rev2", "ns-1", "cert-1-uid", map[string]string{"cert-manager.io/certificate-revision": "2"}),
+			wantNextCR: nil,
+		},
+		"when the secret holds a certificate, the input is for a renewal": {
+			givenCert: gen.Certificate("cert-1", gen.SetCertificateNamespace("ns-1"),
+				gen.SetCertificateSecretName("secret-1"),
+				gen.SetCertificateUID("cert-1-uid"),
+			),
+			builder: &testpkg.Builder{KubeObjects: []runtime.Object{
+				secret("secret-1", "ns-1", map[string][]byte{corev1.TLSCertKey: []byte("cert")}),
+			}},
+			wantSecret:  secret("secret-1", "ns-1", map[string][]byte{corev1.TLSCertKey: []byte("cert")}),
+			wantRenewal: true,
+		},
+		"when the secret does not hold a certificate, the input is for the initial issuance": {
+			givenCert: gen.Certificate("cert-1", gen.SetCertificateNamespace("ns-1"),
+				gen.SetCertificateSecretName("secret-1"),
+				gen.SetCertificateUID("cert-1-uid"),
+			),
+			builder: &testpkg.Builder{KubeObjects: []runtime.Object{
+				secret("secret-1", "ns-1", map[string][]byte{corev1.TLSPrivateKeyKey: []byte("key")}),
+			}},
+			wantSecret: secret("secret-1", "ns-1", map[string][]byte{corev1.TLSPrivateKeyKey: []byte("key")}),
+		},
+		"should not error when duplicate previous CRs are found": {
+			givenCert: gen.Certificate("cert-1", gen.SetCertificateNamespace("ns-1"),
+				gen.SetCertificateUID("cert-1-uid"),
+				gen.SetCertificateRevision(2),
+			),
+			builder: &testpkg.Builder{CertManagerObjects: []runtime.Object{
+				cr("cr-1-rev1a", "ns-1", "cert-1-uid", map[string]string{"cert-manager.io/certificate-revision": "1"}),
+				cr("cr-1-rev1b", "ns-1", "cert-1-uid", map[string]string{"cert-manager.io/certificate-revision": "1"}),
+			}},
+			wantPrevCR: nil,
+		},
 		"should error when duplicate current CRs are found": {
 			givenCert: gen.Certificate("cert-1", gen.SetCertificateNamespace("ns-1"),
 				gen.SetCertificateUID("cert-1-uid"),
@@ -226,7 +278,9 @@ func TestDataForCertificate(t *testing.T) {
 				assert.Equal(t, test.givenCert, got.Certificate, "input cert should always be equal to returned cert")
 				assert.Equal(t, test.wantCurCR, got.CurrentRevisionRequest)
 				assert.Equal(t, test.wantNextCR, got.NextRevisionRequest)
+				assert.Equal(t, test.wantPrevCR, got.PreviousRevisionRequest)
 				assert.Equal(t, test.wantSecret, got.Secret)
+				assert.Equal(t, !test.wantRenewal, got.IsInitialIssuance)
 			}
 		})
 	}
@@ -252,3 +306,10 @@ func cr(crName, crNamespace, ownerCertUID string, annot map[string]string) *cmap
 		gen.AddCertificateRequestAnnotations(annot),
 	)
 }
+
+func secret(name, namespace string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       data,
+	}
+}
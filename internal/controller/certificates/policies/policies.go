@@ -18,10 +18,15 @@ limitations under the License.
 package policies
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/clock"
 
+	internalcertificates "github.com/cert-manager/cert-manager/internal/controller/certificates"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/cert-manager/pkg/issuer"
 )
 
 type Input struct {
@@ -40,22 +45,49 @@ type Input struct {
 	// Take a look at the gatherer package's documentation to see more about why
 	// we care about the "next" certificate request.
 	NextRevisionRequest *cmapi.CertificateRequest
+
+	// The "previous" certificate request designates the certificate request
+	// that led to the revision before the current one, if it still exists.
+	// Its CSR holds the public key of the previous revision, allowing policies
+	// to compare the key material of the current and previous revisions, for
+	// example to detect private keys being reused between revisions.
+	// It is only populated by the gatherer and is nil when the request no
+	// longer exists or the caller has no revision history, so policies must
+	// treat a nil value as "unknown" rather than as a violation.
+	PreviousRevisionRequest *cmapi.CertificateRequest
+
+	// IsInitialIssuance is true when no certificate has been stored for the
+	// Certificate yet, i.e. when the Secret does not exist or does not
+	// contain a certificate. Policies can use it to behave differently for
+	// the first issuance of a Certificate than for its renewal.
+	// It is populated by the gatherer.
+	IsInitialIssuance bool
 }
 
 // A Func evaluates the given input data and decides whether a check has passed
 // or failed, returning additional human readable information in the 'reason'
 // and 'message' return parameters if so.
+// A Func must be safe for concurrent use, as the controllers evaluating
+// policies do so from multiple workers at once. It must not modify its input.
 type Func func(Input) (reason, message string, failed bool)
 
-// A Chain of PolicyFuncs to be evaluated in order.
-type Chain []Func
+// Policy is a named policy Func.
+type Policy struct {
+	// Name of the policy, e.g. SecretDoesNotExistPolicy.
+	Name string
+	// Func evaluates the policy.
+	Func Func
+}
+
+// A Chain of Policies to be evaluated in order.
+type Chain []Policy
 
 // Evaluate will evaluate the entire policy chain using the provided input.
 // As soon as it is discovered that the input violates one policy,
 // Evaluate will return and not evaluate the rest of the chain.
 func (c Chain) Evaluate(input Input) (string, string, bool) {
-	for _, policyFunc := range c {
-		reason, message, violationFound := policyFunc(input)
+	for _, policy := range c {
+		reason, message, violationFound := policy.Func(input)
 		if violationFound {
 			return reason, message, violationFound
 		}
@@ -63,39 +95,412 @@ func (c Chain) Evaluate(input Input) (string, string, bool) {
 	return "", "", false
 }
 
+// Names returns the names of the policies in the chain, in the order in
+// which they are evaluated.
+func (c Chain) Names() []string {
+	names := make([]string, len(c))
+	for i, policy := range c {
+		names[i] = policy.Name
+	}
+	return names
+}
+
+// Names of the policies in the trigger policy chain. These are stable and can
+// be used to disable individual policies using
+// TriggerPolicyOptions.DisabledPolicies.
+const (
+	IssuerDoesNotExistPolicy                         = "IssuerDoesNotExist"
+	DurationBelowMinimumPolicy                       = "DurationBelowMinimum"
+	SecretDoesNotExistPolicy                         = "SecretDoesNotExist"
+	SecretIsTerminatingPolicy                        = "SecretIsTerminating"
+	SecretIsImmutablePolicy                          = "SecretIsImmutable"
+	SecretIsNotManagedPolicy                         = "SecretIsNotManaged"
+	SecretHasWrongTypePolicy                         = "SecretHasWrongType"
+	SecretIsMissingDataPolicy                        = "SecretIsMissingData"
+	SecretHasMultipleLeafCertificatesPolicy          = "SecretHasMultipleLeafCertificates"
+	SecretPublicKeysDifferPolicy                     = "SecretPublicKeysDiffer"
+	SecretChainDoesNotVerifyPolicy                   = "SecretChainDoesNotVerify"
+	SecretAdditionalOutputFormatsMismatchPolicy      = "SecretAdditionalOutputFormatsMismatch"
+	SecretPrivateKeyBelowMinimumSizePolicy           = "SecretPrivateKeyBelowMinimumSize"
+	SecretPrivateKeyMatchesSpecPolicy                = "SecretPrivateKeyMatchesSpec"
+	SecretPrivateKeyReusedPolicy                     = "SecretPrivateKeyReused"
+	SecretIssuerAnnotationsNotUpToDatePolicy         = "SecretIssuerAnnotationsNotUpToDate"
+	SecretIssuerProfileNotUpToDatePolicy             = "SecretIssuerProfileNotUpToDate"
+	SecretCertificateHasDeniedUsagesPolicy           = "SecretCertificateHasDeniedUsages"
+	SecretAuthorityKeyIDMismatchPolicy               = "SecretAuthorityKeyIDMismatch"
+	SecretCertificateOutlivesIssuerCAPolicy          = "SecretCertificateOutlivesIssuerCA"
+	SecretIssuedByRevokedIntermediatePolicy          = "SecretIssuedByRevokedIntermediate"
+	SecretRevisionInvalidPolicy                      = "SecretRevisionInvalid"
+	CurrentCertificateRequestPublicKeyMismatchPolicy = "CurrentCertificateRequestPublicKeyMismatch"
+	SecretCertificateMismatchesCurrentRequestPolicy  = "SecretCertificateMismatchesCurrentRequest"
+	CurrentCertificateRequestNotValidForSpecPolicy   = "CurrentCertificateRequestNotValidForSpec"
+	SecretOCSPMustStapleMismatchPolicy               = "SecretOCSPMustStapleMismatch"
+	SecretEmbeddedSCTsMismatchPolicy                 = "SecretEmbeddedSCTsMismatch"
+	SecretCertificatePoliciesMissingPolicy           = "SecretCertificatePoliciesMissing"
+	SecretCertificateMissingSANsPolicy               = "SecretCertificateMissingSANs"
+	SecretCertificateHasUnexpectedCommonNamePolicy   = "SecretCertificateHasUnexpectedCommonName"
+	SecretDurationExceedsSpecPolicy                  = "SecretDurationExceedsSpec"
+	SecretIssuedBeforeCutoffPolicy                   = "SecretIssuedBeforeCutoff"
+	CurrentCertificateOutsideValidityWindowPolicy    = "CurrentCertificateOutsideValidityWindow"
+	CurrentCertificateNearingExpiryPolicy            = "CurrentCertificateNearingExpiry"
+	CurrentCertificateExpiresBeforeResyncPolicy      = "CurrentCertificateExpiresBeforeResync"
+	SecretIsMissingRevisionPolicy                    = "SecretIsMissingRevision"
+	SecretSerialNumberDuplicatedPolicy               = "SecretSerialNumberDuplicated"
+)
+
+// Names of the policies that are only included in the readiness and post
+// issuance policy chains.
+const (
+	CurrentCertificateHasExpiredPolicy                = "CurrentCertificateHasExpired"
+	CurrentCertificateHasInvalidUsagesPolicy          = "CurrentCertificateHasInvalidUsages"
+	SecretTemplateMismatchesSecretPolicy              = "SecretTemplateMismatchesSecret"
+	SecretTemplateMismatchesSecretManagedFieldsPolicy = "SecretTemplateMismatchesSecretManagedFields"
+	SecretKeystorePasswordMismatchPolicy              = "SecretKeystorePasswordMismatch"
+)
+
+// GuardPolicyNames returns the names of the policies in the trigger policy
+// chain that the policies after them rely on, e.g. to not have to handle a
+// missing Secret. They are always included in the chain and cannot be
+// disabled.
+func GuardPolicyNames() sets.String {
+	return sets.NewString(
+		SecretDoesNotExistPolicy,
+		SecretIsMissingDataPolicy,
+	)
+}
+
+// TriggerPolicyNames returns the names of all policies that may be included
+// in the trigger policy chain and can be disabled. The policies returned by
+// GuardPolicyNames are not included.
+func TriggerPolicyNames() sets.String {
+	return sets.NewString(
+		IssuerDoesNotExistPolicy,
+		DurationBelowMinimumPolicy,
+		SecretIsTerminatingPolicy,
+		SecretIsImmutablePolicy,
+		SecretIsNotManagedPolicy,
+		SecretHasWrongTypePolicy,
+		SecretHasMultipleLeafCertificatesPolicy,
+		SecretPublicKeysDifferPolicy,
+		SecretChainDoesNotVerifyPolicy,
+		SecretAdditionalOutputFormatsMismatchPolicy,
+		SecretPrivateKeyBelowMinimumSizePolicy,
+		SecretPrivateKeyMatchesSpecPolicy,
+		SecretPrivateKeyReusedPolicy,
+		SecretIssuerAnnotationsNotUpToDatePolicy,
+		SecretIssuerProfileNotUpToDatePolicy,
+		SecretCertificateHasDeniedUsagesPolicy,
+		SecretAuthorityKeyIDMismatchPolicy,
+		SecretCertificateOutlivesIssuerCAPolicy,
+		SecretIssuedByRevokedIntermediatePolicy,
+		SecretRevisionInvalidPolicy,
+		CurrentCertificateRequestPublicKeyMismatchPolicy,
+		SecretCertificateMismatchesCurrentRequestPolicy,
+		CurrentCertificateRequestNotValidForSpecPolicy,
+		SecretOCSPMustStapleMismatchPolicy,
+		SecretEmbeddedSCTsMismatchPolicy,
+		SecretCertificatePoliciesMissingPolicy,
+		SecretCertificateMissingSANsPolicy,
+		SecretCertificateHasUnexpectedCommonNamePolicy,
+		SecretDurationExceedsSpecPolicy,
+		SecretIssuedBeforeCutoffPolicy,
+		CurrentCertificateOutsideValidityWindowPolicy,
+		CurrentCertificateNearingExpiryPolicy,
+		CurrentCertificateExpiresBeforeResyncPolicy,
+		SecretIsMissingRevisionPolicy,
+		SecretSerialNumberDuplicatedPolicy,
+	)
+}
+
+// TriggerPolicyOptions configures the policies included in the trigger
+// policy chain.
+type TriggerPolicyOptions struct {
+	// RenewalJitterWindow is the size of the window before a Certificate's
+	// renewal time within which its renewal will be triggered. A value of 0
+	// disables jitter.
+	RenewalJitterWindow time.Duration
+
+	// RenewalGraceTolerance is the amount of time after a Certificate's
+	// (jittered) renewal time that must have passed before its renewal is
+	// triggered. This avoids renewals flapping due to small amounts of clock
+	// skew between controller replicas. A value of 0 disables the tolerance.
+	RenewalGraceTolerance time.Duration
+
+	// RenewalTimeOfDay, if set, moves the time at which a Certificate's
+	// renewal is triggered to the next occurrence of this time of day in UTC,
+	// given as the time since midnight. Renewals are never moved past the
+	// notAfter of the issued certificate.
+	RenewalTimeOfDay *time.Duration
+
+	// ResyncPeriod is the period at which Certificates are resynced by the
+	// controller's informers. It is used by the
+	// CurrentCertificateExpiresBeforeResync policy.
+	ResyncPeriod time.Duration
+
+	// ResyncExpiryMargin enables the CurrentCertificateExpiresBeforeResync
+	// policy when greater than 0. Certificates that would expire within
+	// ResyncPeriod plus this margin are renewed straight away.
+	ResyncExpiryMargin time.Duration
+
+	// DurationTolerance enables the SecretDurationExceedsSpec policy when
+	// greater than 0. Certificates whose stored certificate is valid for
+	// longer than spec.duration plus this tolerance are reissued.
+	DurationTolerance time.Duration
+
+	// MinimumDuration enables the DurationBelowMinimum policy when greater
+	// than 0. Certificates whose spec.duration is shorter than this are not
+	// issued, as their issuer would reject them.
+	MinimumDuration time.Duration
+
+	// IssuedBeforeCutoff enables the SecretIssuedBeforeCutoff policy when
+	// not zero. Certificates whose stored certificate has a notBefore
+	// earlier than this time are reissued.
+	IssuedBeforeCutoff time.Time
+
+	// ProtectUnmanagedSecrets enables the SecretIsNotManaged policy, which
+	// blocks issuance into existing Secrets that were not created by
+	// cert-manager.
+	ProtectUnmanagedSecrets bool
+
+	// DetectPrivateKeyReuse enables the SecretPrivateKeyReused policy.
+	DetectPrivateKeyReuse bool
+
+	// CompareIssuerProfile enables the SecretIssuerProfileNotUpToDate policy.
+	CompareIssuerProfile bool
+
+	// CheckDeniedUsages enables the SecretCertificateHasDeniedUsages policy.
+	CheckDeniedUsages bool
+
+	// CheckMissingRevision enables the SecretIsMissingRevision policy.
+	CheckMissingRevision bool
+
+	// CheckMultipleLeafCertificates enables the
+	// SecretHasMultipleLeafCertificates policy.
+	CheckMultipleLeafCertificates bool
+
+	// CheckChainVerifies enables the SecretChainDoesNotVerify policy.
+	CheckChainVerifies bool
+
+	// CheckMissingSANs enables the SecretCertificateMissingSANs policy.
+	CheckMissingSANs bool
+
+	// CheckUnexpectedCommonName enables the
+	// SecretCertificateHasUnexpectedCommonName policy.
+	CheckUnexpectedCommonName bool
+
+	// MinimumRSAKeySize enables the SecretPrivateKeyBelowMinimumSize policy
+	// when greater than 0. Certificates whose stored RSA private key is
+	// smaller than this number of bits are reissued. The Certificate passed
+	// to the chain should have its key size raised using
+	// certificates.ApplyMinimumRSAKeySize, so that the larger key is not
+	// reported as not matching the spec.
+	MinimumRSAKeySize int
+
+	// IssuerCA enables the SecretAuthorityKeyIDMismatch policy when set. It
+	// returns the CA certificate used by an issuer, whose subject key ID is
+	// compared to the authority key ID of the issued certificate.
+	IssuerCA IssuerCAFunc
+
+	// IssuerCAExpiry enables the SecretCertificateOutlivesIssuerCA policy
+	// when set. It returns the CA certificate used by an issuer, whose
+	// notAfter is compared to the notAfter of the issued certificate.
+	IssuerCAExpiry IssuerCAFunc
+
+	// RevokedIntermediates enables the SecretIssuedByRevokedIntermediate
+	// policy when set. It returns the fingerprints of revoked intermediate
+	// CA certificates.
+	RevokedIntermediates RevokedIntermediatesFunc
+
+	// CheckOutputFormats enables the SecretAdditionalOutputFormatsMismatch
+	// policy. It should only be set when the AdditionalCertificateOutputFormats
+	// feature is enabled, as the additional output formats are otherwise never
+	// written to the Secret.
+	CheckOutputFormats bool
+
+	// SerialIndex enables the SecretSerialNumberDuplicated policy when set.
+	// The policy only looks serial numbers up in it, so the caller must record
+	// the serial numbers of issued certificates with SerialIndex.Record.
+	SerialIndex *SerialIndex
+
+	// DisabledPolicies holds the names of policies that are left out of the
+	// chain, even if they are otherwise enabled. The policies returned by
+	// GuardPolicyNames cannot be disabled and are ignored.
+	DisabledPolicies sets.String
+}
+
 // NewTriggerPolicyChain includes trigger policy checks, which if return true,
 // should cause a Certificate to be marked for issuance.
-func NewTriggerPolicyChain(c clock.Clock) Chain {
-	return Chain{
-		SecretDoesNotExist,
-		SecretIsMissingData,
-		SecretPublicKeysDiffer,
-		SecretPrivateKeyMatchesSpec,
-		SecretIssuerAnnotationsNotUpToDate,
-		CurrentCertificateRequestNotValidForSpec,
-		CurrentCertificateNearingExpiry(c),
+//
+// These reasons block issuance rather than trigger it:
+//  - IssuerNotFound: the issuer does not exist.
+//  - SecretTerminating: the Secret is being deleted.
+//  - SecretImmutable: the Secret is immutable.
+//  - SecretNotManaged: the Secret was not created by cert-manager.
+//  - DuplicateSerial: the issuing CA appears to be broken.
+//  - DurationTooShort: the issuer would reject spec.duration.
+//
+// These policies are opt-in, enabled by the given option:
+//  - DurationBelowMinimum: MinimumDuration.
+//  - SecretIsNotManaged: ProtectUnmanagedSecrets.
+//  - SecretHasMultipleLeafCertificates: CheckMultipleLeafCertificates.
+//  - SecretChainDoesNotVerify: CheckChainVerifies.
+//  - SecretAdditionalOutputFormatsMismatch: CheckOutputFormats.
+//  - SecretPrivateKeyBelowMinimumSize: MinimumRSAKeySize.
+//  - SecretPrivateKeyReused: DetectPrivateKeyReuse.
+//  - SecretIssuerProfileNotUpToDate: CompareIssuerProfile.
+//  - SecretCertificateHasDeniedUsages: CheckDeniedUsages.
+//  - SecretAuthorityKeyIDMismatch: IssuerCA.
+//  - SecretCertificateOutlivesIssuerCA: IssuerCAExpiry.
+//  - SecretIssuedByRevokedIntermediate: RevokedIntermediates.
+//  - SecretCertificateMissingSANs: CheckMissingSANs.
+//  - SecretCertificateHasUnexpectedCommonName: CheckUnexpectedCommonName.
+//  - SecretDurationExceedsSpec: DurationTolerance.
+//  - SecretIssuedBeforeCutoff: IssuedBeforeCutoff.
+//  - CurrentCertificateExpiresBeforeResync: ResyncExpiryMargin.
+//  - SecretIsMissingRevision: CheckMissingRevision.
+//  - SecretSerialNumberDuplicated: SerialIndex.
+//
+// The key pair and x509 spec checks are skipped for externally issued
+// Certificates, see UnlessExternallyIssued. Policies in opts.DisabledPolicies
+// are skipped, except for the guards returned by GuardPolicyNames.
+func NewTriggerPolicyChain(c clock.Clock, helper issuer.Helper, opts TriggerPolicyOptions) Chain {
+	guards := GuardPolicyNames()
+	var chain Chain
+	add := func(name string, policy Func) {
+		if guards.Has(name) || !opts.DisabledPolicies.Has(name) {
+			chain = append(chain, Policy{Name: name, Func: policy})
+		}
+	}
+
+	add(IssuerDoesNotExistPolicy, IssuerDoesNotExist(helper))
+	if opts.MinimumDuration > 0 {
+		add(DurationBelowMinimumPolicy, DurationBelowMinimum(opts))
+	}
+	add(SecretDoesNotExistPolicy, SecretDoesNotExist)
+	add(SecretIsTerminatingPolicy, SecretIsTerminating)
+	add(SecretIsImmutablePolicy, SecretIsImmutable)
+	if opts.ProtectUnmanagedSecrets {
+		add(SecretIsNotManagedPolicy, SecretIsNotManaged)
+	}
+	add(SecretHasWrongTypePolicy, SecretHasWrongType)
+	add(SecretIsMissingDataPolicy, SecretIsMissingData)
+	if opts.CheckMultipleLeafCertificates {
+		add(SecretHasMultipleLeafCertificatesPolicy, SecretHasMultipleLeafCertificates)
+	}
+	add(SecretPublicKeysDifferPolicy, UnlessExternallyIssued(SecretPublicKeysDiffer))
+	if opts.CheckChainVerifies {
+		add(SecretChainDoesNotVerifyPolicy, SecretChainDoesNotVerify)
+	}
+	if opts.CheckOutputFormats {
+		add(SecretAdditionalOutputFormatsMismatchPolicy, SecretAdditionalOutputFormatsMismatch)
+	}
+	if opts.MinimumRSAKeySize > 0 {
+		add(SecretPrivateKeyBelowMinimumSizePolicy, SecretPrivateKeyBelowMinimumSize(opts))
+	}
+	add(SecretPrivateKeyMatchesSpecPolicy, UnlessExternallyIssued(SecretPrivateKeyMatchesSpec))
+	if opts.DetectPrivateKeyReuse {
+		add(SecretPrivateKeyReusedPolicy, SecretPrivateKeyReused)
+	}
+	add(SecretIssuerAnnotationsNotUpToDatePolicy, SecretIssuerAnnotationsNotUpToDate)
+	if opts.CompareIssuerProfile {
+		add(SecretIssuerProfileNotUpToDatePolicy, SecretIssuerProfileNotUpToDate(helper))
+	}
+	if opts.CheckDeniedUsages {
+		add(SecretCertificateHasDeniedUsagesPolicy, SecretCertificateHasDeniedUsages(helper))
 	}
+	if opts.IssuerCA != nil {
+		add(SecretAuthorityKeyIDMismatchPolicy, SecretAuthorityKeyIDMismatch(helper, opts.IssuerCA))
+	}
+	if opts.IssuerCAExpiry != nil {
+		add(SecretCertificateOutlivesIssuerCAPolicy, SecretCertificateOutlivesIssuerCA(helper, opts.IssuerCAExpiry))
+	}
+	if opts.RevokedIntermediates != nil {
+		add(SecretIssuedByRevokedIntermediatePolicy, SecretIssuedByRevokedIntermediate(opts.RevokedIntermediates))
+	}
+	add(SecretRevisionInvalidPolicy, SecretRevisionInvalid)
+	add(CurrentCertificateRequestPublicKeyMismatchPolicy, UnlessExternallyIssued(CurrentCertificateRequestPublicKeyMismatch))
+	add(SecretCertificateMismatchesCurrentRequestPolicy, UnlessExternallyIssued(SecretCertificateMismatchesCurrentRequest))
+	add(CurrentCertificateRequestNotValidForSpecPolicy, UnlessExternallyIssued(CurrentCertificateRequestNotValidForSpec))
+	add(SecretOCSPMustStapleMismatchPolicy, SecretOCSPMustStapleMismatch)
+	add(SecretEmbeddedSCTsMismatchPolicy, SecretEmbeddedSCTsMismatch)
+	add(SecretCertificatePoliciesMissingPolicy, SecretCertificatePoliciesMissing)
+	if opts.CheckMissingSANs {
+		add(SecretCertificateMissingSANsPolicy, SecretCertificateMissingSANs)
+	}
+	if opts.CheckUnexpectedCommonName {
+		add(SecretCertificateHasUnexpectedCommonNamePolicy, SecretCertificateHasUnexpectedCommonName)
+	}
+	if opts.DurationTolerance > 0 {
+		add(SecretDurationExceedsSpecPolicy, SecretDurationExceedsSpec(opts))
+	}
+	if !opts.IssuedBeforeCutoff.IsZero() {
+		add(SecretIssuedBeforeCutoffPolicy, SecretIssuedBeforeCutoff(opts))
+	}
+	add(CurrentCertificateOutsideValidityWindowPolicy, CurrentCertificateOutsideValidityWindow(c))
+	add(CurrentCertificateNearingExpiryPolicy, CurrentCertificateNearingExpiry(c, opts))
+	if opts.ResyncExpiryMargin > 0 {
+		add(CurrentCertificateExpiresBeforeResyncPolicy, CurrentCertificateExpiresBeforeResync(c, opts))
+	}
+	if opts.CheckMissingRevision {
+		add(SecretIsMissingRevisionPolicy, SecretIsMissingRevision)
+	}
+	// Duplicate serial numbers are only reported once no other policy
+	// requires the Certificate to be reissued, so that they do not prevent
+	// renewals.
+	if opts.SerialIndex != nil {
+		add(SecretSerialNumberDuplicatedPolicy, SecretSerialNumberDuplicated(opts.SerialIndex))
+	}
+	return chain
+}
+
+// ReadinessPolicyOptions configures the policies included in the readiness
+// policy chain.
+type ReadinessPolicyOptions struct {
+	// CheckUsages enables the CurrentCertificateHasInvalidUsages policy.
+	CheckUsages bool
 }
 
 // NewReadinessPolicyChain includes readiness policy checks, which if return
 // true, would cause a Certificate to be marked as not ready.
-func NewReadinessPolicyChain(c clock.Clock) Chain {
-	return Chain{
-		SecretDoesNotExist,
-		SecretIsMissingData,
-		SecretPublicKeysDiffer,
-		CurrentCertificateRequestNotValidForSpec,
-		CurrentCertificateHasExpired(c),
+func NewReadinessPolicyChain(c clock.Clock, opts ReadinessPolicyOptions) Chain {
+	chain := Chain{
+		{Name: SecretDoesNotExistPolicy, Func: SecretDoesNotExist},
+		{Name: SecretHasWrongTypePolicy, Func: SecretHasWrongType},
+		{Name: SecretIsMissingDataPolicy, Func: SecretIsMissingData},
+		{Name: SecretPublicKeysDifferPolicy, Func: SecretPublicKeysDiffer},
+		{Name: CurrentCertificateRequestNotValidForSpecPolicy, Func: CurrentCertificateRequestNotValidForSpec},
+		{Name: CurrentCertificateHasExpiredPolicy, Func: CurrentCertificateHasExpired(c)},
 	}
+	if opts.CheckUsages {
+		chain = append(chain, Policy{Name: CurrentCertificateHasInvalidUsagesPolicy, Func: CurrentCertificateHasInvalidUsages})
+	}
+	return chain
+}
+
+// PostIssuancePolicyOptions configures the policies included in the post
+// issuance policy chain.
+type PostIssuancePolicyOptions struct {
+	// IgnoreManagedFieldsParseErrors causes managed fields entries on the
+	// Secret that cannot be decoded to be treated as empty, rather than
+	// reported as a ManagedFieldsParseError violation. This prevents a single
+	// corrupt entry from stopping the Secret from being reconciled.
+	IgnoreManagedFieldsParseErrors bool
+
+	// SecretUpdateStrategy is the strategy used by the controller to write
+	// Secrets. The managed fields of Secrets are only checked when Secrets
+	// are applied, as they do not record the fields owned by cert-manager
+	// when Secrets are written using the Update strategy.
+	SecretUpdateStrategy internalcertificates.SecretUpdateStrategy
 }
 
 // NewSecretPostIssuancePolicyChain includes policy checks that are to be
 // performed _after_ issuance has been successful, testing for the presence and
 // correctness of metadata and output formats of Certificate's Secrets.
-func NewSecretPostIssuancePolicyChain(fieldManager string) Chain {
+func NewSecretPostIssuancePolicyChain(fieldManager string, opts PostIssuancePolicyOptions) Chain {
 	return Chain{
-		SecretTemplateMismatchesSecret,
-		SecretTemplateMismatchesSecretManagedFields(fieldManager),
+		{Name: SecretTemplateMismatchesSecretPolicy, Func: SecretTemplateMismatchesSecret},
+		{Name: SecretTemplateMismatchesSecretManagedFieldsPolicy, Func: SecretTemplateMismatchesSecretManagedFields(fieldManager, opts)},
+		{Name: SecretKeystorePasswordMismatchPolicy, Func: SecretKeystorePasswordMismatch},
 	}
 }
 
@@ -103,8 +508,8 @@ func NewSecretPostIssuancePolicyChain(fieldManager string) Chain {
 // temporary certificate is valid.
 func NewTemporaryCertificatePolicyChain() Chain {
 	return Chain{
-		SecretDoesNotExist,
-		SecretIsMissingData,
-		SecretPublicKeysDiffer,
+		{Name: SecretDoesNotExistPolicy, Func: SecretDoesNotExist},
+		{Name: SecretIsMissingDataPolicy, Func: SecretIsMissingData},
+		{Name: SecretPublicKeysDifferPolicy, Func: SecretPublicKeysDiffer},
 	}
 }
@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"crypto/x509"
+	"hash/fnv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/cert-manager/pkg/controller/certificates"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+)
+
+// RenewBeforeSource describes where the effective renewBefore duration used
+// to compute a Certificate's renewal time was derived from.
+type RenewBeforeSource string
+
+const (
+	// RenewBeforeSourceDefault is used when the effective renewBefore is the
+	// default of 1/3 of the certificate's actual duration, i.e. the
+	// certificate is renewed 2/3 of the way through its lifetime.
+	RenewBeforeSourceDefault RenewBeforeSource = "Default"
+
+	// RenewBeforeSourceSpec is used when the effective renewBefore is taken
+	// from the Certificate's spec.renewBefore field.
+	RenewBeforeSourceSpec RenewBeforeSource = "RenewBefore"
+
+	// RenewBeforeSourcePercentage is used when the effective renewBefore is
+	// derived from the Certificate's spec.renewBeforePercentage field.
+	RenewBeforeSourcePercentage RenewBeforeSource = "RenewBeforePercentage"
+)
+
+// RenewalTimeExplanation holds the inputs used to compute the renewal time of
+// a Certificate, as well as the computed renewal time itself. It is intended
+// to answer the question "why is renewalTime set to X?".
+type RenewalTimeExplanation struct {
+	// NotBefore is the notBefore time of the issued certificate.
+	NotBefore time.Time
+
+	// NotAfter is the notAfter time of the issued certificate.
+	NotAfter time.Time
+
+	// Duration is the actual duration of the issued certificate, which may
+	// differ from the requested spec.duration.
+	Duration time.Duration
+
+	// RenewBefore is the effective amount of time before NotAfter at which
+	// the certificate will be renewed.
+	RenewBefore time.Duration
+
+	// RenewBeforeSource describes how RenewBefore was derived.
+	RenewBeforeSource RenewBeforeSource
+
+	// RenewalTime is the computed time at which the certificate will be
+	// renewed.
+	RenewalTime time.Time
+}
+
+// ExplainRenewalTime returns the inputs used to compute the renewal time of
+// the given Certificate with the given issued X.509 certificate, along with
+// the computed renewal time. The renewal time is computed in the same way as
+// it is by the CurrentCertificateNearingExpiry policy.
+func ExplainRenewalTime(crt *cmapi.Certificate, x509cert *x509.Certificate) RenewalTimeExplanation {
+	duration := x509cert.NotAfter.Sub(x509cert.NotBefore)
+	renewBefore := certificates.RenewBefore(duration, crt.Spec.RenewBefore, crt.Spec.RenewBeforePercentage)
+
+	// This mirrors the precedence used by certificates.RenewBefore:
+	// spec.renewBefore is always preferred over spec.renewBeforePercentage.
+	source := RenewBeforeSourceDefault
+	pct := crt.Spec.RenewBeforePercentage
+	switch {
+	case crt.Spec.RenewBefore != nil:
+		if crt.Spec.RenewBefore.Duration < duration {
+			source = RenewBeforeSourceSpec
+		}
+	case pct != nil && *pct > 0 && *pct < 100:
+		source = RenewBeforeSourcePercentage
+	}
+
+	return RenewalTimeExplanation{
+		NotBefore:         x509cert.NotBefore,
+		NotAfter:          x509cert.NotAfter,
+		Duration:          duration,
+		RenewBefore:       renewBefore,
+		RenewBeforeSource: source,
+		RenewalTime:       certificates.RenewalTime(x509cert.NotBefore, x509cert.NotAfter, crt.Spec.RenewBefore, crt.Spec.RenewBeforePercentage).Time,
+	}
+}
+
+// RenewalJitter returns a deterministic amount of time in the range
+// [0, window) by which the renewal of the given Certificate should be brought
+// forward. The jitter is derived from the Certificate's UID so that it is
+// stable across evaluations, but differs between Certificates that would
+// otherwise all be renewed at the same time.
+func RenewalJitter(crt *cmapi.Certificate, window time.Duration) time.Duration {
+	if window <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	// Writing to a hash.Hash never returns an error.
+	_, _ = h.Write([]byte(crt.UID))
+	return time.Duration(h.Sum64() % uint64(window))
+}
+
+// JitteredRenewalTime returns the given renewal time brought forward by the
+// Certificate's RenewalJitter for the given window. The jitter is limited to
+// half of the time between notBefore and renewalTime so that a newly issued
+// certificate is never renewed straight away. Like the renewal time itself,
+// the result is truncated to the nearest second.
+func JitteredRenewalTime(crt *cmapi.Certificate, notBefore, renewalTime time.Time, window time.Duration) time.Time {
+	jitter := RenewalJitter(crt, window)
+	if maxJitter := renewalTime.Sub(notBefore) / 2; jitter > maxJitter {
+		jitter = maxJitter
+	}
+	if jitter <= 0 {
+		return renewalTime
+	}
+	return renewalTime.Add(-jitter).Truncate(time.Second)
+}
+
+// AnchoredRenewalTime returns the first time at or after the given renewal
+// time whose time of day in UTC is timeOfDay, e.g. 2h for 02:00 UTC, so that
+// renewals happen at a predictable time of day. If that time is not before
+// notAfter, the renewal time is returned unchanged, as delaying the renewal
+// any further would let the certificate expire.
+func AnchoredRenewalTime(renewalTime, notAfter time.Time, timeOfDay time.Duration) time.Time {
+	utc := renewalTime.UTC()
+	anchored := time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC).Add(timeOfDay)
+	if anchored.Before(utc) {
+		anchored = anchored.AddDate(0, 0, 1)
+	}
+	if !anchored.Before(notAfter) {
+		return renewalTime
+	}
+	return anchored
+}
+
+// RenewalTriggerTime returns the time at which the CurrentCertificateNearingExpiry
+// policy triggers renewal of the given Certificate, given the validity and
+// renewal time of its issued certificate. The renewal time is brought forward
+// by the Certificate's jitter, moved to the next occurrence of
+// opts.RenewalTimeOfDay if set, and then delayed by the grace tolerance.
+func RenewalTriggerTime(crt *cmapi.Certificate, notBefore, notAfter, renewalTime time.Time, opts TriggerPolicyOptions) time.Time {
+	triggerTime := JitteredRenewalTime(crt, notBefore, renewalTime, opts.RenewalJitterWindow)
+	if opts.RenewalTimeOfDay != nil {
+		triggerTime = AnchoredRenewalTime(triggerTime, notAfter, *opts.RenewalTimeOfDay)
+	}
+	return triggerTime.Add(opts.RenewalGraceTolerance)
+}
+
+// NextEvaluation returns how long until the result of evaluating the given
+// trigger policy chain for the input may next change due to the passage of
+// time, so that callers can schedule the next evaluation precisely rather
+// than polling. The chain must have been built with the given opts.
+// If the chain is violated, zero is returned as action is needed now.
+// Otherwise, the earliest of the following times that is still in the future
+// is used:
+//   - the time at which renewal of the stored certificate is triggered by the
+//     CurrentCertificateNearingExpiry policy, see RenewalTriggerTime;
+//   - the time from which the stored certificate would expire before the
+//     next resync, if opts.ResyncExpiryMargin is set;
+//   - the time at which the stored certificate expires.
+//
+// The returned bool is false if none of these times are in the future, or
+// the stored certificate cannot be decoded, in which case the evaluation
+// result is not expected to change with time.
+func NextEvaluation(c clock.Clock, chain Chain, input Input, opts TriggerPolicyOptions) (time.Duration, bool) {
+	if _, _, violated := chain.Evaluate(input); violated {
+		return 0, true
+	}
+	if input.Secret == nil {
+		return 0, false
+	}
+	x509cert, err := pki.DecodeX509CertificateBytes(input.Secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return 0, false
+	}
+
+	renewalTime := ExplainRenewalTime(input.Certificate, x509cert).RenewalTime
+	candidates := []time.Time{
+		RenewalTriggerTime(input.Certificate, x509cert.NotBefore, x509cert.NotAfter, renewalTime, opts),
+		x509cert.NotAfter,
+	}
+	if opts.ResyncExpiryMargin > 0 {
+		window := opts.ResyncPeriod + opts.ResyncExpiryMargin
+		if x509cert.NotAfter.Sub(x509cert.NotBefore) > window {
+			candidates = append(candidates, x509cert.NotAfter.Add(-window))
+		}
+	}
+
+	now := c.Now()
+	var next time.Duration
+	found := false
+	for _, candidate := range candidates {
+		d := candidate.Sub(now)
+		if d > 0 && (!found || d < next) {
+			next, found = d, true
+		}
+	}
+	return next, found
+}
@@ -0,0 +1,355 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	testcrypto "github.com/cert-manager/cert-manager/test/unit/crypto"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+)
+
+func Test_ExplainRenewalTime(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tests := map[string]struct {
+		spec      cmapi.CertificateSpec
+		notBefore time.Time
+		notAfter  time.Time
+
+		expected RenewalTimeExplanation
+	}{
+		"spec.renewBefore is not set, renew 2/3 through the certificate's lifetime": {
+			notBefore: now,
+			notAfter:  now.Add(time.Hour * 3),
+			expected: RenewalTimeExplanation{
+				NotBefore:         now,
+				NotAfter:          now.Add(time.Hour * 3),
+				Duration:          time.Hour * 3,
+				RenewBefore:       time.Hour,
+				RenewBeforeSource: RenewBeforeSourceDefault,
+				RenewalTime:       now.Add(time.Hour * 2),
+			},
+		},
+		"spec.renewBefore is set, renew renewBefore before expiry": {
+			spec:      cmapi.CertificateSpec{RenewBefore: &metav1.Duration{Duration: time.Hour * 20}},
+			notBefore: now,
+			notAfter:  now.Add(time.Hour * 24),
+			expected: RenewalTimeExplanation{
+				NotBefore:         now,
+				NotAfter:          now.Add(time.Hour * 24),
+				Duration:          time.Hour * 24,
+				RenewBefore:       time.Hour * 20,
+				RenewBeforeSource: RenewBeforeSourceSpec,
+				RenewalTime:       now.Add(time.Hour * 4),
+			},
+		},
+		"spec.renewBefore is longer than the certificate's duration, fall back to the default": {
+			spec:      cmapi.CertificateSpec{RenewBefore: &metav1.Duration{Duration: time.Hour * 25}},
+			notBefore: now,
+			notAfter:  now.Add(time.Hour * 24),
+			expected: RenewalTimeExplanation{
+				NotBefore:         now,
+				NotAfter:          now.Add(time.Hour * 24),
+				Duration:          time.Hour * 24,
+				RenewBefore:       time.Hour * 8,
+				RenewBeforeSource: RenewBeforeSourceDefault,
+				RenewalTime:       now.Add(time.Hour * 16),
+			},
+		},
+		"spec.renewBeforePercentage is set, renew that percentage of the duration before expiry": {
+			spec:      cmapi.CertificateSpec{RenewBeforePercentage: pointer.Int32(25)},
+			notBefore: now,
+			notAfter:  now.Add(time.Hour * 24),
+			expected: RenewalTimeExplanation{
+				NotBefore:         now,
+				NotAfter:          now.Add(time.Hour * 24),
+				Duration:          time.Hour * 24,
+				RenewBefore:       time.Hour * 6,
+				RenewBeforeSource: RenewBeforeSourcePercentage,
+				RenewalTime:       now.Add(time.Hour * 18),
+			},
+		},
+		"both spec.renewBefore and spec.renewBeforePercentage are set, spec.renewBefore takes precedence": {
+			spec: cmapi.CertificateSpec{
+				RenewBefore:           &metav1.Duration{Duration: time.Hour * 20},
+				RenewBeforePercentage: pointer.Int32(25),
+			},
+			notBefore: now,
+			notAfter:  now.Add(time.Hour * 24),
+			expected: RenewalTimeExplanation{
+				NotBefore:         now,
+				NotAfter:          now.Add(time.Hour * 24),
+				Duration:          time.Hour * 24,
+				RenewBefore:       time.Hour * 20,
+				RenewBeforeSource: RenewBeforeSourceSpec,
+				RenewalTime:       now.Add(time.Hour * 4),
+			},
+		},
+		"renewal time is truncated to the nearest second": {
+			notBefore: now,
+			notAfter:  now.Add(time.Hour * 24).Add(time.Second * -1),
+			expected: RenewalTimeExplanation{
+				NotBefore:         now,
+				NotAfter:          now.Add(time.Hour * 24).Add(time.Second * -1),
+				Duration:          time.Hour*24 - time.Second,
+				RenewBefore:       (time.Hour*24 - time.Second) / 3,
+				RenewBeforeSource: RenewBeforeSourceDefault,
+				RenewalTime:       now.Add(time.Hour * 16).Add(time.Second * -1),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			crt := &cmapi.Certificate{Spec: test.spec}
+			x509cert := &x509.Certificate{NotBefore: test.notBefore, NotAfter: test.notAfter}
+			assert.Equal(t, test.expected, ExplainRenewalTime(crt, x509cert))
+		})
+	}
+}
+
+func Test_JitteredRenewalTime(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	crtA := &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{UID: types.UID("uid-a")}}
+	crtB := &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{UID: types.UID("uid-b")}}
+
+	tests := map[string]struct {
+		crt         *cmapi.Certificate
+		notBefore   time.Time
+		renewalTime time.Time
+		window      time.Duration
+
+		expected time.Time
+	}{
+		"no jitter is applied if the window is zero": {
+			crt:         crtA,
+			notBefore:   now,
+			renewalTime: now.Add(time.Hour * 16),
+			expected:    now.Add(time.Hour * 16),
+		},
+		"renewal time is brought forward by the jitter derived from the UID": {
+			crt:         crtA,
+			notBefore:   now,
+			renewalTime: now.Add(time.Hour * 16),
+			window:      time.Hour,
+			expected:    now.Add(time.Hour*16 - (time.Minute*25 + time.Second*28)),
+		},
+		"a different UID results in a different renewal time": {
+			crt:         crtB,
+			notBefore:   now,
+			renewalTime: now.Add(time.Hour * 16),
+			window:      time.Hour,
+			expected:    now.Add(time.Hour*16 - (time.Minute*43 + time.Second*48)),
+		},
+		"jitter is limited to half of the time between notBefore and the renewal time": {
+			crt:         crtB,
+			notBefore:   now,
+			renewalTime: now.Add(time.Minute * 30),
+			window:      time.Hour,
+			expected:    now.Add(time.Minute * 15),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, JitteredRenewalTime(test.crt, test.notBefore, test.renewalTime, test.window))
+		})
+	}
+}
+
+func Test_AnchoredRenewalTime(t *testing.T) {
+	renewalTime := time.Date(2022, time.June, 1, 14, 30, 0, 0, time.UTC)
+	twoAM := 2 * time.Hour
+
+	tests := map[string]struct {
+		renewalTime time.Time
+		notAfter    time.Time
+		timeOfDay   time.Duration
+
+		expected time.Time
+	}{
+		"renewal time snaps to the time of day on the next day if it has passed": {
+			renewalTime: renewalTime,
+			notAfter:    renewalTime.Add(30 * 24 * time.Hour),
+			timeOfDay:   twoAM,
+			expected:    time.Date(2022, time.June, 2, 2, 0, 0, 0, time.UTC),
+		},
+		"renewal time snaps to the time of day on the same day if it has not passed": {
+			renewalTime: renewalTime,
+			notAfter:    renewalTime.Add(30 * 24 * time.Hour),
+			timeOfDay:   22*time.Hour + 15*time.Minute,
+			expected:    time.Date(2022, time.June, 1, 22, 15, 0, 0, time.UTC),
+		},
+		"renewal time is unchanged if it is at the time of day": {
+			renewalTime: time.Date(2022, time.June, 1, 2, 0, 0, 0, time.UTC),
+			notAfter:    renewalTime.Add(30 * 24 * time.Hour),
+			timeOfDay:   twoAM,
+			expected:    time.Date(2022, time.June, 1, 2, 0, 0, 0, time.UTC),
+		},
+		"renewal time in another time zone snaps to the time of day in UTC": {
+			renewalTime: renewalTime.In(time.FixedZone("UTC+10", 10*60*60)),
+			notAfter:    renewalTime.Add(30 * 24 * time.Hour),
+			timeOfDay:   twoAM,
+			expected:    time.Date(2022, time.June, 2, 2, 0, 0, 0, time.UTC),
+		},
+		"renewal time is unchanged if the certificate expires before the time of day": {
+			renewalTime: renewalTime,
+			notAfter:    time.Date(2022, time.June, 2, 1, 0, 0, 0, time.UTC),
+			timeOfDay:   twoAM,
+			expected:    renewalTime,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, test.expected.Equal(AnchoredRenewalTime(test.renewalTime, test.notAfter, test.timeOfDay)))
+		})
+	}
+}
+
+func Test_RenewalTriggerTime(t *testing.T) {
+	crt := &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{UID: types.UID("uid-a")}}
+	notBefore := time.Date(2022, time.June, 1, 14, 30, 0, 0, time.UTC)
+	notAfter := notBefore.Add(90 * 24 * time.Hour)
+	renewalTime := notBefore.Add(60 * 24 * time.Hour)
+	twoAM := 2 * time.Hour
+
+	// The jitter is applied before the renewal time is anchored, and the
+	// grace tolerance after.
+	opts := TriggerPolicyOptions{
+		RenewalJitterWindow:   time.Hour,
+		RenewalGraceTolerance: time.Minute,
+		RenewalTimeOfDay:      &twoAM,
+	}
+	expected := time.Date(2022, time.August, 1, 2, 1, 0, 0, time.UTC)
+	assert.Equal(t, expected, RenewalTriggerTime(crt, notBefore, notAfter, renewalTime, opts))
+}
+
+func Test_RenewalJitter(t *testing.T) {
+	crt := &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{UID: types.UID("uid-a")}}
+	jitter := RenewalJitter(crt, time.Hour)
+	assert.True(t, jitter >= 0 && jitter < time.Hour, "jitter %s not within window", jitter)
+	assert.Equal(t, jitter, RenewalJitter(crt.DeepCopy(), time.Hour), "jitter should be deterministic")
+	assert.Equal(t, time.Duration(0), RenewalJitter(crt, 0))
+	assert.Equal(t, time.Duration(0), RenewalJitter(crt, -time.Hour))
+}
+
+func Test_NextEvaluation(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	clock := fakeclock.NewFakeClock(now)
+	pk := testcrypto.MustCreatePEMPrivateKey(t)
+	day := 24 * time.Hour
+
+	tests := map[string]struct {
+		crt       *cmapi.Certificate
+		notBefore time.Time
+		notAfter  time.Time
+		opts      TriggerPolicyOptions
+		// noPolicies evaluates an empty chain, so that the stored
+		// certificate never violates it.
+		noPolicies bool
+
+		expected   time.Duration
+		expectedOK bool
+	}{
+		"a certificate that is due for renewal now needs action straight away": {
+			crt:        gen.Certificate("test"),
+			notBefore:  now.Add(-80 * day),
+			notAfter:   now.Add(10 * day),
+			expected:   0,
+			expectedOK: true,
+		},
+		"a certificate that is due for renewal soon is evaluated again at its renewal time": {
+			crt:        gen.Certificate("test"),
+			notBefore:  now.Add(-59 * day),
+			notAfter:   now.Add(31 * day),
+			expected:   day,
+			expectedOK: true,
+		},
+		"a certificate that is due for renewal far in the future is evaluated again at its renewal time": {
+			crt:        gen.Certificate("test"),
+			notBefore:  now,
+			notAfter:   now.Add(90 * day),
+			expected:   60 * day,
+			expectedOK: true,
+		},
+		"the grace tolerance delays the next evaluation": {
+			crt:        gen.Certificate("test"),
+			notBefore:  now,
+			notAfter:   now.Add(90 * day),
+			opts:       TriggerPolicyOptions{RenewalGraceTolerance: time.Minute},
+			expected:   60*day + time.Minute,
+			expectedOK: true,
+		},
+		"a certificate that would expire before the next resync is evaluated again before its renewal time": {
+			crt:        gen.Certificate("test", gen.SetCertificateRenewBefore(time.Hour)),
+			notBefore:  now,
+			notAfter:   now.Add(2 * day),
+			opts:       TriggerPolicyOptions{ResyncPeriod: 10 * time.Hour, ResyncExpiryMargin: 2 * time.Hour},
+			expected:   36 * time.Hour,
+			expectedOK: true,
+		},
+		"a certificate is evaluated again at its expiry if renewal is not checked": {
+			crt:        gen.Certificate("test"),
+			notBefore:  now.Add(-80 * day),
+			notAfter:   now.Add(10 * day),
+			noPolicies: true,
+			expected:   10 * day,
+			expectedOK: true,
+		},
+		"no evaluation is needed for an expired certificate if renewal is not checked": {
+			crt:        gen.Certificate("test"),
+			notBefore:  now.Add(-90 * day),
+			notAfter:   now.Add(-day),
+			noPolicies: true,
+			expected:   0,
+			expectedOK: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := Input{
+				Certificate: tc.crt,
+				Secret: &corev1.Secret{Data: map[string][]byte{
+					corev1.TLSCertKey: testcrypto.MustCreateCertWithNotBeforeAfter(t, pk, gen.Certificate("test", gen.SetCertificateCommonName("example.com")), tc.notBefore, tc.notAfter),
+				}},
+			}
+			chain := Chain{
+				{Name: CurrentCertificateNearingExpiryPolicy, Func: CurrentCertificateNearingExpiry(clock, tc.opts)},
+				{Name: CurrentCertificateExpiresBeforeResyncPolicy, Func: CurrentCertificateExpiresBeforeResync(clock, tc.opts)},
+			}
+			if tc.noPolicies {
+				chain = nil
+			}
+
+			next, ok := NextEvaluation(clock, chain, input, tc.opts)
+			assert.Equal(t, tc.expected, next)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
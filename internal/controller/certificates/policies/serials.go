@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"container/list"
+	"crypto/x509"
+	"sync"
+)
+
+// DefaultSerialIndexSize is the number of serial numbers held by a
+// SerialIndex created for the trigger controller.
+const DefaultSerialIndexSize = 10000
+
+// serialKey identifies a serial number within a namespace. Serial numbers are
+// only unique per issuing CA, so the issuer's distinguished name and key
+// identifier are part of the key.
+type serialKey struct {
+	namespace      string
+	issuer         string
+	authorityKeyID string
+	serial         string
+}
+
+// certificateKey identifies a Certificate.
+type certificateKey struct {
+	namespace string
+	name      string
+}
+
+// serialEntry is an entry in a SerialIndex.
+type serialEntry struct {
+	key  serialKey
+	name string
+}
+
+// SerialIndex is an in-memory index of the serial numbers of the certificates
+// stored in the Secrets of Certificates, used to detect Certificates in the
+// same namespace whose certificates were issued by the same CA with the same
+// serial number. It holds at most one serial number per Certificate and a
+// fixed number of serial numbers in total, evicting the least recently
+// recorded ones first. It is safe for concurrent use.
+type SerialIndex struct {
+	lock       sync.Mutex
+	maxEntries int
+	// order holds *serialEntry values, most recently recorded first.
+	order   *list.List
+	entries map[serialKey]*list.Element
+	// certificates holds the entry recorded for each Certificate.
+	certificates map[certificateKey]*list.Element
+}
+
+// NewSerialIndex returns a SerialIndex that holds at most maxEntries serial
+// numbers.
+func NewSerialIndex(maxEntries int) *SerialIndex {
+	return &SerialIndex{
+		maxEntries:   maxEntries,
+		order:        list.New(),
+		entries:      make(map[serialKey]*list.Element),
+		certificates: make(map[certificateKey]*list.Element),
+	}
+}
+
+func newSerialKey(namespace string, cert *x509.Certificate) serialKey {
+	return serialKey{
+		namespace:      namespace,
+		issuer:         string(cert.RawIssuer),
+		authorityKeyID: string(cert.AuthorityKeyId),
+		serial:         cert.SerialNumber.String(),
+	}
+}
+
+// Lookup returns the name of the Certificate in the same namespace whose
+// certificate was recorded with the same issuer and serial number as the
+// given certificate, if that is a different Certificate than the named one.
+// Lookup does not modify the index.
+func (i *SerialIndex) Lookup(namespace, name string, cert *x509.Certificate) (string, bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	elem, ok := i.entries[newSerialKey(namespace, cert)]
+	if !ok {
+		return "", false
+	}
+	if existing := elem.Value.(*serialEntry).name; existing != name {
+		return existing, true
+	}
+	return "", false
+}
+
+// Record records that the certificate stored for the named Certificate is the
+// given certificate, replacing the serial number previously recorded for the
+// Certificate. If the serial number is already recorded for a different
+// Certificate, that record is kept.
+func (i *SerialIndex) Record(namespace, name string, cert *x509.Certificate) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	key := newSerialKey(namespace, cert)
+	crtKey := certificateKey{namespace: namespace, name: name}
+	if elem, ok := i.certificates[crtKey]; ok {
+		if elem.Value.(*serialEntry).key == key {
+			i.order.MoveToFront(elem)
+			return
+		}
+		i.remove(elem)
+	}
+	if _, ok := i.entries[key]; ok {
+		return
+	}
+
+	elem := i.order.PushFront(&serialEntry{key: key, name: name})
+	i.entries[key] = elem
+	i.certificates[crtKey] = elem
+	for i.order.Len() > i.maxEntries {
+		i.remove(i.order.Back())
+	}
+}
+
+// Forget removes the serial number recorded for the named Certificate, e.g.
+// because the Certificate has been deleted.
+func (i *SerialIndex) Forget(namespace, name string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if elem, ok := i.certificates[certificateKey{namespace: namespace, name: name}]; ok {
+		i.remove(elem)
+	}
+}
+
+// remove removes the given entry from the index. The lock must be held.
+func (i *SerialIndex) remove(elem *list.Element) {
+	entry := elem.Value.(*serialEntry)
+	i.order.Remove(elem)
+	delete(i.entries, entry.key)
+	delete(i.certificates, certificateKey{namespace: entry.key.namespace, name: entry.name})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policies
+
+import (
+	"crypto/x509"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerialIndex(t *testing.T) {
+	cert := func(issuer string, serial int64) *x509.Certificate {
+		return &x509.Certificate{RawIssuer: []byte(issuer), SerialNumber: big.NewInt(serial)}
+	}
+	index := NewSerialIndex(2)
+
+	_, duplicated := index.Lookup("ns", "a", cert("ca-1", 1))
+	assert.False(t, duplicated)
+	index.Record("ns", "a", cert("ca-1", 1))
+
+	other, duplicated := index.Lookup("ns", "b", cert("ca-1", 1))
+	assert.True(t, duplicated)
+	assert.Equal(t, "a", other)
+
+	// looking up the serial number for the same Certificate is fine
+	_, duplicated = index.Lookup("ns", "a", cert("ca-1", 1))
+	assert.False(t, duplicated)
+
+	// serial numbers are only compared within a namespace and issuer
+	_, duplicated = index.Lookup("other-ns", "b", cert("ca-1", 1))
+	assert.False(t, duplicated)
+	_, duplicated = index.Lookup("ns", "b", cert("ca-2", 1))
+	assert.False(t, duplicated)
+
+	// recording a new serial number for a Certificate drops its previous one
+	index.Record("ns", "a", cert("ca-1", 2))
+	_, duplicated = index.Lookup("ns", "b", cert("ca-1", 1))
+	assert.False(t, duplicated)
+	assert.Equal(t, 1, index.order.Len())
+
+	// forgetting a Certificate drops its serial number
+	index.Forget("ns", "a")
+	_, duplicated = index.Lookup("ns", "b", cert("ca-1", 2))
+	assert.False(t, duplicated)
+	assert.Equal(t, 0, index.order.Len())
+
+	// the index is full, so recording another serial number evicts the least
+	// recently recorded one
+	index.Record("ns", "a", cert("ca-1", 1))
+	index.Record("ns", "b", cert("ca-1", 2))
+	index.Record("ns", "c", cert("ca-1", 3))
+	_, duplicated = index.Lookup("ns", "d", cert("ca-1", 1))
+	assert.False(t, duplicated)
+	assert.Equal(t, 2, index.order.Len())
+	assert.Len(t, index.entries, 2)
+	assert.Len(t, index.certificates, 2)
+}
@@ -17,14 +17,34 @@ limitations under the License.
 package certificates
 
 import (
+	"bytes"
 	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"strings"
 
 	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	utilpki "github.com/cert-manager/cert-manager/pkg/util/pki"
 )
 
+// SecretUpdateStrategy is the strategy used by the controller to write the
+// Secrets of Certificates.
+type SecretUpdateStrategy string
+
+const (
+	// SecretUpdateStrategyApply writes Secrets using server-side apply, so
+	// their managed fields record the fields owned by cert-manager.
+	SecretUpdateStrategyApply SecretUpdateStrategy = "Apply"
+
+	// SecretUpdateStrategyUpdate writes Secrets using Create and Update
+	// calls. The managed fields of Secrets written this way do not record
+	// which fields are owned by cert-manager.
+	SecretUpdateStrategyUpdate SecretUpdateStrategy = "Update"
+)
+
 // AnnotationsForCertificateSecret returns a map which is set on all
 // Certificate Secret's Annotations when issued. These annotations contain
 // information about the Issuer and Certificate.
@@ -38,6 +58,17 @@ func AnnotationsForCertificateSecret(crt *cmapi.Certificate, certificate *x509.C
 	annotations[cmapi.IssuerKindAnnotationKey] = apiutil.IssuerKind(crt.Spec.IssuerRef)
 	annotations[cmapi.IssuerGroupAnnotationKey] = crt.Spec.IssuerRef.Group
 
+	// Record the password Secret references that any keystores are built
+	// with, so that they are rebuilt should the references change.
+	if ks := crt.Spec.Keystores; ks != nil {
+		if ks.PKCS12 != nil && ks.PKCS12.Create {
+			annotations[cmapi.PKCS12PasswordSecretRefAnnotationKey] = KeystorePasswordSecretRef(ks.PKCS12.PasswordSecretRef)
+		}
+		if ks.JKS != nil && ks.JKS.Create {
+			annotations[cmapi.JKSPasswordSecretRefAnnotationKey] = KeystorePasswordSecretRef(ks.JKS.PasswordSecretRef)
+		}
+	}
+
 	// Only add certificate data if certificate is non-nil.
 	if certificate != nil {
 		annotations[cmapi.CommonNameAnnotationKey] = certificate.Subject.CommonName
@@ -48,3 +79,29 @@ func AnnotationsForCertificateSecret(crt *cmapi.Certificate, certificate *x509.C
 
 	return annotations
 }
+
+// KeystorePasswordSecretRef returns the value of the annotation recording the
+// password Secret reference a keystore was built with.
+func KeystorePasswordSecretRef(ref cmmeta.SecretKeySelector) string {
+	return ref.Name + "/" + ref.Key
+}
+
+// AdditionalOutputFormatData returns the Secret data key and value for the
+// given additional output format, derived from the given PEM encoded private
+// key and certificate.
+func AdditionalOutputFormatData(format cmapi.CertificateOutputFormatType, privateKey, certificate []byte) (string, []byte, error) {
+	switch format {
+	case cmapi.CertificateOutputFormatDER:
+		// Binary format of the private key
+		block, _ := pem.Decode(privateKey)
+		if block == nil {
+			return "", nil, errors.New("failed to decode private key PEM data")
+		}
+		return cmapi.CertificateOutputFormatDERKey, block.Bytes, nil
+	case cmapi.CertificateOutputFormatCombinedPEM:
+		// Combination of tls.key and tls.crt
+		return cmapi.CertificateOutputFormatCombinedPEMKey, bytes.Join([][]byte{privateKey, certificate}, []byte("\n")), nil
+	default:
+		return "", nil, fmt.Errorf("unknown additional output format %s", format)
+	}
+}
@@ -140,6 +140,29 @@ func Test_AnnotationsForCertificateSecret(t *testing.T) {
 				"cert-manager.io/uri-sans":         "",
 			},
 		},
+		"if keystores are created, expect their password Secret references to be present": {
+			crt: gen.Certificate("test-certificate",
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "test-issuer", Kind: "", Group: "cert-manager.io"}),
+				gen.SetCertificateKeystore(&cmapi.CertificateKeystores{
+					PKCS12: &cmapi.PKCS12Keystore{
+						Create:            true,
+						PasswordSecretRef: cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: "pkcs12-password"}, Key: "password"},
+					},
+					JKS: &cmapi.JKSKeystore{
+						Create:            false,
+						PasswordSecretRef: cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: "jks-password"}, Key: "password"},
+					},
+				}),
+			),
+			certificate: nil,
+			expAnnotations: map[string]string{
+				"cert-manager.io/certificate-name":           "test-certificate",
+				"cert-manager.io/issuer-name":                "test-issuer",
+				"cert-manager.io/issuer-kind":                "Issuer",
+				"cert-manager.io/issuer-group":               "cert-manager.io",
+				"cert-manager.io/pkcs12-password-secret-ref": "pkcs12-password/password",
+			},
+		},
 		"if no certificate data, then expect no X.509 related annotations": {
 			crt: gen.Certificate("test-certificate",
 				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "test-issuer", Kind: "", Group: "cert-manager.io"}),
@@ -201,6 +201,25 @@ func SetCertificateCondition(crt *cmapi.Certificate, observedGeneration int64, c
 	logf.V(logf.InfoLevel).Infof("Setting lastTransitionTime for Certificate %q condition %q to %v", crt.Name, conditionType, nowTime.Time)
 }
 
+// SetCertificateConditionIfChanged will set a 'condition' on the given
+// Certificate in the same way as SetCertificateCondition, but only if the
+// Certificate does not already have a condition of the given type with the
+// same status, reason, message and observed generation. It returns true if
+// the condition was set.
+// This avoids updating Certificates when the same condition is evaluated
+// repeatedly.
+func SetCertificateConditionIfChanged(crt *cmapi.Certificate, observedGeneration int64, conditionType cmapi.CertificateConditionType,
+	status cmmeta.ConditionStatus, reason, message string) bool {
+	cond := GetCertificateCondition(crt, conditionType)
+	if cond != nil && cond.Status == status && cond.Reason == reason &&
+		cond.Message == message && cond.ObservedGeneration == observedGeneration {
+		return false
+	}
+
+	SetCertificateCondition(crt, observedGeneration, conditionType, status, reason, message)
+	return true
+}
+
 // RemoveCertificateCondition will remove any condition with this condition type
 func RemoveCertificateCondition(crt *cmapi.Certificate, conditionType cmapi.CertificateConditionType) {
 	var updatedConditions []cmapi.CertificateCondition
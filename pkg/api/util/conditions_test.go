@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/utils/clock"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+)
+
+func TestSetCertificateConditionIfChanged(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := fakeclock.NewFakeClock(start)
+	Clock = fakeClock
+	defer func() { Clock = clock.RealClock{} }()
+
+	crt := &cmapi.Certificate{}
+	if !SetCertificateConditionIfChanged(crt, 1, cmapi.CertificateConditionIssuing, cmmeta.ConditionFalse, "IssuerNotFound", "not found") {
+		t.Fatalf("expected the condition to be set on a Certificate without conditions")
+	}
+
+	// a repeated identical evaluation must not update the condition
+	fakeClock.Step(time.Minute)
+	if SetCertificateConditionIfChanged(crt, 1, cmapi.CertificateConditionIssuing, cmmeta.ConditionFalse, "IssuerNotFound", "not found") {
+		t.Errorf("expected the condition not to be set again when nothing changed")
+	}
+	if got := crt.Status.Conditions[0].LastTransitionTime.Time; !got.Equal(start) {
+		t.Errorf("expected lastTransitionTime to be preserved as %v, got %v", start, got)
+	}
+
+	// a change of reason updates the condition but is not a transition
+	fakeClock.Step(time.Minute)
+	if !SetCertificateConditionIfChanged(crt, 1, cmapi.CertificateConditionIssuing, cmmeta.ConditionFalse, "SecretTerminating", "terminating") {
+		t.Errorf("expected the condition to be set when the reason changed")
+	}
+	if got := crt.Status.Conditions[0]; got.Reason != "SecretTerminating" || !got.LastTransitionTime.Time.Equal(start) {
+		t.Errorf("expected reason SecretTerminating with lastTransitionTime %v, got %q with %v", start, got.Reason, got.LastTransitionTime.Time)
+	}
+
+	// a change of status is a transition
+	fakeClock.Step(time.Minute)
+	if !SetCertificateConditionIfChanged(crt, 1, cmapi.CertificateConditionIssuing, cmmeta.ConditionTrue, "Renewing", "renewing") {
+		t.Errorf("expected the condition to be set when the status changed")
+	}
+	if got := crt.Status.Conditions[0].LastTransitionTime.Time; !got.Equal(fakeClock.Now()) {
+		t.Errorf("expected lastTransitionTime to be updated to %v, got %v", fakeClock.Now(), got)
+	}
+	if len(crt.Status.Conditions) != 1 {
+		t.Errorf("expected a single condition, got %d", len(crt.Status.Conditions))
+	}
+}
@@ -34,6 +34,15 @@ const (
 	// solver for each ingress class.
 	ACMECertificateHTTP01IngressClassOverride = "acme.cert-manager.io/http01-override-ingress-class"
 
+	// ACMEChallengeMustScheduleAnnotationKey can be set to "true" on a
+	// Certificate to mark the challenges created for it as must-schedule.
+	// Must-schedule challenges are scheduled ahead of other challenges and
+	// may use the part of the scheduler's concurrency budget that is
+	// reserved for them, so that they are processed even when the scheduler
+	// is at capacity. The annotation is copied to the CertificateRequest and
+	// Order, from which it is copied to the Challenges.
+	ACMEChallengeMustScheduleAnnotationKey = "acme.cert-manager.io/must-schedule"
+
 	// IngressEditInPlaceAnnotationKey is used to toggle the use of ingressClass instead
 	// of ingress on the created Certificate resource
 	IngressEditInPlaceAnnotationKey = "acme.cert-manager.io/http01-edit-in-place"
@@ -49,6 +58,24 @@ const (
 	// SolverIdentificationLabelKey is added to the labels of a Pod serving an ACME challenge.
 	// Its value will be the "true" if the Pod is an HTTP-01 solver.
 	SolverIdentificationLabelKey = "acme.cert-manager.io/http01-solver"
+
+	// SolverGroupLabelKey is added to the labels of a Pod and Service serving
+	// ACME challenges when solver Pods are shared between challenges.
+	// Its value will be the hash identifying the group of challenges sharing the Pod.
+	SolverGroupLabelKey = "acme.cert-manager.io/http01-solver-group"
+
+	// IssuerMaintenanceAnnotationKey can be set to "true" on an ACME Issuer
+	// or ClusterIssuer to mark it as temporarily unavailable, e.g. during a
+	// known outage of the ACME server or DNS provider. While set, challenges
+	// for the issuer are not processed and are instead checked again after
+	// a delay.
+	IssuerMaintenanceAnnotationKey = "acme.cert-manager.io/maintenance"
+
+	// IssuerDNS01CheckRetryPeriodAnnotationKey can be set on an ACME Issuer
+	// or ClusterIssuer to override the time to wait between DNS01 propagation
+	// checks for challenges of that issuer. Its value must be a positive
+	// duration as accepted by time.ParseDuration, e.g. "30s".
+	IssuerDNS01CheckRetryPeriodAnnotationKey = "acme.cert-manager.io/dns01-check-retry-period"
 )
 
 const (
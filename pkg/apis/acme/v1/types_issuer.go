@@ -244,6 +244,15 @@ type ACMEChallengeSolverHTTP01Ingress struct {
 	// ingress used for HTTP01 challenges.
 	// +optional
 	IngressTemplate *ACMEChallengeSolverHTTP01IngressTemplate `json:"ingressTemplate,omitempty"`
+
+	// Optional path prefix to prepend to the '/.well-known/acme-challenge/'
+	// path used to solve HTTP01 challenges, for use with reverse proxies that
+	// route requests to the ingress controller under a path prefix. The
+	// prefix is used both in the Ingress rules created for challenges and for
+	// the self check. The prefix must start with a '/' and must not end with
+	// one.
+	// +optional
+	PathPrefix string `json:"pathPrefix,omitempty"`
 }
 
 // The ACMEChallengeSolverHTTP01GatewayHTTPRoute solver will create HTTPRoute objects for a Gateway class
@@ -308,6 +317,21 @@ type ACMEChallengeSolverHTTP01IngressPodSpec struct {
 	// If specified, the pod's service account
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// If specified, the compute resource requests and limits of the solver
+	// pod's container. Any requests or limits set here override the defaults
+	// configured on the controller; those not set here keep their defaults.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// If true, the solver pod uses the host's network namespace and serves
+	// challenges on the node's network interfaces. This may be required on
+	// nodes whose egress or ingress is only available to the host network.
+	// As the solver listens on a fixed port, only one solver pod can then run
+	// on each node at a time.
+	// Defaults to false.
+	// +optional
+	HostNetwork bool `json:"hostNetwork,omitempty"`
 }
 
 type ACMEChallengeSolverHTTP01IngressTemplate struct {
@@ -338,6 +362,29 @@ type ACMEChallengeSolverDNS01 struct {
 	// +optional
 	CNAMEStrategy CNAMEStrategy `json:"cnameStrategy,omitempty"`
 
+	// CheckAuthoritativeNameservers overrides, for this solver, whether the
+	// DNS01 propagation self-check queries the zone's authoritative
+	// nameservers directly rather than the controller's recursive
+	// nameservers. Querying the authoritative nameservers avoids stale records
+	// being returned from the caches of recursive nameservers. If not set,
+	// the controller's --dns01-recursive-nameservers-only flag is used.
+	// +optional
+	CheckAuthoritativeNameservers *bool `json:"checkAuthoritativeNameservers,omitempty"`
+
+	// RequireAllNameservers sets whether the DNS01 propagation self-check
+	// only passes once every nameserver it queries returns the challenge
+	// record. If set to false, the self-check passes as soon as any one of
+	// them returns the record. If not set, all nameservers must return it.
+	// +optional
+	RequireAllNameservers *bool `json:"requireAllNameservers,omitempty"`
+
+	// TTL is the time to live, in seconds, of the TXT records created to
+	// solve DNS01 challenges. It is supported by the Akamai, CloudDNS,
+	// Cloudflare, Route53, AzureDNS and DigitalOcean providers, and ignored
+	// by other providers. If not set, the provider's default TTL is used.
+	// +optional
+	TTL *int32 `json:"ttl,omitempty"`
+
 	// Use the Akamai DNS zone management API to manage DNS01 challenge records.
 	// +optional
 	Akamai *ACMEIssuerDNS01ProviderAkamai `json:"akamai,omitempty"`
@@ -376,6 +423,12 @@ type ACMEChallengeSolverDNS01 struct {
 	// DNS01 challenge records.
 	// +optional
 	Webhook *ACMEIssuerDNS01ProviderWebhook `json:"webhook,omitempty"`
+
+	// Run an executable on the cert-manager controller to manage DNS01
+	// challenge records. The executable must be explicitly allowed using the
+	// controller's --dns01-exec-provider-commands flag.
+	// +optional
+	Exec *ACMEIssuerDNS01ProviderExec `json:"exec,omitempty"`
 }
 
 // CNAMEStrategy configures how the DNS01 provider should handle CNAME records
@@ -445,6 +498,19 @@ type ACMEIssuerDNS01ProviderDigitalOcean struct {
 	Token cmmeta.SecretKeySelector `json:"tokenSecretRef"`
 }
 
+// ACMEIssuerDNS01ProviderExec is a structure containing the configuration for
+// managing DNS01 challenge records by running an executable on the
+// cert-manager controller.
+// The executable is run as `<command> present <fqdn> <value>` to present a
+// record and `<command> cleanup <fqdn> <value>` to clean it up, and must exit
+// with status 0 on success.
+type ACMEIssuerDNS01ProviderExec struct {
+	// Command is the absolute path of the executable to run. It must be one
+	// of the commands allowed by the controller's
+	// --dns01-exec-provider-commands flag.
+	Command string `json:"command"`
+}
+
 // ACMEIssuerDNS01ProviderRoute53 is a structure containing the Route 53
 // configuration for AWS
 type ACMEIssuerDNS01ProviderRoute53 struct {
@@ -48,9 +48,34 @@ const (
 	// Annotation key for the 'group' of the Issuer resource.
 	IssuerGroupAnnotationKey = "cert-manager.io/issuer-group"
 
+	// Annotation key for the profile of the Issuer resource. Users may set
+	// this annotation on an Issuer or ClusterIssuer, and it is copied to the
+	// Secrets of Certificates issued by that issuer.
+	IssuerProfileAnnotationKey = "cert-manager.io/issuer-profile"
+
+	// Annotation key for the extended key usages that an Issuer or
+	// ClusterIssuer no longer grants, as a comma separated list of key usage
+	// names, e.g. 'server auth'. When the controller's denied usages check
+	// is enabled, Certificates whose issued certificate has one of these
+	// extended key usages are reissued.
+	DeniedExtendedKeyUsagesAnnotationKey = "cert-manager.io/denied-extended-key-usages"
+
+	// Annotation keys for the password Secret references that the PKCS12 and
+	// JKS keystores stored in a Certificate's Secret were built with, in the
+	// form '<secret name>/<key>'. They are used to rebuild the keystores when
+	// the Certificate's passwordSecretRef changes.
+	PKCS12PasswordSecretRefAnnotationKey = "cert-manager.io/pkcs12-password-secret-ref"
+	JKSPasswordSecretRefAnnotationKey    = "cert-manager.io/jks-password-secret-ref"
+
 	// Annotation key for the name of the certificate that a resource is related to.
 	CertificateNameKey = "cert-manager.io/certificate-name"
 
+	// Annotation key that can be set to "true" on an existing Secret that was
+	// not created by cert-manager to allow a Certificate to write its issued
+	// certificate to it, when cert-manager is configured to protect such
+	// Secrets from being overwritten.
+	AllowSecretAdoptionAnnotationKey = "cert-manager.io/allow-adoption"
+
 	// Annotation key used to denote whether a Secret is named on a Certificate
 	// as a 'next private key' Secret resource.
 	IsNextPrivateKeySecretLabelKey = "cert-manager.io/next-private-key"
@@ -81,6 +106,9 @@ const (
 	// on the resource.
 	// This annotation *may* not be present, and is used by the 'self signing'
 	// issuer type to self-sign certificates.
+	// The value may be of the form '<secret name>/<key>' to read the private
+	// key from a specific entry of the Secret. Otherwise, the 'tls.key' and
+	// 'ca.key' entries are tried in order.
 	CertificateRequestPrivateKeyAnnotationKey = "cert-manager.io/private-key-secret-name"
 
 	// Annotation to declare the CertificateRequest "revision", belonging to a Certificate Resource
@@ -94,6 +122,56 @@ const (
 	// stored in the target Secret resource whilst the real Issuer is processing
 	// the certificate request.
 	IssueTemporaryCertificateAnnotation = "cert-manager.io/issue-temporary-certificate"
+
+	// RequiredSecretKeysAnnotation is an annotation that can be added to
+	// Certificate resources.
+	// It contains a comma separated list of additional keys that must be
+	// present in the target Secret resource, in addition to `tls.crt` and
+	// `tls.key`. If any of these keys is missing, the certificate will be
+	// re-issued. Only keys that are written to the Secret upon issuance
+	// (e.g. `ca.crt` or the keys of additional output formats) should be
+	// listed, otherwise the certificate will be continuously re-issued.
+	RequiredSecretKeysAnnotation = "cert-manager.io/required-secret-keys"
+
+	// OCSPMustStapleAnnotation is an annotation that can be added to
+	// Certificate resources.
+	// If set to "true", the OCSP Must-Staple (TLS Feature) extension is
+	// requested for the certificate, and the certificate will be re-issued if
+	// the stored certificate does not contain it. If set to "false", the
+	// certificate will be re-issued if the stored certificate does contain it.
+	// The referenced issuer must honour the extension as requested, otherwise
+	// the certificate will be continuously re-issued.
+	OCSPMustStapleAnnotation = "cert-manager.io/ocsp-must-staple"
+
+	// CertificatePoliciesAnnotation is an annotation that can be added to
+	// Certificate resources.
+	// It contains a comma separated list of certificate policy OIDs in dotted
+	// decimal notation, e.g. "2.23.140.1.2.1", which are requested for the
+	// certificate. The certificate will be re-issued if the stored
+	// certificate does not contain all of them. The referenced issuer must
+	// honour the requested policies, otherwise the certificate will be
+	// continuously re-issued.
+	CertificatePoliciesAnnotation = "cert-manager.io/certificate-policies"
+
+	// EmbeddedSCTsAnnotation is an annotation that can be added to
+	// Certificate resources.
+	// If set to "true", the certificate will be re-issued if the stored
+	// certificate does not contain embedded Signed Certificate Timestamps
+	// (SCTs) from certificate transparency logs. If set to "false", the
+	// certificate will be re-issued if the stored certificate does contain
+	// them. cert-manager does not request SCTs, so the referenced issuer must
+	// embed them as expected, otherwise the certificate will be continuously
+	// re-issued.
+	EmbeddedSCTsAnnotation = "cert-manager.io/embedded-scts"
+
+	// ExternallyIssuedAnnotation is an annotation that can be added to
+	// Certificate resources.
+	// If set to "true", the certificate and private key stored in the target
+	// Secret are issued outside of cert-manager and imported into the Secret.
+	// Certificates stored in the Secret will not be re-issued because their
+	// key pair or x509 fields do not match the Certificate's spec, but the
+	// Secret's metadata and additional output formats are still managed.
+	ExternallyIssuedAnnotation = "cert-manager.io/externally-issued"
 )
 
 // Common/known resource kinds.
@@ -116,6 +116,18 @@ type CertificateSpec struct {
 	// +optional
 	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
 
+	// `renewBeforePercentage` is like `renewBefore`, except it is a relative
+	// percentage rather than an absolute duration. For example, if a
+	// certificate is valid for 60 minutes, and `renewBeforePercentage=25`,
+	// cert-manager will begin to attempt to renew the certificate 45 minutes
+	// after it was issued (i.e. when there are 15 minutes (25%) remaining
+	// until the certificate is no longer valid).
+	// The actual lifetime of the issued certificate is used to determine the
+	// renewal time. Value must be an integer in the range (0,100).
+	// Cannot be set if `renewBefore` is set.
+	// +optional
+	RenewBeforePercentage *int32 `json:"renewBeforePercentage,omitempty"`
+
 	// DNSNames is a list of DNS subjectAltNames to be set on the Certificate.
 	// +optional
 	DNSNames []string `json:"dnsNames,omitempty"`
@@ -411,6 +411,11 @@ func (in *CertificateSpec) DeepCopyInto(out *CertificateSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.RenewBeforePercentage != nil {
+		in, out := &in.RenewBeforePercentage, &out.RenewBeforePercentage
+		*out = new(int32)
+		**out = **in
+	}
 	if in.DNSNames != nil {
 		in, out := &in.DNSNames, &out.DNSNames
 		*out = make([]string, len(*in))
@@ -799,6 +804,21 @@ func (in *SelfSignedIssuer) DeepCopyInto(out *SelfSignedIssuer) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.AdditionalDNSNames != nil {
+		in, out := &in.AdditionalDNSNames, &out.AdditionalDNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdditionalIPAddresses != nil {
+		in, out := &in.AdditionalIPAddresses, &out.AdditionalIPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -36,6 +36,14 @@ const (
 	// This annotation *may* not be present, and is used by the 'self signing'
 	// issuer type to self-sign certificates.
 	CertificateSigningRequestPrivateKeyAnnotationKey = "experimental.cert-manager.io/private-key-secret-name"
+
+	// CertificateSigningRequestCAAnnotationKey is the annotation key set by
+	// the 'self signing' issuer type on signed requests to expose the PEM
+	// encoded CA certificate stored in the `ca.crt` key of the Secret
+	// referenced by CertificateSigningRequestPrivateKeyAnnotationKey, if
+	// present. Kubernetes CertificateSigningRequests do not allow the root CA
+	// to be included in `status.certificate`.
+	CertificateSigningRequestCAAnnotationKey = "experimental.cert-manager.io/ca-certificate"
 )
 
 // Venafi Issuer specific Annotations
@@ -16,4 +16,34 @@ limitations under the License.
 
 package acmechallenges
 
-// no checks for the acme orders controller yet
+import (
+	"fmt"
+	"time"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// issuerUnderMaintenance returns true if the given issuer has been marked as
+// temporarily unavailable using the IssuerMaintenanceAnnotationKey annotation.
+func issuerUnderMaintenance(issuer cmapi.GenericIssuer) bool {
+	return issuer.GetObjectMeta().Annotations[cmacme.IssuerMaintenanceAnnotationKey] == "true"
+}
+
+// issuerDNS01CheckRetryPeriod returns the DNS01 check retry period set on the
+// given issuer using the IssuerDNS01CheckRetryPeriodAnnotationKey annotation.
+// It returns zero if the annotation is not set.
+func issuerDNS01CheckRetryPeriod(issuer cmapi.GenericIssuer) (time.Duration, error) {
+	value, ok := issuer.GetObjectMeta().Annotations[cmacme.IssuerDNS01CheckRetryPeriodAnnotationKey]
+	if !ok {
+		return 0, nil
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation: %w", cmacme.IssuerDNS01CheckRetryPeriodAnnotationKey, err)
+	}
+	if period <= 0 {
+		return 0, fmt.Errorf("invalid %s annotation: duration must be positive, got %q", cmacme.IssuerDNS01CheckRetryPeriodAnnotationKey, value)
+	}
+	return period, nil
+}
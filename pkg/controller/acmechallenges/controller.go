@@ -18,6 +18,8 @@ package acmechallenges
 
 import (
 	"context"
+	"fmt"
+	"hash/fnv"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -27,10 +29,13 @@ import (
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 
 	"github.com/cert-manager/cert-manager/internal/ingress"
 	"github.com/cert-manager/cert-manager/pkg/acme/accounts"
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
 	cmclient "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
 	cmacmelisters "github.com/cert-manager/cert-manager/pkg/client/listers/acme/v1"
 	cmlisters "github.com/cert-manager/cert-manager/pkg/client/listers/certmanager/v1"
@@ -42,6 +47,14 @@ import (
 	logf "github.com/cert-manager/cert-manager/pkg/logs"
 )
 
+const (
+	// defaultBackoffBaseDelay and defaultBackoffMaxDelay are the initial and
+	// maximum delays applied to challenges that fail to sync if no other
+	// values are configured.
+	defaultBackoffBaseDelay = time.Second * 5
+	defaultBackoffMaxDelay  = time.Minute * 30
+)
+
 type controller struct {
 	// issuer helper is used to obtain references to issuers, used by Sync()
 	helper issuer.Helper
@@ -64,6 +77,9 @@ type controller struct {
 	// for processing. This job runs periodically every N seconds, so it cannot
 	// be constructed as a traditional controller.
 	scheduler *scheduler.Scheduler
+	// scheduleLimiter, if set, limits the rate at which challenges are
+	// marked as processing by the scheduler.
+	scheduleLimiter flowcontrol.RateLimiter
 
 	// used to record Events about resources to the API
 	recorder record.EventRecorder
@@ -73,6 +89,9 @@ type controller struct {
 	// maintain a reference to the workqueue for this controller
 	// so the handleOwnedResource method can enqueue resources
 	queue workqueue.RateLimitingInterface
+	// rateLimiter applies an exponential back-off to challenges in the queue
+	// that fail to sync.
+	rateLimiter workqueue.RateLimiter
 
 	// logger to be used by this controller
 	log logr.Logger
@@ -80,6 +99,21 @@ type controller struct {
 	dns01Nameservers []string
 
 	DNS01CheckRetryPeriod time.Duration
+
+	// DNS01CheckInitialDelay is the time to wait before checking a challenge
+	// again if its first propagation check, made right after presenting it,
+	// fails.
+	DNS01CheckInitialDelay time.Duration
+
+	// issuerMaintenanceRetryPeriod is the time to wait before processing a
+	// challenge again if its issuer is under maintenance.
+	issuerMaintenanceRetryPeriod time.Duration
+
+	// processingWarningThreshold is the time a challenge may be processing
+	// for before a Warning event is recorded on it. Zero disables the event.
+	processingWarningThreshold time.Duration
+
+	clock clock.Clock
 }
 
 func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitingInterface, []cache.InformerSynced, error) {
@@ -87,7 +121,15 @@ func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	c.log = logf.FromContext(ctx.RootContext, ControllerName)
 
 	// create a queue used to queue up items to be processed
-	c.queue = workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Second*5, time.Minute*30), ControllerName)
+	baseDelay, maxDelay := defaultBackoffBaseDelay, defaultBackoffMaxDelay
+	if ctx.ACMEOptions.ChallengeBackoffBaseDelay > 0 {
+		baseDelay = ctx.ACMEOptions.ChallengeBackoffBaseDelay
+	}
+	if ctx.ACMEOptions.ChallengeBackoffMaxDelay > 0 {
+		maxDelay = ctx.ACMEOptions.ChallengeBackoffMaxDelay
+	}
+	c.rateLimiter = workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay)
+	c.queue = workqueue.NewNamedRateLimitingQueue(c.rateLimiter, ControllerName)
 
 	// obtain references to all the informers used by this controller
 	challengeInformer := ctx.SharedInformerFactory.Acme().V1().Challenges()
@@ -136,8 +178,23 @@ func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	challengeInformer.Informer().AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: c.queue})
 
 	c.helper = issuer.NewHelper(c.issuerLister, c.clusterIssuerLister)
-	c.scheduler = scheduler.New(logf.NewContext(ctx.RootContext, c.log), c.challengeLister, ctx.SchedulerOptions.MaxConcurrentChallenges)
-	c.recorder = ctx.Recorder
+	var maintenanceWindow *scheduler.MaintenanceWindow
+	if window := ctx.SchedulerOptions.MaintenanceWindow; window != "" {
+		maintenanceWindow, err = scheduler.ParseMaintenanceWindow(window)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	c.scheduler = scheduler.New(logf.NewContext(ctx.RootContext, c.log), c.challengeLister, scheduler.Limits{
+		MaxConcurrentChallenges:          ctx.SchedulerOptions.MaxConcurrentChallenges,
+		MaxConcurrentChallengesPerIssuer: ctx.SchedulerOptions.MaxConcurrentChallengesPerIssuer,
+		ReservedMustScheduleChallenges:   ctx.SchedulerOptions.ReservedMustScheduleChallenges,
+		MaintenanceWindow:                maintenanceWindow,
+	})
+	if qps := ctx.SchedulerOptions.StatusUpdateQPS; qps > 0 {
+		c.scheduleLimiter = flowcontrol.NewTokenBucketRateLimiterWithClock(qps, 1, ctx.Clock)
+	}
+	c.recorder = ctx.EventRecorderFor(ctx.ACMEOptions.ChallengesEventComponent)
 	c.cmClient = ctx.CMClient
 	c.accountRegistry = ctx.ACMEOptions.AccountRegistry
 
@@ -153,6 +210,10 @@ func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	// read options from context
 	c.dns01Nameservers = ctx.ACMEOptions.DNS01Nameservers
 	c.DNS01CheckRetryPeriod = ctx.ACMEOptions.DNS01CheckRetryPeriod
+	c.DNS01CheckInitialDelay = ctx.ACMEOptions.DNS01CheckInitialDelay
+	c.issuerMaintenanceRetryPeriod = ctx.ACMEOptions.IssuerMaintenanceRetryPeriod
+	c.processingWarningThreshold = ctx.ACMEOptions.ChallengeProcessingWarningThreshold
+	c.clock = ctx.Clock
 
 	return c.queue, mustSync, nil
 }
@@ -183,13 +244,16 @@ func (c *controller) runScheduler(ctx context.Context) {
 		ch = ch.DeepCopy()
 		ch.Status.Processing = true
 
+		if c.scheduleLimiter != nil {
+			c.scheduleLimiter.Accept()
+		}
 		_, err := c.cmClient.AcmeV1().Challenges(ch.Namespace).UpdateStatus(ctx, ch, metav1.UpdateOptions{})
 		if err != nil {
 			log.Error(err, "error scheduling challenge for processing")
 			return
 		}
 
-		c.recorder.Event(ch, corev1.EventTypeNormal, "Started", "Challenge scheduled for processing")
+		c.recorder.Event(ch, corev1.EventTypeNormal, reasonStarted, "Challenge scheduled for processing")
 	}
 
 	if len(toSchedule) > 0 {
@@ -216,8 +280,30 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		return err
 	}
 
-	ctx = logf.NewContext(ctx, logf.WithResource(log, ch))
-	return c.Sync(ctx, ch)
+	return c.Sync(challengeContext(ctx, ch), ch)
+}
+
+// correlationIDKey is the key under which the correlation ID of a challenge
+// is added to the logger.
+const correlationIDKey = "correlation_id"
+
+// correlationID returns a short identifier for the given challenge that is
+// derived from its UID, so is stable across syncs and unique to each
+// challenge.
+func correlationID(ch *cmacme.Challenge) string {
+	h := fnv.New32a()
+	// Writing to a hash.Hash never returns an error.
+	_, _ = h.Write([]byte(ch.UID))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// challengeContext returns a context whose logger identifies the given
+// challenge. The logger carries the challenge's correlation ID so that the
+// log lines for a single challenge, including those from the solvers, can
+// be followed when several challenges are processed concurrently.
+func challengeContext(ctx context.Context, ch *cmacme.Challenge) context.Context {
+	log := logf.WithResource(logf.FromContext(ctx), ch).WithValues(correlationIDKey, correlationID(ch))
+	return logf.NewContext(ctx, log)
 }
 
 const (
@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acmechallenges
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	coretesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	testpkg "github.com/cert-manager/cert-manager/pkg/controller/test"
+	logf "github.com/cert-manager/cert-manager/pkg/logs"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+)
+
+func TestRunScheduler(t *testing.T) {
+	ch := gen.Challenge("testchal",
+		gen.SetChallengeDNSName("example.com"),
+		gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+	)
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		CertManagerObjects: []runtime.Object{ch},
+		ExpectedActions: []testpkg.Action{
+			testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+				"status",
+				gen.DefaultTestNamespace,
+				gen.ChallengeFrom(ch, gen.SetChallengeProcessing(true)),
+			)),
+		},
+		ExpectedEvents: []string{
+			"Normal Started Challenge scheduled for processing",
+		},
+	}
+	builder.Init()
+	defer builder.Stop()
+	builder.Context.SchedulerOptions.MaxConcurrentChallenges = 1
+
+	c := &controller{}
+	if _, _, err := c.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	builder.Start()
+
+	c.runScheduler(context.Background())
+
+	builder.CheckAndFinish()
+}
+
+func TestRunSchedulerStatusUpdateQPS(t *testing.T) {
+	now := time.Now()
+	clock := fakeclock.NewFakeClock(now)
+
+	var challenges []runtime.Object
+	var expectedActions []testpkg.Action
+	var expectedEvents []string
+	for i := 0; i < 3; i++ {
+		ch := gen.Challenge(fmt.Sprintf("testchal-%d", i),
+			gen.SetChallengeDNSName(fmt.Sprintf("%d.example.com", i)),
+			gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+		)
+		ch.CreationTimestamp = metav1.NewTime(now.Add(time.Duration(i) * time.Second))
+		challenges = append(challenges, ch)
+		expectedActions = append(expectedActions, testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+			"status",
+			gen.DefaultTestNamespace,
+			gen.ChallengeFrom(ch, gen.SetChallengeProcessing(true)),
+		)))
+		expectedEvents = append(expectedEvents, "Normal Started Challenge scheduled for processing")
+	}
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              clock,
+		CertManagerObjects: challenges,
+		ExpectedActions:    expectedActions,
+		ExpectedEvents:     expectedEvents,
+	}
+	builder.Init()
+	defer builder.Stop()
+	builder.Context.SchedulerOptions.MaxConcurrentChallenges = 3
+	builder.Context.SchedulerOptions.StatusUpdateQPS = 10
+
+	c := &controller{}
+	if _, _, err := c.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	builder.Start()
+
+	c.runScheduler(context.Background())
+
+	// The first update is made straight away, and each subsequent update
+	// waits for 1/10th of a second.
+	if elapsed := clock.Since(now); elapsed < 200*time.Millisecond {
+		t.Errorf("expected status updates to be throttled for at least 200ms, but only %s elapsed", elapsed)
+	}
+
+	builder.CheckAndFinish()
+}
+
+func TestRegisterBackoff(t *testing.T) {
+	tests := map[string]struct {
+		baseDelay, maxDelay           time.Duration
+		expectedBase, expectedCeiling time.Duration
+	}{
+		"uses the default back-off if none is configured": {
+			expectedBase:    time.Second * 5,
+			expectedCeiling: time.Minute * 30,
+		},
+		"uses a configured lower ceiling": {
+			baseDelay:       time.Second,
+			maxDelay:        time.Minute * 5,
+			expectedBase:    time.Second,
+			expectedCeiling: time.Minute * 5,
+		},
+		"uses a configured higher ceiling": {
+			baseDelay:       time.Second * 10,
+			maxDelay:        time.Hour * 2,
+			expectedBase:    time.Second * 10,
+			expectedCeiling: time.Hour * 2,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := &testpkg.Builder{T: t}
+			builder.Init()
+			defer builder.Stop()
+			builder.Context.ACMEOptions.ChallengeBackoffBaseDelay = tc.baseDelay
+			builder.Context.ACMEOptions.ChallengeBackoffMaxDelay = tc.maxDelay
+
+			c := &controller{}
+			if _, _, err := c.Register(builder.Context); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedBase, c.rateLimiter.When("ns/name"))
+			assert.Equal(t, tc.expectedBase*2, c.rateLimiter.When("ns/name"))
+			var last time.Duration
+			for i := 0; i < 20; i++ {
+				last = c.rateLimiter.When("ns/name")
+			}
+			assert.Equal(t, tc.expectedCeiling, last)
+		})
+	}
+}
+
+func TestRegisterEventComponent(t *testing.T) {
+	builder := &testpkg.Builder{T: t}
+	builder.Init()
+	defer builder.Stop()
+
+	broadcaster := record.NewBroadcaster()
+	defer broadcaster.Shutdown()
+	events := make(chan *corev1.Event, 1)
+	broadcaster.StartEventWatcher(func(e *corev1.Event) { events <- e })
+	builder.Context.EventBroadcaster = broadcaster
+	builder.Context.ACMEOptions.ChallengesEventComponent = "cert-manager-challenges"
+
+	c := &controller{}
+	if _, _, err := c.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+
+	c.recorder.Event(gen.Challenge("testchal"), corev1.EventTypeNormal, reasonStarted, "Challenge scheduled for processing")
+	select {
+	case e := <-events:
+		assert.Equal(t, "cert-manager-challenges", e.Source.Component)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
+
+func TestChallengeContextCorrelationID(t *testing.T) {
+	ch := gen.Challenge("testchal", gen.SetChallengeNamespace(gen.DefaultTestNamespace))
+	ch.UID = "challenge-uid"
+	other := ch.DeepCopy()
+	other.UID = "other-challenge-uid"
+
+	var lines []string
+	log := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})
+
+	ctx := challengeContext(logf.NewContext(context.Background(), log), ch)
+	logf.FromContext(ctx, "solver").Info("presenting challenge")
+
+	id := correlationID(ch)
+	assert.Len(t, id, 8)
+	assert.Equal(t, id, correlationID(ch.DeepCopy()), "correlation ID should be stable")
+	assert.NotEqual(t, id, correlationID(other), "correlation ID should differ between challenges")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], fmt.Sprintf("%q=%q", correlationIDKey, id))
+	assert.Contains(t, lines[0], fmt.Sprintf("%q=%q", logf.ResourceNameKey, "testchal"))
+}
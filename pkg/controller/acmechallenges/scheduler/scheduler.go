@@ -18,13 +18,18 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/clock"
 
 	"github.com/cert-manager/cert-manager/pkg/acme"
 	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmacmelisters "github.com/cert-manager/cert-manager/pkg/client/listers/acme/v1"
 	"github.com/cert-manager/cert-manager/pkg/logs"
 )
@@ -33,19 +38,119 @@ import (
 // to challenge resources in order to determine which challenges should be
 // processing at a given time.
 type Scheduler struct {
-	log                     logr.Logger
-	challengeLister         cmacmelisters.ChallengeLister
-	maxConcurrentChallenges int
+	log             logr.Logger
+	challengeLister cmacmelisters.ChallengeLister
+	limits          Limits
+
+	// clock is used to determine whether the scheduler is within its
+	// maintenance window.
+	clock clock.Clock
+}
+
+// Limits holds the concurrency limits applied when selecting challenges to
+// schedule.
+type Limits struct {
+	// MaxConcurrentChallenges is the maximum number of challenges that can be
+	// processing at once.
+	MaxConcurrentChallenges int
+
+	// MaxConcurrentChallengesPerIssuer limits the number of challenges that
+	// can be processing at once for an individual issuer, keyed by IssuerKey.
+	// Issuers without an entry are only limited by MaxConcurrentChallenges.
+	MaxConcurrentChallengesPerIssuer map[string]int
+
+	// ReservedMustScheduleChallenges is the number of MaxConcurrentChallenges
+	// that is reserved for must-schedule challenges, i.e. challenges with the
+	// must-schedule annotation set to "true". Other challenges are not
+	// scheduled once MaxConcurrentChallenges minus this number of challenges
+	// are processing.
+	ReservedMustScheduleChallenges int
+
+	// MaintenanceWindow is a daily window during which only must-schedule
+	// challenges are scheduled, e.g. because DNS maintenance is performed
+	// during it. Challenges that are already processing are not affected.
+	// No maintenance window applies if it is nil.
+	MaintenanceWindow *MaintenanceWindow
 }
 
-// New will construct a new instance of a scheduler
-func New(ctx context.Context, l cmacmelisters.ChallengeLister, maxConcurrentChallenges int) *Scheduler {
+// MaintenanceWindow is a window of time that recurs daily.
+type MaintenanceWindow struct {
+	// Start and End are the times of day, as offsets from midnight UTC, at
+	// which the window starts and ends. The window spans midnight if End is
+	// before Start.
+	Start, End time.Duration
+}
+
+// ParseMaintenanceWindow parses a daily maintenance window of the form
+// "HH:MM-HH:MM", where the times are in UTC. For example, "23:00-01:30"
+// starts at 23:00 and ends at 01:30 the next day.
+func ParseMaintenanceWindow(s string) (*MaintenanceWindow, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("maintenance window %q must be of the form HH:MM-HH:MM", s)
+	}
+	var offsets [2]time.Duration
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("maintenance window %q must be of the form HH:MM-HH:MM: %w", s, err)
+		}
+		offsets[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if offsets[0] == offsets[1] {
+		return nil, fmt.Errorf("maintenance window %q must not start and end at the same time", s)
+	}
+	return &MaintenanceWindow{Start: offsets[0], End: offsets[1]}, nil
+}
+
+// Contains returns true if the given time is within the maintenance window.
+// The window includes its start time but not its end time. A nil
+// MaintenanceWindow contains no time.
+func (w *MaintenanceWindow) Contains(t time.Time) bool {
+	if w == nil {
+		return false
+	}
+	t = t.UTC()
+	offset := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// New will construct a new instance of a scheduler that applies the given
+// limits.
+func New(ctx context.Context, l cmacmelisters.ChallengeLister, limits Limits) *Scheduler {
 	log := logs.FromContext(ctx, "challenge-scheduler")
-	return &Scheduler{log: log, challengeLister: l, maxConcurrentChallenges: maxConcurrentChallenges}
+	return &Scheduler{
+		log:             log,
+		challengeLister: l,
+		limits:          limits,
+		clock:           clock.RealClock{},
+	}
+}
+
+// MustSchedule returns true if the given challenge must be scheduled ahead of
+// other challenges, as its must-schedule annotation is set to "true".
+func MustSchedule(ch *cmacme.Challenge) bool {
+	return ch.Annotations[cmacme.ACMEChallengeMustScheduleAnnotationKey] == "true"
+}
+
+// IssuerKey returns the key used to identify the issuer of the given
+// challenge when applying per-issuer concurrency limits. ClusterIssuers are
+// identified as "ClusterIssuer/<name>" and Issuers as
+// "Issuer/<namespace>/<name>".
+func IssuerKey(ch *cmacme.Challenge) string {
+	ref := ch.Spec.IssuerRef
+	if ref.Kind == cmapi.ClusterIssuerKind {
+		return cmapi.ClusterIssuerKind + "/" + ref.Name
+	}
+	return cmapi.IssuerKind + "/" + ch.Namespace + "/" + ref.Name
 }
 
 // ScheduleN will return a maximum of N challenge resources that should be
-// scheduled for processing.
+// scheduled for processing. Within the maintenance window, only must-schedule
+// challenges are returned.
 // It may return an empty list if there are no challenges that can/should be
 // scheduled.
 func (s *Scheduler) ScheduleN(n int) ([]*cmacme.Challenge, error) {
@@ -55,44 +160,109 @@ func (s *Scheduler) ScheduleN(n int) ([]*cmacme.Challenge, error) {
 		return nil, err
 	}
 
-	return s.scheduleN(n, allChallenges)
+	limits := s.limits
+	if limits.MaintenanceWindow.Contains(s.clock.Now()) {
+		// Only must-schedule challenges may be scheduled, using the whole
+		// concurrency budget.
+		s.log.V(logs.DebugLevel).Info("within maintenance window, only scheduling must-schedule challenges")
+		limits.ReservedMustScheduleChallenges = limits.MaxConcurrentChallenges
+	}
+
+	return SelectChallenges(s.log, allChallenges, n, limits), nil
 }
 
-func (s *Scheduler) scheduleN(n int, allChallenges []*cmacme.Challenge) ([]*cmacme.Challenge, error) {
+// SelectChallenges returns a maximum of n challenges from allChallenges that
+// should be scheduled for processing, given the challenges that are already
+// processing and the given limits. It does not modify allChallenges and only
+// uses log to explain its decisions.
+// Must-schedule challenges are selected first, and may use the part of
+// MaxConcurrentChallenges reserved for them. Otherwise, challenges are
+// selected oldest first. Challenges with the same creation timestamp are
+// selected in order of their DNS name and then their type.
+// It may return an empty list if there are no challenges that can/should be
+// scheduled.
+func SelectChallenges(log logr.Logger, allChallenges []*cmacme.Challenge, n int, limits Limits) []*cmacme.Challenge {
 	// Determine the list of challenges that could feasibly be scheduled on
 	// this pass of the scheduler.
 	// This function returns a list of candidates sorted by creation timestamp.
-	candidates, inProgressChallengeCount, err := s.determineChallengeCandidates(allChallenges)
-	if err != nil {
-		return nil, err
-	}
+	candidates, inProgress := determineChallengeCandidates(log, allChallenges, limits.MaxConcurrentChallenges)
+
+	numberToSelect := remainingChallenges(n, limits.MaxConcurrentChallenges, len(inProgress))
+	// Challenges that are not must-schedule may not use the reserved part of
+	// the concurrency budget.
+	numberNotMustScheduleToSelect := remainingChallenges(n, limits.MaxConcurrentChallenges-limits.ReservedMustScheduleChallenges, len(inProgress))
+
+	return selectChallengesToSchedule(log, prioritiseMustSchedule(candidates), numberToSelect, numberNotMustScheduleToSelect, inProgress, limits.MaxConcurrentChallengesPerIssuer)
+}
 
-	numberToSelect := n
-	remainingNumberAllowedChallenges := s.maxConcurrentChallenges - inProgressChallengeCount
-	if remainingNumberAllowedChallenges < 0 {
-		remainingNumberAllowedChallenges = 0
+// remainingChallenges returns the number of challenges, up to n, that can be
+// selected without more than max challenges processing at once.
+func remainingChallenges(n, max, inProgress int) int {
+	remaining := max - inProgress
+	if remaining < 0 {
+		remaining = 0
 	}
-	if numberToSelect > remainingNumberAllowedChallenges {
-		numberToSelect = remainingNumberAllowedChallenges
+	if n > remaining {
+		return remaining
 	}
+	return n
+}
 
-	candidates, err = s.selectChallengesToSchedule(candidates, numberToSelect)
-	if err != nil {
-		return nil, err
+// prioritiseMustSchedule returns the given challenges with must-schedule
+// challenges moved to the front, otherwise keeping their order.
+func prioritiseMustSchedule(chs []*cmacme.Challenge) []*cmacme.Challenge {
+	sorted := make([]*cmacme.Challenge, 0, len(chs))
+	for _, ch := range chs {
+		if MustSchedule(ch) {
+			sorted = append(sorted, ch)
+		}
 	}
-
-	return candidates, nil
+	for _, ch := range chs {
+		if !MustSchedule(ch) {
+			sorted = append(sorted, ch)
+		}
+	}
+	return sorted
 }
 
 // selectChallengesToSchedule will apply some sorting heuristic to the allowed
 // challenge candidates and return a maximum of N challenges that should be
-// scheduled for processing.
-func (s *Scheduler) selectChallengesToSchedule(candidates []*cmacme.Challenge, n int) ([]*cmacme.Challenge, error) {
-	// Trim the candidates returned to 'n'
-	if len(candidates) > n {
-		candidates = candidates[:n]
+// scheduled for processing, of which at most nNotMustSchedule are not
+// must-schedule challenges.
+// Candidates whose issuer already has as many challenges processing as its
+// per-issuer limit allows, including those selected on this pass, are
+// skipped so that they do not block challenges for other issuers.
+func selectChallengesToSchedule(log logr.Logger, candidates []*cmacme.Challenge, n, nNotMustSchedule int, inProgress []*cmacme.Challenge, maxPerIssuer map[string]int) []*cmacme.Challenge {
+	perIssuer := make(map[string]int)
+	for _, ch := range inProgress {
+		perIssuer[IssuerKey(ch)]++
+	}
+
+	selected := []*cmacme.Challenge{}
+	selectedNotMustSchedule := 0
+	for _, ch := range candidates {
+		if len(selected) >= n {
+			break
+		}
+		mustSchedule := MustSchedule(ch)
+		if !mustSchedule && selectedNotMustSchedule >= nNotMustSchedule {
+			// Candidates are sorted with must-schedule challenges first, so
+			// no more challenges can be selected.
+			log.V(logs.DebugLevel).Info("hit maximum concurrent challenge limit for challenges that are not must-schedule. refusing to schedule more challenges.")
+			break
+		}
+		key := IssuerKey(ch)
+		if limit, ok := maxPerIssuer[key]; ok && perIssuer[key] >= limit {
+			log.V(logs.DebugLevel).Info("hit maximum concurrent challenge limit for issuer. refusing to schedule more challenges for it.", "issuer", key, "in_progress", perIssuer[key], "max_concurrent", limit)
+			continue
+		}
+		perIssuer[key]++
+		if !mustSchedule {
+			selectedNotMustSchedule++
+		}
+		selected = append(selected, ch)
 	}
-	return candidates, nil
+	return selected
 }
 
 // determineChallengeCandidates will determine which, if any, challenges can
@@ -100,7 +270,9 @@ func (s *Scheduler) selectChallengesToSchedule(candidates []*cmacme.Challenge, n
 // processing.
 // The returned challenges will be sorted in ascending order based on timestamp
 // (i.e. the oldest challenge will be element zero).
-func (s *Scheduler) determineChallengeCandidates(allChallenges []*cmacme.Challenge) ([]*cmacme.Challenge, int, error) {
+// The challenges that are already processing are returned alongside the
+// candidates.
+func determineChallengeCandidates(log logr.Logger, allChallenges []*cmacme.Challenge, maxConcurrentChallenges int) ([]*cmacme.Challenge, []*cmacme.Challenge) {
 	// consider the entire set of challenges for 'in progress', in case a challenge
 	// has processing=true whilst still being in a 'final' state
 	inProgress := processingChallenges(allChallenges)
@@ -109,9 +281,9 @@ func (s *Scheduler) determineChallengeCandidates(allChallenges []*cmacme.Challen
 	// Ensure we only run a max of MaxConcurrentChallenges at a time
 	// We perform this check here to avoid extra processing if we've already
 	// hit the maximum number of challenges.
-	if inProgressChallengeCount >= s.maxConcurrentChallenges {
-		s.log.V(logs.DebugLevel).Info("hit maximum concurrent challenge limit. refusing to schedule more challenges.", "in_progress", len(inProgress), "max_concurrent", s.maxConcurrentChallenges)
-		return []*cmacme.Challenge{}, inProgressChallengeCount, nil
+	if inProgressChallengeCount >= maxConcurrentChallenges {
+		log.V(logs.DebugLevel).Info("hit maximum concurrent challenge limit. refusing to schedule more challenges.", "in_progress", len(inProgress), "max_concurrent", maxConcurrentChallenges)
+		return []*cmacme.Challenge{}, inProgress
 	}
 
 	// Calculate incomplete challenges
@@ -129,7 +301,7 @@ func (s *Scheduler) determineChallengeCandidates(allChallenges []*cmacme.Challen
 	candidates := filterChallenges(dedupedCandidates, func(ch *cmacme.Challenge) bool {
 		for _, inPCh := range inProgress {
 			if compareChallenges(ch, inPCh) == 0 {
-				s.log.V(logs.DebugLevel).Info("there is already a challenge processing with this domain", "domain", ch.Spec.DNSName, "type", ch.Spec.Type)
+				log.V(logs.DebugLevel).Info("there is already a challenge processing with this domain", "domain", ch.Spec.DNSName, "type", ch.Spec.Type)
 				return false
 			}
 		}
@@ -139,11 +311,14 @@ func (s *Scheduler) determineChallengeCandidates(allChallenges []*cmacme.Challen
 	// Finally, sorted the challenges by timestamp to ensure a stable output
 	sortChallengesByTimestamp(candidates)
 
-	return candidates, inProgressChallengeCount, nil
+	return candidates, inProgress
 }
 
+// sortChallengesByTimestamp sorts the given challenges by creation timestamp,
+// oldest first. The sort is stable, so challenges with the same creation
+// timestamp keep their relative order.
 func sortChallengesByTimestamp(chs []*cmacme.Challenge) {
-	sort.Slice(chs, func(i, j int) bool {
+	sort.SliceStable(chs, func(i, j int) bool {
 		return chs[i].CreationTimestamp.Before(&chs[j].CreationTimestamp)
 	})
 }
@@ -23,11 +23,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
+	fakeclock "k8s.io/utils/clock/testing"
 
 	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
 	cminformers "github.com/cert-manager/cert-manager/pkg/client/informers/externalversions"
 	"github.com/cert-manager/cert-manager/pkg/util"
@@ -79,11 +82,9 @@ func BenchmarkScheduleAscending(b *testing.B) {
 	for _, c := range counts {
 		b.Run(fmt.Sprintf("With %d challenges to schedule", c), func(b *testing.B) {
 			chs := ascendingChallengeN(c)
-			s := &Scheduler{}
 			b.ResetTimer()
 			for n := 0; n < b.N; n++ {
-				_, err := s.scheduleN(30, chs)
-				require.NoError(b, err)
+				SelectChallenges(logr.Discard(), chs, 30, Limits{})
 			}
 		})
 	}
@@ -94,11 +95,9 @@ func BenchmarkScheduleRandom(b *testing.B) {
 	for _, c := range counts {
 		b.Run(fmt.Sprintf("With %d random challenges to schedule", c), func(b *testing.B) {
 			chs := randomChallengeN(c, 0)
-			s := &Scheduler{}
 			b.ResetTimer()
 			for n := 0; n < b.N; n++ {
-				_, err := s.scheduleN(30, chs)
-				require.NoError(b, err)
+				SelectChallenges(logr.Discard(), chs, 30, Limits{})
 			}
 		})
 	}
@@ -109,11 +108,9 @@ func BenchmarkScheduleDuplicates(b *testing.B) {
 	for _, c := range counts {
 		b.Run(fmt.Sprintf("With %d random but likely duplicate challenges to schedule", c), func(b *testing.B) {
 			chs := randomChallengeN(c, 3)
-			s := &Scheduler{}
 			b.ResetTimer()
 			for n := 0; n < b.N; n++ {
-				_, err := s.scheduleN(30, chs)
-				require.NoError(b, err)
+				SelectChallenges(logr.Discard(), chs, 30, Limits{})
 			}
 		})
 	}
@@ -319,7 +316,7 @@ func TestScheduleN(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			s := New(context.Background(), challengesInformer.Lister(), maxConcurrentChallenges)
+			s := New(context.Background(), challengesInformer.Lister(), Limits{MaxConcurrentChallenges: maxConcurrentChallenges})
 
 			if test.expected == nil {
 				test.expected = []*cmacme.Challenge{}
@@ -337,3 +334,487 @@ func TestScheduleN(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduleNPerIssuerLimits(t *testing.T) {
+	slowIssuer := gen.SetChallengeIssuer(cmmeta.ObjectReference{Name: "slow", Kind: "ClusterIssuer"})
+	fastIssuer := gen.SetChallengeIssuer(cmmeta.ObjectReference{Name: "fast", Kind: "ClusterIssuer"})
+	challenge := func(name string, ts int64, mods ...gen.ChallengeModifier) *cmacme.Challenge {
+		mods = append([]gen.ChallengeModifier{
+			gen.SetChallengeDNSName(name + ".example.com"),
+			gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+			withCreationTimestamp(ts),
+		}, mods...)
+		return gen.Challenge(name, mods...)
+	}
+
+	tests := []struct {
+		name       string
+		n          int
+		max        int
+		perIssuer  map[string]int
+		challenges []*cmacme.Challenge
+		expected   []string
+	}{
+		{
+			name:      "an issuer at its limit does not block other issuers",
+			n:         5,
+			max:       10,
+			perIssuer: map[string]int{"ClusterIssuer/slow": 2},
+			challenges: []*cmacme.Challenge{
+				challenge("slow-processing-1", 0, slowIssuer, gen.SetChallengeProcessing(true)),
+				challenge("slow-processing-2", 1, slowIssuer, gen.SetChallengeProcessing(true)),
+				challenge("slow-1", 2, slowIssuer),
+				challenge("slow-2", 3, slowIssuer),
+				challenge("fast-1", 4, fastIssuer),
+				challenge("fast-2", 5, fastIssuer),
+			},
+			expected: []string{"fast-1", "fast-2"},
+		},
+		{
+			name:      "challenges selected on the same pass count towards the per-issuer limit",
+			n:         5,
+			max:       10,
+			perIssuer: map[string]int{"ClusterIssuer/slow": 2},
+			challenges: []*cmacme.Challenge{
+				challenge("slow-1", 0, slowIssuer),
+				challenge("slow-2", 1, slowIssuer),
+				challenge("slow-3", 2, slowIssuer),
+				challenge("fast-1", 3, fastIssuer),
+			},
+			expected: []string{"slow-1", "slow-2", "fast-1"},
+		},
+		{
+			name:      "the global limit still applies across issuers",
+			n:         5,
+			max:       3,
+			perIssuer: map[string]int{"ClusterIssuer/slow": 2},
+			challenges: []*cmacme.Challenge{
+				challenge("slow-processing-1", 0, slowIssuer, gen.SetChallengeProcessing(true)),
+				challenge("fast-processing-1", 1, fastIssuer, gen.SetChallengeProcessing(true)),
+				challenge("slow-1", 2, slowIssuer),
+				challenge("fast-1", 3, fastIssuer),
+				challenge("fast-2", 4, fastIssuer),
+			},
+			expected: []string{"slow-1"},
+		},
+		{
+			name:      "a per-issuer limit of zero prevents scheduling challenges for that issuer",
+			n:         5,
+			max:       10,
+			perIssuer: map[string]int{"ClusterIssuer/slow": 0},
+			challenges: []*cmacme.Challenge{
+				challenge("slow-1", 0, slowIssuer),
+				challenge("fast-1", 1, fastIssuer),
+				challenge("fast-2", 2, fastIssuer),
+			},
+			expected: []string{"fast-1", "fast-2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cl := fake.NewSimpleClientset()
+			factory := cminformers.NewSharedInformerFactory(cl, 0)
+			challengesInformer := factory.Acme().V1().Challenges()
+			for _, ch := range test.challenges {
+				err := challengesInformer.Informer().GetIndexer().Add(ch)
+				require.NoError(t, err)
+			}
+
+			s := New(context.Background(), challengesInformer.Lister(), Limits{MaxConcurrentChallenges: test.max, MaxConcurrentChallengesPerIssuer: test.perIssuer})
+
+			chs, err := s.ScheduleN(test.n)
+			require.NoError(t, err)
+			var names []string
+			for _, ch := range chs {
+				names = append(names, ch.Name)
+			}
+			require.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestScheduleNMustSchedule(t *testing.T) {
+	mustSchedule := func(ch *cmacme.Challenge) {
+		ch.Annotations = map[string]string{cmacme.ACMEChallengeMustScheduleAnnotationKey: "true"}
+	}
+	challenge := func(name string, ts int64, mods ...gen.ChallengeModifier) *cmacme.Challenge {
+		mods = append([]gen.ChallengeModifier{
+			gen.SetChallengeDNSName(name + ".example.com"),
+			gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+			withCreationTimestamp(ts),
+		}, mods...)
+		return gen.Challenge(name, mods...)
+	}
+
+	tests := []struct {
+		name       string
+		n          int
+		limits     Limits
+		challenges []*cmacme.Challenge
+		expected   []string
+	}{
+		{
+			name:   "a must-schedule challenge is scheduled when the normal budget is exhausted",
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 3, ReservedMustScheduleChallenges: 1},
+			challenges: []*cmacme.Challenge{
+				challenge("processing-1", 0, gen.SetChallengeProcessing(true)),
+				challenge("processing-2", 1, gen.SetChallengeProcessing(true)),
+				challenge("normal-1", 2),
+				challenge("urgent-1", 3, mustSchedule),
+			},
+			expected: []string{"urgent-1"},
+		},
+		{
+			name:   "must-schedule challenges are scheduled ahead of older challenges",
+			n:      2,
+			limits: Limits{MaxConcurrentChallenges: 10, ReservedMustScheduleChallenges: 1},
+			challenges: []*cmacme.Challenge{
+				challenge("normal-1", 0),
+				challenge("normal-2", 1),
+				challenge("urgent-1", 2, mustSchedule),
+			},
+			expected: []string{"urgent-1", "normal-1"},
+		},
+		{
+			name:   "challenges that are not must-schedule cannot use the reserved budget",
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 3, ReservedMustScheduleChallenges: 1},
+			challenges: []*cmacme.Challenge{
+				challenge("normal-1", 0),
+				challenge("normal-2", 1),
+				challenge("normal-3", 2),
+			},
+			expected: []string{"normal-1", "normal-2"},
+		},
+		{
+			name:   "must-schedule challenges are still limited by the global limit",
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 2, ReservedMustScheduleChallenges: 1},
+			challenges: []*cmacme.Challenge{
+				challenge("processing-1", 0, gen.SetChallengeProcessing(true)),
+				challenge("processing-2", 1, gen.SetChallengeProcessing(true), mustSchedule),
+				challenge("urgent-1", 2, mustSchedule),
+			},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cl := fake.NewSimpleClientset()
+			factory := cminformers.NewSharedInformerFactory(cl, 0)
+			challengesInformer := factory.Acme().V1().Challenges()
+			for _, ch := range test.challenges {
+				err := challengesInformer.Informer().GetIndexer().Add(ch)
+				require.NoError(t, err)
+			}
+
+			s := New(context.Background(), challengesInformer.Lister(), test.limits)
+
+			chs, err := s.ScheduleN(test.n)
+			require.NoError(t, err)
+			var names []string
+			for _, ch := range chs {
+				names = append(names, ch.Name)
+			}
+			require.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestScheduleNMaintenanceWindow(t *testing.T) {
+	mustSchedule := func(ch *cmacme.Challenge) {
+		ch.Annotations = map[string]string{cmacme.ACMEChallengeMustScheduleAnnotationKey: "true"}
+	}
+	challenge := func(name string, ts int64, mods ...gen.ChallengeModifier) *cmacme.Challenge {
+		mods = append([]gen.ChallengeModifier{
+			gen.SetChallengeDNSName(name + ".example.com"),
+			gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+			withCreationTimestamp(ts),
+		}, mods...)
+		return gen.Challenge(name, mods...)
+	}
+	challenges := []*cmacme.Challenge{
+		challenge("normal-1", 0),
+		challenge("urgent-1", 1, mustSchedule),
+		challenge("normal-2", 2),
+	}
+	day := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		window   string
+		now      time.Time
+		expected []string
+	}{
+		{
+			name:     "all challenges are scheduled if there is no maintenance window",
+			now:      day.Add(time.Hour),
+			expected: []string{"urgent-1", "normal-1", "normal-2"},
+		},
+		{
+			name:     "only must-schedule challenges are scheduled within the window",
+			window:   "01:00-02:00",
+			now:      day.Add(time.Hour + 30*time.Minute),
+			expected: []string{"urgent-1"},
+		},
+		{
+			name:     "the window includes its start time",
+			window:   "01:00-02:00",
+			now:      day.Add(time.Hour),
+			expected: []string{"urgent-1"},
+		},
+		{
+			name:     "all challenges are scheduled at the end of the window",
+			window:   "01:00-02:00",
+			now:      day.Add(2 * time.Hour),
+			expected: []string{"urgent-1", "normal-1", "normal-2"},
+		},
+		{
+			name:     "all challenges are scheduled before the window",
+			window:   "01:00-02:00",
+			now:      day.Add(30 * time.Minute),
+			expected: []string{"urgent-1", "normal-1", "normal-2"},
+		},
+		{
+			name:     "a window spanning midnight applies before midnight",
+			window:   "23:00-01:30",
+			now:      day.Add(23*time.Hour + 30*time.Minute),
+			expected: []string{"urgent-1"},
+		},
+		{
+			name:     "a window spanning midnight applies after midnight",
+			window:   "23:00-01:30",
+			now:      day.Add(time.Hour),
+			expected: []string{"urgent-1"},
+		},
+		{
+			name:     "a window spanning midnight does not apply during the day",
+			window:   "23:00-01:30",
+			now:      day.Add(12 * time.Hour),
+			expected: []string{"urgent-1", "normal-1", "normal-2"},
+		},
+		{
+			name:     "the window is evaluated in UTC",
+			window:   "01:00-02:00",
+			now:      day.Add(time.Hour + 30*time.Minute).In(time.FixedZone("UTC+5", 5*60*60)),
+			expected: []string{"urgent-1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cl := fake.NewSimpleClientset()
+			factory := cminformers.NewSharedInformerFactory(cl, 0)
+			challengesInformer := factory.Acme().V1().Challenges()
+			for _, ch := range challenges {
+				err := challengesInformer.Informer().GetIndexer().Add(ch)
+				require.NoError(t, err)
+			}
+
+			limits := Limits{MaxConcurrentChallenges: 10}
+			if test.window != "" {
+				window, err := ParseMaintenanceWindow(test.window)
+				require.NoError(t, err)
+				limits.MaintenanceWindow = window
+			}
+			s := New(context.Background(), challengesInformer.Lister(), limits)
+			s.clock = fakeclock.NewFakeClock(test.now)
+
+			chs, err := s.ScheduleN(10)
+			require.NoError(t, err)
+			var names []string
+			for _, ch := range chs {
+				names = append(names, ch.Name)
+			}
+			require.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	tests := map[string]struct {
+		window   string
+		expected *MaintenanceWindow
+		err      bool
+	}{
+		"a window within a day": {
+			window:   "01:00-02:30",
+			expected: &MaintenanceWindow{Start: time.Hour, End: 2*time.Hour + 30*time.Minute},
+		},
+		"a window spanning midnight": {
+			window:   "23:00-01:00",
+			expected: &MaintenanceWindow{Start: 23 * time.Hour, End: time.Hour},
+		},
+		"a window without an end": {
+			window: "01:00",
+			err:    true,
+		},
+		"a window with an invalid time": {
+			window: "01:00-25:00",
+			err:    true,
+		},
+		"a window that starts and ends at the same time": {
+			window: "01:00-01:00",
+			err:    true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			window, err := ParseMaintenanceWindow(test.window)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, window)
+		})
+	}
+}
+
+func TestSelectChallenges(t *testing.T) {
+	challenge := func(name, dnsName string, ts int64, mods ...gen.ChallengeModifier) *cmacme.Challenge {
+		mods = append([]gen.ChallengeModifier{
+			gen.SetChallengeDNSName(dnsName),
+			gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+			withCreationTimestamp(ts),
+		}, mods...)
+		return gen.Challenge(name, mods...)
+	}
+
+	tests := map[string]struct {
+		n          int
+		limits     Limits
+		challenges []*cmacme.Challenge
+		expected   []string
+	}{
+		"nil input selects nothing": {
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 10},
+		},
+		"empty input selects nothing": {
+			n:          5,
+			limits:     Limits{MaxConcurrentChallenges: 10},
+			challenges: []*cmacme.Challenge{},
+		},
+		"n of zero selects nothing": {
+			n:      0,
+			limits: Limits{MaxConcurrentChallenges: 10},
+			challenges: []*cmacme.Challenge{
+				challenge("a", "a.example.com", 0),
+			},
+		},
+		"challenges with the same creation timestamp are selected in order of DNS name": {
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 10},
+			challenges: []*cmacme.Challenge{
+				challenge("c", "c.example.com", 0),
+				challenge("a", "a.example.com", 0),
+				challenge("b", "b.example.com", 0),
+			},
+			expected: []string{"a", "b", "c"},
+		},
+		"challenges with the same creation timestamp and DNS name are selected in order of type": {
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 10},
+			challenges: []*cmacme.Challenge{
+				challenge("http", "example.com", 0),
+				challenge("dns", "example.com", 0, gen.SetChallengeType(cmacme.ACMEChallengeTypeDNS01)),
+			},
+			expected: []string{"dns", "http"},
+		},
+		"older challenges are selected before newer ones regardless of DNS name": {
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 10},
+			challenges: []*cmacme.Challenge{
+				challenge("a", "a.example.com", 2),
+				challenge("b", "b.example.com", 1),
+				challenge("c", "c.example.com", 0),
+			},
+			expected: []string{"c", "b", "a"},
+		},
+		"only the oldest of several challenges for the same DNS name and type is selected": {
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 10},
+			challenges: []*cmacme.Challenge{
+				challenge("newer", "example.com", 1),
+				challenge("older", "example.com", 0),
+			},
+			expected: []string{"older"},
+		},
+		"the number of challenges selected is capped by the remaining concurrency": {
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 3},
+			challenges: []*cmacme.Challenge{
+				challenge("processing", "processing.example.com", 0, gen.SetChallengeProcessing(true)),
+				challenge("a", "a.example.com", 1),
+				challenge("b", "b.example.com", 2),
+				challenge("c", "c.example.com", 3),
+			},
+			expected: []string{"a", "b"},
+		},
+		"nothing is selected if the concurrency cap has been reached": {
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 1},
+			challenges: []*cmacme.Challenge{
+				challenge("processing", "processing.example.com", 0, gen.SetChallengeProcessing(true)),
+				challenge("a", "a.example.com", 1),
+			},
+		},
+		"nothing is selected if more challenges than the concurrency cap are processing": {
+			n:      5,
+			limits: Limits{MaxConcurrentChallenges: 1},
+			challenges: []*cmacme.Challenge{
+				challenge("processing-1", "processing-1.example.com", 0, gen.SetChallengeProcessing(true)),
+				challenge("processing-2", "processing-2.example.com", 1, gen.SetChallengeProcessing(true)),
+				challenge("a", "a.example.com", 2),
+			},
+		},
+		"the number of challenges selected is capped by n": {
+			n:      2,
+			limits: Limits{MaxConcurrentChallenges: 10},
+			challenges: []*cmacme.Challenge{
+				challenge("a", "a.example.com", 0),
+				challenge("b", "b.example.com", 1),
+				challenge("c", "c.example.com", 2),
+			},
+			expected: []string{"a", "b"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := make([]*cmacme.Challenge, len(test.challenges))
+			copy(input, test.challenges)
+
+			chs := SelectChallenges(logr.Discard(), test.challenges, test.n, test.limits)
+			var names []string
+			for _, ch := range chs {
+				names = append(names, ch.Name)
+			}
+			require.Equal(t, test.expected, names)
+			for i := range input {
+				require.Same(t, input[i], test.challenges[i], "the input should not be reordered")
+			}
+		})
+	}
+}
+
+func TestIssuerKey(t *testing.T) {
+	clusterIssuerChallenge := gen.Challenge("test",
+		gen.SetChallengeNamespace("ns"),
+		gen.SetChallengeIssuer(cmmeta.ObjectReference{Name: "letsencrypt", Kind: "ClusterIssuer"}))
+	require.Equal(t, "ClusterIssuer/letsencrypt", IssuerKey(clusterIssuerChallenge))
+
+	issuerChallenge := gen.Challenge("test",
+		gen.SetChallengeNamespace("ns"),
+		gen.SetChallengeIssuer(cmmeta.ObjectReference{Name: "letsencrypt", Kind: "Issuer"}))
+	require.Equal(t, "Issuer/ns/letsencrypt", IssuerKey(issuerChallenge))
+
+	defaultKindChallenge := gen.Challenge("test",
+		gen.SetChallengeNamespace("ns"),
+		gen.SetChallengeIssuer(cmmeta.ObjectReference{Name: "letsencrypt"}))
+	require.Equal(t, "Issuer/ns/letsencrypt", IssuerKey(defaultKindChallenge))
+}
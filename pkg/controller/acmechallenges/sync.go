@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	acmeapi "golang.org/x/crypto/acme"
 	corev1 "k8s.io/api/core/v1"
@@ -38,12 +39,43 @@ import (
 	utilfeature "github.com/cert-manager/cert-manager/pkg/util/feature"
 )
 
+// Event reasons recorded on Challenge resources. Each meaningful transition
+// in a Challenge's lifecycle is recorded with its own reason.
 const (
+	// reasonStarted is recorded when a Challenge is scheduled for processing.
+	reasonStarted = "Started"
+	// reasonCAACheckFailed is recorded when the CAA records for the domain
+	// do not allow the ACME server to issue certificates for it.
+	reasonCAACheckFailed = "CAACheckFailed"
+	// reasonPresentError is recorded when presenting the Challenge fails.
+	reasonPresentError = "PresentError"
+	// reasonPresentFailed is recorded when presenting the Challenge fails
+	// with an error that retrying will not resolve, e.g. because the DNS
+	// provider's credentials are invalid, and the Challenge is failed.
+	reasonPresentFailed = "PresentFailed"
+	// reasonPresented is recorded once the Challenge has been presented.
+	reasonPresented = "Presented"
+	// reasonSelfCheckPassed is recorded once the propagation self check has
+	// passed and the Challenge is about to be accepted.
+	reasonSelfCheckPassed = "SelfCheckPassed"
+	// reasonAcceptError is recorded when the ACME server returns an error
+	// when accepting the Challenge.
+	reasonAcceptError = "AcceptError"
+	// reasonDomainVerified is recorded once the ACME server has validated
+	// the Challenge.
 	reasonDomainVerified = "DomainVerified"
-	reasonCleanUpError   = "CleanUpError"
-	reasonPresentError   = "PresentError"
-	reasonPresented      = "Presented"
-	reasonFailed         = "Failed"
+	// reasonFailed is recorded when the ACME server has failed to validate
+	// the Challenge.
+	reasonFailed = "Failed"
+	// reasonCleanedUp is recorded once a Challenge in a final state has been
+	// cleaned up.
+	reasonCleanedUp = "CleanedUp"
+	// reasonCleanUpError is recorded when cleaning up the Challenge fails.
+	reasonCleanUpError = "CleanUpError"
+	// reasonProcessingTooLong is recorded when a Challenge has been
+	// processing for longer than the configured threshold without reaching
+	// a final state.
+	reasonProcessingTooLong = "ProcessingTooLong"
 )
 
 // solver solves ACME challenges by presenting the given token and key in an
@@ -88,6 +120,8 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 		return nil
 	}
 
+	c.checkProcessingDuration(ch)
+
 	genericIssuer, err := c.helper.GetGenericIssuer(ch.Spec.IssuerRef, ch.Namespace)
 	if err != nil {
 		return fmt.Errorf("error reading (cluster)issuer %q: %v", ch.Spec.IssuerRef.Name, err)
@@ -112,6 +146,7 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 			}
 
 			ch.Status.Presented = false
+			c.recorder.Eventf(ch, corev1.EventTypeNormal, reasonCleanedUp, "Cleaned up %s challenge in %s state", ch.Spec.Type, ch.Status.State)
 		}
 
 		ch.Status.Processing = false
@@ -119,6 +154,24 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 		return nil
 	}
 
+	// if the issuer is under maintenance, skip any work involving the ACME
+	// server or the solvers and check the challenge again later rather than
+	// failing and applying back-off. Challenges in a final state are still
+	// cleaned up above, so that they stop counting towards the scheduler's
+	// concurrency limits.
+	if issuerUnderMaintenance(genericIssuer) {
+		key, err := controllerpkg.KeyFunc(ch)
+		// This is an unexpected edge case and should never occur
+		if err != nil {
+			return err
+		}
+
+		log.V(logf.DebugLevel).Info("issuer is under maintenance, skipping challenge processing", "retry_after", c.issuerMaintenanceRetryPeriod)
+		c.queue.AddAfter(key, c.issuerMaintenanceRetryPeriod)
+
+		return nil
+	}
+
 	cl, err := c.accountRegistry.GetClient(string(genericIssuer.GetUID()))
 	if err != nil {
 		return err
@@ -158,6 +211,7 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 		if len(dir.CAA) != 0 {
 			err := dnsutil.ValidateCAA(ch.Spec.DNSName, dir.CAA, ch.Spec.Wildcard, c.dns01Nameservers)
 			if err != nil {
+				c.recorder.Eventf(ch, corev1.EventTypeWarning, reasonCAACheckFailed, "CAA self-check failed: %v", err)
 				ch.Status.Reason = fmt.Sprintf("CAA self-check failed: %s", err)
 				return err
 			}
@@ -169,8 +223,17 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 		return err
 	}
 
+	presentedNow := false
 	if !ch.Status.Presented {
 		err := solver.Present(ctx, genericIssuer, ch)
+		if permanentErr, ok := dnsutil.AsPermanentError(err); ok {
+			// fail the challenge straight away rather than retrying with
+			// back-off, as retrying will not resolve the error.
+			c.recorder.Eventf(ch, corev1.EventTypeWarning, reasonPresentFailed, "Error presenting challenge (%s), not retrying: %v", permanentErr.Reason, err)
+			ch.Status.State = cmacme.Errored
+			ch.Status.Reason = fmt.Sprintf("Error presenting challenge (%s): %v", permanentErr.Reason, err)
+			return nil
+		}
 		if err != nil {
 			c.recorder.Eventf(ch, corev1.EventTypeWarning, reasonPresentError, "Error presenting challenge: %v", err)
 			ch.Status.Reason = err.Error()
@@ -178,6 +241,7 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 		}
 
 		ch.Status.Presented = true
+		presentedNow = true
 		c.recorder.Eventf(ch, corev1.EventTypeNormal, reasonPresented, "Presented challenge using %s challenge mechanism", ch.Spec.Type)
 	}
 
@@ -192,11 +256,13 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 			return err
 		}
 
-		c.queue.AddAfter(key, c.DNS01CheckRetryPeriod)
+		c.queue.AddAfter(key, c.propagationCheckRetryPeriod(ctx, genericIssuer, presentedNow))
 
 		return nil
 	}
 
+	c.recorder.Eventf(ch, corev1.EventTypeNormal, reasonSelfCheckPassed, "Self check passed for %s challenge, accepting challenge", ch.Spec.Type)
+
 	err = c.acceptChallenge(ctx, cl, ch)
 	if err != nil {
 		return err
@@ -205,6 +271,51 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 	return nil
 }
 
+// checkProcessingDuration records the time at which the challenge was first
+// observed processing, and records a Warning event if it has since been
+// processing for longer than the configured threshold without reaching a
+// final state. Nothing is recorded if no threshold is configured.
+// The event's message does not change between syncs, so that the event
+// recorder aggregates the repeated warnings of a stuck challenge into a
+// single event.
+func (c *controller) checkProcessingDuration(ch *cmacme.Challenge) {
+	if c.processingWarningThreshold <= 0 || acme.IsFinalState(ch.Status.State) {
+		return
+	}
+
+	now := c.clock.Now()
+	if ch.Status.ProcessingStartTime == nil {
+		startTime := metav1.NewTime(now)
+		ch.Status.ProcessingStartTime = &startTime
+		return
+	}
+
+	if now.Sub(ch.Status.ProcessingStartTime.Time) > c.processingWarningThreshold {
+		c.recorder.Eventf(ch, corev1.EventTypeWarning, reasonProcessingTooLong, "Challenge has been processing for longer than %s without reaching a final state", c.processingWarningThreshold)
+	}
+}
+
+// propagationCheckRetryPeriod returns the time to wait before checking the
+// propagation of a challenge again after a failed check. Propagation is
+// often not complete when the first check is made straight after presenting
+// the challenge, so that check is retried after the (shorter) initial delay
+// rather than the full retry period. The retry period can be overridden per
+// issuer using the IssuerDNS01CheckRetryPeriodAnnotationKey annotation.
+func (c *controller) propagationCheckRetryPeriod(ctx context.Context, issuer cmapi.GenericIssuer, presentedNow bool) time.Duration {
+	retryPeriod := c.DNS01CheckRetryPeriod
+	override, err := issuerDNS01CheckRetryPeriod(issuer)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "ignoring issuer DNS01 check retry period override", "retry_period", retryPeriod)
+	} else if override > 0 {
+		retryPeriod = override
+	}
+
+	if presentedNow && c.DNS01CheckInitialDelay < retryPeriod {
+		return c.DNS01CheckInitialDelay
+	}
+	return retryPeriod
+}
+
 // handleError will handle ACME error types, updating the challenge resource
 // with any new information found whilst inspecting the error response.
 // This may include marking the challenge as expired.
@@ -376,6 +487,7 @@ func (c *controller) acceptChallenge(ctx context.Context, cl acmecl.Interface, c
 	}
 	if err != nil {
 		log.Error(err, "error accepting challenge")
+		c.recorder.Eventf(ch, corev1.EventTypeWarning, reasonAcceptError, "Error accepting challenge: %v", err)
 		ch.Status.Reason = fmt.Sprintf("Error accepting challenge: %v", err)
 		return handleError(ch, err)
 	}
@@ -19,11 +19,16 @@ package acmechallenges
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	acmeapi "golang.org/x/crypto/acme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/workqueue"
+	fakeclock "k8s.io/utils/clock/testing"
 
 	accountstest "github.com/cert-manager/cert-manager/pkg/acme/accounts/test"
 	acmecl "github.com/cert-manager/cert-manager/pkg/acme/client"
@@ -32,6 +37,7 @@ import (
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	testpkg "github.com/cert-manager/cert-manager/pkg/controller/test"
 	"github.com/cert-manager/cert-manager/pkg/issuer"
+	dnsutil "github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/util"
 	"github.com/cert-manager/cert-manager/test/unit/gen"
 )
 
@@ -86,6 +92,66 @@ func TestSyncHappyPath(t *testing.T) {
 	)
 
 	tests := map[string]testT{
+		"if the issuer is under maintenance, skip processing the challenge": {
+			challenge: gen.ChallengeFrom(baseChallenge,
+				gen.SetChallengeProcessing(true),
+				gen.SetChallengeURL("testurl"),
+			),
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{gen.ChallengeFrom(baseChallenge,
+					gen.SetChallengeProcessing(true),
+					gen.SetChallengeURL("testurl"),
+				), gen.IssuerFrom(testIssuerHTTP01Enabled,
+					gen.AddIssuerAnnotations(map[string]string{cmacme.IssuerMaintenanceAnnotationKey: "true"}),
+				)},
+				ExpectedActions: []testpkg.Action{},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeGetAuthorization: func(ctx context.Context, url string) (*acmeapi.Authorization, error) {
+					return nil, fmt.Errorf("unexpected call to GetAuthorization")
+				},
+			},
+		},
+		"if the issuer is under maintenance, still mark the challenge as not processing if it is already valid": {
+			challenge: gen.ChallengeFrom(baseChallenge,
+				gen.SetChallengeProcessing(true),
+				gen.SetChallengeURL("testurl"),
+				gen.SetChallengeState(cmacme.Valid),
+				gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+				gen.SetChallengePresented(true),
+			),
+			httpSolver: &fakeSolver{
+				fakeCleanUp: func(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
+					return nil
+				},
+			},
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{gen.ChallengeFrom(baseChallenge,
+					gen.SetChallengeProcessing(true),
+					gen.SetChallengeURL("testurl"),
+					gen.SetChallengeState(cmacme.Valid),
+					gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+					gen.SetChallengePresented(true),
+				), gen.IssuerFrom(testIssuerHTTP01Enabled,
+					gen.AddIssuerAnnotations(map[string]string{cmacme.IssuerMaintenanceAnnotationKey: "true"}),
+				)},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(baseChallenge,
+							gen.SetChallengeProcessing(false),
+							gen.SetChallengeURL("testurl"),
+							gen.SetChallengeState(cmacme.Valid),
+							gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+							gen.SetChallengePresented(false),
+						))),
+				},
+				ExpectedEvents: []string{
+					"Normal CleanedUp Cleaned up HTTP-01 challenge in valid state",
+				},
+			},
+		},
 		"if GetAuthorization doesn't return challenge, error": {
 			challenge: gen.ChallengeFrom(baseChallenge,
 				gen.SetChallengeProcessing(true),
@@ -254,6 +320,7 @@ func TestSyncHappyPath(t *testing.T) {
 						))),
 				},
 				ExpectedEvents: []string{
+					"Normal SelfCheckPassed Self check passed for HTTP-01 challenge, accepting challenge",
 					`Normal DomainVerified Domain "test.com" verified with "HTTP-01" validation`,
 				},
 			},
@@ -307,6 +374,7 @@ func TestSyncHappyPath(t *testing.T) {
 						))),
 				},
 				ExpectedEvents: []string{
+					"Normal SelfCheckPassed Self check passed for HTTP-01 challenge, accepting challenge",
 					"Warning Failed Accepting challenge authorization failed: acme: authorization error for example.com: an error happened",
 				},
 			},
@@ -366,6 +434,7 @@ func TestSyncHappyPath(t *testing.T) {
 						))),
 				},
 				ExpectedEvents: []string{
+					"Normal SelfCheckPassed Self check passed for HTTP-01 challenge, accepting challenge",
 					"Warning Failed Accepting challenge authorization failed: acme: authorization error for example.com: 400 fakeerror: this is a very detailed error",
 				},
 			},
@@ -391,6 +460,55 @@ func TestSyncHappyPath(t *testing.T) {
 				},
 			},
 		},
+		"record an AcceptError event if accepting the challenge fails": {
+			challenge: gen.ChallengeFrom(baseChallenge,
+				gen.SetChallengeProcessing(true),
+				gen.SetChallengeURL("testurl"),
+				gen.SetChallengeDNSName("test.com"),
+				gen.SetChallengeState(cmacme.Pending),
+				gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+				gen.SetChallengePresented(true),
+			),
+			httpSolver: &fakeSolver{
+				fakeCheck: func(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
+					return nil
+				},
+			},
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{gen.ChallengeFrom(baseChallenge,
+					gen.SetChallengeProcessing(true),
+					gen.SetChallengeURL("testurl"),
+					gen.SetChallengeDNSName("test.com"),
+					gen.SetChallengeState(cmacme.Pending),
+					gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+					gen.SetChallengePresented(true),
+				), testIssuerHTTP01Enabled},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(baseChallenge,
+							gen.SetChallengeProcessing(true),
+							gen.SetChallengeURL("testurl"),
+							gen.SetChallengeDNSName("test.com"),
+							gen.SetChallengeState(cmacme.Pending),
+							gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+							gen.SetChallengePresented(true),
+							gen.SetChallengeReason("Error accepting challenge: an error happened"),
+						))),
+				},
+				ExpectedEvents: []string{
+					"Normal SelfCheckPassed Self check passed for HTTP-01 challenge, accepting challenge",
+					"Warning AcceptError Error accepting challenge: an error happened",
+				},
+			},
+			expectErr: true,
+			acmeClient: &acmecl.FakeACME{
+				FakeAccept: func(context.Context, *acmeapi.Challenge) (*acmeapi.Challenge, error) {
+					return nil, fmt.Errorf("an error happened")
+				},
+			},
+		},
 		"mark the challenge as not processing if it is already valid": {
 			challenge: gen.ChallengeFrom(baseChallenge,
 				gen.SetChallengeProcessing(true),
@@ -424,6 +542,9 @@ func TestSyncHappyPath(t *testing.T) {
 							gen.SetChallengePresented(false),
 						))),
 				},
+				ExpectedEvents: []string{
+					"Normal CleanedUp Cleaned up HTTP-01 challenge in valid state",
+				},
 			},
 		},
 		"mark the challenge as not processing if it is already failed": {
@@ -459,6 +580,9 @@ func TestSyncHappyPath(t *testing.T) {
 							gen.SetChallengePresented(false),
 						))),
 				},
+				ExpectedEvents: []string{
+					"Normal CleanedUp Cleaned up HTTP-01 challenge in invalid state",
+				},
 			},
 		},
 	}
@@ -500,3 +624,324 @@ func runTest(t *testing.T, test testT) {
 
 	test.builder.CheckAndFinish(err)
 }
+
+// recordingQueue records the durations passed to AddAfter.
+type recordingQueue struct {
+	workqueue.RateLimitingInterface
+	addedAfter []time.Duration
+}
+
+func (q *recordingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.addedAfter = append(q.addedAfter, duration)
+}
+
+func TestSyncPropagationCheckDelay(t *testing.T) {
+	testIssuer := gen.Issuer("testissuer", gen.SetIssuerACME(cmacme.ACMEIssuer{
+		Solvers: []cmacme.ACMEChallengeSolver{
+			{DNS01: &cmacme.ACMEChallengeSolverDNS01{}},
+		},
+	}))
+	baseChallenge := gen.Challenge("testchal",
+		gen.SetChallengeIssuer(cmmeta.ObjectReference{Name: "testissuer"}),
+		gen.SetChallengeProcessing(true),
+		gen.SetChallengeURL("testurl"),
+		gen.SetChallengeState(cmacme.Pending),
+		gen.SetChallengeType(cmacme.ACMEChallengeTypeDNS01),
+	)
+
+	annotatedIssuer := func(period string) *v1.Issuer {
+		return gen.IssuerFrom(testIssuer, gen.AddIssuerAnnotations(map[string]string{
+			cmacme.IssuerDNS01CheckRetryPeriodAnnotationKey: period,
+		}))
+	}
+
+	tests := map[string]struct {
+		challenge    *cmacme.Challenge
+		issuer       *v1.Issuer
+		initialDelay time.Duration
+		expected     time.Duration
+	}{
+		"first check after presenting the challenge is retried after the initial delay": {
+			challenge:    baseChallenge,
+			initialDelay: time.Second * 2,
+			expected:     time.Second * 2,
+		},
+		"subsequent checks are retried after the retry period": {
+			challenge:    gen.ChallengeFrom(baseChallenge, gen.SetChallengePresented(true)),
+			initialDelay: time.Second * 2,
+			expected:     time.Second * 10,
+		},
+		"initial delay longer than the retry period falls back to the retry period": {
+			challenge:    baseChallenge,
+			initialDelay: time.Minute,
+			expected:     time.Second * 10,
+		},
+		"issuer annotation overrides the retry period": {
+			challenge:    gen.ChallengeFrom(baseChallenge, gen.SetChallengePresented(true)),
+			issuer:       annotatedIssuer("45s"),
+			initialDelay: time.Second * 2,
+			expected:     time.Second * 45,
+		},
+		"initial delay longer than the overridden retry period falls back to the overridden retry period": {
+			challenge:    baseChallenge,
+			issuer:       annotatedIssuer("1s"),
+			initialDelay: time.Second * 2,
+			expected:     time.Second,
+		},
+		"invalid issuer annotation falls back to the default retry period": {
+			challenge:    gen.ChallengeFrom(baseChallenge, gen.SetChallengePresented(true)),
+			issuer:       annotatedIssuer("soon"),
+			initialDelay: time.Second * 2,
+			expected:     time.Second * 10,
+		},
+		"non-positive issuer annotation falls back to the default retry period": {
+			challenge:    gen.ChallengeFrom(baseChallenge, gen.SetChallengePresented(true)),
+			issuer:       annotatedIssuer("0s"),
+			initialDelay: time.Second * 2,
+			expected:     time.Second * 10,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			iss := test.issuer
+			if iss == nil {
+				iss = testIssuer
+			}
+			builder := &testpkg.Builder{
+				T:                  t,
+				CertManagerObjects: []runtime.Object{test.challenge, iss},
+			}
+			builder.Init()
+			defer builder.Stop()
+
+			c := &controller{}
+			c.Register(builder.Context)
+			queue := &recordingQueue{RateLimitingInterface: c.queue}
+			c.queue = queue
+			c.DNS01CheckRetryPeriod = time.Second * 10
+			c.DNS01CheckInitialDelay = test.initialDelay
+			c.helper = issuer.NewHelper(
+				builder.SharedInformerFactory.Certmanager().V1().Issuers().Lister(),
+				builder.SharedInformerFactory.Certmanager().V1().ClusterIssuers().Lister(),
+			)
+			c.accountRegistry = &accountstest.FakeRegistry{
+				GetClientFunc: func(_ string) (acmecl.Interface, error) {
+					return &acmecl.FakeACME{}, nil
+				},
+			}
+			c.dnsSolver = &fakeSolver{
+				fakePresent: func(context.Context, v1.GenericIssuer, *cmacme.Challenge) error {
+					return nil
+				},
+				fakeCheck: func(context.Context, v1.GenericIssuer, *cmacme.Challenge) error {
+					return fmt.Errorf("record not yet propagated")
+				},
+			}
+			builder.Start()
+
+			if err := c.Sync(context.Background(), test.challenge); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(queue.addedAfter) != 1 || queue.addedAfter[0] != test.expected {
+				t.Errorf("expected challenge to be requeued after %s, got %v", test.expected, queue.addedAfter)
+			}
+		})
+	}
+}
+
+func TestSyncProcessingWarning(t *testing.T) {
+	now := time.Now()
+	testIssuer := gen.Issuer("testissuer", gen.SetIssuerACME(cmacme.ACMEIssuer{
+		Solvers: []cmacme.ACMEChallengeSolver{
+			{DNS01: &cmacme.ACMEChallengeSolverDNS01{}},
+		},
+	}))
+	baseChallenge := gen.Challenge("testchal",
+		gen.SetChallengeIssuer(cmmeta.ObjectReference{Name: "testissuer"}),
+		gen.SetChallengeProcessing(true),
+		gen.SetChallengePresented(true),
+		gen.SetChallengeURL("testurl"),
+		gen.SetChallengeState(cmacme.Pending),
+		gen.SetChallengeType(cmacme.ACMEChallengeTypeDNS01),
+	)
+	warning := "Warning ProcessingTooLong Challenge has been processing for longer than 30m0s without reaching a final state"
+
+	tests := map[string]struct {
+		challenge         *cmacme.Challenge
+		threshold         time.Duration
+		expectedStartTime *metav1.Time
+		expectedEvents    []string
+	}{
+		"record the processing start time when it is first observed": {
+			challenge:         baseChallenge,
+			threshold:         time.Minute * 30,
+			expectedStartTime: &metav1.Time{Time: now},
+		},
+		"do not record a warning before the threshold has passed": {
+			challenge:         gen.ChallengeFrom(baseChallenge, gen.SetChallengeProcessingStartTime(metav1.NewTime(now.Add(-time.Minute*15)))),
+			threshold:         time.Minute * 30,
+			expectedStartTime: &metav1.Time{Time: now.Add(-time.Minute * 15)},
+		},
+		"record a warning once the threshold has passed": {
+			challenge:         gen.ChallengeFrom(baseChallenge, gen.SetChallengeProcessingStartTime(metav1.NewTime(now.Add(-time.Minute*45)))),
+			threshold:         time.Minute * 30,
+			expectedStartTime: &metav1.Time{Time: now.Add(-time.Minute * 45)},
+			expectedEvents:    []string{warning},
+		},
+		"record the same warning however long the challenge has been processing": {
+			challenge:         gen.ChallengeFrom(baseChallenge, gen.SetChallengeProcessingStartTime(metav1.NewTime(now.Add(-time.Hour*3)))),
+			threshold:         time.Minute * 30,
+			expectedStartTime: &metav1.Time{Time: now.Add(-time.Hour * 3)},
+			expectedEvents:    []string{warning},
+		},
+		"do nothing if no threshold is configured": {
+			challenge:         gen.ChallengeFrom(baseChallenge, gen.SetChallengeProcessingStartTime(metav1.NewTime(now.Add(-time.Minute*45)))),
+			expectedStartTime: &metav1.Time{Time: now.Add(-time.Minute * 45)},
+		},
+		"do not record the processing start time if no threshold is configured": {
+			challenge: baseChallenge,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := &testpkg.Builder{
+				T:                  t,
+				Clock:              fakeclock.NewFakeClock(now),
+				CertManagerObjects: []runtime.Object{test.challenge, testIssuer},
+			}
+			builder.Init()
+			defer builder.Stop()
+
+			c := &controller{}
+			c.Register(builder.Context)
+			c.queue = &recordingQueue{RateLimitingInterface: c.queue}
+			c.processingWarningThreshold = test.threshold
+			c.helper = issuer.NewHelper(
+				builder.SharedInformerFactory.Certmanager().V1().Issuers().Lister(),
+				builder.SharedInformerFactory.Certmanager().V1().ClusterIssuers().Lister(),
+			)
+			c.accountRegistry = &accountstest.FakeRegistry{
+				GetClientFunc: func(_ string) (acmecl.Interface, error) {
+					return &acmecl.FakeACME{}, nil
+				},
+			}
+			c.dnsSolver = &fakeSolver{
+				fakeCheck: func(context.Context, v1.GenericIssuer, *cmacme.Challenge) error {
+					return fmt.Errorf("record not yet propagated")
+				},
+			}
+			builder.Start()
+
+			if err := c.Sync(context.Background(), test.challenge); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ch, err := builder.CMClient.AcmeV1().Challenges(test.challenge.Namespace).Get(context.Background(), test.challenge.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error getting challenge: %v", err)
+			}
+			if !ch.Status.ProcessingStartTime.Equal(test.expectedStartTime) {
+				t.Errorf("expected processing start time %v, got %v", test.expectedStartTime, ch.Status.ProcessingStartTime)
+			}
+			if events := builder.Events(); !reflect.DeepEqual(events, test.expectedEvents) {
+				t.Errorf("expected events %v, got %v", test.expectedEvents, events)
+			}
+		})
+	}
+}
+
+func TestSyncPresentErrorClassification(t *testing.T) {
+	testIssuer := gen.Issuer("testissuer", gen.SetIssuerACME(cmacme.ACMEIssuer{
+		Solvers: []cmacme.ACMEChallengeSolver{
+			{DNS01: &cmacme.ACMEChallengeSolverDNS01{}},
+		},
+	}))
+	challenge := gen.Challenge("testchal",
+		gen.SetChallengeIssuer(cmmeta.ObjectReference{Name: "testissuer"}),
+		gen.SetChallengeProcessing(true),
+		gen.SetChallengeURL("testurl"),
+		gen.SetChallengeState(cmacme.Pending),
+		gen.SetChallengeType(cmacme.ACMEChallengeTypeDNS01),
+	)
+
+	tests := map[string]struct {
+		presentErr     error
+		expectErr      bool
+		expectedState  cmacme.State
+		expectedReason string
+		expectedEvents []string
+	}{
+		"transient errors are retried": {
+			presentErr:     fmt.Errorf("Throttling: Rate exceeded"),
+			expectErr:      true,
+			expectedState:  cmacme.Pending,
+			expectedReason: "Throttling: Rate exceeded",
+			expectedEvents: []string{"Warning PresentError Error presenting challenge: Throttling: Rate exceeded"},
+		},
+		"invalid credentials fail the challenge": {
+			presentErr:     dnsutil.NewPermanentError(dnsutil.ReasonInvalidCredentials, fmt.Errorf("InvalidClientTokenId: The security token included in the request is invalid")),
+			expectedState:  cmacme.Errored,
+			expectedReason: "Error presenting challenge (InvalidCredentials): InvalidClientTokenId: The security token included in the request is invalid",
+			expectedEvents: []string{"Warning PresentFailed Error presenting challenge (InvalidCredentials), not retrying: InvalidClientTokenId: The security token included in the request is invalid"},
+		},
+		"a zone that is not found fails the challenge": {
+			presentErr:     fmt.Errorf("failed to determine hosted zone: %w", dnsutil.NewPermanentError(dnsutil.ReasonZoneNotFound, fmt.Errorf("zone example.com. not found"))),
+			expectedState:  cmacme.Errored,
+			expectedReason: "Error presenting challenge (ZoneNotFound): failed to determine hosted zone: zone example.com. not found",
+			expectedEvents: []string{"Warning PresentFailed Error presenting challenge (ZoneNotFound), not retrying: failed to determine hosted zone: zone example.com. not found"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := &testpkg.Builder{
+				T:                  t,
+				CertManagerObjects: []runtime.Object{challenge, testIssuer},
+			}
+			builder.Init()
+			defer builder.Stop()
+
+			c := &controller{}
+			c.Register(builder.Context)
+			c.helper = issuer.NewHelper(
+				builder.SharedInformerFactory.Certmanager().V1().Issuers().Lister(),
+				builder.SharedInformerFactory.Certmanager().V1().ClusterIssuers().Lister(),
+			)
+			c.accountRegistry = &accountstest.FakeRegistry{
+				GetClientFunc: func(_ string) (acmecl.Interface, error) {
+					return &acmecl.FakeACME{}, nil
+				},
+			}
+			c.dnsSolver = &fakeSolver{
+				fakePresent: func(context.Context, v1.GenericIssuer, *cmacme.Challenge) error {
+					return tc.presentErr
+				},
+			}
+			builder.Start()
+
+			err := c.Sync(context.Background(), challenge)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got: %v", tc.expectErr, err)
+			}
+
+			ch, err := builder.CMClient.AcmeV1().Challenges(challenge.Namespace).Get(context.Background(), challenge.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error getting challenge: %v", err)
+			}
+			if ch.Status.State != tc.expectedState {
+				t.Errorf("expected state %q, got %q", tc.expectedState, ch.Status.State)
+			}
+			if ch.Status.Reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, ch.Status.Reason)
+			}
+			if ch.Status.Presented {
+				t.Errorf("expected challenge not to be presented")
+			}
+			if events := builder.Events(); !reflect.DeepEqual(events, tc.expectedEvents) {
+				t.Errorf("expected events %v, got %v", tc.expectedEvents, events)
+			}
+		})
+	}
+}
@@ -21,7 +21,9 @@ import (
 	"context"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	acmeapi "golang.org/x/crypto/acme"
@@ -42,8 +44,9 @@ import (
 )
 
 const (
-	reasonSolver  = "Solver"
-	reasonCreated = "Created"
+	reasonSolver           = "Solver"
+	reasonNoMatchingSolver = "NoMatchingSolver"
+	reasonCreated          = "Created"
 )
 
 var (
@@ -121,9 +124,21 @@ func (c *controller) Sync(ctx context.Context, o *cmacme.Order) (err error) {
 	requiredChallenges, err := buildRequiredChallenges(ctx, cl, genericIssuer, o)
 	if err != nil {
 		log.Error(err, "Failed to determine the list of Challenge resources needed for the Order")
+		var noSolverErr *noMatchingSolverError
+		if errors.As(err, &noSolverErr) {
+			// Record the reason on the Order so that it does not appear to
+			// be stalled without explanation. The Order is not failed, as
+			// the issuer's solvers may be updated to match the DNS name.
+			o.Status.Reason = fmt.Sprintf("%s: %v", reasonNoMatchingSolver, err)
+			c.recorder.Eventf(o, corev1.EventTypeWarning, reasonNoMatchingSolver, "Failed to determine a valid solver configuration for the set of domains on the Order: %v", err)
+			return nil
+		}
 		c.recorder.Eventf(o, corev1.EventTypeWarning, reasonSolver, "Failed to determine a valid solver configuration for the set of domains on the Order: %v", err)
 		return nil
 	}
+	if strings.HasPrefix(o.Status.Reason, reasonNoMatchingSolver+":") {
+		o.Status.Reason = ""
+	}
 
 	dbg.Info("Determining if any challenge resources need to be created")
 	needToCreateChallenges, err := c.anyRequiredChallengesDoNotExist(requiredChallenges)
@@ -210,6 +210,13 @@ rUCGwbCUDI0mxadJ3Bz4WxR6fyNpBK2yAinWEsikxqEt
 	if err != nil {
 		t.Fatalf("error building Challenge resource test fixture: %v", err)
 	}
+	testOrderPendingMustSchedule := gen.OrderFrom(testOrderPending, gen.SetOrderAnnotations(map[string]string{
+		cmacme.ACMEChallengeMustScheduleAnnotationKey: "true",
+	}))
+	testAuthorizationChallengeMustSchedule := testAuthorizationChallenge.DeepCopy()
+	testAuthorizationChallengeMustSchedule.Annotations = map[string]string{
+		cmacme.ACMEChallengeMustScheduleAnnotationKey: "true",
+	}
 	testAuthorizationChallengeValid := testAuthorizationChallenge.DeepCopy()
 	testAuthorizationChallengeValid.Status.State = cmacme.Valid
 	testAuthorizationChallengeInvalid := testAuthorizationChallenge.DeepCopy()
@@ -330,6 +337,23 @@ rUCGwbCUDI0mxadJ3Bz4WxR6fyNpBK2yAinWEsikxqEt
 				},
 			},
 		},
+		"copy the must-schedule annotation from the order to the created challenge": {
+			order: testOrderPendingMustSchedule,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuerHTTP01TestCom, testOrderPendingMustSchedule},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewCreateAction(cmacme.SchemeGroupVersion.WithResource("challenges"), testAuthorizationChallengeMustSchedule.Namespace, testAuthorizationChallengeMustSchedule)),
+				},
+				ExpectedEvents: []string{
+					`Normal Created Created Challenge resource "testorder-2179654896" for domain "test.com"`,
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeHTTP01ChallengeResponse: func(s string) (string, error) {
+					return "key", nil
+				},
+			},
+		},
 		"create a challenge resource for the test.com dnsName on the order": {
 			order: testOrderPending,
 			builder: &testpkg.Builder{
@@ -370,12 +394,90 @@ rUCGwbCUDI0mxadJ3Bz4WxR6fyNpBK2yAinWEsikxqEt
 			builder: &testpkg.Builder{
 				CertManagerObjects: []runtime.Object{
 					testIssuerHTTP01TestCom,
+					testOrderPending,
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("orders"),
+						"status",
+						testOrderPending.Namespace,
+						gen.OrderFrom(testOrderPending, gen.SetOrderStatus(cmacme.OrderStatus{
+							State:       cmacme.Pending,
+							Reason:      `NoMatchingSolver: no configured challenge solvers can be used for the DNS name "test.com"`,
+							URL:         "http://testurl.com/abcde",
+							FinalizeURL: "http://testurl.com/abcde/finalize",
+							Authorizations: []cmacme.ACMEAuthorization{
+								{
+									URL:        "http://authzurl",
+									Identifier: "test.com",
+									Challenges: []cmacme.ACMEChallenge{
+										{
+											URL:   "http://chalurl",
+											Token: "token",
+											Type:  "unknown-type",
+										},
+									},
+								},
+							},
+						})))),
 				},
 				ExpectedEvents: []string{
 					// the 'unsupported challenge type' text is not printed here as the code that 'selects'
 					// a solver to use for a challenge filters out unsupported challenge types earlier
 					// in its selection routine.
-					`Warning Solver Failed to determine a valid solver configuration for the set of domains on the Order: no configured challenge solvers can be used for this challenge`,
+					`Warning NoMatchingSolver Failed to determine a valid solver configuration for the set of domains on the Order: no configured challenge solvers can be used for the DNS name "test.com"`,
+				},
+			},
+		},
+		"should report the DNS name if no solver selector matches it": {
+			order: gen.OrderFrom(testOrderPending, gen.SetOrderStatus(cmacme.OrderStatus{
+				State:       cmacme.Pending,
+				URL:         "http://testurl.com/abcde",
+				FinalizeURL: "http://testurl.com/abcde/finalize",
+				Authorizations: []cmacme.ACMEAuthorization{
+					{
+						URL:        "http://authzurl",
+						Identifier: "example.com",
+						Challenges: []cmacme.ACMEChallenge{
+							{
+								URL:   "http://chalurl",
+								Token: "token",
+								Type:  "http-01",
+							},
+						},
+					},
+				},
+			})),
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					testIssuerHTTP01TestCom,
+					testOrderPending,
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("orders"),
+						"status",
+						testOrderPending.Namespace,
+						gen.OrderFrom(testOrderPending, gen.SetOrderStatus(cmacme.OrderStatus{
+							State:       cmacme.Pending,
+							Reason:      `NoMatchingSolver: no configured challenge solvers can be used for the DNS name "example.com"`,
+							URL:         "http://testurl.com/abcde",
+							FinalizeURL: "http://testurl.com/abcde/finalize",
+							Authorizations: []cmacme.ACMEAuthorization{
+								{
+									URL:        "http://authzurl",
+									Identifier: "example.com",
+									Challenges: []cmacme.ACMEChallenge{
+										{
+											URL:   "http://chalurl",
+											Token: "token",
+											Type:  "http-01",
+										},
+									},
+								},
+							},
+						})))),
+				},
+				ExpectedEvents: []string{
+					`Warning NoMatchingSolver Failed to determine a valid solver configuration for the set of domains on the Order: no configured challenge solvers can be used for the DNS name "example.com"`,
 				},
 			},
 		},
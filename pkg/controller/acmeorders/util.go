@@ -35,6 +35,17 @@ var (
 	orderGvk = cmacme.SchemeGroupVersion.WithKind("Order")
 )
 
+// noMatchingSolverError is returned when none of the solvers configured on an
+// issuer can be used to solve the challenge for a DNS name, e.g. because no
+// solver's selector matches it.
+type noMatchingSolverError struct {
+	dnsName string
+}
+
+func (e *noMatchingSolverError) Error() string {
+	return fmt.Sprintf("no configured challenge solvers can be used for the DNS name %q", e.dnsName)
+}
+
 func buildRequiredChallenges(ctx context.Context, cl acmecl.Interface, issuer cmapi.GenericIssuer, o *cmacme.Order) ([]cmacme.Challenge, error) {
 	chs := make([]cmacme.Challenge, 0)
 	for _, a := range o.Status.Authorizations {
@@ -68,10 +79,18 @@ func buildChallenge(ctx context.Context, cl acmecl.Interface, issuer cmapi.Gener
 		return nil, err
 	}
 
+	// The must-schedule annotation is copied from the Certificate to the
+	// Order, and is used by the challenge scheduler.
+	var annotations map[string]string
+	if v, ok := o.Annotations[cmacme.ACMEChallengeMustScheduleAnnotationKey]; ok {
+		annotations = map[string]string{cmacme.ACMEChallengeMustScheduleAnnotationKey: v}
+	}
+
 	return &cmacme.Challenge{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            chName,
 			Namespace:       o.Namespace,
+			Annotations:     annotations,
 			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(o, orderGvk)},
 			Finalizers:      []string{cmacme.ACMEFinalizer},
 		},
@@ -248,7 +267,7 @@ func challengeSpecForAuthorization(ctx context.Context, cl acmecl.Interface, iss
 	}
 
 	if selectedSolver == nil || selectedChallenge == nil {
-		return nil, fmt.Errorf("no configured challenge solvers can be used for this challenge")
+		return nil, &noMatchingSolverError{dnsName: domainToFind}
 	}
 
 	// It should never be possible for this case to be hit as earlier in this
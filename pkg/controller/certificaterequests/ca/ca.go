@@ -121,6 +121,10 @@ func (c *CA) Sign(ctx context.Context, cr *cmapi.CertificateRequest, issuerObj c
 	template.CRLDistributionPoints = issuerObj.GetSpec().CA.CRLDistributionPoints
 	template.OCSPServer = issuerObj.GetSpec().CA.OCSPServers
 
+	if c.issuerOptions.CALimitDurationToCA && pki.LimitTemplateToIssuer(template, caCerts[0]) {
+		log.V(logf.InfoLevel).Info("Requested duration would outlive the issuer's CA certificate, limiting the certificate's notAfter to the CA's", "notAfter", template.NotAfter)
+	}
+
 	bundle, err := c.signingFn(caCerts, caKey, template)
 	if err != nil {
 		message := "Error signing certificate"
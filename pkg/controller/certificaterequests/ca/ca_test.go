@@ -476,11 +476,13 @@ func TestCA_Sign(t *testing.T) {
 	testCSR := generateCSR(t, testpk, x509.ECDSAWithSHA256)
 
 	tests := map[string]struct {
-		givenCASecret    *corev1.Secret
-		givenCAIssuer    cmapi.GenericIssuer
-		givenCR          *cmapi.CertificateRequest
-		assertSignedCert func(t *testing.T, got *x509.Certificate)
-		wantErr          string
+		givenCASecret *corev1.Secret
+		givenCAIssuer cmapi.GenericIssuer
+		givenCR       *cmapi.CertificateRequest
+		// limitDurationToCA sets IssuerOptions.CALimitDurationToCA.
+		limitDurationToCA bool
+		assertSignedCert  func(t *testing.T, got *x509.Certificate)
+		wantErr           string
 	}{
 		"when the CertificateRequest has the duration field set, it should appear as notAfter on the signed ca": {
 			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
@@ -523,6 +525,27 @@ func TestCA_Sign(t *testing.T) {
 				assert.LessOrEqualf(t, deltaSec, 1., "expected a time delta lower than 1 second. Time expected='%s', got='%s'", expectNotAfter.String(), got.NotAfter.String())
 			},
 		},
+		"when limiting the duration to the CA's and the CA expires before the requested duration, notAfter should be the CA's notAfter": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestDuration(&metav1.Duration{
+					Duration: 30 * time.Minute,
+				}),
+			),
+			limitDurationToCA: true,
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, rootCert.NotAfter, got.NotAfter)
+			},
+		},
 		"when the CertificateRequest has the isCA field set, it should appear on the signed ca": {
 			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
 			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
@@ -588,6 +611,7 @@ func TestCA_Sign(t *testing.T) {
 					ClusterResourceNamespace:        "",
 					ClusterIssuerAmbientCredentials: false,
 					IssuerAmbientCredentials:        false,
+					CALimitDurationToCA:             test.limitDurationToCA,
 				},
 				reporter: util.NewReporter(fixedClock, rec),
 				secretsLister: testlisters.FakeSecretListerFrom(testlisters.NewFakeSecretLister(),
@@ -22,6 +22,8 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
@@ -47,9 +49,71 @@ const (
 
 type signingFn func(*x509.Certificate, *x509.Certificate, crypto.PublicKey, interface{}) ([]byte, *x509.Certificate, error)
 
+// PrivateKeyLoader loads the private key named by a CertificateRequest's
+// private key annotation, which is used to sign the self-signed certificate.
+// Implementations may keep keys outside of Kubernetes, e.g. in a KMS, as long
+// as they can be used as a crypto.Signer. A NotFound error causes the request
+// to be retried later, and an InvalidData error marks it as pending.
+type PrivateKeyLoader interface {
+	LoadPrivateKey(ctx context.Context, namespace, name string) (crypto.Signer, error)
+}
+
+// defaultPrivateKeySecretKeys are the Secret entries that are tried in order
+// when the private key annotation does not name an entry.
+var defaultPrivateKeySecretKeys = []string{corev1.TLSPrivateKeyKey, "ca.key"}
+
+// missingKeyDataError is returned when none of the candidate entries are
+// present in the referenced Secret.
+type missingKeyDataError struct {
+	namespace, name string
+	keys            []string
+}
+
+func (e *missingKeyDataError) Error() string {
+	return fmt.Sprintf("none of the entries %q are present in secret '%s/%s'", e.keys, e.namespace, e.name)
+}
+
+// splitPrivateKeyRef splits the value of the private key annotation into the
+// Secret name and the entries within it that may hold the private key.
+func splitPrivateKeyRef(ref string) (string, []string) {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) == 2 && parts[1] != "" {
+		return parts[0], []string{parts[1]}
+	}
+	return parts[0], defaultPrivateKeySecretKeys
+}
+
+// secretKeyLoader loads private keys from Secrets. The name may be of the
+// form '<secret name>/<key>' to select the entry holding the key, otherwise
+// the defaultPrivateKeySecretKeys are tried in order.
+type secretKeyLoader struct {
+	secretsLister corelisters.SecretLister
+}
+
+func (l secretKeyLoader) LoadPrivateKey(ctx context.Context, namespace, ref string) (crypto.Signer, error) {
+	name, keys := splitPrivateKeyRef(ref)
+	secret, err := l.secretsLister.Secrets(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if _, ok := secret.Data[key]; !ok {
+			continue
+		}
+		pk, _, err := kube.ParseTLSKeyFromSecret(secret, key)
+		return pk, err
+	}
+
+	return nil, &missingKeyDataError{namespace: namespace, name: name, keys: keys}
+}
+
 type SelfSigned struct {
 	issuerOptions controllerpkg.IssuerOptions
-	secretsLister corelisters.SecretLister
+
+	// keyLoader loads the private key used to sign certificates. It defaults
+	// to loading the key from a Secret.
+	keyLoader PrivateKeyLoader
 
 	reporter *crutil.Reporter
 	recorder record.EventRecorder
@@ -70,13 +134,23 @@ func init() {
 func NewSelfSigned(ctx *controllerpkg.Context) certificaterequests.Issuer {
 	return &SelfSigned{
 		issuerOptions: ctx.IssuerOptions,
-		secretsLister: ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister(),
+		keyLoader:     secretKeyLoader{secretsLister: ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister()},
 		reporter:      crutil.NewReporter(ctx.Clock, ctx.Recorder),
 		recorder:      ctx.Recorder,
 		signingFn:     pki.SignCertificate,
 	}
 }
 
+// NewSelfSignedWithKeyLoader returns a constructor for a SelfSigned issuer
+// that loads private keys using the given loader instead of from Secrets.
+func NewSelfSignedWithKeyLoader(loader PrivateKeyLoader) func(*controllerpkg.Context) certificaterequests.Issuer {
+	return func(ctx *controllerpkg.Context) certificaterequests.Issuer {
+		s := NewSelfSigned(ctx).(*SelfSigned)
+		s.keyLoader = loader
+		return s
+	}
+}
+
 func (s *SelfSigned) Sign(ctx context.Context, cr *cmapi.CertificateRequest, issuerObj cmapi.GenericIssuer) (*issuer.IssueResponse, error) {
 	log := logf.FromContext(ctx, "sign")
 
@@ -94,9 +168,10 @@ func (s *SelfSigned) Sign(ctx context.Context, cr *cmapi.CertificateRequest, iss
 		return nil, nil
 	}
 
-	privatekey, err := kube.SecretTLSKey(ctx, s.secretsLister, cr.Namespace, secretName)
+	privatekey, err := s.keyLoader.LoadPrivateKey(ctx, cr.Namespace, secretName)
 	if k8sErrors.IsNotFound(err) {
-		message := fmt.Sprintf("Referenced secret %s/%s not found", cr.Namespace, secretName)
+		name, _ := splitPrivateKeyRef(secretName)
+		message := fmt.Sprintf("Referenced secret %s/%s not found", cr.Namespace, name)
 
 		s.reporter.Pending(cr, err, "MissingSecret", message)
 		log.Error(err, message)
@@ -104,6 +179,17 @@ func (s *SelfSigned) Sign(ctx context.Context, cr *cmapi.CertificateRequest, iss
 		return nil, nil
 	}
 
+	var missingErr *missingKeyDataError
+	if errors.As(err, &missingErr) {
+		message := fmt.Sprintf("Failed to find private key data for %q referenced in annotation %q",
+			secretName, cmapi.CertificateRequestPrivateKeyAnnotationKey)
+
+		s.reporter.Pending(cr, err, "MissingKeyData", message)
+		log.Error(err, message)
+
+		return nil, nil
+	}
+
 	if cmerrors.IsInvalidData(err) {
 		message := fmt.Sprintf("Failed to get key %q referenced in annotation %q",
 			secretName, cmapi.CertificateRequestPrivateKeyAnnotationKey)
@@ -132,6 +218,23 @@ func (s *SelfSigned) Sign(ctx context.Context, cr *cmapi.CertificateRequest, iss
 
 	template.CRLDistributionPoints = issuerObj.GetSpec().SelfSigned.CRLDistributionPoints
 
+	selfSigned := issuerObj.GetSpec().SelfSigned
+	if err := pki.AddTemplateSANs(template, selfSigned.AdditionalDNSNames, selfSigned.AdditionalIPAddresses); err != nil {
+		message := "Error adding the issuer's additional subject alternative names"
+		s.reporter.Failed(cr, err, "ErrorGenerating", message)
+		log.Error(err, message)
+		return nil, nil
+	}
+
+	if maxDuration := issuerObj.GetSpec().SelfSigned.MaxDuration; maxDuration != nil {
+		requested := template.NotAfter.Sub(template.NotBefore).Round(time.Second)
+		if pki.LimitTemplateDuration(template, maxDuration.Duration) {
+			message := fmt.Sprintf("Requested duration %s exceeds the issuer's maximum duration, issuing certificate with a duration of %s", requested, maxDuration.Duration)
+			log.V(logf.InfoLevel).Info(message)
+			s.recorder.Event(cr, corev1.EventTypeWarning, "MaxDurationExceeded", message)
+		}
+	}
+
 	if template.Subject.String() == "" {
 		// RFC 5280 (https://tools.ietf.org/html/rfc5280#section-4.1.2.4) says that:
 		// "The issuer field MUST contain a non-empty distinguished name (DN)."
@@ -142,13 +245,7 @@ func (s *SelfSigned) Sign(ctx context.Context, cr *cmapi.CertificateRequest, iss
 	}
 
 	// extract the public component of the key
-	publickey, err := pki.PublicKeyForPrivateKey(privatekey)
-	if err != nil {
-		message := "Failed to get public key from private key"
-		s.reporter.Failed(cr, err, "ErrorPublicKey", message)
-		log.Error(err, message)
-		return nil, nil
-	}
+	publickey := privatekey.Public()
 
 	ok, err = pki.PublicKeysEqual(publickey, template.PublicKey)
 	if err != nil || !ok {
@@ -164,6 +261,16 @@ func (s *SelfSigned) Sign(ctx context.Context, cr *cmapi.CertificateRequest, iss
 		return nil, nil
 	}
 
+	if name := issuerObj.GetSpec().SelfSigned.SignatureAlgorithm; len(name) > 0 {
+		template.SignatureAlgorithm, err = pki.SignatureAlgorithmForKey(name, publickey)
+		if err != nil {
+			message := fmt.Sprintf("Issuer signature algorithm %q is not compatible with the referenced private key", name)
+			s.reporter.Failed(cr, err, "ErrorSignatureAlgorithm", message)
+			log.Error(err, message)
+			return nil, nil
+		}
+	}
+
 	// sign and encode the certificate
 	certPem, _, err := s.signingFn(template, template, publickey, privatekey)
 	if err != nil {
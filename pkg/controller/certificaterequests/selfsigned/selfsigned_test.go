@@ -26,10 +26,14 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"reflect"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientcorev1 "k8s.io/client-go/listers/core/v1"
@@ -358,6 +362,35 @@ func TestSign(t *testing.T) {
 				},
 			},
 		},
+		"if the referenced secret contains no private key entries then should record pending": {
+			certificateRequest: baseCR.DeepCopy(),
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{&corev1.Secret{
+					ObjectMeta: rsaKeySecret.ObjectMeta,
+					Data:       map[string][]byte{"other.key": skRSAPEM},
+				}},
+				CertManagerObjects: []runtime.Object{baseCR.DeepCopy(), baseIssuer},
+				ExpectedEvents: []string{
+					`Normal MissingKeyData Failed to find private key data for "test-rsa-key" referenced in annotation "cert-manager.io/private-key-secret-name": none of the entries ["tls.key" "ca.key"] are present in secret 'default-unit-test-ns/test-rsa-key'`,
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(baseCR,
+							gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+								Type:               cmapi.CertificateRequestConditionReady,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             cmapi.CertificateRequestReasonPending,
+								Message:            `Failed to find private key data for "test-rsa-key" referenced in annotation "cert-manager.io/private-key-secret-name": none of the entries ["tls.key" "ca.key"] are present in secret 'default-unit-test-ns/test-rsa-key'`,
+								LastTransitionTime: &metaFixedClockStart,
+							}),
+						),
+					)),
+				},
+			},
+		},
 		"should exit nil and set status pending if referenced issuer is not ready": {
 			certificateRequest: baseCR.DeepCopy(),
 			builder: &testpkg.Builder{
@@ -629,7 +662,7 @@ func runTest(t *testing.T, test testT) {
 	self := NewSelfSigned(test.builder.Context).(*SelfSigned)
 
 	if test.fakeLister != nil {
-		self.secretsLister = test.fakeLister
+		self.keyLoader = secretKeyLoader{secretsLister: test.fakeLister}
 	}
 
 	if test.signingFn != nil {
@@ -653,3 +686,227 @@ func runTest(t *testing.T, test testT) {
 
 	test.builder.CheckAndFinish(err)
 }
+
+func TestSecretKeyLoader(t *testing.T) {
+	sk, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skPEM, err := pki.EncodeECPrivateKey(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSK, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSKPEM, err := pki.EncodeECPrivateKey(otherSK)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		data          map[string][]byte
+		ref           string
+		expectKey     bool
+		expectMissing bool
+	}{
+		"key stored in tls.key": {
+			data:      map[string][]byte{corev1.TLSPrivateKeyKey: skPEM},
+			ref:       "test-key",
+			expectKey: true,
+		},
+		"key stored in ca.key": {
+			data:      map[string][]byte{"ca.key": skPEM},
+			ref:       "test-key",
+			expectKey: true,
+		},
+		"tls.key is preferred over ca.key": {
+			data:      map[string][]byte{corev1.TLSPrivateKeyKey: skPEM, "ca.key": otherSKPEM},
+			ref:       "test-key",
+			expectKey: true,
+		},
+		"key stored in an entry named by the annotation": {
+			data:      map[string][]byte{corev1.TLSPrivateKeyKey: otherSKPEM, "signing.key": skPEM},
+			ref:       "test-key/signing.key",
+			expectKey: true,
+		},
+		"entry named by the annotation is missing": {
+			data:          map[string][]byte{corev1.TLSPrivateKeyKey: skPEM},
+			ref:           "test-key/signing.key",
+			expectMissing: true,
+		},
+		"no candidate entries are present": {
+			data:          map[string][]byte{"other.key": skPEM},
+			ref:           "test-key",
+			expectMissing: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			loader := secretKeyLoader{secretsLister: &listersfake.FakeSecretLister{
+				SecretsFn: func(namespace string) clientcorev1.SecretNamespaceLister {
+					return &listersfake.FakeSecretNamespaceLister{
+						GetFn: func(name string) (*corev1.Secret, error) {
+							if name != "test-key" {
+								t.Errorf("unexpected secret name %q", name)
+							}
+							return &corev1.Secret{
+								ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+								Data:       tc.data,
+							}, nil
+						},
+					}
+				},
+			}}
+
+			key, err := loader.LoadPrivateKey(context.Background(), gen.DefaultTestNamespace, tc.ref)
+			var missingErr *missingKeyDataError
+			if tc.expectMissing != errors.As(err, &missingErr) {
+				t.Errorf("expected missing key data error=%t, got: %v", tc.expectMissing, err)
+			}
+			if !tc.expectMissing && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.expectKey {
+				ok, err := pki.PublicKeysEqual(key.Public(), sk.Public())
+				if err != nil || !ok {
+					t.Errorf("loaded key does not match the expected key (err=%v)", err)
+				}
+			}
+		})
+	}
+}
+
+// fakeSigner is a crypto.Signer that hides the type of its underlying key,
+// as is the case for keys held in a KMS.
+type fakeSigner struct {
+	signer crypto.Signer
+}
+
+func (f fakeSigner) Public() crypto.PublicKey {
+	return f.signer.Public()
+}
+
+func (f fakeSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return f.signer.Sign(rand, digest, opts)
+}
+
+type fakeKeyLoader struct {
+	key crypto.Signer
+	err error
+}
+
+func (f fakeKeyLoader) LoadPrivateKey(context.Context, string, string) (crypto.Signer, error) {
+	return f.key, f.err
+}
+
+func TestSignWithKeyLoader(t *testing.T) {
+	sk, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := gen.Issuer("test-issuer", gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{}))
+	cr := gen.CertificateRequest("test-cr",
+		gen.SetCertificateRequestAnnotations(map[string]string{
+			cmapi.CertificateRequestPrivateKeyAnnotationKey: "kms-key",
+		}),
+		gen.SetCertificateRequestCSR(generateCSR(t, sk, x509.ECDSAWithSHA256, "test-kms")),
+	)
+
+	tests := map[string]struct {
+		loader     fakeKeyLoader
+		expectCert bool
+	}{
+		"should sign the certificate using the loaded signer": {
+			loader:     fakeKeyLoader{key: fakeSigner{signer: sk}},
+			expectCert: true,
+		},
+		"should not sign the certificate if the key cannot be found": {
+			loader: fakeKeyLoader{err: apierrors.NewNotFound(corev1.Resource("secrets"), "kms-key")},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := &testpkg.Builder{T: t, Clock: fakeclock.NewFakeClock(fixedClockStart)}
+			builder.Init()
+			defer builder.Stop()
+
+			self := NewSelfSignedWithKeyLoader(tc.loader)(builder.Context).(*SelfSigned)
+
+			resp, err := self.Sign(context.Background(), cr.DeepCopy(), issuer)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tc.expectCert {
+				if resp != nil {
+					t.Errorf("expected no certificate to be issued, got %v", resp)
+				}
+				return
+			}
+			if resp == nil {
+				t.Fatal("expected a certificate to be issued")
+			}
+
+			cert, err := pki.DecodeX509CertificateBytes(resp.Certificate)
+			if err != nil {
+				t.Fatalf("failed to decode issued certificate: %v", err)
+			}
+			if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+				t.Errorf("issued certificate was not signed by the loaded key: %v", err)
+			}
+		})
+	}
+}
+
+func TestSignAdditionalSANs(t *testing.T) {
+	csrPEM, sk, err := gen.CSR(x509.ECDSA,
+		gen.SetCSRDNSNames("example.com", "pod.internal"),
+		gen.SetCSRIPAddresses(net.ParseIP("10.0.0.1")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr := gen.CertificateRequest("test-cr",
+		gen.SetCertificateRequestAnnotations(map[string]string{
+			cmapi.CertificateRequestPrivateKeyAnnotationKey: "test-key",
+		}),
+		gen.SetCertificateRequestCSR(csrPEM),
+	)
+	issuer := gen.Issuer("test-issuer", gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{
+		AdditionalDNSNames:    []string{"pod.internal", "identity.internal"},
+		AdditionalIPAddresses: []string{"10.0.0.1", "10.0.0.2"},
+	}))
+
+	builder := &testpkg.Builder{T: t, Clock: fakeclock.NewFakeClock(fixedClockStart)}
+	builder.Init()
+	defer builder.Stop()
+
+	self := NewSelfSignedWithKeyLoader(fakeKeyLoader{key: sk})(builder.Context).(*SelfSigned)
+	resp, err := self.Sign(context.Background(), cr, issuer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp == nil {
+		t.Fatal("expected a certificate to be issued")
+	}
+
+	cert, err := pki.DecodeX509CertificateBytes(resp.Certificate)
+	if err != nil {
+		t.Fatalf("failed to decode issued certificate: %v", err)
+	}
+	expectedDNSNames := []string{"example.com", "pod.internal", "identity.internal"}
+	if !reflect.DeepEqual(cert.DNSNames, expectedDNSNames) {
+		t.Errorf("expected DNS names %v, got %v", expectedDNSNames, cert.DNSNames)
+	}
+	var ips []string
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	expectedIPs := []string{"10.0.0.1", "10.0.0.2"}
+	if !reflect.DeepEqual(ips, expectedIPs) {
+		t.Errorf("expected IP addresses %v, got %v", expectedIPs, ips)
+	}
+}
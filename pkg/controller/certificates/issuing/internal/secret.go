@@ -17,10 +17,9 @@ limitations under the License.
 package internal
 
 import (
-	"bytes"
 	"context"
 	"crypto/x509"
-	"encoding/pem"
+	"errors"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
@@ -44,6 +43,10 @@ var (
 	certificateGvk = cmapi.SchemeGroupVersion.WithKind("Certificate")
 )
 
+// ErrSecretImmutable is returned by UpdateData if the Certificate's Secret is
+// immutable, so cannot be updated.
+var ErrSecretImmutable = errors.New("secret is immutable")
+
 // SecretsManager creates and updates secrets with certificate and key data.
 type SecretsManager struct {
 	secretClient coreclient.SecretsGetter
@@ -52,6 +55,10 @@ type SecretsManager struct {
 	// fieldManager is the manager name used for the Apply operations on Secrets.
 	fieldManager string
 
+	// updateStrategy is the strategy used to write Secrets. Secrets are
+	// applied unless it is set to SecretUpdateStrategyUpdate.
+	updateStrategy certificates.SecretUpdateStrategy
+
 	// if true, Secret resources created by the controller will have an
 	// 'owner reference' set, meaning when the Certificate is deleted, the
 	// Secret resource will be automatically deleted.
@@ -62,27 +69,46 @@ type SecretsManager struct {
 // SecretData is a structure wrapping private key, Certificate and CA data
 type SecretData struct {
 	PrivateKey, Certificate, CA []byte
+
+	// IssuerProfile is the value of the issuer profile annotation of the
+	// issuer that signed the certificate, if any.
+	IssuerProfile string
+
+	// Revision is the revision of the Certificate that the certificate was
+	// issued for, if known.
+	Revision string
 }
 
 // NewSecretsManager returns a new SecretsManager. Setting
 // enableSecretOwnerReferences to true will mean that secrets will be deleted
-// when the corresponding Certificate is deleted.
+// when the corresponding Certificate is deleted. Secrets are written using
+// the given updateStrategy.
 func NewSecretsManager(
 	secretClient coreclient.SecretsGetter,
 	secretLister corelisters.SecretLister,
 	fieldManager string,
+	updateStrategy certificates.SecretUpdateStrategy,
 	enableSecretOwnerReferences bool,
 ) *SecretsManager {
 	return &SecretsManager{
 		secretClient:                secretClient,
 		secretLister:                secretLister,
 		fieldManager:                fieldManager,
+		updateStrategy:              updateStrategy,
 		enableSecretOwnerReferences: enableSecretOwnerReferences,
 	}
 }
 
+// FieldManager returns the manager name used for the Apply operations on
+// Secrets. Managed fields of Secrets owned by this manager are the ones
+// written by cert-manager.
+func (s *SecretsManager) FieldManager() string {
+	return s.fieldManager
+}
+
 // UpdateData will ensure the Secret resource contains the given secret data as
-// well as appropriate metadata using an Apply call.
+// well as appropriate metadata using an Apply call, or Create and Update calls
+// if the SecretsManager uses the Update strategy.
 // If the Secret resource does not exist, it will be created on Apply.
 // UpdateData will also update deprecated annotations if they exist.
 func (s *SecretsManager) UpdateData(ctx context.Context, crt *cmapi.Certificate, data SecretData) error {
@@ -98,6 +124,10 @@ func (s *SecretsManager) UpdateData(ctx context.Context, crt *cmapi.Certificate,
 		return err
 	}
 
+	if s.updateStrategy == certificates.SecretUpdateStrategyUpdate {
+		return s.updateSecret(ctx, crt, secret)
+	}
+
 	// Build Secret apply configuration and options.
 	applyOpts := metav1.ApplyOptions{FieldManager: s.fieldManager, Force: true}
 	applyCnf := applycorev1.Secret(secret.Name, secret.Namespace).
@@ -126,6 +156,67 @@ func (s *SecretsManager) UpdateData(ctx context.Context, crt *cmapi.Certificate,
 	return nil
 }
 
+// updateSecret writes the given Secret using a Create call if it does not
+// exist yet, or otherwise using an Update call of the existing Secret into
+// which the data, annotations and labels of the given Secret are merged.
+// Unlike Apply, values that are no longer set by cert-manager are not removed
+// from existing Secrets.
+func (s *SecretsManager) updateSecret(ctx context.Context, crt *cmapi.Certificate, secret *corev1.Secret) error {
+	log := logf.FromContext(ctx).WithName("secrets_manager")
+	log = logf.WithResource(log, secret)
+
+	existingSecret, err := s.secretLister.Secrets(secret.Namespace).Get(secret.Name)
+	if apierrors.IsNotFound(err) {
+		if s.enableSecretOwnerReferences {
+			secret.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(crt, certificateGvk)}
+		}
+
+		log.V(logf.DebugLevel).Info("creating secret")
+
+		_, err := s.secretClient.Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{FieldManager: s.fieldManager})
+		if err != nil {
+			return fmt.Errorf("failed to create secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	existingSecret = existingSecret.DeepCopy()
+	if existingSecret.Data == nil {
+		existingSecret.Data = make(map[string][]byte)
+	}
+	for k, v := range secret.Data {
+		existingSecret.Data[k] = v
+	}
+	if existingSecret.Annotations == nil {
+		existingSecret.Annotations = make(map[string]string)
+	}
+	for k, v := range secret.Annotations {
+		existingSecret.Annotations[k] = v
+	}
+	if existingSecret.Labels == nil {
+		existingSecret.Labels = make(map[string]string)
+	}
+	for k, v := range secret.Labels {
+		existingSecret.Labels[k] = v
+	}
+	// Only set the owner reference if the Secret is not already controlled,
+	// as an object may only have a single controller.
+	if s.enableSecretOwnerReferences && metav1.GetControllerOf(existingSecret) == nil {
+		existingSecret.OwnerReferences = append(existingSecret.OwnerReferences, *metav1.NewControllerRef(crt, certificateGvk))
+	}
+
+	log.V(logf.DebugLevel).Info("updating secret")
+
+	_, err = s.secretClient.Secrets(secret.Namespace).Update(ctx, existingSecret, metav1.UpdateOptions{FieldManager: s.fieldManager})
+	if err != nil {
+		return fmt.Errorf("failed to update secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	return nil
+}
+
 // setValues will update the Secret resource 'secret' with the data contained
 // in the given secretData.
 // It will update labels and annotations on the Secret resource appropriately.
@@ -163,6 +254,12 @@ func (s *SecretsManager) setValues(crt *cmapi.Certificate, secret *corev1.Secret
 	}
 
 	secret.Annotations = certificates.AnnotationsForCertificateSecret(crt, certificate)
+	if data.IssuerProfile != "" {
+		secret.Annotations[cmapi.IssuerProfileAnnotationKey] = data.IssuerProfile
+	}
+	if data.Revision != "" {
+		secret.Annotations[cmapi.CertificateRequestRevisionAnnotationKey] = data.Revision
+	}
 	if secret.Labels == nil {
 		secret.Labels = make(map[string]string)
 	}
@@ -203,6 +300,12 @@ func (s *SecretsManager) getCertificateSecret(ctx context.Context, crt *cmapi.Ce
 		return nil, err
 	}
 
+	// The data of an immutable Secret can never be changed, so there is no
+	// point attempting to Apply it.
+	if existingSecret.Immutable != nil && *existingSecret.Immutable {
+		return nil, ErrSecretImmutable
+	}
+
 	// Only copy Secret Type to not take ownership of annotations or labels on
 	// Apply.
 	return &corev1.Secret{
@@ -284,17 +387,11 @@ func (s *SecretsManager) setKeystores(crt *cmapi.Certificate, secret *corev1.Sec
 // output formats according to any OutputFormats which have been configured.
 func setAdditionalOutputFormats(crt *cmapi.Certificate, secret *corev1.Secret, data SecretData) error {
 	for _, format := range crt.Spec.AdditionalOutputFormats {
-		switch format.Type {
-		case cmapi.CertificateOutputFormatDER:
-			// Store binary format of the private key
-			block, _ := pem.Decode(data.PrivateKey)
-			secret.Data[cmapi.CertificateOutputFormatDERKey] = block.Bytes
-		case cmapi.CertificateOutputFormatCombinedPEM:
-			// Combine tls.key and tls.crt
-			secret.Data[cmapi.CertificateOutputFormatCombinedPEMKey] = bytes.Join([][]byte{data.PrivateKey, data.Certificate}, []byte("\n"))
-		default:
-			return fmt.Errorf("unknown additional output format %s", format.Type)
+		key, value, err := certificates.AdditionalOutputFormatData(format.Type, data.PrivateKey, data.Certificate)
+		if err != nil {
+			return err
 		}
+		secret.Data[key] = value
 	}
 
 	return nil
@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cert-manager/cert-manager/internal/controller/certificates"
 	"github.com/cert-manager/cert-manager/internal/controller/feature"
 	testpkg "github.com/cert-manager/cert-manager/pkg/controller/test"
 	utilfeature "github.com/cert-manager/cert-manager/pkg/util/feature"
@@ -34,6 +35,7 @@ import (
 	apitypes "k8s.io/apimachinery/pkg/types"
 	applycorev1 "k8s.io/client-go/applyconfigurations/core/v1"
 	applymetav1 "k8s.io/client-go/applyconfigurations/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	featuregatetesting "k8s.io/component-base/featuregate/testing"
 	fakeclock "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
@@ -155,6 +157,78 @@ func Test_SecretsManager(t *testing.T) {
 			expectedErr: false,
 		},
 
+		"if secret does not exist, create new Secret, with issuer profile annotation": {
+			certificateOptions: controllerpkg.CertificateOptions{EnableOwnerRef: false},
+			certificate:        baseCertBundle.Certificate,
+			existingSecret:     nil,
+			secretData:         SecretData{Certificate: baseCertBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key"), IssuerProfile: "gold"},
+			applyFn: func(t *testing.T) testcoreclients.ApplyFn {
+				return func(_ context.Context, gotCnf *applycorev1.SecretApplyConfiguration, gotOpts metav1.ApplyOptions) (*corev1.Secret, error) {
+					expCnf := applycorev1.Secret("output", gen.DefaultTestNamespace).
+						WithAnnotations(
+							map[string]string{
+								cmapi.CertificateNameKey: "test", cmapi.IssuerGroupAnnotationKey: "foo.io",
+								cmapi.IssuerKindAnnotationKey: "Issuer", cmapi.IssuerNameAnnotationKey: "ca-issuer",
+								cmapi.IssuerProfileAnnotationKey: "gold",
+
+								cmapi.CommonNameAnnotationKey: baseCertBundle.Cert.Subject.CommonName, cmapi.AltNamesAnnotationKey: strings.Join(baseCertBundle.Cert.DNSNames, ","),
+								cmapi.IPSANAnnotationKey:  strings.Join(utilpki.IPAddressesToString(baseCertBundle.Cert.IPAddresses), ","),
+								cmapi.URISANAnnotationKey: strings.Join(utilpki.URLsToString(baseCertBundle.Cert.URIs), ","),
+							}).
+						WithLabels(make(map[string]string)).
+						WithData(map[string][]byte{
+							corev1.TLSCertKey:       baseCertBundle.CertBytes,
+							corev1.TLSPrivateKeyKey: []byte("test-key"),
+							cmmeta.TLSCAKey:         []byte("test-ca"),
+						}).
+						WithType(corev1.SecretTypeTLS)
+					assert.Equal(t, expCnf, gotCnf)
+
+					expOpts := metav1.ApplyOptions{FieldManager: "cert-manager-test", Force: true}
+					assert.Equal(t, expOpts, gotOpts)
+
+					return nil, nil
+				}
+			},
+			expectedErr: false,
+		},
+
+		"if secret does not exist, create new Secret, with revision annotation": {
+			certificateOptions: controllerpkg.CertificateOptions{EnableOwnerRef: false},
+			certificate:        baseCertBundle.Certificate,
+			existingSecret:     nil,
+			secretData:         SecretData{Certificate: baseCertBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key"), Revision: "3"},
+			applyFn: func(t *testing.T) testcoreclients.ApplyFn {
+				return func(_ context.Context, gotCnf *applycorev1.SecretApplyConfiguration, gotOpts metav1.ApplyOptions) (*corev1.Secret, error) {
+					expCnf := applycorev1.Secret("output", gen.DefaultTestNamespace).
+						WithAnnotations(
+							map[string]string{
+								cmapi.CertificateNameKey: "test", cmapi.IssuerGroupAnnotationKey: "foo.io",
+								cmapi.IssuerKindAnnotationKey: "Issuer", cmapi.IssuerNameAnnotationKey: "ca-issuer",
+								cmapi.CertificateRequestRevisionAnnotationKey: "3",
+
+								cmapi.CommonNameAnnotationKey: baseCertBundle.Cert.Subject.CommonName, cmapi.AltNamesAnnotationKey: strings.Join(baseCertBundle.Cert.DNSNames, ","),
+								cmapi.IPSANAnnotationKey:  strings.Join(utilpki.IPAddressesToString(baseCertBundle.Cert.IPAddresses), ","),
+								cmapi.URISANAnnotationKey: strings.Join(utilpki.URLsToString(baseCertBundle.Cert.URIs), ","),
+							}).
+						WithLabels(make(map[string]string)).
+						WithData(map[string][]byte{
+							corev1.TLSCertKey:       baseCertBundle.CertBytes,
+							corev1.TLSPrivateKeyKey: []byte("test-key"),
+							cmmeta.TLSCAKey:         []byte("test-ca"),
+						}).
+						WithType(corev1.SecretTypeTLS)
+					assert.Equal(t, expCnf, gotCnf)
+
+					expOpts := metav1.ApplyOptions{FieldManager: "cert-manager-test", Force: true}
+					assert.Equal(t, expOpts, gotOpts)
+
+					return nil, nil
+				}
+			},
+			expectedErr: false,
+		},
+
 		"if secret does not exist, create new Secret, with owner enabled": {
 			certificateOptions: controllerpkg.CertificateOptions{EnableOwnerRef: true},
 			certificate:        baseCertBundle.Certificate,
@@ -679,7 +753,7 @@ func Test_SecretsManager(t *testing.T) {
 
 			testManager := NewSecretsManager(
 				secretClient, secretLister,
-				"cert-manager-test",
+				"cert-manager-test", certificates.SecretUpdateStrategyApply,
 				test.certificateOptions.EnableOwnerRef,
 			)
 
@@ -694,6 +768,86 @@ func Test_SecretsManager(t *testing.T) {
 	}
 }
 
+func Test_SecretsManager_UpdateStrategy(t *testing.T) {
+	baseCert := gen.Certificate("test",
+		gen.SetCertificateNamespace(gen.DefaultTestNamespace),
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "ca-issuer", Kind: "Issuer", Group: "foo.io"}),
+		gen.SetCertificateSecretName("output"),
+		gen.SetCertificateDNSNames("example.com"),
+		gen.SetCertificateUID(apitypes.UID("test-uid")),
+		gen.SetCertificateSecretTemplate(nil, map[string]string{"template": "label"}),
+	)
+	baseCertBundle := testcrypto.MustCreateCryptoBundle(t, baseCert, fixedClock)
+	secretData := SecretData{Certificate: baseCertBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")}
+
+	tests := map[string]struct {
+		existingSecret *corev1.Secret
+
+		expData   map[string][]byte
+		expLabels map[string]string
+		expOwned  bool
+	}{
+		"if the Secret does not exist, it should be created": {
+			existingSecret: nil,
+			expData: map[string][]byte{
+				corev1.TLSCertKey: baseCertBundle.CertBytes, corev1.TLSPrivateKeyKey: []byte("test-key"), cmmeta.TLSCAKey: []byte("test-ca"),
+			},
+			expLabels: map[string]string{"template": "label"},
+			expOwned:  true,
+		},
+		"if the Secret exists, its data and labels should be merged with the existing values": {
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: gen.DefaultTestNamespace, Name: "output",
+					Labels: map[string]string{"abc": "123"},
+				},
+				Data: map[string][]byte{"foo": []byte("bar"), corev1.TLSCertKey: []byte("old-cert")},
+				Type: corev1.SecretTypeTLS,
+			},
+			expData: map[string][]byte{
+				"foo": []byte("bar"), corev1.TLSCertKey: baseCertBundle.CertBytes, corev1.TLSPrivateKeyKey: []byte("test-key"), cmmeta.TLSCAKey: []byte("test-ca"),
+			},
+			expLabels: map[string]string{"abc": "123", "template": "label"},
+			expOwned:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				kubeClient *kubefake.Clientset
+				mod        testcorelisters.FakeSecretListerModifier
+			)
+			if test.existingSecret != nil {
+				kubeClient = kubefake.NewSimpleClientset(test.existingSecret)
+				mod = testcorelisters.SetFakeSecretNamespaceListerGet(test.existingSecret, nil)
+			} else {
+				kubeClient = kubefake.NewSimpleClientset()
+				mod = testcorelisters.SetFakeSecretNamespaceListerGet(nil, apierrors.NewNotFound(corev1.Resource("secret"), "not found"))
+			}
+
+			testManager := NewSecretsManager(
+				kubeClient.CoreV1(), testcorelisters.NewFakeSecretLister(mod),
+				"cert-manager-test", certificates.SecretUpdateStrategyUpdate,
+				true,
+			)
+
+			if err := testManager.UpdateData(context.Background(), baseCert, secretData); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			secret, err := kubeClient.CoreV1().Secrets(gen.DefaultTestNamespace).Get(context.Background(), "output", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get Secret: %v", err)
+			}
+			assert.Equal(t, test.expData, secret.Data)
+			assert.Equal(t, test.expLabels, secret.Labels)
+			assert.Equal(t, "test", secret.Annotations[cmapi.CertificateNameKey])
+			assert.Equal(t, test.expOwned, metav1.IsControlledBy(secret, baseCert))
+		})
+	}
+}
+
 func Test_getCertificateSecret(t *testing.T) {
 	crt := &cmapi.Certificate{
 		ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", Name: "test-certificate"},
@@ -703,6 +857,7 @@ func Test_getCertificateSecret(t *testing.T) {
 	tests := map[string]struct {
 		existingSecret *corev1.Secret
 		expSecret      *corev1.Secret
+		expErr         error
 	}{
 		"if secret doesn't exist, expect empty secret": {
 			existingSecret: nil,
@@ -746,6 +901,15 @@ func Test_getCertificateSecret(t *testing.T) {
 				Type: corev1.SecretTypeOpaque,
 			},
 		},
+		"if secret exists and is immutable, expect ErrSecretImmutable": {
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", Name: "test-secret"},
+				Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+				Type:       corev1.SecretTypeTLS,
+				Immutable:  pointer.Bool(true),
+			},
+			expErr: ErrSecretImmutable,
+		},
 	}
 
 	for name, test := range tests {
@@ -771,7 +935,7 @@ func Test_getCertificateSecret(t *testing.T) {
 			defer builder.Stop()
 
 			gotSecret, err := s.getCertificateSecret(context.Background(), crt)
-			assert.NoError(t, err)
+			assert.Equal(t, test.expErr, err)
 
 			assert.Equal(t, test.expSecret, gotSecret, "unexpected returned secret")
 		})
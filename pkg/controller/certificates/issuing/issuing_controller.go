@@ -19,7 +19,9 @@ package issuing
 import (
 	"context"
 	"crypto"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -37,6 +39,7 @@ import (
 
 	"github.com/cert-manager/cert-manager/internal/controller/certificates/policies"
 	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	"github.com/cert-manager/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	cmclient "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
@@ -45,6 +48,7 @@ import (
 	controllerpkg "github.com/cert-manager/cert-manager/pkg/controller"
 	"github.com/cert-manager/cert-manager/pkg/controller/certificates"
 	"github.com/cert-manager/cert-manager/pkg/controller/certificates/issuing/internal"
+	"github.com/cert-manager/cert-manager/pkg/issuer"
 	logf "github.com/cert-manager/cert-manager/pkg/logs"
 	utilkube "github.com/cert-manager/cert-manager/pkg/util/kube"
 	utilpki "github.com/cert-manager/cert-manager/pkg/util/pki"
@@ -80,6 +84,14 @@ type controller struct {
 
 	// localTemporarySigner signs a certificate that is stored temporarily
 	localTemporarySigner localTemporarySignerFn
+
+	// issuerHelper is used to look up the issuer profile annotation of the
+	// issuer referenced by a Certificate. If nil, no profile is recorded.
+	issuerHelper issuer.Helper
+
+	// minimumRSAKeySize is the minimum size of RSA private keys, which the
+	// keymanager controller may have raised the next private key to.
+	minimumRSAKeySize int
 }
 
 func NewController(
@@ -126,9 +138,13 @@ func NewController(
 		certificateInformer.Informer().HasSynced,
 	}
 
+	if fieldManager == "" {
+		log.Error(nil, "no field manager configured; Secrets cannot be applied and their managed fields will not be checked against the SecretTemplate")
+	}
 	secretsManager := internal.NewSecretsManager(
 		kubeClient.CoreV1(), secretsInformer.Lister(),
-		fieldManager, certificateControllerOptions.EnableOwnerRef,
+		fieldManager, certificateControllerOptions.SecretUpdateStrategy,
+		certificateControllerOptions.EnableOwnerRef,
 	)
 
 	return &controller{
@@ -139,8 +155,14 @@ func NewController(
 		recorder:                 recorder,
 		clock:                    clock,
 		secretsUpdateData:        secretsManager.UpdateData,
-		postIssuancePolicyChain:  policies.NewSecretPostIssuancePolicyChain(fieldManager),
-		localTemporarySigner:     certificates.GenerateLocallySignedTemporaryCertificate,
+		// The managed fields of Secrets must be checked against the same
+		// field manager that is used to Apply them.
+		postIssuancePolicyChain: policies.NewSecretPostIssuancePolicyChain(secretsManager.FieldManager(), policies.PostIssuancePolicyOptions{
+			IgnoreManagedFieldsParseErrors: certificateControllerOptions.IgnoreManagedFieldsParseErrors,
+			SecretUpdateStrategy:           certificateControllerOptions.SecretUpdateStrategy,
+		}),
+		localTemporarySigner: certificates.GenerateLocallySignedTemporaryCertificate,
+		minimumRSAKeySize:    certificateControllerOptions.MinimumRSAKeySize,
 	}, queue, mustSync
 }
 
@@ -204,7 +226,7 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		logf.WithResource(log, nextPrivateKeySecret).Error(err, "failed to parse next private key, waiting for keymanager controller")
 		return nil
 	}
-	pkViolations, err := certificates.PrivateKeyMatchesSpec(pk, crt.Spec)
+	pkViolations, err := certificates.PrivateKeyMatchesSpec(pk, certificates.ApplyMinimumRSAKeySize(crt, c.minimumRSAKeySize).Spec)
 	if err != nil {
 		return err
 	}
@@ -349,6 +371,36 @@ func (c *controller) failIssueCertificate(ctx context.Context, log logr.Logger,
 	return nil
 }
 
+// failIssueCertificateSecretImmutable will mark the Issuing condition of this
+// Certificate as failed because its Secret is immutable. This only happens if
+// the Secret was made immutable after issuance was triggered, as the trigger
+// controller otherwise blocks issuance with the SecretIsImmutable policy.
+// Setting the last failure time means the trigger controller backs off before
+// evaluating the Certificate again, at which point issuance is blocked.
+func (c *controller) failIssueCertificateSecretImmutable(ctx context.Context, crt *cmapi.Certificate) error {
+	log := logf.FromContext(ctx)
+	nowTime := metav1.NewTime(c.clock.Now())
+	crt.Status.LastFailureTime = &nowTime
+
+	log.V(logf.DebugLevel).Info("Secret is immutable, issuance will be retried after backoff")
+
+	reason := policies.SecretImmutable
+	message := fmt.Sprintf("The Secret %q is immutable and cannot be updated with the issued certificate. "+
+		"Delete the Secret so that it can be recreated, or set spec.secretName to a different Secret", crt.Spec.SecretName)
+
+	crt = crt.DeepCopy()
+	apiutil.SetCertificateCondition(crt, crt.Generation, cmapi.CertificateConditionIssuing, cmmeta.ConditionFalse, reason, message)
+
+	_, err := c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	c.recorder.Event(crt, corev1.EventTypeWarning, reason, message)
+
+	return nil
+}
+
 // issueCertificate will ensure the public key of the CSR matches the signed
 // certificate, and then store the certificate, CA and private key into the
 // Secret in the appropriate format type.
@@ -363,12 +415,16 @@ func (c *controller) issueCertificate(ctx context.Context, nextRevision int, crt
 		return err
 	}
 	secretData := internal.SecretData{
-		PrivateKey:  pkData,
-		Certificate: req.Status.Certificate,
-		CA:          req.Status.CA,
+		PrivateKey:    pkData,
+		Certificate:   req.Status.Certificate,
+		CA:            req.Status.CA,
+		IssuerProfile: c.issuerProfile(crt),
+		Revision:      strconv.Itoa(nextRevision),
 	}
 
-	if err := c.secretsUpdateData(ctx, crt, secretData); err != nil {
+	if err := c.secretsUpdateData(ctx, crt, secretData); errors.Is(err, internal.ErrSecretImmutable) {
+		return c.failIssueCertificateSecretImmutable(ctx, crt)
+	} else if err != nil {
 		return err
 	}
 
@@ -392,6 +448,21 @@ func (c *controller) issueCertificate(ctx context.Context, nextRevision int, crt
 	return nil
 }
 
+// issuerProfile returns the issuer profile annotation of the issuer
+// referenced by the given Certificate. An empty string is returned if the
+// issuer is not a cert-manager issuer, cannot be found or has no profile.
+func (c *controller) issuerProfile(crt *cmapi.Certificate) string {
+	ref := crt.Spec.IssuerRef
+	if c.issuerHelper == nil || (ref.Group != "" && ref.Group != certmanager.GroupName) {
+		return ""
+	}
+	iss, err := c.issuerHelper.GetGenericIssuer(ref, crt.Namespace)
+	if err != nil {
+		return ""
+	}
+	return iss.GetObjectMeta().Annotations[cmapi.IssuerProfileAnnotationKey]
+}
+
 // controllerWrapper wraps the `controller` structure to make it implement
 // the controllerpkg.queueingController interface
 type controllerWrapper struct {
@@ -412,6 +483,18 @@ func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.Rate
 		ctx.Clock,
 		ctx.CertificateOptions,
 	)
+
+	// The issuer helper is used to record the profile of the issuer that
+	// signed a certificate on its Secret.
+	issuerInformer := ctx.SharedInformerFactory.Certmanager().V1().Issuers()
+	mustSync = append(mustSync, issuerInformer.Informer().HasSynced)
+	var clusterIssuerLister cmlisters.ClusterIssuerLister
+	if ctx.Namespace == "" {
+		clusterIssuerInformer := ctx.SharedInformerFactory.Certmanager().V1().ClusterIssuers()
+		mustSync = append(mustSync, clusterIssuerInformer.Informer().HasSynced)
+		clusterIssuerLister = clusterIssuerInformer.Lister()
+	}
+	ctrl.issuerHelper = issuer.NewHelper(issuerInformer.Lister(), clusterIssuerLister)
 	c.controller = ctrl
 
 	return queue, mustSync, nil
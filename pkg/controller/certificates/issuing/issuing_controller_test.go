@@ -56,6 +56,7 @@ func TestIssuingController(t *testing.T) {
 
 		certificate             *cmapi.Certificate
 		expSecretUpdateDataCall *internal.SecretData
+		secretsUpdateDataErr    error
 
 		expectedErr bool
 	}
@@ -456,6 +457,7 @@ func TestIssuingController(t *testing.T) {
 				Certificate: exampleBundle.CertificateRequestReady.Status.Certificate,
 				PrivateKey:  exampleBundle.PrivateKeyBytes,
 				CA:          nil,
+				Revision:    "2",
 			},
 			expectedErr: false,
 		},
@@ -510,10 +512,64 @@ func TestIssuingController(t *testing.T) {
 				Certificate: exampleBundle.CertificateRequestReady.Status.Certificate,
 				PrivateKey:  exampleBundle.PrivateKeyBytes,
 				CA:          nil,
+				Revision:    "2",
 			},
 			expectedErr: false,
 		},
 
+		"if certificate is in Issuing state, one CertificateRequest, and is ready, but the Secret is immutable, report the failure and set last failed time": {
+			certificate: exampleBundle.Certificate,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequestReady,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+						}),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: exampleBundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+						},
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						exampleBundle.Certificate.Namespace,
+						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+								Type:               cmapi.CertificateConditionIssuing,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             "SecretImmutable",
+								Message:            `The Secret "output" is immutable and cannot be updated with the issued certificate. Delete the Secret so that it can be recreated, or set spec.secretName to a different Secret`,
+								LastTransitionTime: &metaFixedClockStart,
+								ObservedGeneration: 3,
+							}),
+							gen.SetCertificateLastFailureTime(metaFixedClockStart),
+						),
+					)),
+				},
+				ExpectedEvents: []string{
+					`Warning SecretImmutable The Secret "output" is immutable and cannot be updated with the issued certificate. Delete the Secret so that it can be recreated, or set spec.secretName to a different Secret`,
+				},
+			},
+			expSecretUpdateDataCall: &internal.SecretData{
+				Certificate: exampleBundle.CertificateRequestReady.Status.Certificate,
+				PrivateKey:  exampleBundle.PrivateKeyBytes,
+				CA:          nil,
+				Revision:    "2",
+			},
+			secretsUpdateDataErr: internal.ErrSecretImmutable,
+			expectedErr:          false,
+		},
+
 		"if certificate is in Issuing state with temp annotation, one CertificateRequest Pending, no target Secret, create target secret with temporary certificate": {
 			certificate: exampleBundle.Certificate,
 			builder: &testpkg.Builder{
@@ -787,6 +843,7 @@ func TestIssuingController(t *testing.T) {
 				Certificate: exampleBundle.CertificateRequestReady.Status.Certificate,
 				PrivateKey:  exampleBundle.PrivateKeyBytes,
 				CA:          nil,
+				Revision:    "2",
 			},
 			expectedErr: false,
 		},
@@ -918,7 +975,7 @@ func TestIssuingController(t *testing.T) {
 			w.controller.secretsUpdateData = func(_ context.Context, _ *cmapi.Certificate, secretData internal.SecretData) error {
 				secretsUpdateDataCalled = true
 				assert.Equal(t, *test.expSecretUpdateDataCall, secretData)
-				return nil
+				return test.secretsUpdateDataErr
 			}
 			t.Cleanup(func() {
 				assert.Equal(t, test.expSecretUpdateDataCall != nil, secretsUpdateDataCalled)
@@ -23,8 +23,10 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/cert-manager/cert-manager/internal/controller/certificates/policies"
+	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/cert-manager/cert-manager/pkg/controller/certificates/issuing/internal"
@@ -33,7 +35,8 @@ import (
 
 // ensureSecretData ensures that the Certificate's Secret is up to date with
 // non-issuing condition related data. Currently only reconciles on Annotations
-// and Labels from the Certificate's SecretTemplate.
+// and Labels from the Certificate's SecretTemplate, and on the password Secret
+// references that keystores are built with.
 func (c *controller) ensureSecretData(ctx context.Context, log logr.Logger, crt *cmapi.Certificate) error {
 	dbg := log.V(logf.DebugLevel)
 
@@ -61,6 +64,11 @@ func (c *controller) ensureSecretData(ctx context.Context, log logr.Logger, crt
 			PrivateKey:  secret.Data[corev1.TLSPrivateKeyKey],
 			Certificate: secret.Data[corev1.TLSCertKey],
 			CA:          secret.Data[cmmeta.TLSCAKey],
+			// The issuer profile and revision can only be determined at
+			// issuance time, so the values recorded on the Secret are
+			// preserved.
+			IssuerProfile: secret.Annotations[cmapi.IssuerProfileAnnotationKey],
+			Revision:      secret.Annotations[cmapi.CertificateRequestRevisionAnnotationKey],
 		}
 	}
 
@@ -85,7 +93,13 @@ func (c *controller) ensureSecretData(ctx context.Context, log logr.Logger, crt
 
 			// Here the Certificate need to be re-reconciled.
 			log.Info("applying Secret data", "message", message)
-			return c.secretsUpdateData(ctx, crt, data)
+			err := c.secretsUpdateData(ctx, crt, data)
+			if errors.Is(err, internal.ErrSecretImmutable) {
+				// The Secret can never be updated, so retrying would loop
+				// forever. Surface the problem on the Certificate instead.
+				return c.setSecretImmutable(ctx, log, crt, secret)
+			}
+			return err
 		}
 	}
 
@@ -93,3 +107,28 @@ func (c *controller) ensureSecretData(ctx context.Context, log logr.Logger, crt
 
 	return nil
 }
+
+// setSecretImmutable sets the Issuing=False condition with the SecretImmutable
+// reason on the Certificate. The message is that of the SecretIsImmutable
+// trigger policy, so that this controller and the trigger controller agree on
+// the condition and do not keep overwriting each other. The Certificate is
+// only updated if the condition changed, so that resyncs do not repeatedly
+// update it.
+func (c *controller) setSecretImmutable(ctx context.Context, log logr.Logger, crt *cmapi.Certificate, secret *corev1.Secret) error {
+	reason, message, immutable := policies.SecretIsImmutable(policies.Input{Secret: secret})
+	if !immutable {
+		// The cached Secret is not immutable yet, so retry once the cache has
+		// caught up.
+		return internal.ErrSecretImmutable
+	}
+
+	crt = crt.DeepCopy()
+	if !apiutil.SetCertificateConditionIfChanged(crt, crt.Generation, cmapi.CertificateConditionIssuing, cmmeta.ConditionFalse, reason, message) {
+		return nil
+	}
+
+	log.Error(internal.ErrSecretImmutable, "cannot apply Secret data as the Secret is immutable")
+
+	_, err := c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{})
+	return err
+}
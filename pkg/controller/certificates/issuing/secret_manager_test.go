@@ -23,8 +23,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coretesting "k8s.io/client-go/testing"
+	fakeclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
 
-	"github.com/cert-manager/cert-manager/internal/controller/certificates/policies"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/cert-manager/cert-manager/pkg/controller/certificates/issuing/internal"
@@ -32,7 +34,10 @@ import (
 )
 
 func Test_ensureSecretData(t *testing.T) {
-	const fieldManager = "cert-manager-unit-tests"
+	// fieldManager is the field manager derived from the User Agent of the
+	// builder's RESTConfig. It is used by the controller both to Apply
+	// Secrets and to check their managed fields.
+	const fieldManager = "cert-manager-unit-testing"
 
 	tests := map[string]struct {
 		// key that should be passed to ProcessItem.
@@ -46,9 +51,17 @@ func Test_ensureSecretData(t *testing.T) {
 		// secret is the optional secret to be loaded into the fake clientset.
 		secret *corev1.Secret
 
+		// secretsUpdateDataErr is the error returned when reconciling the
+		// Secret.
+		secretsUpdateDataErr error
+
 		// expectedAction is true if the test expects that the controller should
 		// reconcile the Secret.
 		expectedAction bool
+
+		// expectedActions are the actions the controller is expected to
+		// perform on the Certificate.
+		expectedActions []testpkg.Action
 	}{
 		"if 'key' is empty, should do nothing and not error": {
 			expectedAction: false,
@@ -231,13 +244,56 @@ func Test_ensureSecretData(t *testing.T) {
 			},
 			expectedAction: true,
 		},
+		"if Certificate exists in a false Issuing condition, Secret is immutable and does not match SecretTemplate, should set the SecretImmutable condition": {
+			key: "test-namespace/test-name",
+			cert: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", Name: "test-name", Generation: 2},
+				Spec: cmapi.CertificateSpec{
+					SecretName:     "test-secret",
+					SecretTemplate: &cmapi.CertificateSecretTemplate{Annotations: map[string]string{"foo": "bar"}, Labels: map[string]string{"abc": "123"}},
+				},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", Name: "test-secret"},
+				Immutable:  pointer.Bool(true),
+			},
+			secretsUpdateDataErr: internal.ErrSecretImmutable,
+			expectedAction:       true,
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+					cmapi.SchemeGroupVersion.WithResource("certificates"),
+					"status",
+					"test-namespace",
+					&cmapi.Certificate{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", Name: "test-name", Generation: 2},
+						Spec: cmapi.CertificateSpec{
+							SecretName:     "test-secret",
+							SecretTemplate: &cmapi.CertificateSecretTemplate{Annotations: map[string]string{"foo": "bar"}, Labels: map[string]string{"abc": "123"}},
+						},
+						Status: cmapi.CertificateStatus{
+							Conditions: []cmapi.CertificateCondition{{
+								Type:               cmapi.CertificateConditionIssuing,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             "SecretImmutable",
+								Message:            `Secret "test-secret" is immutable and cannot be updated with an issued certificate. Delete the Secret so that it can be recreated, or set spec.secretName to a different Secret`,
+								LastTransitionTime: &metav1.Time{Time: fixedClockStart},
+								ObservedGeneration: 2,
+							}},
+						},
+					},
+				)),
+			},
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			// Create and initialise a new unit test builder.
 			builder := &testpkg.Builder{
-				T: t,
+				T:     t,
+				Clock: fakeclock.NewFakeClock(fixedClockStart),
+
+				ExpectedActions: test.expectedActions,
 			}
 			if test.cert != nil {
 				// Ensures cert is loaded into the builder's fake clientset.
@@ -259,9 +315,9 @@ func Test_ensureSecretData(t *testing.T) {
 			var actionCalled bool
 			w.secretsUpdateData = func(_ context.Context, _ *cmapi.Certificate, _ internal.SecretData) error {
 				actionCalled = true
-				return nil
+				return test.secretsUpdateDataErr
 			}
-			w.postIssuancePolicyChain = policies.NewSecretPostIssuancePolicyChain(fieldManager)
+			assert.Equal(t, fieldManager, builder.FieldManager)
 
 			// Start the informers and begin processing updates.
 			builder.Start()
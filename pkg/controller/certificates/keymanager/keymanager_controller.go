@@ -64,6 +64,11 @@ type controller struct {
 	client            cmclient.Interface
 	coreClient        kubernetes.Interface
 	recorder          record.EventRecorder
+
+	// minimumRSAKeySize raises the size of RSA private keys generated and
+	// accepted for Certificates requesting a smaller key. A value of 0
+	// disables it.
+	minimumRSAKeySize int
 }
 
 func NewController(
@@ -209,7 +214,7 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		return c.deleteSecretResources(ctx, secrets)
 	}
 
-	violations, err := certificates.PrivateKeyMatchesSpec(pk, crt.Spec)
+	violations, err := certificates.PrivateKeyMatchesSpec(pk, certificates.ApplyMinimumRSAKeySize(crt, c.minimumRSAKeySize).Spec)
 	if err != nil {
 		log.Error(err, "Internal error verifying if private key matches spec - please open an issue.")
 		return nil
@@ -243,7 +248,7 @@ func (c *controller) createNextPrivateKeyRotationPolicyNever(ctx context.Context
 		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonDecodeFailed, "Failed to decode private key stored in Secret %q - generating new key", crt.Spec.SecretName)
 		return c.createAndSetNextPrivateKey(ctx, crt)
 	}
-	violations, err := certificates.PrivateKeyMatchesSpec(pk, crt.Spec)
+	violations, err := certificates.PrivateKeyMatchesSpec(pk, certificates.ApplyMinimumRSAKeySize(crt, c.minimumRSAKeySize).Spec)
 	if err != nil {
 		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonDecodeFailed, "Failed to check if private key stored in Secret %q is up to date - generating new key", crt.Spec.SecretName)
 		return c.createAndSetNextPrivateKey(ctx, crt)
@@ -264,7 +269,7 @@ func (c *controller) createNextPrivateKeyRotationPolicyNever(ctx context.Context
 }
 
 func (c *controller) createAndSetNextPrivateKey(ctx context.Context, crt *cmapi.Certificate) error {
-	pk, err := pki.GeneratePrivateKeyForCertificate(crt)
+	pk, err := pki.GeneratePrivateKeyForCertificate(certificates.ApplyMinimumRSAKeySize(crt, c.minimumRSAKeySize))
 	if err != nil {
 		return err
 	}
@@ -361,6 +366,7 @@ func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.Rate
 		ctx.SharedInformerFactory,
 		ctx.Recorder,
 	)
+	ctrl.minimumRSAKeySize = ctx.CertificateOptions.MinimumRSAKeySize
 	c.controller = ctrl
 
 	return queue, mustSync, nil
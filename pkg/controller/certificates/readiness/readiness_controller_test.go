@@ -45,7 +45,7 @@ func policyEvaluatorBuilder(c cmapi.CertificateCondition) policyEvaluatorFunc {
 
 // renewalTimeBuilder returns a fake renewalTimeFunc for ReadinessController.
 func renewalTimeBuilder(rt *metav1.Time) certificates.RenewalTimeFunc {
-	return func(notBefore, notAfter time.Time, cert *metav1.Duration) *metav1.Time {
+	return func(notBefore, notAfter time.Time, renewBefore *metav1.Duration, renewBeforePercentage *int32) *metav1.Time {
 		return rt
 	}
 }
@@ -512,7 +512,7 @@ func TestNewReadinessPolicyChain(t *testing.T) {
 			message: "",
 		},
 	}
-	policyChain := policies.NewReadinessPolicyChain(clock)
+	policyChain := policies.NewReadinessPolicyChain(clock, policies.ReadinessPolicyOptions{})
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			reason, message, violationFound := policyChain.Evaluate(policies.Input{
@@ -24,7 +24,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
@@ -33,6 +35,7 @@ import (
 	"k8s.io/utils/clock"
 
 	"github.com/cert-manager/cert-manager/internal/controller/certificates/policies"
+	"github.com/cert-manager/cert-manager/internal/controller/feature"
 	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
@@ -41,8 +44,11 @@ import (
 	cmlisters "github.com/cert-manager/cert-manager/pkg/client/listers/certmanager/v1"
 	controllerpkg "github.com/cert-manager/cert-manager/pkg/controller"
 	"github.com/cert-manager/cert-manager/pkg/controller/certificates"
+	"github.com/cert-manager/cert-manager/pkg/issuer"
 	logf "github.com/cert-manager/cert-manager/pkg/logs"
 	"github.com/cert-manager/cert-manager/pkg/scheduler"
+	utilfeature "github.com/cert-manager/cert-manager/pkg/util/feature"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
 	"github.com/cert-manager/cert-manager/pkg/util/predicate"
 )
 
@@ -61,6 +67,11 @@ type controller struct {
 	recorder                 record.EventRecorder
 	scheduledWorkQueue       scheduler.ScheduledWorkQueue
 
+	// policyOptions are used to compute the time at which a Certificate is
+	// re-checked for renewal. They must match the options used to build the
+	// policy chain in shouldReissue.
+	policyOptions policies.TriggerPolicyOptions
+
 	// The following are used for testing purposes.
 	clock              clock.Clock
 	shouldReissue      policies.Func
@@ -75,6 +86,8 @@ func NewController(
 	recorder record.EventRecorder,
 	clock clock.Clock,
 	shouldReissue policies.Func,
+	policyOptions policies.TriggerPolicyOptions,
+	isNamespaced bool,
 ) (*controller, workqueue.RateLimitingInterface, []cache.InformerSynced) {
 	// create a queue used to queue up items to be processed
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Second*1, time.Second*30), ControllerName)
@@ -83,6 +96,7 @@ func NewController(
 	certificateInformer := cmFactory.Certmanager().V1().Certificates()
 	certificateRequestInformer := cmFactory.Certmanager().V1().CertificateRequests()
 	secretsInformer := factory.Core().V1().Secrets()
+	issuerInformer := cmFactory.Certmanager().V1().Issuers()
 
 	certificateInformer.Informer().AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: queue})
 
@@ -96,6 +110,11 @@ func NewController(
 		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(),
 			predicate.ExtractResourceName(predicate.CertificateSecretName)),
 	})
+	// When an Issuer resource changes, enqueue any Certificate resources that
+	// reference it so that whether the issuer exists is re-evaluated.
+	issuerInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
+		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(), predicate.CertificateIssuerRef),
+	})
 
 	// build a list of InformerSynced functions that will be returned by the Register method.
 	// the controller will only begin processing items once all of these informers have synced.
@@ -103,6 +122,16 @@ func NewController(
 		certificateRequestInformer.Informer().HasSynced,
 		secretsInformer.Informer().HasSynced,
 		certificateInformer.Informer().HasSynced,
+		issuerInformer.Informer().HasSynced,
+	}
+
+	// If we are running in non-namespaced mode, we also watch ClusterIssuers.
+	if !isNamespaced {
+		clusterIssuerInformer := cmFactory.Certmanager().V1().ClusterIssuers()
+		clusterIssuerInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
+			WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(), predicate.CertificateIssuerRef),
+		})
+		mustSync = append(mustSync, clusterIssuerInformer.Informer().HasSynced)
 	}
 
 	return &controller{
@@ -112,6 +141,7 @@ func NewController(
 		client:                   client,
 		recorder:                 recorder,
 		scheduledWorkQueue:       scheduler.NewScheduledWorkQueue(clock, queue.Add),
+		policyOptions:            policyOptions,
 
 		// The following are used for testing purposes.
 		clock:         clock,
@@ -135,6 +165,9 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	crt, err := c.certificateLister.Certificates(namespace).Get(name)
 	if apierrors.IsNotFound(err) {
 		log.V(logf.DebugLevel).Info("certificate not found for key", "error", err.Error())
+		if c.policyOptions.SerialIndex != nil {
+			c.policyOptions.SerialIndex.Forget(namespace, name)
+		}
 		return nil
 	}
 	if err != nil {
@@ -152,6 +185,10 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	if err != nil {
 		return err
 	}
+	// Evaluate policies against the key size that will be used for the next
+	// private key, so that keys raised to the minimum size are not reported
+	// as not matching the spec.
+	input.Certificate = certificates.ApplyMinimumRSAKeySize(input.Certificate, c.policyOptions.MinimumRSAKeySize)
 
 	// Back off from re-issuing immediately when the certificate has been
 	// in failing mode for less than 1 hour.
@@ -165,13 +202,31 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	if crt.Status.RenewalTime != nil {
 		// ensure a resync is scheduled in the future so that we re-check
 		// Certificate resources and trigger them near expiry time
-		c.scheduleRecheckOfCertificateIfRequired(log, key, crt.Status.RenewalTime.Time.Sub(c.clock.Now()))
+		triggerTime := crt.Status.RenewalTime.Time.Add(c.policyOptions.RenewalGraceTolerance)
+		if crt.Status.NotBefore != nil && crt.Status.NotAfter != nil {
+			triggerTime = policies.RenewalTriggerTime(crt, crt.Status.NotBefore.Time, crt.Status.NotAfter.Time, crt.Status.RenewalTime.Time, c.policyOptions)
+		}
+		c.scheduleRecheckOfCertificateIfRequired(log, key, triggerTime.Sub(c.clock.Now()))
 	}
 
 	reason, message, reissue := c.shouldReissue(input)
+	c.recordSerialNumber(input)
+	if blockingReasons.Has(reason) {
+		// Issuance cannot succeed until the violation is resolved, so the
+		// reason is surfaced on the Issuing condition instead:
+		//  - IssuerNotFound: the issuer does not exist.
+		//  - SecretTerminating: the Secret is being deleted.
+		//  - SecretImmutable: the Secret can never be updated.
+		//  - SecretNotManaged: the Secret was not created by cert-manager.
+		//  - DuplicateSerial: the CA is broken, reissuing will not fix it.
+		//  - DurationTooShort: the issuer would reject spec.duration.
+		// The Certificate is re-queued when the issuer, Certificate or Secret
+		// changes.
+		return c.setIssuanceBlocked(ctx, crt, reason, message)
+	}
 	if !reissue {
 		// no re-issuance required, return early
-		return nil
+		return c.removeIssuanceBlocked(ctx, crt)
 	}
 
 	// Although the below recorder.Event already logs the event, the log
@@ -191,6 +246,63 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	return nil
 }
 
+// recordSerialNumber records the serial number of the certificate stored in
+// the Secret in the SerialIndex, if enabled. This is done once the policies
+// have been evaluated, as the SecretSerialNumberDuplicated policy only looks
+// serial numbers up.
+func (c *controller) recordSerialNumber(input policies.Input) {
+	if c.policyOptions.SerialIndex == nil || input.Secret == nil {
+		return
+	}
+	x509cert, err := pki.DecodeX509CertificateBytes(input.Secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return
+	}
+	c.policyOptions.SerialIndex.Record(input.Certificate.Namespace, input.Certificate.Name, x509cert)
+}
+
+// blockingReasons are the policy violation reasons for which issuance is not
+// triggered, as it could not succeed until the violation has been resolved.
+var blockingReasons = sets.NewString(policies.IssuerNotFound, policies.SecretTerminating, policies.SecretImmutable, policies.SecretNotManaged, policies.DuplicateSerial, policies.DurationTooShort)
+
+// setIssuanceBlocked sets the Issuing=False condition with the given blocking
+// reason on the Certificate, if it is not already set with the same reason and
+// message. The lastTransitionTime of the condition is preserved if only the
+// reason or message changed.
+func (c *controller) setIssuanceBlocked(ctx context.Context, crt *cmapi.Certificate, reason, message string) error {
+	log := logf.FromContext(ctx)
+
+	crt = crt.DeepCopy()
+	if !apiutil.SetCertificateConditionIfChanged(crt, crt.Generation, cmapi.CertificateConditionIssuing, cmmeta.ConditionFalse, reason, message) {
+		return nil
+	}
+
+	log.V(logf.InfoLevel).Info("Not issuing certificate as issuance is currently blocked", "reason", reason, "message", message)
+
+	_, err := c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	c.recorder.Event(crt, corev1.EventTypeWarning, reason, message)
+
+	return nil
+}
+
+// removeIssuanceBlocked removes the Issuing condition from the Certificate if
+// it was previously set by setIssuanceBlocked, as the violation has since been
+// resolved.
+func (c *controller) removeIssuanceBlocked(ctx context.Context, crt *cmapi.Certificate) error {
+	cond := apiutil.GetCertificateCondition(crt, cmapi.CertificateConditionIssuing)
+	if cond == nil || !blockingReasons.Has(cond.Reason) {
+		return nil
+	}
+
+	crt = crt.DeepCopy()
+	apiutil.RemoveCertificateCondition(crt, cmapi.CertificateConditionIssuing)
+	_, err := c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{})
+	return err
+}
+
 // shouldBackoffReissuingOnFailure tells us if we should back-off re-issuing for
 // an hour or not. Notably, it returns no back-off when the certificate doesn't
 // match the "next" certificate (since a mismatch means that this certificate
@@ -267,15 +379,81 @@ func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.Rate
 	// construct a new named logger to be reused throughout the controller
 	log := logf.FromContext(ctx.RootContext, ControllerName)
 
+	// The issuer helper is used by the trigger policy chain to check that
+	// the issuer referenced by a Certificate exists.
+	issuerLister := ctx.SharedInformerFactory.Certmanager().V1().Issuers().Lister()
+	var clusterIssuerLister cmlisters.ClusterIssuerLister
+	if ctx.Namespace == "" {
+		clusterIssuerLister = ctx.SharedInformerFactory.Certmanager().V1().ClusterIssuers().Lister()
+	}
+	helper := issuer.NewHelper(issuerLister, clusterIssuerLister)
+
+	policyOptions := policies.TriggerPolicyOptions{
+		RenewalJitterWindow:           ctx.CertificateOptions.RenewalJitterWindow,
+		RenewalGraceTolerance:         ctx.CertificateOptions.RenewalGraceTolerance,
+		RenewalTimeOfDay:              ctx.CertificateOptions.RenewalTimeOfDay,
+		ResyncPeriod:                  controllerpkg.ResyncPeriod,
+		ResyncExpiryMargin:            ctx.CertificateOptions.ResyncExpiryMargin,
+		DurationTolerance:             ctx.CertificateOptions.DurationTolerance,
+		MinimumDuration:               ctx.CertificateOptions.MinimumDuration,
+		IssuedBeforeCutoff:            ctx.CertificateOptions.IssuedBeforeCutoff,
+		DetectPrivateKeyReuse:         ctx.CertificateOptions.DetectPrivateKeyReuse,
+		ProtectUnmanagedSecrets:       ctx.CertificateOptions.ProtectUnmanagedSecrets,
+		CompareIssuerProfile:          ctx.CertificateOptions.CompareIssuerProfile,
+		CheckDeniedUsages:             ctx.CertificateOptions.CheckDeniedUsages,
+		CheckMissingRevision:          ctx.CertificateOptions.CheckMissingRevision,
+		CheckMultipleLeafCertificates: ctx.CertificateOptions.CheckMultipleLeafCertificates,
+		CheckChainVerifies:            ctx.CertificateOptions.CheckChainVerifies,
+		CheckMissingSANs:              ctx.CertificateOptions.CheckMissingSANs,
+		CheckUnexpectedCommonName:     ctx.CertificateOptions.CheckUnexpectedCommonName,
+		MinimumRSAKeySize:             ctx.CertificateOptions.MinimumRSAKeySize,
+		CheckOutputFormats:            utilfeature.DefaultFeatureGate.Enabled(feature.AdditionalCertificateOutputFormats),
+		DisabledPolicies:              sets.NewString(ctx.CertificateOptions.DisabledTriggerPolicies...),
+	}
+	if ctx.CertificateOptions.DetectDuplicateSerials {
+		policyOptions.SerialIndex = policies.NewSerialIndex(policies.DefaultSerialIndexSize)
+	}
+	if ctx.CertificateOptions.CompareAuthorityKeyID {
+		secretLister := ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister()
+		policyOptions.IssuerCA = policies.CAIssuerSigningCertificate(secretLister, ctx.IssuerOptions.ResourceNamespace)
+	}
+	if ctx.CertificateOptions.CheckIssuerCAExpiry {
+		secretLister := ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister()
+		policyOptions.IssuerCAExpiry = policies.CAIssuerSigningCertificate(secretLister, ctx.IssuerOptions.ResourceNamespace)
+	}
+	var revokedIntermediatesSynced cache.InformerSynced
+	if key := ctx.CertificateOptions.RevokedIntermediatesConfigMap; key != "" {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Only the configured ConfigMap is watched, so that other ConfigMaps
+		// are not cached and do not need to be readable by the controller.
+		factory := informers.NewSharedInformerFactoryWithOptions(ctx.Client, controllerpkg.ResyncPeriod,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+				opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+			}),
+		)
+		configMapInformer := factory.Core().V1().ConfigMaps()
+		policyOptions.RevokedIntermediates = policies.RevokedIntermediatesFromConfigMap(configMapInformer.Lister(), namespace, name)
+		revokedIntermediatesSynced = configMapInformer.Informer().HasSynced
+		factory.Start(ctx.StopCh)
+	}
 	ctrl, queue, mustSync := NewController(log,
 		ctx.CMClient,
 		ctx.KubeSharedInformerFactory,
 		ctx.SharedInformerFactory,
 		ctx.Recorder,
 		ctx.Clock,
-		policies.NewTriggerPolicyChain(ctx.Clock).Evaluate,
+		policies.NewTriggerPolicyChain(ctx.Clock, helper, policyOptions).Evaluate,
+		policyOptions,
+		ctx.Namespace != "",
 	)
 	c.controller = ctrl
+	if revokedIntermediatesSynced != nil {
+		mustSync = append(mustSync, revokedIntermediatesSynced)
+	}
 
 	return queue, mustSync, nil
 }
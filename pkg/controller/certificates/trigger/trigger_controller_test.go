@@ -24,7 +24,9 @@ import (
 
 	logtesting "github.com/go-logr/logr/testing"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
 	fakeclock "k8s.io/utils/clock/testing"
 
@@ -32,6 +34,7 @@ import (
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	controllerpkg "github.com/cert-manager/cert-manager/pkg/controller"
 	testpkg "github.com/cert-manager/cert-manager/pkg/controller/test"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
 	testcrypto "github.com/cert-manager/cert-manager/test/unit/crypto"
 	"github.com/cert-manager/cert-manager/test/unit/gen"
 )
@@ -230,6 +233,197 @@ func Test_controller_ProcessItem(t *testing.T) {
 				ObservedGeneration: 42,
 			}},
 		},
+		"should set Issuing=False if shouldReissue tells us the issuer does not exist": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateGeneration(42),
+			),
+			wantDataForCertificateCalled: true,
+			mockDataForCertificateReturn: policies.Input{},
+			wantShouldReissueCalled:      true,
+			mockShouldReissue: func(*testing.T) policies.Func {
+				return func(policies.Input) (string, string, bool) {
+					return policies.IssuerNotFound, `Referenced Issuer "issuer-1" does not exist`, true
+				}
+			},
+			wantEvent: `Warning IssuerNotFound Referenced Issuer "issuer-1" does not exist`,
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               "Issuing",
+				Status:             "False",
+				Reason:             "IssuerNotFound",
+				Message:            `Referenced Issuer "issuer-1" does not exist`,
+				LastTransitionTime: &fixedNow,
+				ObservedGeneration: 42,
+			}},
+		},
+		"should set Issuing=False if shouldReissue tells us the Secret is being deleted": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateGeneration(42),
+			),
+			wantDataForCertificateCalled: true,
+			mockDataForCertificateReturn: policies.Input{},
+			wantShouldReissueCalled:      true,
+			mockShouldReissue: func(*testing.T) policies.Func {
+				return func(policies.Input) (string, string, bool) {
+					return policies.SecretTerminating, `Secret "secret-1" is being deleted, waiting for it to be removed before issuing`, true
+				}
+			},
+			wantEvent: `Warning SecretTerminating Secret "secret-1" is being deleted, waiting for it to be removed before issuing`,
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               "Issuing",
+				Status:             "False",
+				Reason:             "SecretTerminating",
+				Message:            `Secret "secret-1" is being deleted, waiting for it to be removed before issuing`,
+				LastTransitionTime: &fixedNow,
+				ObservedGeneration: 42,
+			}},
+		},
+		"should set Issuing=False if shouldReissue tells us the Secret is immutable": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateGeneration(42),
+			),
+			wantDataForCertificateCalled: true,
+			mockDataForCertificateReturn: policies.Input{},
+			wantShouldReissueCalled:      true,
+			mockShouldReissue: func(*testing.T) policies.Func {
+				return func(policies.Input) (string, string, bool) {
+					return policies.SecretImmutable, `Secret "secret-1" is immutable`, true
+				}
+			},
+			wantEvent: `Warning SecretImmutable Secret "secret-1" is immutable`,
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               "Issuing",
+				Status:             "False",
+				Reason:             "SecretImmutable",
+				Message:            `Secret "secret-1" is immutable`,
+				LastTransitionTime: &fixedNow,
+				ObservedGeneration: 42,
+			}},
+		},
+		"should set Issuing=False if shouldReissue tells us spec.duration is too short": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateGeneration(42),
+			),
+			wantDataForCertificateCalled: true,
+			mockDataForCertificateReturn: policies.Input{},
+			wantShouldReissueCalled:      true,
+			mockShouldReissue: func(*testing.T) policies.Func {
+				return func(policies.Input) (string, string, bool) {
+					return policies.DurationTooShort, "spec.duration of 1h0m0s is shorter than the minimum duration of 24h0m0s accepted by the issuer; increase spec.duration to at least 24h0m0s", true
+				}
+			},
+			wantEvent: "Warning DurationTooShort spec.duration of 1h0m0s is shorter than the minimum duration of 24h0m0s accepted by the issuer; increase spec.duration to at least 24h0m0s",
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               "Issuing",
+				Status:             "False",
+				Reason:             "DurationTooShort",
+				Message:            "spec.duration of 1h0m0s is shorter than the minimum duration of 24h0m0s accepted by the issuer; increase spec.duration to at least 24h0m0s",
+				LastTransitionTime: &fixedNow,
+				ObservedGeneration: 42,
+			}},
+		},
+		"should not update Certificate if it already has the IssuerNotFound condition": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateGeneration(42),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+					Type:               "Issuing",
+					Status:             "False",
+					Reason:             "IssuerNotFound",
+					Message:            `Referenced Issuer "issuer-1" does not exist`,
+					LastTransitionTime: &fixedNow,
+					ObservedGeneration: 42,
+				}),
+			),
+			wantDataForCertificateCalled: true,
+			mockDataForCertificateReturn: policies.Input{},
+			wantShouldReissueCalled:      true,
+			mockShouldReissue: func(*testing.T) policies.Func {
+				return func(policies.Input) (string, string, bool) {
+					return policies.IssuerNotFound, `Referenced Issuer "issuer-1" does not exist`, true
+				}
+			},
+		},
+		"should remove the IssuerNotFound condition once the issuer exists and no re-issuance is required": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateGeneration(42),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+					Type:               "Issuing",
+					Status:             "False",
+					Reason:             "IssuerNotFound",
+					Message:            `Referenced Issuer "issuer-1" does not exist`,
+					LastTransitionTime: &fixedNow,
+					ObservedGeneration: 42,
+				}),
+			),
+			wantDataForCertificateCalled: true,
+			mockDataForCertificateReturn: policies.Input{},
+			wantShouldReissueCalled:      true,
+			mockShouldReissue: func(*testing.T) policies.Func {
+				return func(policies.Input) (string, string, bool) {
+					return "", "", false
+				}
+			},
+			wantConditions: []cmapi.CertificateCondition{},
+		},
+		"should set Issuing=True replacing the SecretTerminating condition once the Secret has been deleted": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateGeneration(42),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+					Type:               "Issuing",
+					Status:             "False",
+					Reason:             "SecretTerminating",
+					Message:            `Secret "secret-1" is being deleted, waiting for it to be removed before issuing`,
+					LastTransitionTime: &fixedNow,
+					ObservedGeneration: 42,
+				}),
+			),
+			wantDataForCertificateCalled: true,
+			mockDataForCertificateReturn: policies.Input{},
+			wantShouldReissueCalled:      true,
+			mockShouldReissue: func(*testing.T) policies.Func {
+				return func(policies.Input) (string, string, bool) {
+					return "DoesNotExist", "Issuing certificate as Secret does not exist", true
+				}
+			},
+			wantEvent: "Normal Issuing Issuing certificate as Secret does not exist",
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               "Issuing",
+				Status:             "True",
+				Reason:             "DoesNotExist",
+				Message:            "Issuing certificate as Secret does not exist",
+				LastTransitionTime: &fixedNow,
+				ObservedGeneration: 42,
+			}},
+		},
+		"should set Issuing=True replacing the IssuerNotFound condition once the issuer exists": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateGeneration(42),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+					Type:               "Issuing",
+					Status:             "False",
+					Reason:             "IssuerNotFound",
+					Message:            `Referenced Issuer "issuer-1" does not exist`,
+					LastTransitionTime: &fixedNow,
+					ObservedGeneration: 42,
+				}),
+			),
+			wantDataForCertificateCalled: true,
+			mockDataForCertificateReturn: policies.Input{},
+			wantShouldReissueCalled:      true,
+			mockShouldReissue: func(*testing.T) policies.Func {
+				return func(policies.Input) (string, string, bool) {
+					return "DoesNotExist", "Issuing certificate as Secret does not exist", true
+				}
+			},
+			wantEvent: "Normal Issuing Issuing certificate as Secret does not exist",
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               "Issuing",
+				Status:             "True",
+				Reason:             "DoesNotExist",
+				Message:            "Issuing certificate as Secret does not exist",
+				LastTransitionTime: &fixedNow,
+				ObservedGeneration: 42,
+			}},
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -275,6 +469,9 @@ func Test_controller_ProcessItem(t *testing.T) {
 				}
 				expectedCert := test.existingCertificate.DeepCopy()
 				expectedCert.Status.Conditions = test.wantConditions
+				if len(test.wantConditions) == 0 {
+					expectedCert.Status.Conditions = nil
+				}
 				builder.ExpectedActions = append(builder.ExpectedActions,
 					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
 						cmapi.SchemeGroupVersion.WithResource("certificates"),
@@ -319,6 +516,59 @@ func Test_controller_ProcessItem(t *testing.T) {
 	}
 }
 
+func Test_controller_ProcessItem_SerialIndex(t *testing.T) {
+	crt := gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"), gen.SetCertificateCommonName("example.com"))
+	certPEM := testcrypto.MustCreateCert(t, testcrypto.MustCreatePEMPrivateKey(t), crt)
+	x509cert, err := pki.DecodeX509CertificateBytes(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		CertManagerObjects: []runtime.Object{crt},
+	}
+	builder.Init()
+	builder.Context.CertificateOptions.DetectDuplicateSerials = true
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	w.shouldReissue = func(policies.Input) (string, string, bool) {
+		return "", "", false
+	}
+	w.dataForCertificate = func(_ context.Context, crt *cmapi.Certificate) (policies.Input, error) {
+		return policies.Input{
+			Certificate: crt,
+			Secret:      &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: certPEM}},
+		}, nil
+	}
+	index := w.controller.policyOptions.SerialIndex
+
+	// Record the serial number for a Certificate that does not exist, which
+	// is forgotten when it is processed. It would otherwise keep cert-1 from
+	// recording the same serial number.
+	index.Record("testns", "deleted", x509cert)
+
+	builder.Start()
+	defer builder.Stop()
+
+	if err := w.controller.ProcessItem(context.Background(), "testns/deleted"); err != nil {
+		t.Fatal(err)
+	}
+	// The serial number of the stored certificate is recorded once the
+	// policies have been evaluated.
+	if err := w.controller.ProcessItem(context.Background(), "testns/cert-1"); err != nil {
+		t.Fatal(err)
+	}
+	other, duplicated := index.Lookup("testns", "cert-2", x509cert)
+	assert.True(t, duplicated)
+	assert.Equal(t, "cert-1", other)
+
+	builder.CheckAndFinish()
+}
+
 func Test_shouldBackoffReissuingOnFailure(t *testing.T) {
 	clock := fakeclock.NewFakeClock(time.Date(2020, 11, 20, 16, 05, 00, 0000, time.Local))
 
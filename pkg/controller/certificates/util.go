@@ -23,8 +23,10 @@ import (
 	"crypto/rsa"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
+	"golang.org/x/net/idna"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -60,6 +62,36 @@ func PrivateKeyMatchesSpec(pk crypto.PrivateKey, spec cmapi.CertificateSpec) ([]
 	}
 }
 
+// ApplyMinimumRSAKeySize returns the Certificate with its RSA private key
+// size raised to minSize if the key size it requests, explicitly or by
+// default, is smaller. This allows a minimum key size to be enforced on
+// Certificates whose spec has not been updated. The given Certificate is not
+// modified, and is returned as is if minSize is not greater than 0 or the
+// Certificate does not use an RSA key.
+func ApplyMinimumRSAKeySize(crt *cmapi.Certificate, minSize int) *cmapi.Certificate {
+	if minSize <= 0 {
+		return crt
+	}
+	keySize := pki.MinRSAKeySize
+	if pk := crt.Spec.PrivateKey; pk != nil {
+		if pk.Algorithm != "" && pk.Algorithm != cmapi.RSAKeyAlgorithm {
+			return crt
+		}
+		if pk.Size > 0 {
+			keySize = pk.Size
+		}
+	}
+	if keySize >= minSize {
+		return crt
+	}
+	crt = crt.DeepCopy()
+	if crt.Spec.PrivateKey == nil {
+		crt.Spec.PrivateKey = &cmapi.CertificatePrivateKey{}
+	}
+	crt.Spec.PrivateKey.Size = minSize
+	return crt
+}
+
 func rsaPrivateKeyMatchesSpec(pk crypto.PrivateKey, spec cmapi.CertificateSpec) ([]string, error) {
 	rsaPk, ok := pk.(*rsa.PrivateKey)
 	if !ok {
@@ -76,7 +108,7 @@ func rsaPrivateKeyMatchesSpec(pk crypto.PrivateKey, spec cmapi.CertificateSpec)
 		keySize = spec.PrivateKey.Size
 	}
 	if rsaPk.N.BitLen() != keySize {
-		violations = append(violations, "spec.keySize")
+		violations = append(violations, "spec.privateKey.size")
 	}
 	return violations, nil
 }
@@ -97,7 +129,7 @@ func ecdsaPrivateKeyMatchesSpec(pk crypto.PrivateKey, spec cmapi.CertificateSpec
 		expectedKeySize = spec.PrivateKey.Size
 	}
 	if expectedKeySize != ecdsaPk.Curve.Params().BitSize {
-		violations = append(violations, "spec.keySize")
+		violations = append(violations, "spec.privateKey.size")
 	}
 	return violations, nil
 }
@@ -184,6 +216,27 @@ func RequestMatchesSpec(req *cmapi.CertificateRequest, spec cmapi.CertificateSpe
 	return violations, nil
 }
 
+// normalizeDNSName returns the given DNS name in lower case, with any
+// internationalized labels converted to their ASCII (punycode) form. Names
+// that cannot be converted are only lower-cased.
+func normalizeDNSName(name string) string {
+	name = strings.ToLower(name)
+	if ascii, err := idna.Punycode.ToASCII(name); err == nil {
+		return ascii
+	}
+	return name
+}
+
+// normalizeDNSNames normalizes each of the given DNS names, see
+// normalizeDNSName.
+func normalizeDNSNames(names []string) []string {
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		normalized[i] = normalizeDNSName(name)
+	}
+	return normalized
+}
+
 // SecretDataAltNamesMatchSpec will compare a Secret resource containing certificate
 // data to a CertificateSpec and return a list of 'violations' for any fields that
 // do not match their counterparts.
@@ -202,21 +255,23 @@ func SecretDataAltNamesMatchSpec(secret *corev1.Secret, spec cmapi.CertificateSp
 	// This check allows names to move between the DNSNames and CommonName
 	// field freely in order to account for CAs behaviour of promoting DNSNames
 	// to be CommonNames or vice-versa.
-	expectedDNSNames := sets.NewString(spec.DNSNames...)
+	// Names are normalized before being compared, as some CAs change the
+	// case or encoding of the names they issue certificates for.
+	expectedDNSNames := sets.NewString(normalizeDNSNames(spec.DNSNames)...)
 	if spec.CommonName != "" {
-		expectedDNSNames.Insert(spec.CommonName)
+		expectedDNSNames.Insert(normalizeDNSName(spec.CommonName))
 	}
-	allDNSNames := sets.NewString(x509cert.DNSNames...)
+	allDNSNames := sets.NewString(normalizeDNSNames(x509cert.DNSNames)...)
 	if x509cert.Subject.CommonName != "" {
-		allDNSNames.Insert(x509cert.Subject.CommonName)
+		allDNSNames.Insert(normalizeDNSName(x509cert.Subject.CommonName))
 	}
 	if !allDNSNames.Equal(expectedDNSNames) {
 		// We know a mismatch occurred, so now determine which fields mismatched.
-		if (spec.CommonName != "" && !allDNSNames.Has(spec.CommonName)) || (x509cert.Subject.CommonName != "" && !expectedDNSNames.Has(x509cert.Subject.CommonName)) {
+		if (spec.CommonName != "" && !allDNSNames.Has(normalizeDNSName(spec.CommonName))) || (x509cert.Subject.CommonName != "" && !expectedDNSNames.Has(normalizeDNSName(x509cert.Subject.CommonName))) {
 			violations = append(violations, "spec.commonName")
 		}
 
-		if !allDNSNames.HasAll(spec.DNSNames...) || !expectedDNSNames.HasAll(x509cert.DNSNames...) {
+		if !allDNSNames.HasAll(normalizeDNSNames(spec.DNSNames)...) || !expectedDNSNames.HasAll(normalizeDNSNames(x509cert.DNSNames)...) {
 			violations = append(violations, "spec.dnsNames")
 		}
 	}
@@ -230,6 +285,40 @@ func SecretDataAltNamesMatchSpec(secret *corev1.Secret, spec cmapi.CertificateSp
 	if !util.EqualUnsorted(x509cert.EmailAddresses, spec.EmailAddresses) {
 		violations = append(violations, "spec.emailAddresses")
 	}
+	// A certificate is only a CA if its BasicConstraints extension says so.
+	if x509cert.IsCA != spec.IsCA {
+		violations = append(violations, "spec.isCA")
+	}
+
+	// The remaining Subject fields are only compared if spec.subject is set,
+	// as some issuers add Subject fields of their own (e.g. an organization)
+	// to the certificates they issue.
+	if spec.Subject != nil {
+		if x509cert.Subject.SerialNumber != spec.Subject.SerialNumber {
+			violations = append(violations, "spec.subject.serialNumber")
+		}
+		if !util.EqualUnsorted(x509cert.Subject.Organization, spec.Subject.Organizations) {
+			violations = append(violations, "spec.subject.organizations")
+		}
+		if !util.EqualUnsorted(x509cert.Subject.Country, spec.Subject.Countries) {
+			violations = append(violations, "spec.subject.countries")
+		}
+		if !util.EqualUnsorted(x509cert.Subject.Locality, spec.Subject.Localities) {
+			violations = append(violations, "spec.subject.localities")
+		}
+		if !util.EqualUnsorted(x509cert.Subject.OrganizationalUnit, spec.Subject.OrganizationalUnits) {
+			violations = append(violations, "spec.subject.organizationalUnits")
+		}
+		if !util.EqualUnsorted(x509cert.Subject.PostalCode, spec.Subject.PostalCodes) {
+			violations = append(violations, "spec.subject.postalCodes")
+		}
+		if !util.EqualUnsorted(x509cert.Subject.Province, spec.Subject.Provinces) {
+			violations = append(violations, "spec.subject.provinces")
+		}
+		if !util.EqualUnsorted(x509cert.Subject.StreetAddress, spec.Subject.StreetAddresses) {
+			violations = append(violations, "spec.subject.streetAddresses")
+		}
+	}
 
 	return violations, nil
 }
@@ -284,26 +373,19 @@ func GenerateLocallySignedTemporaryCertificate(crt *cmapi.Certificate, pkData []
 }
 
 //RenewalTimeFunc is a custom function type for calculating renewal time of a certificate.
-type RenewalTimeFunc func(time.Time, time.Time, *metav1.Duration) *metav1.Time
+type RenewalTimeFunc func(time.Time, time.Time, *metav1.Duration, *int32) *metav1.Time
 
 // RenewalTime calculates renewal time for a certificate. Default renewal time
 // is 2/3 through certificate's lifetime. If user has configured
 // spec.renewBefore, renewal time will be renewBefore period before expiry
-// (unless that is after the expiry).
-func RenewalTime(notBefore, notAfter time.Time, renewBeforeOverride *metav1.Duration) *metav1.Time {
+// (unless that is after the expiry). If user has instead configured
+// spec.renewBeforePercentage, renewal time will be that percentage of the
+// certificate's lifetime before expiry.
+func RenewalTime(notBefore, notAfter time.Time, renewBeforeOverride *metav1.Duration, renewBeforePercentageOverride *int32) *metav1.Time {
 
 	// 1. Calculate how long before expiry a cert should be renewed
 
-	actualDuration := notAfter.Sub(notBefore)
-
-	renewBefore := actualDuration / 3
-
-	// If spec.renewBefore was set (and is less than duration)
-	// respect that. We don't want to prevent users from renewing
-	// longer lived certs more frequently.
-	if renewBeforeOverride != nil && renewBeforeOverride.Duration < actualDuration {
-		renewBefore = renewBeforeOverride.Duration
-	}
+	renewBefore := RenewBefore(notAfter.Sub(notBefore), renewBeforeOverride, renewBeforePercentageOverride)
 
 	// 2. Calculate when a cert should be renewed
 
@@ -320,3 +402,30 @@ func RenewalTime(notBefore, notAfter time.Time, renewBeforeOverride *metav1.Dura
 	rt := metav1.NewTime(notAfter.Add(-1 * renewBefore).Truncate(time.Second))
 	return &rt
 }
+
+// RenewBefore returns how long before expiry a certificate with the given
+// actual duration should be renewed. This is 1/3 of the actual duration,
+// unless spec.renewBefore is set and is less than the actual duration, or
+// spec.renewBeforePercentage is set to a value in the range (0,100).
+// spec.renewBefore and spec.renewBeforePercentage are mutually exclusive and
+// this is enforced by validation, but if both are set then spec.renewBefore
+// takes precedence.
+func RenewBefore(actualDuration time.Duration, renewBeforeOverride *metav1.Duration, renewBeforePercentageOverride *int32) time.Duration {
+	// If spec.renewBefore was set (and is less than duration)
+	// respect that. We don't want to prevent users from renewing
+	// longer lived certs more frequently.
+	if renewBeforeOverride != nil {
+		if renewBeforeOverride.Duration < actualDuration {
+			return renewBeforeOverride.Duration
+		}
+		return actualDuration / 3
+	}
+
+	if p := renewBeforePercentageOverride; p != nil && *p > 0 && *p < 100 {
+		// Split the calculation so that the multiplication cannot overflow
+		// for long lived certificates.
+		return actualDuration/100*time.Duration(*p) + actualDuration%100*time.Duration(*p)/100
+	}
+
+	return actualDuration / 3
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/cert-manager/cert-manager/pkg/util/pki"
@@ -72,7 +73,7 @@ func TestPrivateKeyMatchesSpec(t *testing.T) {
 			key:          mustGenerateRSA(t, 2048),
 			expectedAlgo: cmapi.RSAKeyAlgorithm,
 			expectedSize: 4096,
-			violations:   []string{"spec.keySize"},
+			violations:   []string{"spec.privateKey.size"},
 		},
 		"should match if keySize and algorithm are correct (ECDSA)": {
 			key:          mustGenerateECDSA(t, pki.ECCurve256),
@@ -83,7 +84,7 @@ func TestPrivateKeyMatchesSpec(t *testing.T) {
 			key:          mustGenerateECDSA(t, pki.ECCurve256),
 			expectedAlgo: cmapi.ECDSAKeyAlgorithm,
 			expectedSize: pki.ECCurve521,
-			violations:   []string{"spec.keySize"},
+			violations:   []string{"spec.privateKey.size"},
 		},
 		"should not match if keyAlgorithm is incorrect": {
 			key:          mustGenerateECDSA(t, pki.ECCurve256),
@@ -124,6 +125,65 @@ func TestPrivateKeyMatchesSpec(t *testing.T) {
 	}
 }
 
+func TestApplyMinimumRSAKeySize(t *testing.T) {
+	tests := map[string]struct {
+		privateKey   *cmapi.CertificatePrivateKey
+		minSize      int
+		expectedSize int
+	}{
+		"should not change the size if no minimum is configured": {
+			privateKey:   &cmapi.CertificatePrivateKey{Size: 2048},
+			expectedSize: 2048,
+		},
+		"should raise the default RSA size to the minimum": {
+			minSize:      3072,
+			expectedSize: 3072,
+		},
+		"should raise an RSA size one bit below the minimum": {
+			privateKey:   &cmapi.CertificatePrivateKey{Algorithm: cmapi.RSAKeyAlgorithm, Size: 3071},
+			minSize:      3072,
+			expectedSize: 3072,
+		},
+		"should not change an RSA size equal to the minimum": {
+			privateKey:   &cmapi.CertificatePrivateKey{Algorithm: cmapi.RSAKeyAlgorithm, Size: 3072},
+			minSize:      3072,
+			expectedSize: 3072,
+		},
+		"should not change an RSA size above the minimum": {
+			privateKey:   &cmapi.CertificatePrivateKey{Size: 4096},
+			minSize:      3072,
+			expectedSize: 4096,
+		},
+		"should not change the size of ECDSA keys": {
+			privateKey:   &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm, Size: 256},
+			minSize:      3072,
+			expectedSize: 256,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			crt := &cmapi.Certificate{Spec: cmapi.CertificateSpec{PrivateKey: test.privateKey}}
+			orig := crt.DeepCopy()
+
+			got := ApplyMinimumRSAKeySize(crt, test.minSize)
+
+			size := 0
+			if got.Spec.PrivateKey != nil {
+				size = got.Spec.PrivateKey.Size
+			}
+			if test.privateKey == nil && size == 0 {
+				size = pki.MinRSAKeySize
+			}
+			if size != test.expectedSize {
+				t.Errorf("unexpected key size, got=%d, exp=%d", size, test.expectedSize)
+			}
+			if !reflect.DeepEqual(crt, orig) {
+				t.Errorf("input Certificate was modified")
+			}
+		})
+	}
+}
+
 func TestSecretDataAltNamesMatchSpec(t *testing.T) {
 	tests := map[string]struct {
 		data       []byte
@@ -168,6 +228,49 @@ func TestSecretDataAltNamesMatchSpec(t *testing.T) {
 				DNSNames:   []string{"least", "one"},
 			}),
 		},
+		"should match if dnsNames differ only in case": {
+			spec: cmapi.CertificateSpec{
+				DNSNames: []string{"example.com", "www.example.com"},
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				DNSNames: []string{"Example.COM", "WWW.example.com"},
+			}),
+		},
+		"should match if commonName differs only in case": {
+			spec: cmapi.CertificateSpec{
+				CommonName: "example.com",
+				DNSNames:   []string{"example.com"},
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				CommonName: "EXAMPLE.com",
+				DNSNames:   []string{"example.com"},
+			}),
+		},
+		"should match if a mixed-case dnsName is issued in lower case": {
+			spec: cmapi.CertificateSpec{
+				DNSNames: []string{"*.Example.com"},
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				DNSNames: []string{"*.example.com"},
+			}),
+		},
+		"should match if an internationalized dnsName is issued in its ASCII form": {
+			spec: cmapi.CertificateSpec{
+				DNSNames: []string{"Bücher.example"},
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				DNSNames: []string{"xn--bcher-kva.example"},
+			}),
+		},
+		"should not match if dnsNames differ other than in case": {
+			spec: cmapi.CertificateSpec{
+				DNSNames: []string{"example.com", "www.example.com"},
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				DNSNames: []string{"Example.COM", "WWW.example.org"},
+			}),
+			violations: []string{"spec.dnsNames"},
+		},
 		"should not match if commonName is not present on certificate": {
 			spec: cmapi.CertificateSpec{
 				CommonName: "cn",
@@ -255,6 +358,112 @@ func TestSecretDataAltNamesMatchSpec(t *testing.T) {
 			}),
 			violations: []string{"spec.commonName"},
 		},
+		"should match if subject is equal": {
+			spec: cmapi.CertificateSpec{
+				CommonName: "cn",
+				Subject: &cmapi.X509Subject{
+					Organizations:       []string{"org"},
+					OrganizationalUnits: []string{"unit"},
+					Countries:           []string{"GB"},
+				},
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				CommonName: "cn",
+				Subject: &cmapi.X509Subject{
+					Organizations:       []string{"org"},
+					OrganizationalUnits: []string{"unit"},
+					Countries:           []string{"GB"},
+				},
+			}),
+		},
+		"should match if subject is not set in spec but is set on certificate": {
+			spec: cmapi.CertificateSpec{
+				CommonName: "cn",
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				CommonName: "cn",
+				Subject: &cmapi.X509Subject{
+					Organizations: []string{"org"},
+				},
+			}),
+		},
+		"should not match if organization has changed": {
+			spec: cmapi.CertificateSpec{
+				CommonName: "cn",
+				Subject: &cmapi.X509Subject{
+					Organizations: []string{"new-org"},
+				},
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				CommonName: "cn",
+				Subject: &cmapi.X509Subject{
+					Organizations: []string{"org"},
+				},
+			}),
+			violations: []string{"spec.subject.organizations"},
+		},
+		"should not match if country has changed": {
+			spec: cmapi.CertificateSpec{
+				CommonName: "cn",
+				Subject: &cmapi.X509Subject{
+					Organizations: []string{"org"},
+					Countries:     []string{"US"},
+				},
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				CommonName: "cn",
+				Subject: &cmapi.X509Subject{
+					Organizations: []string{"org"},
+					Countries:     []string{"GB"},
+				},
+			}),
+			violations: []string{"spec.subject.countries"},
+		},
+		"should not match if subject serialNumber has changed": {
+			spec: cmapi.CertificateSpec{
+				CommonName: "cn",
+				Subject: &cmapi.X509Subject{
+					SerialNumber: "device-2",
+				},
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				CommonName: "cn",
+				Subject: &cmapi.X509Subject{
+					SerialNumber: "device-1",
+				},
+			}),
+			violations: []string{"spec.subject.serialNumber"},
+		},
+		"should not match if isCA has changed from false to true": {
+			spec: cmapi.CertificateSpec{
+				CommonName: "cn",
+				IsCA:       true,
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				CommonName: "cn",
+			}),
+			violations: []string{"spec.isCA"},
+		},
+		"should not match if isCA has changed from true to false": {
+			spec: cmapi.CertificateSpec{
+				CommonName: "cn",
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				CommonName: "cn",
+				IsCA:       true,
+			}),
+			violations: []string{"spec.isCA"},
+		},
+		"should match if isCA is true on both": {
+			spec: cmapi.CertificateSpec{
+				CommonName: "cn",
+				IsCA:       true,
+			},
+			data: selfSignCertificate(t, cmapi.CertificateSpec{
+				CommonName: "cn",
+				IsCA:       true,
+			}),
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -297,10 +506,11 @@ func selfSignCertificate(t *testing.T, spec cmapi.CertificateSpec) []byte {
 
 func TestRenewalTime(t *testing.T) {
 	type scenario struct {
-		notBefore           time.Time
-		notAfter            time.Time
-		renewBeforeOverride *metav1.Duration
-		expectedRenewalTime *metav1.Time
+		notBefore                     time.Time
+		notAfter                      time.Time
+		renewBeforeOverride           *metav1.Duration
+		renewBeforePercentageOverride *int32
+		expectedRenewalTime           *metav1.Time
 	}
 	now := time.Now().Truncate(time.Second)
 	tests := map[string]scenario{
@@ -352,10 +562,53 @@ func TestRenewalTime(t *testing.T) {
 			notAfter:            now.Add(time.Hour * 24).Add(time.Second * -1),
 			expectedRenewalTime: &metav1.Time{Time: now.Add(time.Hour * 16).Add(time.Second * -1)},
 		},
+		"spec.renewBeforePercentage is set": {
+			notBefore:                     now,
+			notAfter:                      now.Add(time.Hour * 100),
+			renewBeforePercentageOverride: pointer.Int32(25),
+			expectedRenewalTime:           &metav1.Time{Time: now.Add(time.Hour * 75)},
+		},
+		"spec.renewBeforePercentage is set to the minimum value": {
+			notBefore:                     now,
+			notAfter:                      now.Add(time.Hour * 100),
+			renewBeforePercentageOverride: pointer.Int32(1),
+			expectedRenewalTime:           &metav1.Time{Time: now.Add(time.Hour * 99)},
+		},
+		"spec.renewBeforePercentage is set to the maximum value": {
+			notBefore:                     now,
+			notAfter:                      now.Add(time.Hour * 100),
+			renewBeforePercentageOverride: pointer.Int32(99),
+			expectedRenewalTime:           &metav1.Time{Time: now.Add(time.Hour)},
+		},
+		"spec.renewBeforePercentage is out of range, fall back to the default": {
+			notBefore:                     now,
+			notAfter:                      now.Add(time.Hour * 3),
+			renewBeforePercentageOverride: pointer.Int32(100),
+			expectedRenewalTime:           &metav1.Time{Time: now.Add(time.Hour * 2)},
+		},
+		"spec.renewBeforePercentage is set on a very long lived cert": {
+			notBefore:                     now,
+			notAfter:                      now.Add(time.Hour * 24 * 365 * 100), // 100 years
+			renewBeforePercentageOverride: pointer.Int32(50),
+			expectedRenewalTime:           &metav1.Time{Time: now.Add(time.Hour * 24 * 365 * 50)},
+		},
+		"spec.renewBeforePercentage renewal time is truncated to the nearest second": {
+			notBefore:                     now,
+			notAfter:                      now.Add(time.Hour * 24).Add(time.Second * -1),
+			renewBeforePercentageOverride: pointer.Int32(50),
+			expectedRenewalTime:           &metav1.Time{Time: now.Add(time.Hour * 12).Add(time.Second * -1)},
+		},
+		"both spec.renewBefore and spec.renewBeforePercentage are set, spec.renewBefore takes precedence": {
+			notBefore:                     now,
+			notAfter:                      now.Add(time.Hour * 24),
+			renewBeforeOverride:           &metav1.Duration{Duration: time.Hour * 20},
+			renewBeforePercentageOverride: pointer.Int32(50),
+			expectedRenewalTime:           &metav1.Time{Time: now.Add(time.Hour * 4)},
+		},
 	}
 	for n, s := range tests {
 		t.Run(n, func(t *testing.T) {
-			renewalTime := RenewalTime(s.notBefore, s.notAfter, s.renewBeforeOverride)
+			renewalTime := RenewalTime(s.notBefore, s.notAfter, s.renewBeforeOverride, s.renewBeforePercentageOverride)
 			assert.Equal(t, s.expectedRenewalTime, renewalTime, fmt.Sprintf("Expected renewal time: %v got: %v", s.expectedRenewalTime, renewalTime))
 
 		})
@@ -22,7 +22,11 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/go-logr/logr"
 	certificatesv1 "k8s.io/api/certificates/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -30,10 +34,14 @@ import (
 	certificatesclient "k8s.io/client-go/kubernetes/typed/certificates/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
 
 	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	experimentalapi "github.com/cert-manager/cert-manager/pkg/apis/experimental/v1alpha1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	controllerpkg "github.com/cert-manager/cert-manager/pkg/controller"
 	"github.com/cert-manager/cert-manager/pkg/controller/certificatesigningrequests"
 	"github.com/cert-manager/cert-manager/pkg/controller/certificatesigningrequests/util"
@@ -50,16 +58,83 @@ const (
 
 type signingFn func(*x509.Certificate, *x509.Certificate, crypto.PublicKey, interface{}) ([]byte, *x509.Certificate, error)
 
+// PrivateKeyLoader loads the private key named by a
+// CertificateSigningRequest's private key annotation, which is used to sign
+// the self-signed certificate. Implementations may keep keys outside of
+// Kubernetes, e.g. in a KMS, as long as they can be used as a crypto.Signer.
+// NotFound and InvalidData errors mark the request as failed.
+type PrivateKeyLoader interface {
+	LoadPrivateKey(ctx context.Context, namespace, name string) (crypto.Signer, error)
+}
+
+// defaultPrivateKeySecretKeys are the Secret entries that are tried in order
+// when the private key annotation does not name an entry.
+var defaultPrivateKeySecretKeys = []string{corev1.TLSPrivateKeyKey, "ca.key"}
+
+// missingKeyDataError is returned when none of the candidate entries are
+// present in the referenced Secret.
+type missingKeyDataError struct {
+	namespace, name string
+	keys            []string
+}
+
+func (e *missingKeyDataError) Error() string {
+	return fmt.Sprintf("none of the entries %q are present in secret '%s/%s'", e.keys, e.namespace, e.name)
+}
+
+// splitPrivateKeyRef splits the value of the private key annotation into the
+// Secret name and the entries within it that may hold the private key.
+func splitPrivateKeyRef(ref string) (string, []string) {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) == 2 && parts[1] != "" {
+		return parts[0], []string{parts[1]}
+	}
+	return parts[0], defaultPrivateKeySecretKeys
+}
+
+// secretKeyLoader loads private keys from Secrets. The name may be of the
+// form '<secret name>/<key>' to select the entry holding the key, otherwise
+// the defaultPrivateKeySecretKeys are tried in order.
+type secretKeyLoader struct {
+	secretsLister corelisters.SecretLister
+}
+
+func (l secretKeyLoader) LoadPrivateKey(ctx context.Context, namespace, ref string) (crypto.Signer, error) {
+	name, keys := splitPrivateKeyRef(ref)
+	secret, err := l.secretsLister.Secrets(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if _, ok := secret.Data[key]; !ok {
+			continue
+		}
+		pk, _, err := kube.ParseTLSKeyFromSecret(secret, key)
+		return pk, err
+	}
+
+	return nil, &missingKeyDataError{namespace: namespace, name: name, keys: keys}
+}
+
 // SelfSigned is a controller for signing Kubernetes CertificateSigningRequest
 // using SelfSigning Issuers.
 type SelfSigned struct {
 	issuerOptions controllerpkg.IssuerOptions
 	secretsLister corelisters.SecretLister
 
+	// keyLoader loads the private key used to sign certificates. It defaults
+	// to loading the key from a Secret.
+	keyLoader PrivateKeyLoader
+
 	certClient certificatesclient.CertificateSigningRequestInterface
 
 	recorder record.EventRecorder
 
+	// rateLimiter limits the rate at which each issuer signs requests. It is
+	// nil if signing is not rate limited.
+	rateLimiter *signingRateLimiter
+
 	// Used for testing to get reproducible resulting certificates
 	signingFn signingFn
 }
@@ -75,13 +150,29 @@ func init() {
 
 // NewSelfSigned returns a new instance of SelfSigned type
 func NewSelfSigned(ctx *controllerpkg.Context) certificatesigningrequests.Signer {
-	return &SelfSigned{
+	secretsLister := ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister()
+	s := &SelfSigned{
 		issuerOptions: ctx.IssuerOptions,
-		secretsLister: ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister(),
+		secretsLister: secretsLister,
+		keyLoader:     secretKeyLoader{secretsLister: secretsLister},
 		certClient:    ctx.Client.CertificatesV1().CertificateSigningRequests(),
-		recorder:      ctx.Recorder,
+		recorder:      ctx.EventRecorderFor(ctx.IssuerOptions.SelfSignedEventComponent),
 		signingFn:     pki.SignCertificate,
 	}
+	if qps := ctx.IssuerOptions.SelfSignedSigningQPS; qps > 0 {
+		s.rateLimiter = newSigningRateLimiter(qps, ctx.IssuerOptions.SelfSignedSigningBurst, ctx.Clock)
+	}
+	return s
+}
+
+// NewSelfSignedWithKeyLoader returns a constructor for a SelfSigned signer
+// that loads private keys using the given loader instead of from Secrets.
+func NewSelfSignedWithKeyLoader(loader PrivateKeyLoader) func(*controllerpkg.Context) certificatesigningrequests.Signer {
+	return func(ctx *controllerpkg.Context) certificatesigningrequests.Signer {
+		s := NewSelfSigned(ctx).(*SelfSigned)
+		s.keyLoader = loader
+		return s
+	}
 }
 
 // Sign attempts to sign the given CertificateSigningRequest based on the
@@ -91,39 +182,51 @@ func NewSelfSigned(ctx *controllerpkg.Context) certificatesigningrequests.Signer
 // CertificateSigningRequests must have the
 // "experimental.cert-manager.io/private-key-secret-name" annotation present to
 // be signed. This annotation must reference a valid Secret containing a
-// private key for signing.
+// private key for signing, either as '<secret name>', in which case the key is
+// read from tls.key or else ca.key, or as '<secret name>/<key>'.
 func (s *SelfSigned) Sign(ctx context.Context, csr *certificatesv1.CertificateSigningRequest, issuerObj cmapi.GenericIssuer) error {
 	log := logf.FromContext(ctx, "sign")
 
+	// Defer the request if the issuer has exceeded its signing rate. The
+	// returned error causes the request to be requeued with a backoff.
+	if !s.rateLimiter.tryAccept(issuerObj) {
+		log.V(logf.DebugLevel).Info("signing rate limit exceeded for issuer, requeuing")
+		return fmt.Errorf("signing rate limit of %v per second exceeded for issuer %s", s.rateLimiter.qps, issuerKey(issuerObj))
+	}
+
 	secretName, ok := csr.GetAnnotations()[experimentalapi.CertificateSigningRequestPrivateKeyAnnotationKey]
 	if !ok || len(secretName) == 0 {
 		message := fmt.Sprintf("Missing private key reference annotation: %q", experimentalapi.CertificateSigningRequestPrivateKeyAnnotationKey)
 		log.Error(errors.New(message), "")
 		s.recorder.Event(csr, corev1.EventTypeWarning, "MissingAnnotation", message)
-		util.CertificateSigningRequestSetFailed(csr, "MissingAnnotation", message)
-		_, err := s.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
-		return err
+		return s.setFailed(ctx, csr, "MissingAnnotation", message)
 	}
 
 	resourceNamespace := s.issuerOptions.ResourceNamespace(issuerObj)
 
-	privatekey, err := kube.SecretTLSKey(ctx, s.secretsLister, resourceNamespace, secretName)
+	privatekey, err := s.keyLoader.LoadPrivateKey(ctx, resourceNamespace, secretName)
 	if apierrors.IsNotFound(err) {
-		message := fmt.Sprintf("Referenced Secret %s/%s not found", resourceNamespace, secretName)
+		name, _ := splitPrivateKeyRef(secretName)
+		message := fmt.Sprintf("Referenced Secret %s/%s not found", resourceNamespace, name)
 		log.Error(err, message)
 		s.recorder.Event(csr, corev1.EventTypeWarning, "SecretNotFound", message)
-		util.CertificateSigningRequestSetFailed(csr, "SecretNotFound", message)
-		_, err = s.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
-		return err
+		return s.setFailed(ctx, csr, "SecretNotFound", message)
+	}
+
+	var missingErr *missingKeyDataError
+	if errors.As(err, &missingErr) {
+		message := fmt.Sprintf("Failed to find private key data for %q referenced in annotation %q",
+			secretName, experimentalapi.CertificateSigningRequestPrivateKeyAnnotationKey)
+		log.Error(err, message)
+		s.recorder.Eventf(csr, corev1.EventTypeWarning, "MissingKeyData", "%s: %s", message, err)
+		return s.setFailed(ctx, csr, "MissingKeyData", message)
 	}
 
 	if cmerrors.IsInvalidData(err) {
 		message := fmt.Sprintf("Failed to parse signing key from secret %s/%s", resourceNamespace, secretName)
 		log.Error(err, message)
 		s.recorder.Eventf(csr, corev1.EventTypeWarning, "ErrorParsingKey", "%s: %s", message, err)
-		util.CertificateSigningRequestSetFailed(csr, "ErrorParsingKey", message)
-		_, err = s.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
-		return err
+		return s.setFailed(ctx, csr, "ErrorParsingKey", message)
 	}
 
 	if err != nil {
@@ -131,9 +234,7 @@ func (s *SelfSigned) Sign(ctx context.Context, csr *certificatesv1.CertificateSi
 		message := fmt.Sprintf("Failed to get certificate CA key from secret %s/%s", resourceNamespace, secretName)
 		log.Error(err, message)
 		s.recorder.Eventf(csr, corev1.EventTypeWarning, "ErrorGettingSecret", "%s: %s", message, err)
-		util.CertificateSigningRequestSetFailed(csr, "ErrorGettingSecret", message)
-		_, err = s.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
-		return err
+		return s.setFailed(ctx, csr, "ErrorGettingSecret", message)
 	}
 
 	template, err := pki.GenerateTemplateFromCertificateSigningRequest(csr)
@@ -141,24 +242,39 @@ func (s *SelfSigned) Sign(ctx context.Context, csr *certificatesv1.CertificateSi
 		message := fmt.Sprintf("Error generating certificate template: %s", err)
 		log.Error(err, message)
 		s.recorder.Event(csr, corev1.EventTypeWarning, "ErrorGenerating", message)
-		util.CertificateSigningRequestSetFailed(csr, "ErrorGenerating", message)
-		_, err = s.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
-		return err
+		return s.setFailed(ctx, csr, "ErrorGenerating", message)
+	}
+
+	if err := pki.ValidatePublicKeyAlgorithm(template.PublicKey); err != nil {
+		message := fmt.Sprintf("Public key algorithm of the request is not supported: %s", err)
+		log.Error(err, message)
+		s.recorder.Event(csr, corev1.EventTypeWarning, "UnsupportedKeyAlgorithm", message)
+		return s.setFailed(ctx, csr, "UnsupportedKeyAlgorithm", message)
 	}
 
 	template.CRLDistributionPoints = issuerObj.GetSpec().SelfSigned.CRLDistributionPoints
 
-	// extract the public component of the key
-	publickey, err := pki.PublicKeyForPrivateKey(privatekey)
-	if err != nil {
-		message := "Failed to get public key from private key"
+	selfSigned := issuerObj.GetSpec().SelfSigned
+	if err := pki.AddTemplateSANs(template, selfSigned.AdditionalDNSNames, selfSigned.AdditionalIPAddresses); err != nil {
+		message := fmt.Sprintf("Error adding the issuer's additional subject alternative names: %s", err)
 		log.Error(err, message)
-		s.recorder.Event(csr, corev1.EventTypeWarning, "ErrorPublicKey", message)
-		util.CertificateSigningRequestSetFailed(csr, "ErrorPublicKey", message)
-		_, err = s.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
-		return err
+		s.recorder.Event(csr, corev1.EventTypeWarning, "ErrorGenerating", message)
+		return s.setFailed(ctx, csr, "ErrorGenerating", message)
 	}
 
+	if maxDuration := issuerObj.GetSpec().SelfSigned.MaxDuration; maxDuration != nil {
+		requested := template.NotAfter.Sub(template.NotBefore).Round(time.Second)
+		if pki.LimitTemplateDuration(template, maxDuration.Duration) {
+			message := fmt.Sprintf("Requested duration %s exceeds the issuer's maximum duration, issuing certificate with a duration of %s", requested, maxDuration.Duration)
+			log.V(logf.InfoLevel).Info(message)
+			s.recorder.Event(csr, corev1.EventTypeWarning, "MaxDurationExceeded", message)
+		}
+	}
+
+	// extract the public component of the key. This is done through the
+	// crypto.Signer interface since loaded keys may not expose their type.
+	publickey := privatekey.Public()
+
 	ok, err = pki.PublicKeysEqual(publickey, template.PublicKey)
 	if err != nil || !ok {
 		if err == nil {
@@ -168,22 +284,50 @@ func (s *SelfSigned) Sign(ctx context.Context, csr *certificatesv1.CertificateSi
 		message := "Referenced private key in Secret does not match that in the request"
 		log.Error(err, message)
 		s.recorder.Event(csr, corev1.EventTypeWarning, "ErrorKeyMatch", message)
-		util.CertificateSigningRequestSetFailed(csr, "ErrorKeyMatch", message)
-		_, err = s.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
-		return err
+		return s.setFailed(ctx, csr, "ErrorKeyMatch", message)
+	}
+
+	if name := issuerObj.GetSpec().SelfSigned.SignatureAlgorithm; len(name) > 0 {
+		template.SignatureAlgorithm, err = pki.SignatureAlgorithmForKey(name, publickey)
+		if err != nil {
+			message := fmt.Sprintf("Issuer signature algorithm %q is not compatible with the referenced private key", name)
+			log.Error(err, message)
+			s.recorder.Eventf(csr, corev1.EventTypeWarning, "ErrorSignatureAlgorithm", "%s: %s", message, err)
+			return s.setFailed(ctx, csr, "ErrorSignatureAlgorithm", message)
+		}
+	}
+
+	// Expose the CA stored alongside the private key, if any, so that clients
+	// can validate the chain in the same way as when using a Certificate. This
+	// is done before signing so that a failed update does not discard a
+	// signed certificate.
+	secretName, _ = splitPrivateKeyRef(secretName)
+	if caPEM := s.caCertificateFromSecret(log, resourceNamespace, secretName); len(caPEM) > 0 &&
+		csr.GetAnnotations()[experimentalapi.CertificateSigningRequestCAAnnotationKey] != string(caPEM) {
+		updated, err := s.update(ctx, csr, s.certClient.Update, func(csr *certificatesv1.CertificateSigningRequest) {
+			if csr.Annotations == nil {
+				csr.Annotations = make(map[string]string)
+			}
+			csr.Annotations[experimentalapi.CertificateSigningRequestCAAnnotationKey] = string(caPEM)
+		})
+		if err != nil {
+			message := "Error updating CA certificate annotation"
+			s.recorder.Eventf(csr, corev1.EventTypeWarning, "ErrorUpdate", "%s: %s", message, err)
+			return err
+		}
+		csr = updated
 	}
 
 	certPEM, _, err := s.signingFn(template, template, publickey, privatekey)
 	if err != nil {
 		message := fmt.Sprintf("Error signing certificate: %s", err)
 		s.recorder.Event(csr, corev1.EventTypeWarning, "ErrorSigning", message)
-		util.CertificateSigningRequestSetFailed(csr, "ErrorSigning", message)
-		_, err = s.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
-		return err
+		return s.setFailed(ctx, csr, "ErrorSigning", message)
 	}
 
-	csr.Status.Certificate = certPEM
-	csr, err = s.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
+	csr, err = s.updateStatus(ctx, csr, func(csr *certificatesv1.CertificateSigningRequest) {
+		csr.Status.Certificate = certPEM
+	})
 	if err != nil {
 		message := "Error updating certificate"
 		s.recorder.Eventf(csr, corev1.EventTypeWarning, "ErrorUpdate", "%s: %s", message, err)
@@ -195,3 +339,118 @@ func (s *SelfSigned) Sign(ctx context.Context, csr *certificatesv1.CertificateSi
 
 	return nil
 }
+
+// setFailed marks the given CertificateSigningRequest as failed with the
+// given reason and message, and updates its status.
+func (s *SelfSigned) setFailed(ctx context.Context, csr *certificatesv1.CertificateSigningRequest, reason, message string) error {
+	_, err := s.updateStatus(ctx, csr, func(csr *certificatesv1.CertificateSigningRequest) {
+		util.CertificateSigningRequestSetFailed(csr, reason, message)
+	})
+	return err
+}
+
+// updateStatus applies mutate to the given CertificateSigningRequest and
+// updates its status, as described by update.
+func (s *SelfSigned) updateStatus(ctx context.Context, csr *certificatesv1.CertificateSigningRequest,
+	mutate func(*certificatesv1.CertificateSigningRequest)) (*certificatesv1.CertificateSigningRequest, error) {
+	return s.update(ctx, csr, s.certClient.UpdateStatus, mutate)
+}
+
+// update applies mutate to the given CertificateSigningRequest and writes it
+// using updateFn. If the update fails with a conflict because the
+// CertificateSigningRequest was modified concurrently, the latest version is
+// fetched, mutate is applied to it and the update is retried, a bounded
+// number of times. This avoids re-signing a new certificate just because
+// the resource changed while it was being signed.
+func (s *SelfSigned) update(ctx context.Context, csr *certificatesv1.CertificateSigningRequest,
+	updateFn func(context.Context, *certificatesv1.CertificateSigningRequest, metav1.UpdateOptions) (*certificatesv1.CertificateSigningRequest, error),
+	mutate func(*certificatesv1.CertificateSigningRequest)) (*certificatesv1.CertificateSigningRequest, error) {
+	var updated *certificatesv1.CertificateSigningRequest
+	latest := csr
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if latest == nil {
+			if latest, err = s.certClient.Get(ctx, csr.Name, metav1.GetOptions{}); err != nil {
+				return err
+			}
+		}
+		mutate(latest)
+		updated, err = updateFn(ctx, latest, metav1.UpdateOptions{})
+		if err != nil {
+			latest = nil
+		}
+		return err
+	})
+	if err != nil {
+		// Return the most recent version known, so that events can still
+		// be recorded against it.
+		return csr, err
+	}
+	return updated, nil
+}
+
+// caCertificateFromSecret returns the PEM encoded CA certificate stored in
+// the ca.crt key of the referenced Secret. Nil is returned if the Secret has
+// no CA certificate, or if it is not a valid certificate, since the CA is
+// informational only and must not prevent signing.
+func (s *SelfSigned) caCertificateFromSecret(log logr.Logger, namespace, name string) []byte {
+	secret, err := s.secretsLister.Secrets(namespace).Get(name)
+	if err != nil {
+		return nil
+	}
+	caPEM := secret.Data[cmmeta.TLSCAKey]
+	if len(caPEM) == 0 {
+		return nil
+	}
+	if _, err := pki.DecodeX509CertificateBytes(caPEM); err != nil {
+		log.V(logf.WarnLevel).Info("ignoring invalid CA certificate in referenced Secret", "secret", namespace+"/"+name, "error", err)
+		return nil
+	}
+	return caPEM
+}
+
+// signingRateLimiter limits the rate at which certificates are signed by each
+// issuer, so that a client creating CertificateSigningRequests in a loop
+// cannot exhaust the controller's resources.
+type signingRateLimiter struct {
+	qps   float32
+	burst int
+	clock clock.PassiveClock
+
+	lock     sync.Mutex
+	limiters map[string]flowcontrol.PassiveRateLimiter
+}
+
+func newSigningRateLimiter(qps float32, burst int, c clock.PassiveClock) *signingRateLimiter {
+	return &signingRateLimiter{
+		qps:      qps,
+		burst:    burst,
+		clock:    c,
+		limiters: make(map[string]flowcontrol.PassiveRateLimiter),
+	}
+}
+
+// tryAccept returns true if the given issuer may sign a certificate now. A
+// nil signingRateLimiter accepts every request.
+func (l *signingRateLimiter) tryAccept(issuerObj cmapi.GenericIssuer) bool {
+	if l == nil {
+		return true
+	}
+
+	key := issuerKey(issuerObj)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = flowcontrol.NewTokenBucketPassiveRateLimiterWithClock(l.qps, l.burst, l.clock)
+		l.limiters[key] = limiter
+	}
+	return limiter.TryAccept()
+}
+
+// issuerKey returns a key uniquely identifying the given Issuer or
+// ClusterIssuer. ClusterIssuers have no namespace, so cannot collide with
+// Issuers.
+func issuerKey(issuerObj cmapi.GenericIssuer) string {
+	return issuerObj.GetNamespace() + "/" + issuerObj.GetName()
+}
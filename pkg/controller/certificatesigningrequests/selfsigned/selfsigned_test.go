@@ -19,12 +19,16 @@ package selfsigned
 import (
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
+	"io"
 	"math"
+	"math/big"
 	"testing"
 	"time"
 
@@ -33,9 +37,11 @@ import (
 	authzv1 "k8s.io/api/authorization/v1"
 	certificatesv1 "k8s.io/api/certificates/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 	fakeclock "k8s.io/utils/clock/testing"
 
 	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
@@ -68,12 +74,15 @@ func mustCryptoBundle(t *testing.T) cryptoBundle {
 	if err != nil {
 		t.Fatal(err)
 	}
+	return mustCryptoBundleWithKey(t, key, x509.ECDSAWithSHA256)
+}
 
+func mustCryptoBundleWithKey(t *testing.T, key crypto.Signer, sigAlgo x509.SignatureAlgorithm) cryptoBundle {
 	template := x509.CertificateRequest{
 		Subject: pkix.Name{
 			CommonName: "test",
 		},
-		SignatureAlgorithm: x509.ECDSAWithSHA256,
+		SignatureAlgorithm: sigAlgo,
 	}
 
 	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &template, key)
@@ -105,6 +114,12 @@ func mustCryptoBundle(t *testing.T) cryptoBundle {
 	}
 }
 
+func fakeSecretLister(secret *corev1.Secret) *testlisters.FakeSecretLister {
+	return testlisters.FakeSecretListerFrom(testlisters.NewFakeSecretLister(),
+		testlisters.SetFakeSecretNamespaceListerGet(secret, nil),
+	)
+}
+
 func TestProcessItem(t *testing.T) {
 	metaFixedClockStart := metav1.NewTime(fixedClockStart)
 	util.Clock = fixedClock
@@ -130,6 +145,23 @@ func TestProcessItem(t *testing.T) {
 		}),
 	)
 
+	caPEM, _, err := pki.SignCertificate(&x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test-ca"},
+		NotBefore:    fixedClockStart,
+		NotAfter:     fixedClockStart.Add(time.Hour),
+		IsCA:         true,
+	}, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test-ca"},
+		NotBefore:    fixedClockStart,
+		NotAfter:     fixedClockStart.Add(time.Hour),
+		IsCA:         true,
+	}, csrBundle.key.Public(), csrBundle.key)
+	require.NoError(t, err)
+	secretWithCA := csrBundle.secret.DeepCopy()
+	secretWithCA.Data[cmmeta.TLSCAKey] = caPEM
+
 	tests := map[string]struct {
 		builder     *testpkg.Builder
 		csr         *certificatesv1.CertificateSigningRequest
@@ -629,6 +661,86 @@ func TestProcessItem(t *testing.T) {
 				},
 			},
 		},
+		"an approved CSR whose private key Secret contains a CA certificate should set the CA certificate annotation": {
+			csr: gen.CertificateSigningRequestFrom(baseCSR,
+				gen.SetCertificateSigningRequestStatusCondition(certificatesv1.CertificateSigningRequestCondition{
+					Type:   certificatesv1.CertificateApproved,
+					Status: corev1.ConditionTrue,
+				}),
+				gen.AddCertificateSigningRequestAnnotations(map[string]string{
+					"experimental.cert-manager.io/private-key-secret-name": "test-secret",
+				}),
+				gen.SetCertificateSigningRequestRequest(csrBundle.csrPEM),
+			),
+			signingFn: func(*x509.Certificate, *x509.Certificate, crypto.PublicKey, interface{}) ([]byte, *x509.Certificate, error) {
+				return []byte("signed-cert"), nil, nil
+			},
+
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{baseIssuer.DeepCopy()},
+				KubeObjects:        []runtime.Object{secretWithCA},
+				ExpectedEvents: []string{
+					"Normal CertificateIssued Certificate self signed successfully",
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewCreateAction(
+						authzv1.SchemeGroupVersion.WithResource("subjectaccessreviews"),
+						"",
+						&authzv1.SubjectAccessReview{
+							Spec: authzv1.SubjectAccessReviewSpec{
+								User:   "user-1",
+								Groups: []string{"group-1", "group-2"},
+								Extra: map[string]authzv1.ExtraValue{
+									"extra": []string{"1", "2"},
+								},
+								UID: "uid-1",
+
+								ResourceAttributes: &authzv1.ResourceAttributes{
+									Group:     certmanager.GroupName,
+									Resource:  "signers",
+									Verb:      "reference",
+									Namespace: baseIssuer.Namespace,
+									Name:      baseIssuer.Name,
+									Version:   "*",
+								},
+							},
+						},
+					)),
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						certificatesv1.SchemeGroupVersion.WithResource("certificatesigningrequests"),
+						"",
+						gen.CertificateSigningRequestFrom(baseCSR.DeepCopy(),
+							gen.AddCertificateSigningRequestAnnotations(map[string]string{
+								"experimental.cert-manager.io/private-key-secret-name": "test-secret",
+								"experimental.cert-manager.io/ca-certificate":          string(caPEM),
+							}),
+							gen.SetCertificateSigningRequestRequest(csrBundle.csrPEM),
+							gen.SetCertificateSigningRequestStatusCondition(certificatesv1.CertificateSigningRequestCondition{
+								Type:   certificatesv1.CertificateApproved,
+								Status: corev1.ConditionTrue,
+							}),
+						),
+					)),
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						certificatesv1.SchemeGroupVersion.WithResource("certificatesigningrequests"),
+						"status",
+						"",
+						gen.CertificateSigningRequestFrom(baseCSR.DeepCopy(),
+							gen.AddCertificateSigningRequestAnnotations(map[string]string{
+								"experimental.cert-manager.io/private-key-secret-name": "test-secret",
+								"experimental.cert-manager.io/ca-certificate":          string(caPEM),
+							}),
+							gen.SetCertificateSigningRequestRequest(csrBundle.csrPEM),
+							gen.SetCertificateSigningRequestStatusCondition(certificatesv1.CertificateSigningRequestCondition{
+								Type:   certificatesv1.CertificateApproved,
+								Status: corev1.ConditionTrue,
+							}),
+							gen.SetCertificateSigningRequestCertificate([]byte("signed-cert")),
+						),
+					)),
+				},
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -660,6 +772,7 @@ func TestProcessItem(t *testing.T) {
 
 			if test.fakeLister != nil {
 				selfsigned.secretsLister = test.fakeLister
+				selfsigned.keyLoader = secretKeyLoader{secretsLister: test.fakeLister}
 			}
 
 			if test.signingFn != nil {
@@ -776,12 +889,11 @@ func TestSign(t *testing.T) {
 			builder.Start()
 
 			selfsigned := &SelfSigned{
-				certClient: builder.Client.CertificatesV1().CertificateSigningRequests(),
-				recorder:   new(testpkg.FakeRecorder),
-				secretsLister: testlisters.FakeSecretListerFrom(testlisters.NewFakeSecretLister(),
-					testlisters.SetFakeSecretNamespaceListerGet(csrBundle.secret, nil),
-				),
-				signingFn: pki.SignCertificate,
+				certClient:    builder.Client.CertificatesV1().CertificateSigningRequests(),
+				recorder:      new(testpkg.FakeRecorder),
+				secretsLister: fakeSecretLister(csrBundle.secret),
+				keyLoader:     secretKeyLoader{secretsLister: fakeSecretLister(csrBundle.secret)},
+				signingFn:     pki.SignCertificate,
 			}
 
 			gotErr := selfsigned.Sign(context.Background(), test.csr, test.issuer)
@@ -799,3 +911,588 @@ func TestSign(t *testing.T) {
 		})
 	}
 }
+
+func TestSign_SignatureAlgorithm(t *testing.T) {
+	rsaKey, err := pki.GenerateRSAPrivateKey(2048)
+	require.NoError(t, err)
+	rsaBundle := mustCryptoBundleWithKey(t, rsaKey, x509.SHA256WithRSA)
+	ecBundle := mustCryptoBundle(t)
+
+	tests := map[string]struct {
+		bundle             cryptoBundle
+		signatureAlgorithm string
+
+		expectedSigAlgo x509.SignatureAlgorithm
+		expectedEvent   string
+		expectedFailed  bool
+	}{
+		"an RSA key should be signed with the default signature algorithm if none is configured": {
+			bundle:          rsaBundle,
+			expectedSigAlgo: x509.SHA256WithRSA,
+			expectedEvent:   "Normal CertificateIssued Certificate self signed successfully",
+		},
+		"an RSA key should be signed with SHA384WithRSA if configured on the Issuer": {
+			bundle:             rsaBundle,
+			signatureAlgorithm: "SHA384WithRSA",
+			expectedSigAlgo:    x509.SHA384WithRSA,
+			expectedEvent:      "Normal CertificateIssued Certificate self signed successfully",
+		},
+		"an ECDSA key should fail if the Issuer's signature algorithm requires an RSA key": {
+			bundle:             ecBundle,
+			signatureAlgorithm: "SHA384WithRSA",
+			expectedEvent:      `Warning ErrorSignatureAlgorithm Issuer signature algorithm "SHA384WithRSA" is not compatible with the referenced private key: signature algorithm "SHA384WithRSA" requires a RSA key, but the key is ECDSA`,
+			expectedFailed:     true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			csr := gen.CertificateSigningRequest("csr-1",
+				gen.AddCertificateSigningRequestAnnotations(map[string]string{
+					"experimental.cert-manager.io/private-key-secret-name": "test-secret",
+				}),
+				gen.SetCertificateSigningRequestSignerName("issuers.cert-manager.io/default-unit-test-ns.issuer-1"),
+				gen.SetCertificateSigningRequestRequest(test.bundle.csrPEM),
+			)
+			issuer := gen.Issuer("issuer-1",
+				gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{SignatureAlgorithm: test.signatureAlgorithm}),
+			)
+
+			builder := &testpkg.Builder{
+				KubeObjects:        []runtime.Object{csr, test.bundle.secret},
+				CertManagerObjects: []runtime.Object{issuer},
+			}
+			builder.T = t
+			builder.Init()
+			defer builder.Stop()
+			builder.Start()
+
+			recorder := new(testpkg.FakeRecorder)
+			selfsigned := &SelfSigned{
+				certClient:    builder.Client.CertificatesV1().CertificateSigningRequests(),
+				recorder:      recorder,
+				secretsLister: fakeSecretLister(test.bundle.secret),
+				keyLoader:     secretKeyLoader{secretsLister: fakeSecretLister(test.bundle.secret)},
+				signingFn:     pki.SignCertificate,
+			}
+
+			require.NoError(t, selfsigned.Sign(context.Background(), csr, issuer))
+			builder.Sync()
+			assert.Equal(t, []string{test.expectedEvent}, recorder.Events)
+
+			got, err := builder.Client.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), csr.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+
+			if test.expectedFailed {
+				assert.True(t, util.CertificateSigningRequestIsFailed(got), "expected CertificateSigningRequest to be failed")
+				assert.Empty(t, got.Status.Certificate)
+				return
+			}
+
+			require.NotEmpty(t, got.Status.Certificate)
+			gotCert, err := pki.DecodeX509CertificateBytes(got.Status.Certificate)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedSigAlgo, gotCert.SignatureAlgorithm)
+		})
+	}
+}
+
+func TestSign_UnsupportedKeyAlgorithm(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	require.NoError(t, err)
+	bundle := mustCryptoBundleWithKey(t, key, x509.ECDSAWithSHA256)
+
+	csr := gen.CertificateSigningRequest("csr-1",
+		gen.AddCertificateSigningRequestAnnotations(map[string]string{
+			"experimental.cert-manager.io/private-key-secret-name": "test-secret",
+		}),
+		gen.SetCertificateSigningRequestSignerName("issuers.cert-manager.io/default-unit-test-ns.issuer-1"),
+		gen.SetCertificateSigningRequestRequest(bundle.csrPEM),
+	)
+	issuer := gen.Issuer("issuer-1", gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{}))
+
+	builder := &testpkg.Builder{
+		KubeObjects:        []runtime.Object{csr, bundle.secret},
+		CertManagerObjects: []runtime.Object{issuer},
+	}
+	builder.T = t
+	builder.Init()
+	defer builder.Stop()
+	builder.Start()
+
+	recorder := new(testpkg.FakeRecorder)
+	selfsigned := &SelfSigned{
+		certClient:    builder.Client.CertificatesV1().CertificateSigningRequests(),
+		recorder:      recorder,
+		secretsLister: fakeSecretLister(bundle.secret),
+		keyLoader:     secretKeyLoader{secretsLister: fakeSecretLister(bundle.secret)},
+		signingFn:     pki.SignCertificate,
+	}
+
+	require.NoError(t, selfsigned.Sign(context.Background(), csr, issuer))
+	builder.Sync()
+	assert.Equal(t, []string{
+		"Warning UnsupportedKeyAlgorithm Public key algorithm of the request is not supported: unsupported ecdsa curve: P-224",
+	}, recorder.Events)
+
+	got, err := builder.Client.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), csr.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, got.Status.Certificate)
+	require.True(t, util.CertificateSigningRequestIsFailed(got), "expected CertificateSigningRequest to be failed")
+	for _, cond := range got.Status.Conditions {
+		if cond.Type == certificatesv1.CertificateFailed {
+			assert.Equal(t, "UnsupportedKeyAlgorithm", cond.Reason)
+		}
+	}
+}
+
+func TestSign_UpdateStatusConflict(t *testing.T) {
+	bundle := mustCryptoBundle(t)
+	csr := gen.CertificateSigningRequest("csr-1",
+		gen.AddCertificateSigningRequestAnnotations(map[string]string{
+			"experimental.cert-manager.io/private-key-secret-name": "test-secret",
+		}),
+		gen.SetCertificateSigningRequestSignerName("issuers.cert-manager.io/default-unit-test-ns.issuer-1"),
+		gen.SetCertificateSigningRequestRequest(bundle.csrPEM),
+	)
+	issuer := gen.Issuer("issuer-1", gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{}))
+
+	builder := &testpkg.Builder{
+		KubeObjects:        []runtime.Object{csr, bundle.secret},
+		CertManagerObjects: []runtime.Object{issuer},
+	}
+	builder.T = t
+	builder.Init()
+	defer builder.Stop()
+	builder.Start()
+
+	// Return a conflict for the first status update only.
+	var conflicts int
+	builder.FakeKubeClient().PrependReactor("update", "certificatesigningrequests", func(action coretesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "status" || conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		return true, nil, apierrors.NewConflict(certificatesv1.Resource("certificatesigningrequests"), csr.Name, errors.New("object has been modified"))
+	})
+
+	var signed int
+	recorder := new(testpkg.FakeRecorder)
+	selfsigned := &SelfSigned{
+		certClient:    builder.Client.CertificatesV1().CertificateSigningRequests(),
+		recorder:      recorder,
+		secretsLister: fakeSecretLister(bundle.secret),
+		keyLoader:     secretKeyLoader{secretsLister: fakeSecretLister(bundle.secret)},
+		signingFn: func(template, parent *x509.Certificate, pub crypto.PublicKey, priv interface{}) ([]byte, *x509.Certificate, error) {
+			signed++
+			return pki.SignCertificate(template, parent, pub, priv)
+		},
+	}
+
+	require.NoError(t, selfsigned.Sign(context.Background(), csr, issuer))
+	assert.Equal(t, 1, conflicts, "expected the first status update to conflict")
+	assert.Equal(t, 1, signed, "expected the certificate to be signed only once")
+	assert.Equal(t, []string{"Normal CertificateIssued Certificate self signed successfully"}, recorder.Events)
+
+	got, err := builder.Client.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), csr.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, got.Status.Certificate)
+}
+
+func TestSign_UpdateCAAnnotation(t *testing.T) {
+	bundle := mustCryptoBundle(t)
+	ca := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test-ca"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	caPEM, _, err := pki.SignCertificate(ca, ca, bundle.key.Public(), bundle.key)
+	require.NoError(t, err)
+	secret := bundle.secret.DeepCopy()
+	secret.Data[cmmeta.TLSCAKey] = caPEM
+	issuer := gen.Issuer("issuer-1", gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{}))
+
+	tests := map[string]struct {
+		// updateErrs are returned, in order, by updates to the
+		// CertificateSigningRequest that are not status updates.
+		updateErrs     []error
+		expectedErr    bool
+		expectedSigned int
+		expectedEvents []string
+	}{
+		"a conflict updating the CA certificate annotation should be retried": {
+			updateErrs:     []error{apierrors.NewConflict(certificatesv1.Resource("certificatesigningrequests"), "csr-1", errors.New("object has been modified"))},
+			expectedSigned: 1,
+			expectedEvents: []string{"Normal CertificateIssued Certificate self signed successfully"},
+		},
+		"failing to update the CA certificate annotation should not sign the certificate": {
+			updateErrs:     []error{errors.New("this is a network error")},
+			expectedErr:    true,
+			expectedSigned: 0,
+			expectedEvents: []string{"Warning ErrorUpdate Error updating CA certificate annotation: this is a network error"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			csr := gen.CertificateSigningRequest("csr-1",
+				gen.AddCertificateSigningRequestAnnotations(map[string]string{
+					"experimental.cert-manager.io/private-key-secret-name": "test-secret",
+				}),
+				gen.SetCertificateSigningRequestSignerName("issuers.cert-manager.io/default-unit-test-ns.issuer-1"),
+				gen.SetCertificateSigningRequestRequest(bundle.csrPEM),
+			)
+
+			builder := &testpkg.Builder{
+				KubeObjects:        []runtime.Object{csr, secret},
+				CertManagerObjects: []runtime.Object{issuer},
+			}
+			builder.T = t
+			builder.Init()
+			defer builder.Stop()
+			builder.Start()
+
+			updateErrs := test.updateErrs
+			builder.FakeKubeClient().PrependReactor("update", "certificatesigningrequests", func(action coretesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "status" || len(updateErrs) == 0 {
+					return false, nil, nil
+				}
+				err := updateErrs[0]
+				updateErrs = updateErrs[1:]
+				return true, nil, err
+			})
+
+			var signed int
+			recorder := new(testpkg.FakeRecorder)
+			selfsigned := &SelfSigned{
+				certClient:    builder.Client.CertificatesV1().CertificateSigningRequests(),
+				recorder:      recorder,
+				secretsLister: fakeSecretLister(secret),
+				keyLoader:     secretKeyLoader{secretsLister: fakeSecretLister(secret)},
+				signingFn: func(template, parent *x509.Certificate, pub crypto.PublicKey, priv interface{}) ([]byte, *x509.Certificate, error) {
+					signed++
+					return pki.SignCertificate(template, parent, pub, priv)
+				},
+			}
+
+			err := selfsigned.Sign(context.Background(), csr.DeepCopy(), issuer)
+			assert.Equal(t, test.expectedErr, err != nil, "unexpected error: %v", err)
+			assert.Equal(t, test.expectedSigned, signed)
+			assert.Equal(t, test.expectedEvents, recorder.Events)
+
+			got, err := builder.Client.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), csr.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			if test.expectedErr {
+				assert.Empty(t, got.Status.Certificate)
+				return
+			}
+			assert.Equal(t, string(caPEM), got.Annotations["experimental.cert-manager.io/ca-certificate"])
+			assert.NotEmpty(t, got.Status.Certificate)
+		})
+	}
+}
+
+func TestSign_RateLimit(t *testing.T) {
+	bundle := mustCryptoBundle(t)
+	issuer := gen.Issuer("issuer-1", gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{}))
+	otherIssuer := gen.Issuer("issuer-2", gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{}))
+
+	var csrs []runtime.Object
+	for _, name := range []string{"csr-1", "csr-2", "csr-3", "csr-4", "csr-5"} {
+		csrs = append(csrs, gen.CertificateSigningRequest(name,
+			gen.AddCertificateSigningRequestAnnotations(map[string]string{
+				"experimental.cert-manager.io/private-key-secret-name": "test-secret",
+			}),
+			gen.SetCertificateSigningRequestSignerName("issuers.cert-manager.io/default-unit-test-ns.issuer-1"),
+			gen.SetCertificateSigningRequestRequest(bundle.csrPEM),
+		))
+	}
+
+	builder := &testpkg.Builder{
+		KubeObjects:        append(csrs, bundle.secret),
+		CertManagerObjects: []runtime.Object{issuer, otherIssuer},
+	}
+	builder.T = t
+	builder.Init()
+	defer builder.Stop()
+	builder.Start()
+
+	clock := fakeclock.NewFakeClock(fixedClockStart)
+	var signed int
+	selfsigned := &SelfSigned{
+		certClient:    builder.Client.CertificatesV1().CertificateSigningRequests(),
+		recorder:      new(testpkg.FakeRecorder),
+		secretsLister: fakeSecretLister(bundle.secret),
+		keyLoader:     secretKeyLoader{secretsLister: fakeSecretLister(bundle.secret)},
+		rateLimiter:   newSigningRateLimiter(1, 2, clock),
+		signingFn: func(template, parent *x509.Certificate, pub crypto.PublicKey, priv interface{}) ([]byte, *x509.Certificate, error) {
+			signed++
+			return pki.SignCertificate(template, parent, pub, priv)
+		},
+	}
+	sign := func(i int, issuer cmapi.GenericIssuer) error {
+		return selfsigned.Sign(context.Background(), csrs[i].(*certificatesv1.CertificateSigningRequest), issuer)
+	}
+
+	// The burst allows the first two requests to be signed straight away.
+	require.NoError(t, sign(0, issuer))
+	require.NoError(t, sign(1, issuer))
+	assert.Equal(t, 2, signed)
+
+	// Further requests are deferred until the rate allows them, without
+	// being signed or failed.
+	assert.EqualError(t, sign(2, issuer), "signing rate limit of 1 per second exceeded for issuer default-unit-test-ns/issuer-1")
+	assert.Equal(t, 2, signed)
+	got, err := builder.Client.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), "csr-3", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, got.Status.Certificate)
+	assert.Empty(t, got.Status.Conditions)
+
+	// Other issuers are limited separately.
+	require.NoError(t, sign(3, otherIssuer))
+	assert.Equal(t, 3, signed)
+
+	// A token becomes available once a second has passed.
+	clock.Step(time.Second)
+	require.NoError(t, sign(2, issuer))
+	assert.Error(t, sign(4, issuer))
+	assert.Equal(t, 4, signed)
+}
+
+func TestNewSelfSigned_EventComponent(t *testing.T) {
+	builder := &testpkg.Builder{T: t}
+	builder.Init()
+	defer builder.Stop()
+
+	broadcaster := record.NewBroadcaster()
+	defer broadcaster.Shutdown()
+	events := make(chan *corev1.Event, 1)
+	broadcaster.StartEventWatcher(func(e *corev1.Event) { events <- e })
+	builder.Context.EventBroadcaster = broadcaster
+	builder.Context.IssuerOptions.SelfSignedEventComponent = "cert-manager-selfsigned"
+
+	csr := gen.CertificateSigningRequest("csr-1")
+	csr.TypeMeta = metav1.TypeMeta{APIVersion: "certificates.k8s.io/v1", Kind: "CertificateSigningRequest"}
+
+	selfsigned := NewSelfSigned(builder.Context).(*SelfSigned)
+	selfsigned.recorder.Event(csr, corev1.EventTypeNormal, "CertificateIssued", "Certificate self signed successfully")
+	select {
+	case e := <-events:
+		assert.Equal(t, "cert-manager-selfsigned", e.Source.Component)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
+
+func TestSign_MaxDuration(t *testing.T) {
+	bundle := mustCryptoBundle(t)
+
+	tests := map[string]struct {
+		maxDuration *metav1.Duration
+
+		expectedDuration time.Duration
+		expectedEvents   []string
+	}{
+		"the requested duration should be used if no max duration is configured": {
+			expectedDuration: time.Hour * 48,
+			expectedEvents:   []string{"Normal CertificateIssued Certificate self signed successfully"},
+		},
+		"the requested duration should be used if it is under the max duration": {
+			maxDuration:      &metav1.Duration{Duration: time.Hour * 72},
+			expectedDuration: time.Hour * 48,
+			expectedEvents:   []string{"Normal CertificateIssued Certificate self signed successfully"},
+		},
+		"the max duration should be used if the requested duration is over it": {
+			maxDuration:      &metav1.Duration{Duration: time.Hour * 24},
+			expectedDuration: time.Hour * 24,
+			expectedEvents: []string{
+				"Warning MaxDurationExceeded Requested duration 48h0m0s exceeds the issuer's maximum duration, issuing certificate with a duration of 24h0m0s",
+				"Normal CertificateIssued Certificate self signed successfully",
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			csr := gen.CertificateSigningRequest("csr-1",
+				gen.AddCertificateSigningRequestAnnotations(map[string]string{
+					"experimental.cert-manager.io/private-key-secret-name": "test-secret",
+					"experimental.cert-manager.io/request-duration":        "48h",
+				}),
+				gen.SetCertificateSigningRequestSignerName("issuers.cert-manager.io/default-unit-test-ns.issuer-1"),
+				gen.SetCertificateSigningRequestRequest(bundle.csrPEM),
+			)
+			issuer := gen.Issuer("issuer-1",
+				gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{MaxDuration: test.maxDuration}),
+			)
+
+			builder := &testpkg.Builder{
+				KubeObjects:        []runtime.Object{csr, bundle.secret},
+				CertManagerObjects: []runtime.Object{issuer},
+			}
+			builder.T = t
+			builder.Init()
+			defer builder.Stop()
+			builder.Start()
+
+			recorder := new(testpkg.FakeRecorder)
+			selfsigned := &SelfSigned{
+				certClient:    builder.Client.CertificatesV1().CertificateSigningRequests(),
+				recorder:      recorder,
+				secretsLister: fakeSecretLister(bundle.secret),
+				keyLoader:     secretKeyLoader{secretsLister: fakeSecretLister(bundle.secret)},
+				signingFn:     pki.SignCertificate,
+			}
+
+			require.NoError(t, selfsigned.Sign(context.Background(), csr, issuer))
+			builder.Sync()
+			assert.Equal(t, test.expectedEvents, recorder.Events)
+
+			got, err := builder.Client.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), csr.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.NotEmpty(t, got.Status.Certificate)
+			gotCert, err := pki.DecodeX509CertificateBytes(got.Status.Certificate)
+			require.NoError(t, err)
+			assert.WithinDuration(t, gotCert.NotBefore.Add(test.expectedDuration), gotCert.NotAfter, time.Second)
+		})
+	}
+}
+
+// fakeSigner is a crypto.Signer that hides the type of its underlying key,
+// as is the case for keys held in a KMS.
+type fakeSigner struct {
+	signer crypto.Signer
+}
+
+func (f fakeSigner) Public() crypto.PublicKey {
+	return f.signer.Public()
+}
+
+func (f fakeSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return f.signer.Sign(rand, digest, opts)
+}
+
+type fakeKeyLoader struct {
+	key crypto.Signer
+	err error
+}
+
+func (f fakeKeyLoader) LoadPrivateKey(context.Context, string, string) (crypto.Signer, error) {
+	return f.key, f.err
+}
+
+func TestSign_PrivateKeyLoader(t *testing.T) {
+	bundle := mustCryptoBundle(t)
+	otherBundle := mustCryptoBundle(t)
+
+	secretWithData := func(data map[string][]byte) *corev1.Secret {
+		secret := bundle.secret.DeepCopy()
+		secret.Data = data
+		return secret
+	}
+
+	tests := map[string]struct {
+		annotation     string
+		loader         PrivateKeyLoader
+		expectedReason string
+		expectedEvents []string
+	}{
+		"should sign the certificate using a custom loader": {
+			annotation: "kms-key",
+			loader:     fakeKeyLoader{key: fakeSigner{signer: bundle.key}},
+			expectedEvents: []string{
+				"Normal CertificateIssued Certificate self signed successfully",
+			},
+		},
+		"should fail if a custom loader cannot find the key": {
+			annotation:     "kms-key",
+			loader:         fakeKeyLoader{err: apierrors.NewNotFound(corev1.Resource("secrets"), "kms-key")},
+			expectedReason: "SecretNotFound",
+			expectedEvents: []string{
+				"Warning SecretNotFound Referenced Secret default-unit-test-ns/kms-key not found",
+			},
+		},
+		"should fall back to the key stored in ca.key": {
+			annotation: "test-secret",
+			loader: secretKeyLoader{secretsLister: fakeSecretLister(secretWithData(map[string][]byte{
+				"ca.key": bundle.keyPEM,
+			}))},
+			expectedEvents: []string{
+				"Normal CertificateIssued Certificate self signed successfully",
+			},
+		},
+		"should use the key stored in the entry named by the annotation": {
+			annotation: "test-secret/signing.key",
+			loader: secretKeyLoader{secretsLister: fakeSecretLister(secretWithData(map[string][]byte{
+				corev1.TLSPrivateKeyKey: otherBundle.keyPEM,
+				"signing.key":           bundle.keyPEM,
+			}))},
+			expectedEvents: []string{
+				"Normal CertificateIssued Certificate self signed successfully",
+			},
+		},
+		"should fail if the entry named by the annotation is missing": {
+			annotation:     "test-secret/signing.key",
+			loader:         secretKeyLoader{secretsLister: fakeSecretLister(bundle.secret)},
+			expectedReason: "MissingKeyData",
+			expectedEvents: []string{
+				`Warning MissingKeyData Failed to find private key data for "test-secret/signing.key" referenced in annotation "experimental.cert-manager.io/private-key-secret-name": none of the entries ["signing.key"] are present in secret 'default-unit-test-ns/test-secret'`,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			csr := gen.CertificateSigningRequest("csr-1",
+				gen.AddCertificateSigningRequestAnnotations(map[string]string{
+					"experimental.cert-manager.io/private-key-secret-name": test.annotation,
+				}),
+				gen.SetCertificateSigningRequestSignerName("issuers.cert-manager.io/default-unit-test-ns.issuer-1"),
+				gen.SetCertificateSigningRequestRequest(bundle.csrPEM),
+			)
+			issuer := gen.Issuer("issuer-1", gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{}))
+
+			builder := &testpkg.Builder{
+				KubeObjects:        []runtime.Object{csr},
+				CertManagerObjects: []runtime.Object{issuer},
+			}
+			builder.T = t
+			builder.Init()
+			defer builder.Stop()
+			builder.Start()
+
+			recorder := new(testpkg.FakeRecorder)
+			selfsigned := &SelfSigned{
+				certClient: builder.Client.CertificatesV1().CertificateSigningRequests(),
+				recorder:   recorder,
+				secretsLister: testlisters.FakeSecretListerFrom(testlisters.NewFakeSecretLister(),
+					testlisters.SetFakeSecretNamespaceListerGet(nil, apierrors.NewNotFound(corev1.Resource("secrets"), "test-secret")),
+				),
+				keyLoader: test.loader,
+				signingFn: pki.SignCertificate,
+			}
+
+			require.NoError(t, selfsigned.Sign(context.Background(), csr, issuer))
+			builder.Sync()
+			assert.Equal(t, test.expectedEvents, recorder.Events)
+
+			got, err := builder.Client.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), csr.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			if len(test.expectedReason) > 0 {
+				assert.Empty(t, got.Status.Certificate)
+				require.True(t, util.CertificateSigningRequestIsFailed(got), "expected CertificateSigningRequest to be failed")
+				for _, cond := range got.Status.Conditions {
+					if cond.Type == certificatesv1.CertificateFailed {
+						assert.Equal(t, test.expectedReason, cond.Reason)
+					}
+				}
+				return
+			}
+
+			require.NotEmpty(t, got.Status.Certificate)
+			cert, err := pki.DecodeX509CertificateBytes(got.Status.Certificate)
+			require.NoError(t, err)
+			assert.NoError(t, cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature),
+				"issued certificate was not signed by the loaded key")
+		})
+	}
+}
@@ -40,6 +40,7 @@ import (
 	gwscheme "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/scheme"
 	gwinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
 
+	"github.com/cert-manager/cert-manager/internal/controller/certificates"
 	"github.com/cert-manager/cert-manager/internal/controller/feature"
 	"github.com/cert-manager/cert-manager/pkg/acme/accounts"
 	clientset "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
@@ -52,10 +53,10 @@ import (
 	utilfeature "github.com/cert-manager/cert-manager/pkg/util/feature"
 )
 
-// This sets the informer's resync period to 10 hours
+// ResyncPeriod is the informers' resync period. It is set to 10 hours
 // following the controller-runtime defaults
 // and following discussion: https://github.com/kubernetes-sigs/controller-runtime/pull/88#issuecomment-408500629
-const resyncPeriod = 10 * time.Hour
+const ResyncPeriod = 10 * time.Hour
 
 // Context contains various types that are used by controller implementations.
 // We purposely don't have specific informers/listers here, and instead keep a
@@ -88,6 +89,10 @@ type Context struct {
 
 	// Recorder to record events to
 	Recorder record.EventRecorder
+	// EventBroadcaster is the broadcaster that Recorder records events to.
+	// It is used to create recorders for other source components, see
+	// EventRecorderFor.
+	EventBroadcaster record.EventBroadcaster
 
 	// KubeSharedInformerFactory can be used to obtain shared
 	// SharedIndexInformer instances for Kubernetes types
@@ -151,6 +156,26 @@ type IssuerOptions struct {
 	// IssuerAmbientCredentials controls whether an issuer should pick up ambient
 	// credentials, such as those from metadata services, to construct clients.
 	IssuerAmbientCredentials bool
+
+	// SelfSignedSigningQPS limits the number of CertificateSigningRequests
+	// signed per second by each SelfSigned issuer. Requests exceeding the
+	// limit are requeued. A value of 0 disables the limit.
+	SelfSignedSigningQPS float32
+
+	// SelfSignedSigningBurst is the number of CertificateSigningRequests
+	// that may be signed at once by each SelfSigned issuer when
+	// SelfSignedSigningQPS is set.
+	SelfSignedSigningBurst int
+
+	// SelfSignedEventComponent is the source component of events recorded
+	// by the SelfSigned CertificateSigningRequest signer. If empty, the
+	// controller's Recorder is used.
+	SelfSignedEventComponent string
+
+	// CALimitDurationToCA limits the notAfter of certificates signed by CA
+	// issuers for CertificateRequests to the notAfter of the issuer's CA
+	// certificate, so that they do not outlive the CA.
+	CALimitDurationToCA bool
 }
 
 type ACMEOptions struct {
@@ -174,6 +199,17 @@ type ACMEOptions struct {
 	// for ACME HTTP01 validations.
 	HTTP01SolverNameservers []string
 
+	// HTTP01SolverSkipSelfCheck disables the HTTP01 self-check performed by
+	// the controller before accepting a challenge. The solver resources are
+	// still presented as normal.
+	HTTP01SolverSkipSelfCheck bool
+
+	// HTTP01SolverSharePods causes HTTP01 challenges belonging to the same
+	// Order and using the same solver configuration to share a single solver
+	// Pod and Service. Only the path routed to the shared Service differs
+	// between challenges.
+	HTTP01SolverSharePods bool
+
 	// DNS01CheckAuthoritative is a flag for controlling if auth nss are used
 	// for checking propagation of an RR. This is the ideal scenario
 	DNS01CheckAuthoritative bool
@@ -182,12 +218,52 @@ type ACMEOptions struct {
 	// for ACME DNS01 validations.
 	DNS01Nameservers []string
 
+	// DNS01ProviderQPS limits the rate at which records are presented and
+	// cleaned up with each DNS01 provider. Zero means no limit.
+	DNS01ProviderQPS float32
+
+	// DNS01ProviderBurst is the number of calls that can be made to a DNS01
+	// provider at once before DNS01ProviderQPS applies.
+	DNS01ProviderBurst int
+
+	// DNS01ExecProviderCommands is the list of absolute paths of executables
+	// that the exec DNS01 provider may run. The exec provider is disabled if
+	// the list is empty.
+	DNS01ExecProviderCommands []string
+
 	// AccountRegistry is used as a cache of ACME accounts between various
 	// components of cert-manager
 	AccountRegistry accounts.Registry
 
 	// DNS01CheckRetryPeriod is the time the controller should wait between checking if a ACME dns entry exists.
 	DNS01CheckRetryPeriod time.Duration
+
+	// DNS01CheckInitialDelay is the time the controller should wait before
+	// checking if a challenge has propagated for the first time after
+	// presenting it.
+	DNS01CheckInitialDelay time.Duration
+
+	// IssuerMaintenanceRetryPeriod is the time the controller should wait
+	// before processing a challenge again if its issuer has been marked as
+	// being under maintenance.
+	IssuerMaintenanceRetryPeriod time.Duration
+
+	// ChallengeProcessingWarningThreshold is the time a challenge may be
+	// processing for without reaching a final state before the controller
+	// records a Warning event on it. If zero, no event is recorded.
+	ChallengeProcessingWarningThreshold time.Duration
+
+	// ChallengesEventComponent is the source component of events recorded
+	// by the challenges controller. If empty, the controller's Recorder is
+	// used.
+	ChallengesEventComponent string
+
+	// ChallengeBackoffBaseDelay and ChallengeBackoffMaxDelay are the initial
+	// and maximum delays of the exponential back-off applied to challenges
+	// that fail to sync. If zero, the challenges controller's defaults are
+	// used.
+	ChallengeBackoffBaseDelay time.Duration
+	ChallengeBackoffMaxDelay  time.Duration
 }
 
 // IngressShimOptions contain default Issuer GVK config for the certificate-shim controllers.
@@ -207,12 +283,132 @@ type CertificateOptions struct {
 	// CopiedAnnotationPrefixes defines which annotations should be copied
 	// Certificate -> CertificateRequest, CertificateRequest -> Order.
 	CopiedAnnotationPrefixes []string
+	// RenewalJitterWindow is the size of the window before a Certificate's
+	// renewal time within which its renewal will be triggered. A value of 0
+	// disables jitter.
+	RenewalJitterWindow time.Duration
+	// RenewalGraceTolerance is the amount of time that must have passed
+	// since a Certificate's renewal time before its renewal is triggered.
+	// A value of 0 disables the tolerance.
+	RenewalGraceTolerance time.Duration
+	// RenewalTimeOfDay moves the renewal of Certificates to the next
+	// occurrence of this time of day in UTC, given as the time since
+	// midnight. A nil value disables anchoring.
+	RenewalTimeOfDay *time.Duration
+	// ResyncExpiryMargin causes Certificates that would expire within the
+	// informers' resync period plus this margin to be renewed straight away,
+	// regardless of their renewal time. A value of 0 disables the check.
+	ResyncExpiryMargin time.Duration
+	// DurationTolerance causes Certificates to be reissued if the validity
+	// of their issued certificate exceeds spec.duration by more than this
+	// tolerance. A value of 0 disables the check.
+	DurationTolerance time.Duration
+	// CheckIssuerCAExpiry causes Certificates to be reissued if their issued
+	// certificate expires after the CA certificate of the CA issuer they
+	// reference.
+	CheckIssuerCAExpiry bool
+	// MinimumDuration prevents Certificates whose spec.duration is shorter
+	// than this from being issued, as their issuer would reject them. A
+	// value of 0 disables the check.
+	MinimumDuration time.Duration
+	// MinimumRSAKeySize causes Certificates to be reissued if their stored
+	// RSA private key is smaller than this number of bits, and new RSA
+	// private keys to be generated with at least this size. A value of 0
+	// disables the check.
+	MinimumRSAKeySize int
+	// IssuedBeforeCutoff causes Certificates to be reissued if their issued
+	// certificate has a notBefore earlier than this time. The zero value
+	// disables the check.
+	IssuedBeforeCutoff time.Time
+	// ProtectUnmanagedSecrets prevents Certificates from being issued into
+	// existing Secrets that contain data but were not created by
+	// cert-manager, unless the Secret has been annotated to allow adoption.
+	ProtectUnmanagedSecrets bool
+	// DetectPrivateKeyReuse causes Certificates with a private key rotation
+	// policy of Always to be reissued if their private key is the same as the
+	// one used for the previous revision.
+	DetectPrivateKeyReuse bool
+	// DetectDuplicateSerials causes Certificates whose issued certificate has
+	// the same issuer and serial number as that of another Certificate in the
+	// same namespace to be flagged rather than reissued.
+	DetectDuplicateSerials bool
+	// CompareIssuerProfile causes Certificates to be reissued if the issuer
+	// profile annotation recorded on their Secret differs from the one on
+	// the issuer they reference.
+	CompareIssuerProfile bool
+	// CheckDeniedUsages causes Certificates to be reissued if their issued
+	// certificate has an extended key usage that is denied by the issuer
+	// they reference.
+	CheckDeniedUsages bool
+	// CheckMissingRevision causes Certificates to be reissued if their Secret
+	// does not have the revision annotation.
+	CheckMissingRevision bool
+	// CheckMultipleLeafCertificates causes Certificates to be reissued if the
+	// tls.crt entry of their Secret contains more than one end-entity
+	// certificate.
+	CheckMultipleLeafCertificates bool
+	// CheckChainVerifies causes Certificates to be reissued if the
+	// certificate in their Secret does not verify against the CA
+	// certificates in its ca.crt entry.
+	CheckChainVerifies bool
+	// CheckMissingSANs causes non-CA Certificates with a common name to be
+	// reissued if their issued certificate has no subject alternative name
+	// extension.
+	CheckMissingSANs bool
+	// CheckUnexpectedCommonName causes Certificates without a common name to
+	// be reissued if their issued certificate has a common name.
+	CheckUnexpectedCommonName bool
+	// IgnoreManagedFieldsParseErrors causes managed fields entries on
+	// Certificates' Secrets that cannot be decoded to be treated as empty
+	// when checking the Secret against the SecretTemplate, instead of
+	// stopping the Secret from being reconciled.
+	IgnoreManagedFieldsParseErrors bool
+	// SecretUpdateStrategy is the strategy used to write the Secrets of
+	// Certificates. The managed fields of Secrets are only checked against
+	// the SecretTemplate when Secrets are applied.
+	SecretUpdateStrategy certificates.SecretUpdateStrategy
+	// CompareAuthorityKeyID causes Certificates to be reissued if the
+	// authority key ID of their issued certificate differs from the subject
+	// key ID of the CA used by the issuer they reference.
+	CompareAuthorityKeyID bool
+	// RevokedIntermediatesConfigMap is the <namespace>/<name> of a ConfigMap
+	// holding the SHA-256 fingerprints of revoked intermediate CA
+	// certificates. Certificates signed by one of them are reissued. An
+	// empty value disables the check.
+	RevokedIntermediatesConfigMap string
+	// CheckUsages causes Certificates to be marked as not ready if the key
+	// usages, extended key usages and basic constraints of their issued
+	// certificate contradict each other.
+	CheckUsages bool
+	// DisabledTriggerPolicies holds the names of policies that are left out
+	// of the trigger policy chain.
+	DisabledTriggerPolicies []string
 }
 
 type SchedulerOptions struct {
 	// MaxConcurrentChallenges determines the maximum number of challenges that can be
 	// scheduled as 'processing' at once.
 	MaxConcurrentChallenges int
+
+	// MaxConcurrentChallengesPerIssuer optionally limits the number of
+	// challenges that can be scheduled as 'processing' at once for individual
+	// issuers, keyed as described by scheduler.IssuerKey.
+	MaxConcurrentChallengesPerIssuer map[string]int
+
+	// ReservedMustScheduleChallenges is the number of MaxConcurrentChallenges
+	// reserved for challenges marked as must-schedule, which other
+	// challenges cannot use.
+	ReservedMustScheduleChallenges int
+
+	// StatusUpdateQPS limits the rate at which the scheduler updates the
+	// status of challenges it has scheduled for processing. Zero means no
+	// limit.
+	StatusUpdateQPS float32
+
+	// MaintenanceWindow is a daily window of the form HH:MM-HH:MM, in UTC,
+	// during which only must-schedule challenges are scheduled. Empty means
+	// no maintenance window.
+	MaintenanceWindow string
 }
 
 // ContextFactory is used for constructing new Contexts who's clients have been
@@ -260,9 +456,9 @@ func NewContextFactory(ctx context.Context, opts ContextOptions) (*ContextFactor
 		return nil, err
 	}
 
-	sharedInformerFactory := informers.NewSharedInformerFactoryWithOptions(clients.cmClient, resyncPeriod, informers.WithNamespace(opts.Namespace))
-	kubeSharedInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(clients.kubeClient, resyncPeriod, kubeinformers.WithNamespace(opts.Namespace))
-	gwSharedInformerFactory := gwinformers.NewSharedInformerFactoryWithOptions(clients.gwClient, resyncPeriod, gwinformers.WithNamespace(opts.Namespace))
+	sharedInformerFactory := informers.NewSharedInformerFactoryWithOptions(clients.cmClient, ResyncPeriod, informers.WithNamespace(opts.Namespace))
+	kubeSharedInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(clients.kubeClient, ResyncPeriod, kubeinformers.WithNamespace(opts.Namespace))
+	gwSharedInformerFactory := gwinformers.NewSharedInformerFactoryWithOptions(clients.gwClient, ResyncPeriod, gwinformers.WithNamespace(opts.Namespace))
 
 	return &ContextFactory{
 		baseRestConfig: restConfig,
@@ -308,10 +504,21 @@ func (c *ContextFactory) Build(component ...string) (*Context, error) {
 	ctx.GWClient = clients.gwClient
 	ctx.DiscoveryClient = clients.kubeClient.Discovery()
 	ctx.Recorder = recorder
+	ctx.EventBroadcaster = eventBroadcaster
 
 	return &ctx, nil
 }
 
+// EventRecorderFor returns an event recorder that records events with the
+// given source component. If component is empty, or the Context has no
+// EventBroadcaster, the Context's Recorder is returned.
+func (c *Context) EventRecorderFor(component string) record.EventRecorder {
+	if component == "" || c.EventBroadcaster == nil {
+		return c.Recorder
+	}
+	return c.EventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component})
+}
+
 // contextClients is a helper struct containing API clients.
 type contextClients struct {
 	kubeClient       kubernetes.Interface
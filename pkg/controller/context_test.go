@@ -19,8 +19,12 @@ package controller
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 func Test_NewContextFactory(t *testing.T) {
@@ -40,3 +44,26 @@ func Test_NewContextFactory(t *testing.T) {
 	assert.NotNil(t, ctx1.RESTConfig.RateLimiter)
 	assert.Equal(t, ctx1.RESTConfig.RateLimiter, ctx2.RESTConfig.RateLimiter)
 }
+
+func Test_EventRecorderFor(t *testing.T) {
+	broadcaster := record.NewBroadcaster()
+	defer broadcaster.Shutdown()
+	events := make(chan *corev1.Event, 1)
+	broadcaster.StartEventWatcher(func(e *corev1.Event) { events <- e })
+
+	recorder := record.NewFakeRecorder(1)
+	ctx := &Context{Recorder: recorder, EventBroadcaster: broadcaster}
+
+	assert.Equal(t, recorder, ctx.EventRecorderFor(""), "expected the Context's Recorder for an empty component")
+	assert.Equal(t, recorder, (&Context{Recorder: recorder}).EventRecorderFor("cert-manager-test"),
+		"expected the Context's Recorder without an EventBroadcaster")
+
+	pod := &corev1.Pod{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}}
+	ctx.EventRecorderFor("cert-manager-test").Event(pod, corev1.EventTypeNormal, "Test", "test")
+	select {
+	case e := <-events:
+		assert.Equal(t, "cert-manager-test", e.Source.Component)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
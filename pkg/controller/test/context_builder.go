@@ -159,10 +159,12 @@ func (b *Builder) Init() {
 }
 
 // InitWithRESTConfig() will call builder.Init(), then assign an initialised
-// RESTConfig with a `cert-manager/unit-test` User Agent.
+// RESTConfig with a `cert-manager/unit-test` User Agent. The FieldManager is
+// derived from the User Agent in the same way as for the controller Context.
 func (b *Builder) InitWithRESTConfig() {
 	b.Init()
 	b.RESTConfig = util.RestConfigWithUserAgent(new(rest.Config), "unit-testing")
+	b.FieldManager = util.PrefixFromUserAgent(b.RESTConfig.UserAgent)
 }
 
 func (b *Builder) FakeKubeClient() *kubefake.Clientset {
@@ -36,6 +36,9 @@ type DNSProvider struct {
 	resourceGroupName string
 	zoneName          string
 	log               logr.Logger
+
+	// TTL is the TTL, in seconds, of the TXT records created by the provider.
+	TTL int
 }
 
 // NewDNSProviderCredentials returns a DNSProvider instance configured for the Azure
@@ -68,6 +71,7 @@ func NewDNSProviderCredentials(environment, clientID, clientSecret, subscription
 		resourceGroupName: resourceGroupName,
 		zoneName:          zoneName,
 		log:               logf.Log.WithName("azure-dns"),
+		TTL:               60,
 	}, nil
 }
 
@@ -105,7 +109,7 @@ func getAuthorization(env azure.Environment, clientID, clientSecret, subscriptio
 
 // Present creates a TXT record using the specified parameters
 func (c *DNSProvider) Present(domain, fqdn, value string) error {
-	return c.createRecord(fqdn, value, 60)
+	return c.createRecord(fqdn, value, c.TTL)
 }
 
 // CleanUp removes the TXT record matching the specified parameters
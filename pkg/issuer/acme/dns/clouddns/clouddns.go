@@ -34,6 +34,9 @@ type DNSProvider struct {
 	project          string
 	client           *dns.Service
 	log              logr.Logger
+
+	// TTL is the TTL, in seconds, of the TXT records created by the provider.
+	TTL int
 }
 
 // NewDNSProvider returns a new DNSProvider Instance with configuration
@@ -91,6 +94,7 @@ func NewDNSProviderCredentials(project string, dns01Nameservers []string, hosted
 		dns01Nameservers: dns01Nameservers,
 		hostedZoneName:   hostedZoneName,
 		log:              logf.Log.WithName("clouddns"),
+		TTL:              60,
 	}, nil
 }
 
@@ -139,6 +143,7 @@ func NewDNSProviderServiceAccountBytes(project string, saBytes []byte, dns01Name
 		dns01Nameservers: dns01Nameservers,
 		hostedZoneName:   hostedZoneName,
 		log:              logf.Log.WithName("clouddns"),
+		TTL:              60,
 	}, nil
 }
 
@@ -152,7 +157,7 @@ func (c *DNSProvider) Present(domain, fqdn, value string) error {
 	rec := &dns.ResourceRecordSet{
 		Name:    fqdn,
 		Rrdatas: []string{value},
-		Ttl:     int64(60),
+		Ttl:     int64(c.TTL),
 		Type:    "TXT",
 	}
 	change := &dns.Change{
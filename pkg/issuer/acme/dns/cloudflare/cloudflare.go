@@ -41,6 +41,9 @@ type DNSProvider struct {
 	authToken        string
 
 	userAgent string
+
+	// TTL is the TTL, in seconds, of the TXT records created by the provider.
+	TTL int
 }
 
 // DNSZone is the Zone-Record returned from Cloudflare (we`ll ignore everything we don't need)
@@ -88,6 +91,7 @@ func NewDNSProviderCredentials(email, key, token string, dns01Nameservers []stri
 		authToken:        token,
 		dns01Nameservers: dns01Nameservers,
 		userAgent:        userAgent,
+		TTL:              120,
 	}, nil
 }
 
@@ -132,9 +136,9 @@ func FindNearestZoneForFQDN(c DNSProviderType, fqdn string) (DNSZone, error) {
 		nextName = string([]rune(nextName)[from:to])
 	}
 	if lastErr != nil {
-		return DNSZone{}, fmt.Errorf("while attempting to find Zones for domain %s\n%s", fqdn, lastErr)
+		return DNSZone{}, fmt.Errorf("while attempting to find Zones for domain %s\n%w", fqdn, lastErr)
 	}
-	return DNSZone{}, fmt.Errorf("Found no Zones for domain %s (neither in the sub-domain nor in the SLD) please make sure your domain-entries in the config are correct and the API key is correctly setup with Zone.read rights.", fqdn)
+	return DNSZone{}, util.NewPermanentError(util.ReasonZoneNotFound, fmt.Errorf("Found no Zones for domain %s (neither in the sub-domain nor in the SLD) please make sure your domain-entries in the config are correct and the API key is correctly setup with Zone.read rights.", fqdn))
 }
 
 // Present creates a TXT record to fulfil the dns-01 challenge
@@ -165,7 +169,7 @@ func (c *DNSProvider) Present(domain, fqdn, value string) error {
 		Type:    "TXT",
 		Name:    util.UnFqdn(fqdn),
 		Content: value,
-		TTL:     120,
+		TTL:     c.TTL,
 	}
 
 	body, err := json.Marshal(rec)
@@ -287,6 +291,7 @@ func (c *DNSProvider) makeRequest(method, uri string, body io.Reader) (json.RawM
 	}
 
 	if !r.Success {
+		var err error
 		if len(r.Errors) > 0 {
 			errStr := ""
 			for _, apiErr := range r.Errors {
@@ -295,9 +300,16 @@ func (c *DNSProvider) makeRequest(method, uri string, body io.Reader) (json.RawM
 					errStr += fmt.Sprintf("<- %d: %s", chainErr.Code, chainErr.Message)
 				}
 			}
-			return nil, fmt.Errorf("while querying the Cloudflare API for %s %q \n%s", method, uri, errStr)
+			err = fmt.Errorf("while querying the Cloudflare API for %s %q \n%s", method, uri, errStr)
+		} else {
+			err = fmt.Errorf("while querying the Cloudflare API for %s %q", method, uri)
+		}
+		// The Cloudflare API responds with 401 or 403 if the API key or
+		// token is invalid or lacks the permissions for the request.
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return nil, util.NewPermanentError(util.ReasonInvalidCredentials, err)
 		}
-		return nil, fmt.Errorf("while querying the Cloudflare API for %s %q", method, uri)
+		return nil, err
 	}
 
 	return r.Result, nil
@@ -131,6 +131,34 @@ while querying the Cloudflare API for GET "/zones?name=_acme-challenge.test.sub.
 	assert.Contains(t, err.Error(), "Invalid access token")
 }
 
+func TestFindNearestZoneForFQDNNoZones(t *testing.T) {
+	dnsProvider := new(DNSProviderMock)
+
+	dnsProvider.On("makeRequest", "GET", mock.Anything, mock.Anything).Return([]byte(`[]`), nil)
+
+	_, err := FindNearestZoneForFQDN(dnsProvider, "_acme-challenge.test.sub.domain.com.")
+
+	assert.Error(t, err)
+	permanentErr, ok := util.AsPermanentError(err)
+	assert.True(t, ok, "expected a missing zone to be a permanent error")
+	assert.Equal(t, util.ReasonZoneNotFound, permanentErr.Reason)
+}
+
+func TestFindNearestZoneForFQDNInvalidCredentials(t *testing.T) {
+	dnsProvider := new(DNSProviderMock)
+
+	dnsProvider.On("makeRequest", "GET", mock.Anything, mock.Anything).Return([]byte(nil),
+		util.NewPermanentError(util.ReasonInvalidCredentials, fmt.Errorf(`while querying the Cloudflare API for GET "/zones?name=domain.com"
+	 Error: 9109: Invalid access token`)))
+
+	_, err := FindNearestZoneForFQDN(dnsProvider, "_acme-challenge.test.sub.domain.com.")
+
+	assert.Error(t, err)
+	permanentErr, ok := util.AsPermanentError(err)
+	assert.True(t, ok, "expected invalid credentials to be a permanent error")
+	assert.Equal(t, util.ReasonInvalidCredentials, permanentErr.Reason)
+}
+
 func TestCloudFlarePresent(t *testing.T) {
 	if !cflareLiveTest {
 		t.Skip("skipping live test")
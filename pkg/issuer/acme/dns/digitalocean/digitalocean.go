@@ -34,6 +34,9 @@ import (
 type DNSProvider struct {
 	dns01Nameservers []string
 	client           *godo.Client
+
+	// TTL is the TTL, in seconds, of the TXT records created by the provider.
+	TTL int
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for digitalocean.
@@ -58,6 +61,7 @@ func NewDNSProviderCredentials(token string, dns01Nameservers []string) (*DNSPro
 	return &DNSProvider{
 		dns01Nameservers: dns01Nameservers,
 		client:           godo.NewClient(c),
+		TTL:              60,
 	}, nil
 }
 
@@ -86,7 +90,7 @@ func (c *DNSProvider) Present(domain, fqdn, value string) error {
 		Type: "TXT",
 		Name: fqdn,
 		Data: value,
-		TTL:  60,
+		TTL:  c.TTL,
 	}
 
 	_, _, err = c.client.Domains.CreateRecord(
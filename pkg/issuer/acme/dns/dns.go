@@ -21,11 +21,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/flowcontrol"
 
 	"github.com/cert-manager/cert-manager/pkg/acme/webhook"
 	whapi "github.com/cert-manager/cert-manager/pkg/acme/webhook/apis/acme/v1alpha1"
@@ -39,6 +41,7 @@ import (
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/clouddns"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/cloudflare"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/digitalocean"
+	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/exec"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/rfc2136"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/route53"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/util"
@@ -63,6 +66,7 @@ type dnsProviderConstructors struct {
 	azureDNS     func(environment, clientID, clientSecret, subscriptionID, tenantID, resourceGroupName, hostedZoneName string, dns01Nameservers []string, ambient bool, managedIdentity *cmacme.AzureManagedIdentity) (*azuredns.DNSProvider, error)
 	acmeDNS      func(host string, accountJson []byte, dns01Nameservers []string) (*acmedns.DNSProvider, error)
 	digitalOcean func(token string, dns01Nameservers []string) (*digitalocean.DNSProvider, error)
+	exec         func(command string, allowedCommands []string) (*exec.DNSProvider, error)
 }
 
 // Solver is a solver for the acme dns01 challenge.
@@ -73,6 +77,12 @@ type Solver struct {
 	secretLister            corev1listers.SecretLister
 	dnsProviderConstructors dnsProviderConstructors
 	webhookSolvers          map[string]webhook.Solver
+
+	// providerLimiters rate limit the records presented and cleaned up with
+	// each DNS provider, keyed by providerName. They are only used if
+	// DNS01ProviderQPS is set.
+	providerLimitersLock sync.Mutex
+	providerLimiters     map[string]flowcontrol.RateLimiter
 }
 
 // Present performs the work to configure DNS to resolve a DNS01 challenge.
@@ -85,6 +95,9 @@ func (s *Solver) Present(ctx context.Context, issuer v1.GenericIssuer, ch *cmacm
 		return err
 	}
 	if err == nil {
+		if err := s.waitForProvider(ctx, ch.Spec.Solver.DNS01); err != nil {
+			return err
+		}
 		log.V(logf.InfoLevel).Info("presenting DNS01 challenge for domain")
 		return webhookSolver.Present(req)
 	}
@@ -99,6 +112,9 @@ func (s *Solver) Present(ctx context.Context, issuer v1.GenericIssuer, ch *cmacm
 		return err
 	}
 
+	if err := s.waitForProvider(ctx, providerConfig); err != nil {
+		return err
+	}
 	log.V(logf.DebugLevel).Info("presenting DNS01 challenge for domain")
 
 	return slv.Present(ch.Spec.DNSName, fqdn, ch.Spec.Key)
@@ -108,15 +124,33 @@ func (s *Solver) Present(ctx context.Context, issuer v1.GenericIssuer, ch *cmacm
 func (s *Solver) Check(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
 	log := logf.WithResource(logf.FromContext(ctx, "Check"), ch).WithValues("domain", ch.Spec.DNSName)
 
-	fqdn, err := util.DNS01LookupFQDN(ch.Spec.DNSName, false, s.DNS01Nameservers...)
+	// Check the same record that was written by Present, following the CNAME
+	// for the challenge name if the solver is configured to do so.
+	var strategy cmacme.CNAMEStrategy
+	// The solver may override whether the authoritative nameservers are
+	// queried directly, e.g. to avoid aggressively caching recursive
+	// nameservers.
+	checkAuthoritative := s.Context.DNS01CheckAuthoritative
+	// Unless the solver allows the self-check to pass once any one of the
+	// nameservers has the record, all of them must have it.
+	requireAll := true
+	if ch.Spec.Solver.DNS01 != nil {
+		strategy = ch.Spec.Solver.DNS01.CNAMEStrategy
+		if ch.Spec.Solver.DNS01.CheckAuthoritativeNameservers != nil {
+			checkAuthoritative = *ch.Spec.Solver.DNS01.CheckAuthoritativeNameservers
+		}
+		if ch.Spec.Solver.DNS01.RequireAllNameservers != nil {
+			requireAll = *ch.Spec.Solver.DNS01.RequireAllNameservers
+		}
+	}
+	fqdn, err := util.DNS01LookupFQDN(ch.Spec.DNSName, followCNAME(strategy), s.DNS01Nameservers...)
 	if err != nil {
 		return err
 	}
 
-	log.V(logf.DebugLevel).Info("checking DNS propagation", "nameservers", s.Context.DNS01Nameservers)
+	log.V(logf.DebugLevel).Info("checking DNS propagation", "nameservers", s.Context.DNS01Nameservers, "authoritative", checkAuthoritative, "requireAll", requireAll)
 
-	ok, err := util.PreCheckDNS(fqdn, ch.Spec.Key, s.Context.DNS01Nameservers,
-		s.Context.DNS01CheckAuthoritative)
+	ok, err := util.PreCheckDNS(fqdn, ch.Spec.Key, s.Context.DNS01Nameservers, checkAuthoritative, requireAll)
 	if err != nil {
 		return err
 	}
@@ -143,6 +177,9 @@ func (s *Solver) CleanUp(ctx context.Context, issuer v1.GenericIssuer, ch *cmacm
 		return err
 	}
 	if err == nil {
+		if err := s.waitForProvider(ctx, ch.Spec.Solver.DNS01); err != nil {
+			return err
+		}
 		log.V(logf.DebugLevel).Info("cleaning up DNS01 challenge")
 		return webhookSolver.CleanUp(req)
 	}
@@ -157,9 +194,77 @@ func (s *Solver) CleanUp(ctx context.Context, issuer v1.GenericIssuer, ch *cmacm
 		return err
 	}
 
+	if err := s.waitForProvider(ctx, providerConfig); err != nil {
+		return err
+	}
 	return slv.CleanUp(ch.Spec.DNSName, fqdn, ch.Spec.Key)
 }
 
+// waitForProvider blocks until a record may be presented or cleaned up with
+// the DNS provider configured by the given solver config, according to the
+// configured DNS01ProviderQPS. Each provider is rate limited separately.
+// An error is returned if the context is done before the rate limit allows
+// the call, so that the challenge is requeued rather than blocking a worker.
+func (s *Solver) waitForProvider(ctx context.Context, config *cmacme.ACMEChallengeSolverDNS01) error {
+	if s.DNS01ProviderQPS <= 0 {
+		return nil
+	}
+
+	name := providerName(config)
+	s.providerLimitersLock.Lock()
+	limiter, ok := s.providerLimiters[name]
+	if !ok {
+		burst := s.DNS01ProviderBurst
+		if burst < 1 {
+			burst = 1
+		}
+		limiter = flowcontrol.NewTokenBucketRateLimiter(s.DNS01ProviderQPS, burst)
+		if s.providerLimiters == nil {
+			s.providerLimiters = make(map[string]flowcontrol.RateLimiter)
+		}
+		s.providerLimiters[name] = limiter
+	}
+	s.providerLimitersLock.Unlock()
+
+	logf.FromContext(ctx).V(logf.DebugLevel).Info("waiting for DNS provider rate limit", "provider", name)
+	if err := limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for DNS provider %q rate limit: %w", name, err)
+	}
+	return nil
+}
+
+// providerName returns the name of the DNS provider configured by the given
+// solver config. Webhook solvers are distinguished by their group and solver
+// name.
+func providerName(config *cmacme.ACMEChallengeSolverDNS01) string {
+	switch {
+	case config == nil:
+		return ""
+	case config.Akamai != nil:
+		return "akamai"
+	case config.CloudDNS != nil:
+		return "clouddns"
+	case config.Cloudflare != nil:
+		return "cloudflare"
+	case config.Route53 != nil:
+		return "route53"
+	case config.AzureDNS != nil:
+		return "azuredns"
+	case config.DigitalOcean != nil:
+		return "digitalocean"
+	case config.AcmeDNS != nil:
+		return "acmedns"
+	case config.RFC2136 != nil:
+		return "rfc2136"
+	case config.Webhook != nil:
+		return "webhook/" + config.Webhook.GroupName + "/" + config.Webhook.SolverName
+	case config.Exec != nil:
+		return "exec/" + config.Exec.Command
+	default:
+		return ""
+	}
+}
+
 func followCNAME(strategy cmacme.CNAMEStrategy) bool {
 	return strategy == cmacme.FollowStrategy
 }
@@ -371,13 +476,45 @@ func (s *Solver) solverForChallenge(ctx context.Context, issuer v1.GenericIssuer
 		if err != nil {
 			return nil, providerConfig, fmt.Errorf("error instantiating acmedns challenge solver: %s", err)
 		}
+	case providerConfig.Exec != nil:
+		dbg.Info("preparing to create exec provider")
+		impl, err = s.dnsProviderConstructors.exec(providerConfig.Exec.Command, s.DNS01ExecProviderCommands)
+		if err != nil {
+			return nil, providerConfig, fmt.Errorf("error instantiating exec challenge solver: %s", err)
+		}
 	default:
 		return nil, providerConfig, fmt.Errorf("no dns provider config specified for challenge")
 	}
 
+	if providerConfig.TTL != nil && !setRecordTTL(impl, int(*providerConfig.TTL)) {
+		dbg.Info("DNS01 provider does not support configuring the record TTL, ignoring", "ttl", *providerConfig.TTL)
+	}
+
 	return impl, providerConfig, nil
 }
 
+// setRecordTTL sets the TTL of the TXT records created by the given solver.
+// It returns false if the solver does not support configuring the TTL.
+func setRecordTTL(impl solver, ttl int) bool {
+	switch p := impl.(type) {
+	case *akamai.DNSProvider:
+		p.TTL = ttl
+	case *azuredns.DNSProvider:
+		p.TTL = ttl
+	case *clouddns.DNSProvider:
+		p.TTL = ttl
+	case *cloudflare.DNSProvider:
+		p.TTL = ttl
+	case *digitalocean.DNSProvider:
+		p.TTL = ttl
+	case *route53.DNSProvider:
+		p.TTL = ttl
+	default:
+		return false
+	}
+	return true
+}
+
 func (s *Solver) prepareChallengeRequest(issuer v1.GenericIssuer, ch *cmacme.Challenge) (webhook.Solver, *whapi.ChallengeRequest, error) {
 	dns01Config, err := extractChallengeSolverConfig(ch)
 	if err != nil {
@@ -480,6 +617,7 @@ func NewSolver(ctx *controller.Context) (*Solver, error) {
 			azuredns.NewDNSProviderCredentials,
 			acmedns.NewDNSProviderHostBytes,
 			digitalocean.NewDNSProviderCredentials,
+			exec.NewDNSProvider,
 		},
 		webhookSolvers: initialized,
 	}, nil
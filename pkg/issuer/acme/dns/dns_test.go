@@ -20,11 +20,13 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
 
 	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
 	v1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
@@ -33,6 +35,7 @@ import (
 	"github.com/cert-manager/cert-manager/pkg/controller/test"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/acmedns"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/cloudflare"
+	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/route53"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/util"
 )
 
@@ -352,6 +355,70 @@ func TestSolveForDigitalOcean(t *testing.T) {
 
 }
 
+func TestSolveForExec(t *testing.T) {
+	tests := map[string]struct {
+		allowedCommands []string
+		expectErr       string
+	}{
+		"command is allowed": {
+			allowedCommands: []string{"/usr/local/bin/other", "/usr/local/bin/dns-hook"},
+		},
+		"command is not allowed": {
+			allowedCommands: []string{"/usr/local/bin/other"},
+			expectErr:       `error instantiating exec challenge solver: command "/usr/local/bin/dns-hook" is not allowed by --dns01-exec-provider-commands`,
+		},
+		"exec provider is disabled": {
+			expectErr: "error instantiating exec challenge solver: the exec DNS01 provider is disabled, no commands are allowed by --dns01-exec-provider-commands",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := &solverFixture{
+				Builder: &test.Builder{},
+				Issuer:  newIssuer("test", "default"),
+				Challenge: &cmacme.Challenge{
+					Spec: cmacme.ChallengeSpec{
+						Solver: cmacme.ACMEChallengeSolver{
+							DNS01: &cmacme.ACMEChallengeSolverDNS01{
+								Exec: &cmacme.ACMEIssuerDNS01ProviderExec{
+									Command: "/usr/local/bin/dns-hook",
+								},
+							},
+						},
+					},
+				},
+				dnsProviders: newFakeDNSProviders(),
+			}
+
+			f.Setup(t)
+			defer f.Finish(t)
+
+			s := f.Solver
+			s.DNS01ExecProviderCommands = tc.allowedCommands
+			_, _, err := s.solverForChallenge(context.Background(), f.Issuer, f.Challenge)
+			if tc.expectErr != "" {
+				if err == nil || err.Error() != tc.expectErr {
+					t.Fatalf("expected error %q, but got: %v", tc.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected solverFor to not error, but got: %s", err)
+			}
+
+			expectedExecCall := []fakeDNSProviderCall{
+				{
+					name: "exec",
+					args: []interface{}{"/usr/local/bin/dns-hook", tc.allowedCommands},
+				},
+			}
+			if !reflect.DeepEqual(expectedExecCall, f.dnsProviders.calls) {
+				t.Fatalf("expected %+v == %+v", expectedExecCall, f.dnsProviders.calls)
+			}
+		})
+	}
+}
+
 func TestRoute53TrimCreds(t *testing.T) {
 	f := &solverFixture{
 		Builder: &test.Builder{
@@ -597,3 +664,293 @@ func TestRoute53AssumeRole(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckAuthoritativeNameservers(t *testing.T) {
+	tests := map[string]struct {
+		controllerDefault  bool
+		checkAuthoritative *bool
+		expected           bool
+	}{
+		"uses the controller's setting if the solver does not set it": {
+			controllerDefault: true,
+			expected:          true,
+		},
+		"the solver can disable checking the authoritative nameservers": {
+			controllerDefault:  true,
+			checkAuthoritative: pointer.Bool(false),
+			expected:           false,
+		},
+		"the solver can enable checking the authoritative nameservers": {
+			controllerDefault:  false,
+			checkAuthoritative: pointer.Bool(true),
+			expected:           true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotAuthoritative *bool
+			preCheckDNS := util.PreCheckDNS
+			util.PreCheckDNS = func(fqdn, value string, nameservers []string, useAuthoritative, requireAll bool) (bool, error) {
+				gotAuthoritative = &useAuthoritative
+				// Report the record as not propagated so that Check returns
+				// straight away rather than waiting for the record's TTL.
+				return false, nil
+			}
+			defer func() {
+				util.PreCheckDNS = preCheckDNS
+			}()
+
+			f := &solverFixture{
+				Builder: &test.Builder{},
+				Issuer:  newIssuer("test", "default"),
+				Challenge: &cmacme.Challenge{
+					Spec: cmacme.ChallengeSpec{
+						DNSName: "example.com",
+						Key:     "key",
+						Solver: cmacme.ACMEChallengeSolver{
+							DNS01: &cmacme.ACMEChallengeSolverDNS01{
+								CheckAuthoritativeNameservers: tc.checkAuthoritative,
+							},
+						},
+					},
+				},
+			}
+			f.Setup(t)
+			defer f.Finish(t)
+			f.Solver.Context.DNS01CheckAuthoritative = tc.controllerDefault
+
+			if err := f.Solver.Check(context.Background(), f.Issuer, f.Challenge); err == nil {
+				t.Fatalf("expected Check to return an error as the record is not propagated")
+			}
+			if gotAuthoritative == nil {
+				t.Fatalf("expected the DNS propagation check to be called")
+			}
+			if *gotAuthoritative != tc.expected {
+				t.Errorf("expected useAuthoritative=%t, got %t", tc.expected, *gotAuthoritative)
+			}
+		})
+	}
+}
+
+func TestSolverForChallengeRecordTTL(t *testing.T) {
+	tests := map[string]struct {
+		ttl      *int32
+		expected int
+	}{
+		"uses the provider's default TTL if the solver does not set it": {
+			expected: 10,
+		},
+		"passes the solver's TTL to the provider": {
+			ttl:      pointer.Int32(300),
+			expected: 300,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dnsProviders := newFakeDNSProviders()
+			dnsProviders.constructors.route53 = func(accessKey, secretKey, hostedZoneID, region, role string, ambient bool, dns01Nameservers []string, userAgent string) (*route53.DNSProvider, error) {
+				return &route53.DNSProvider{TTL: 10}, nil
+			}
+
+			f := &solverFixture{
+				Builder: &test.Builder{},
+				Issuer:  newIssuer("test", "default"),
+				Challenge: &cmacme.Challenge{
+					Spec: cmacme.ChallengeSpec{
+						Solver: cmacme.ACMEChallengeSolver{
+							DNS01: &cmacme.ACMEChallengeSolverDNS01{
+								TTL: tc.ttl,
+								Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{
+									Region: "us-west-2",
+								},
+							},
+						},
+					},
+				},
+				dnsProviders: dnsProviders,
+			}
+			f.Setup(t)
+			defer f.Finish(t)
+
+			impl, _, err := f.Solver.solverForChallenge(context.Background(), f.Issuer, f.Challenge)
+			if err != nil {
+				t.Fatalf("expected solverForChallenge to not error, but got: %s", err)
+			}
+			provider, ok := impl.(*route53.DNSProvider)
+			if !ok {
+				t.Fatalf("expected a route53 provider, got %T", impl)
+			}
+			if provider.TTL != tc.expected {
+				t.Errorf("expected TTL=%d, got %d", tc.expected, provider.TTL)
+			}
+		})
+	}
+}
+
+func TestWaitForProviderRateLimit(t *testing.T) {
+	route53Config := &cmacme.ACMEChallengeSolverDNS01{
+		Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{Region: "us-west-2"},
+	}
+	cloudflareConfig := &cmacme.ACMEChallengeSolverDNS01{
+		Cloudflare: &cmacme.ACMEIssuerDNS01ProviderCloudflare{},
+	}
+
+	// tolerance allows for scheduling delays when measuring how long the
+	// calls took.
+	const tolerance = 40 * time.Millisecond
+
+	tests := map[string]struct {
+		qps   float32
+		burst int
+		calls []*cmacme.ACMEChallengeSolverDNS01
+		// expected is the time that should have passed once all calls have
+		// returned.
+		expected time.Duration
+	}{
+		"does not rate limit if no QPS is configured": {
+			calls:    []*cmacme.ACMEChallengeSolverDNS01{route53Config, route53Config, route53Config},
+			expected: 0,
+		},
+		"spaces calls to the same provider according to the QPS": {
+			qps:      10,
+			burst:    1,
+			calls:    []*cmacme.ACMEChallengeSolverDNS01{route53Config, route53Config, route53Config},
+			expected: 200 * time.Millisecond,
+		},
+		"allows a burst of calls before the QPS applies": {
+			qps:      10,
+			burst:    2,
+			calls:    []*cmacme.ACMEChallengeSolverDNS01{route53Config, route53Config, route53Config},
+			expected: 100 * time.Millisecond,
+		},
+		"rate limits each provider separately": {
+			qps:      10,
+			burst:    1,
+			calls:    []*cmacme.ACMEChallengeSolverDNS01{route53Config, cloudflareConfig},
+			expected: 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := &solverFixture{
+				Builder: &test.Builder{},
+			}
+			f.Setup(t)
+			defer f.Finish(t)
+
+			f.Solver.DNS01ProviderQPS = tc.qps
+			f.Solver.DNS01ProviderBurst = tc.burst
+
+			start := time.Now()
+			for _, config := range tc.calls {
+				if err := f.Solver.waitForProvider(context.Background(), config); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if got := time.Since(start); got < tc.expected-tolerance || got > tc.expected+tolerance {
+				t.Errorf("expected calls to take %s, but took %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWaitForProviderContextDone(t *testing.T) {
+	config := &cmacme.ACMEChallengeSolverDNS01{
+		Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{Region: "us-west-2"},
+	}
+
+	f := &solverFixture{
+		Builder: &test.Builder{},
+	}
+	f.Setup(t)
+	defer f.Finish(t)
+
+	// Allow one call per minute, so that the second call would otherwise
+	// block for the lifetime of the test.
+	f.Solver.DNS01ProviderQPS = 1.0 / 60
+	f.Solver.DNS01ProviderBurst = 1
+
+	if err := f.Solver.waitForProvider(context.Background(), config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	if err := f.Solver.waitForProvider(ctx, config); err == nil {
+		t.Errorf("expected an error once the context is done")
+	}
+	if got := time.Since(start); got > 5*time.Second {
+		t.Errorf("expected waiting to stop once the context is done, but took %s", got)
+	}
+}
+
+func TestRequireAllNameservers(t *testing.T) {
+	tests := map[string]struct {
+		requireAllNameservers *bool
+		expected              bool
+	}{
+		"all nameservers must have the record if the solver does not set it": {
+			expected: true,
+		},
+		"the solver can allow any nameserver to have the record": {
+			requireAllNameservers: pointer.Bool(false),
+			expected:              false,
+		},
+		"the solver can require all nameservers to have the record": {
+			requireAllNameservers: pointer.Bool(true),
+			expected:              true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotRequireAll *bool
+			preCheckDNS := util.PreCheckDNS
+			util.PreCheckDNS = func(fqdn, value string, nameservers []string, useAuthoritative, requireAll bool) (bool, error) {
+				gotRequireAll = &requireAll
+				// Report the record as not propagated so that Check returns
+				// straight away rather than waiting for the record's TTL.
+				return false, nil
+			}
+			defer func() {
+				util.PreCheckDNS = preCheckDNS
+			}()
+
+			f := &solverFixture{
+				Builder: &test.Builder{},
+				Issuer:  newIssuer("test", "default"),
+				Challenge: &cmacme.Challenge{
+					Spec: cmacme.ChallengeSpec{
+						DNSName: "example.com",
+						Key:     "key",
+						Solver: cmacme.ACMEChallengeSolver{
+							DNS01: &cmacme.ACMEChallengeSolverDNS01{
+								RequireAllNameservers: tc.requireAllNameservers,
+							},
+						},
+					},
+				},
+			}
+			f.Setup(t)
+			defer f.Finish(t)
+
+			if err := f.Solver.Check(context.Background(), f.Issuer, f.Challenge); err == nil {
+				t.Fatalf("expected Check to return an error as the record is not propagated")
+			}
+			if gotRequireAll == nil {
+				t.Fatalf("expected the DNS propagation check to be called")
+			}
+			if *gotRequireAll != tc.expected {
+				t.Errorf("expected requireAll=%t, got %t", tc.expected, *gotRequireAll)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package exec implements a DNS provider for solving the DNS-01 challenge by
+// running an external executable, in the same way as lego's exec provider.
+package exec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is the default time after which a running executable is
+// killed.
+const DefaultTimeout = 2 * time.Minute
+
+// DNSProvider is an implementation of the acme.ChallengeProvider interface
+// that runs an executable to present and clean up records.
+// The executable is run as `<command> present <fqdn> <value>` or
+// `<command> cleanup <fqdn> <value>`, and must exit with status 0 on success.
+type DNSProvider struct {
+	command string
+
+	// Timeout is the time after which a running executable is killed.
+	Timeout time.Duration
+}
+
+// NewDNSProvider returns a DNSProvider instance that runs the given command.
+// The command must be one of allowedCommands, which is the list of
+// executables the cluster administrator has allowed to be run. The provider
+// is disabled if allowedCommands is empty.
+func NewDNSProvider(command string, allowedCommands []string) (*DNSProvider, error) {
+	if len(allowedCommands) == 0 {
+		return nil, fmt.Errorf("the exec DNS01 provider is disabled, no commands are allowed by --dns01-exec-provider-commands")
+	}
+	for _, allowed := range allowedCommands {
+		if command == allowed {
+			return &DNSProvider{
+				command: command,
+				Timeout: DefaultTimeout,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("command %q is not allowed by --dns01-exec-provider-commands", command)
+}
+
+// Present creates a TXT record to fulfil the dns-01 challenge
+func (c *DNSProvider) Present(domain, fqdn, value string) error {
+	return c.run("present", fqdn, value)
+}
+
+// CleanUp removes the TXT record matching the specified parameters
+func (c *DNSProvider) CleanUp(domain, fqdn, value string) error {
+	return c.run("cleanup", fqdn, value)
+}
+
+// run runs the command with the given action, returning an error containing
+// the command's output if it does not exit with status 0.
+func (c *DNSProvider) run(action, fqdn, value string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.command, action, fqdn, value)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", c.Timeout)
+		}
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("error running %q to %s record for %q: %v: %s", c.command, action, fqdn, err, out)
+		}
+		return fmt.Errorf("error running %q to %s record for %q: %v", c.command, action, fqdn, err)
+	}
+	return nil
+}
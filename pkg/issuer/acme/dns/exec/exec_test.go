@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScript writes an executable shell script with the given body to a
+// temporary directory, returning its path and the path of a file that the
+// script records its arguments in.
+func fakeScript(t *testing.T, body string) (string, string) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "dns-hook")
+	log := filepath.Join(dir, "calls")
+	content := "#!/bin/sh\necho \"$@\" >> " + log + "\n" + body + "\n"
+	require.NoError(t, os.WriteFile(script, []byte(content), 0700))
+	return script, log
+}
+
+func TestNewDNSProvider(t *testing.T) {
+	_, err := NewDNSProvider("/usr/local/bin/dns-hook", nil)
+	assert.EqualError(t, err, "the exec DNS01 provider is disabled, no commands are allowed by --dns01-exec-provider-commands")
+
+	_, err = NewDNSProvider("/usr/local/bin/other", []string{"/usr/local/bin/dns-hook"})
+	assert.EqualError(t, err, `command "/usr/local/bin/other" is not allowed by --dns01-exec-provider-commands`)
+
+	provider, err := NewDNSProvider("/usr/local/bin/dns-hook", []string{"/usr/local/bin/dns-hook"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, provider.Timeout)
+}
+
+func TestPresentAndCleanUp(t *testing.T) {
+	script, log := fakeScript(t, "exit 0")
+	provider, err := NewDNSProvider(script, []string{script})
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Present("example.com", "_acme-challenge.example.com.", "token"))
+	require.NoError(t, provider.CleanUp("example.com", "_acme-challenge.example.com.", "token"))
+
+	calls, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "present _acme-challenge.example.com. token\ncleanup _acme-challenge.example.com. token\n", string(calls))
+}
+
+func TestPresentFailure(t *testing.T) {
+	script, _ := fakeScript(t, "echo 'zone not found'\nexit 3")
+	provider, err := NewDNSProvider(script, []string{script})
+	require.NoError(t, err)
+
+	err = provider.Present("example.com", "_acme-challenge.example.com.", "token")
+	assert.EqualError(t, err, `error running "`+script+`" to present record for "_acme-challenge.example.com.": exit status 3: zone not found`)
+}
+
+func TestPresentTimeout(t *testing.T) {
+	script, _ := fakeScript(t, "exec sleep 10")
+	provider, err := NewDNSProvider(script, []string{script})
+	require.NoError(t, err)
+	provider.Timeout = 100 * time.Millisecond
+
+	err = provider.Present("example.com", "_acme-challenge.example.com.", "token")
+	assert.EqualError(t, err, `error running "`+script+`" to present record for "_acme-challenge.example.com.": timed out after 100ms`)
+}
@@ -31,7 +31,7 @@ import (
 )
 
 const (
-	route53TTL = 10
+	defaultTTL = 10
 )
 
 // DNSProvider implements the util.ChallengeProvider interface
@@ -42,6 +42,9 @@ type DNSProvider struct {
 	log              logr.Logger
 
 	userAgent string
+
+	// TTL is the TTL, in seconds, of the TXT records created by the provider.
+	TTL int
 }
 
 type sessionProvider struct {
@@ -167,25 +170,26 @@ func NewDNSProvider(accessKeyID, secretAccessKey, hostedZoneID, region, role str
 		dns01Nameservers: dns01Nameservers,
 		log:              logf.Log.WithName("route53"),
 		userAgent:        userAgent,
+		TTL:              defaultTTL,
 	}, nil
 }
 
 // Present creates a TXT record using the specified parameters
 func (r *DNSProvider) Present(domain, fqdn, value string) error {
 	value = `"` + value + `"`
-	return r.changeRecord(route53.ChangeActionCreate, fqdn, value, route53TTL)
+	return r.changeRecord(route53.ChangeActionCreate, fqdn, value, r.TTL)
 }
 
 // CleanUp removes the TXT record matching the specified parameters
 func (r *DNSProvider) CleanUp(domain, fqdn, value string) error {
 	value = `"` + value + `"`
-	return r.changeRecord(route53.ChangeActionDelete, fqdn, value, route53TTL)
+	return r.changeRecord(route53.ChangeActionDelete, fqdn, value, r.TTL)
 }
 
 func (r *DNSProvider) changeRecord(action, fqdn, value string, ttl int) error {
 	hostedZoneID, err := r.getHostedZoneID(fqdn)
 	if err != nil {
-		return fmt.Errorf("failed to determine Route 53 hosted zone ID: %v", err)
+		return fmt.Errorf("failed to determine Route 53 hosted zone ID: %w", err)
 	}
 
 	recordSet := newTXTRecordSet(fqdn, value, ttl)
@@ -212,7 +216,7 @@ func (r *DNSProvider) changeRecord(action, fqdn, value string, ttl int) error {
 				return nil
 			}
 		}
-		return fmt.Errorf("failed to change Route 53 record set: %v", removeReqID(err))
+		return fmt.Errorf("failed to change Route 53 record set: %w", classifyError(removeReqID(err)))
 
 	}
 
@@ -249,7 +253,7 @@ func (r *DNSProvider) getHostedZoneID(fqdn string) (string, error) {
 	}
 	resp, err := r.client.ListHostedZonesByName(reqParams)
 	if err != nil {
-		return "", removeReqID(err)
+		return "", classifyError(removeReqID(err))
 	}
 
 	zoneToID := make(map[string]string)
@@ -263,13 +267,13 @@ func (r *DNSProvider) getHostedZoneID(fqdn string) (string, error) {
 	}
 	authZone, err = util.FindBestMatch(fqdn, hostedZones...)
 	if err != nil {
-		return "", fmt.Errorf("zone %s not found in Route 53 for domain %s", authZone, fqdn)
+		return "", util.NewPermanentError(util.ReasonZoneNotFound, fmt.Errorf("zone %s not found in Route 53 for domain %s", authZone, fqdn))
 	}
 
 	hostedZoneID, ok := zoneToID[authZone]
 
 	if len(hostedZoneID) == 0 || !ok {
-		return "", fmt.Errorf("zone %s not found in Route 53 for domain %s", authZone, fqdn)
+		return "", util.NewPermanentError(util.ReasonZoneNotFound, fmt.Errorf("zone %s not found in Route 53 for domain %s", authZone, fqdn))
 	}
 
 	if strings.HasPrefix(hostedZoneID, "/hostedzone/") {
@@ -292,6 +296,20 @@ func newTXTRecordSet(fqdn, value string, ttl int) *route53.ResourceRecordSet {
 	}
 }
 
+// classifyError marks errors returned by the Route 53 API because the
+// credentials in use are invalid or not authorized to manage records as
+// permanent, so that they are not retried. Other errors, e.g. throttling
+// errors, are returned unchanged.
+func classifyError(err error) error {
+	if e, ok := err.(awserr.Error); ok {
+		switch e.Code() {
+		case "AccessDenied", "InvalidClientTokenId", "SignatureDoesNotMatch", "UnrecognizedClientException":
+			return util.NewPermanentError(util.ReasonInvalidCredentials, err)
+		}
+	}
+	return err
+}
+
 // The aws-sdk-go library appends a request id to its error messages. We
 // want our error messages to be the same when the cause is the same to
 // avoid spurious challenge updates.
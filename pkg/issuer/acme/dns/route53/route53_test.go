@@ -140,6 +140,9 @@ func TestRoute53Present(t *testing.T) {
 	nonExistentDomain := "baz.com"
 	err = provider.Present(nonExistentDomain, nonExistentDomain+".", keyAuth)
 	assert.Error(t, err, "Expected Present to return an error")
+	permanentErr, ok := util.AsPermanentError(err)
+	require.True(t, ok, "Expected a missing zone to be a permanent error")
+	assert.Equal(t, util.ReasonZoneNotFound, permanentErr.Reason)
 
 	// This test case makes sure that the request id has been properly
 	// stripped off. It has to be stripped because it changes on every
@@ -321,3 +324,36 @@ func Test_removeReqID(t *testing.T) {
 		})
 	}
 }
+
+func Test_classifyError(t *testing.T) {
+	tests := map[string]struct {
+		err       error
+		expReason string
+	}{
+		"invalid access key is permanent": {
+			err:       awserr.New("InvalidClientTokenId", "The security token included in the request is invalid.", nil),
+			expReason: util.ReasonInvalidCredentials,
+		},
+		"access denied is permanent": {
+			err:       awserr.New("AccessDenied", "User is not authorized to perform: route53:ChangeResourceRecordSets", nil),
+			expReason: util.ReasonInvalidCredentials,
+		},
+		"throttling is transient": {
+			err: awserr.New("Throttling", "Rate exceeded", nil),
+		},
+		"errors not from the AWS SDK are transient": {
+			err: errors.New("connection reset by peer"),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := classifyError(tc.err)
+			assert.Equal(t, tc.err.Error(), err.Error())
+			permanentErr, ok := util.AsPermanentError(err)
+			assert.Equal(t, tc.expReason != "", ok)
+			if ok {
+				assert.Equal(t, tc.expReason, permanentErr.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import "errors"
+
+// Reasons describing the class of a PermanentError.
+const (
+	// ReasonInvalidCredentials is used when the DNS provider rejects the
+	// configured credentials, or they lack the permissions required to
+	// manage records.
+	ReasonInvalidCredentials = "InvalidCredentials"
+	// ReasonZoneNotFound is used when no zone managed by the DNS provider
+	// could be found for the challenge's domain.
+	ReasonZoneNotFound = "ZoneNotFound"
+)
+
+// PermanentError is returned by DNS providers when presenting a challenge
+// fails in a way that retrying will not resolve, e.g. because the provider's
+// credentials are invalid or the zone does not exist. Errors that are not
+// PermanentErrors, such as throttling or network errors, are assumed to be
+// transient.
+type PermanentError struct {
+	// Reason is a short CamelCase description of the class of error, e.g.
+	// ReasonInvalidCredentials.
+	Reason string
+	// Err is the error returned by the DNS provider.
+	Err error
+}
+
+// NewPermanentError wraps err in a PermanentError with the given reason.
+func NewPermanentError(reason string, err error) error {
+	return &PermanentError{Reason: reason, Err: err}
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// AsPermanentError returns the first PermanentError in err's chain, if any.
+func AsPermanentError(err error) (*PermanentError, bool) {
+	var permanentErr *PermanentError
+	if errors.As(err, &permanentErr) {
+		return permanentErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsPermanentError(t *testing.T) {
+	cause := errors.New("zone example.com. not found")
+
+	tests := map[string]struct {
+		err       error
+		expReason string
+		expOK     bool
+	}{
+		"a transient error is not permanent": {
+			err: errors.New("rate exceeded"),
+		},
+		"a permanent error is returned": {
+			err:       NewPermanentError(ReasonZoneNotFound, cause),
+			expReason: ReasonZoneNotFound,
+			expOK:     true,
+		},
+		"a wrapped permanent error is returned": {
+			err:       fmt.Errorf("failed to determine hosted zone: %w", NewPermanentError(ReasonZoneNotFound, cause)),
+			expReason: ReasonZoneNotFound,
+			expOK:     true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			permanentErr, ok := AsPermanentError(tc.err)
+			assert.Equal(t, tc.expOK, ok)
+			if tc.expOK {
+				assert.Equal(t, tc.expReason, permanentErr.Reason)
+				assert.Equal(t, cause.Error(), permanentErr.Error())
+				assert.True(t, errors.Is(tc.err, cause))
+			}
+		})
+	}
+}
@@ -21,7 +21,7 @@ import (
 )
 
 type preCheckDNSFunc func(fqdn, value string, nameservers []string,
-	useAuthoritative bool) (bool, error)
+	useAuthoritative, requireAll bool) (bool, error)
 type dnsQueryFunc func(fqdn string, rtype uint16, nameservers []string, recursive bool) (in *dns.Msg, err error)
 
 var (
@@ -100,9 +100,12 @@ func followCNAMEs(fqdn string, nameservers []string, fqdnChain ...string) (strin
 	return fqdn, nil
 }
 
-// checkDNSPropagation checks if the expected TXT record has been propagated to all authoritative nameservers.
+// checkDNSPropagation checks if the expected TXT record has been propagated
+// to the authoritative nameservers, or to the given nameservers if
+// useAuthoritative is false. If requireAll is false, the record only needs to
+// have propagated to one of the nameservers.
 func checkDNSPropagation(fqdn, value string, nameservers []string,
-	useAuthoritative bool) (bool, error) {
+	useAuthoritative, requireAll bool) (bool, error) {
 
 	var err error
 	fqdn, err = followCNAMEs(fqdn, nameservers)
@@ -111,7 +114,7 @@ func checkDNSPropagation(fqdn, value string, nameservers []string,
 	}
 
 	if !useAuthoritative {
-		return checkAuthoritativeNss(fqdn, value, nameservers)
+		return checkAuthoritativeNss(fqdn, value, nameservers, requireAll)
 	}
 
 	authoritativeNss, err := lookupNameservers(fqdn, nameservers)
@@ -122,20 +125,27 @@ func checkDNSPropagation(fqdn, value string, nameservers []string,
 	for i, ans := range authoritativeNss {
 		authoritativeNss[i] = net.JoinHostPort(ans, "53")
 	}
-	return checkAuthoritativeNss(fqdn, value, authoritativeNss)
+	return checkAuthoritativeNss(fqdn, value, authoritativeNss, requireAll)
 }
 
-// checkAuthoritativeNss queries each of the given nameservers for the expected TXT record.
-func checkAuthoritativeNss(fqdn, value string, nameservers []string) (bool, error) {
+// checkAuthoritativeNss queries each of the given nameservers for the expected
+// TXT record. If requireAll is true, every nameserver must return the record;
+// otherwise it is enough for any one of them to return it, and errors from the
+// other nameservers are only returned if none of them do.
+func checkAuthoritativeNss(fqdn, value string, nameservers []string, requireAll bool) (bool, error) {
+	var lastErr error
 	for _, ns := range nameservers {
-		r, err := DNSQuery(fqdn, dns.TypeTXT, []string{ns}, true)
-		if err != nil {
-			return false, err
+		r, err := dnsQuery(fqdn, dns.TypeTXT, []string{ns}, true)
+		if err == nil && !(r.Rcode == dns.RcodeSuccess || r.Rcode == dns.RcodeNameError) {
+			// NXDomain response is not really an error, just waiting for propagation to happen
+			err = fmt.Errorf("NS %s returned %s for %s", ns, dns.RcodeToString[r.Rcode], fqdn)
 		}
-
-		// NXDomain response is not really an error, just waiting for propagation to happen
-		if !(r.Rcode == dns.RcodeSuccess || r.Rcode == dns.RcodeNameError) {
-			return false, fmt.Errorf("NS %s returned %s for %s", ns, dns.RcodeToString[r.Rcode], fqdn)
+		if err != nil {
+			if requireAll {
+				return false, err
+			}
+			lastErr = err
+			continue
 		}
 
 		logf.V(logf.DebugLevel).Infof("Looking up TXT records for %q", fqdn)
@@ -149,11 +159,17 @@ func checkAuthoritativeNss(fqdn, value string, nameservers []string) (bool, erro
 			}
 		}
 
-		if !found {
+		if found && !requireAll {
+			return true, nil
+		}
+		if !found && requireAll {
 			return false, nil
 		}
 	}
 
+	if !requireAll {
+		return false, lastErr
+	}
 	return true, nil
 }
 
@@ -301,7 +317,7 @@ func lookupNameservers(fqdn string, nameservers []string) ([]string, error) {
 		return nil, fmt.Errorf("Could not determine the zone for %q: %v", fqdn, err)
 	}
 
-	r, err := DNSQuery(zone, dns.TypeNS, nameservers, true)
+	r, err := dnsQuery(zone, dns.TypeNS, nameservers, true)
 	if err != nil {
 		return nil, err
 	}
@@ -349,7 +365,7 @@ func FindZoneByFqdn(fqdn string, nameservers []string) (string, error) {
 	for _, index := range labelIndexes {
 		domain := fqdn[index:]
 
-		in, err := DNSQuery(domain, dns.TypeSOA, nameservers, true)
+		in, err := dnsQuery(domain, dns.TypeSOA, nameservers, true)
 		if err != nil {
 			return "", err
 		}
@@ -167,7 +167,7 @@ func TestMatchCAA(t *testing.T) {
 
 func TestPreCheckDNS(t *testing.T) {
 	// TODO: find a better TXT record to use in tests
-	ok, err := PreCheckDNS("google.com.", "v=spf1 include:_spf.google.com ~all", []string{"8.8.8.8:53"}, true)
+	ok, err := PreCheckDNS("google.com.", "v=spf1 include:_spf.google.com ~all", []string{"8.8.8.8:53"}, true, true)
 	if err != nil || !ok {
 		t.Errorf("preCheckDNS failed for acme-staging.api.letsencrypt.org: %s", err.Error())
 	}
@@ -175,7 +175,7 @@ func TestPreCheckDNS(t *testing.T) {
 
 func TestPreCheckDNSNonAuthoritative(t *testing.T) {
 	// TODO: find a better TXT record to use in tests
-	ok, err := PreCheckDNS("google.com.", "v=spf1 include:_spf.google.com ~all", []string{"1.1.1.1:53"}, false)
+	ok, err := PreCheckDNS("google.com.", "v=spf1 include:_spf.google.com ~all", []string{"1.1.1.1:53"}, false, true)
 	if err != nil || !ok {
 		t.Errorf("preCheckDNS failed for acme-staging.api.letsencrypt.org: %s", err.Error())
 	}
@@ -225,7 +225,7 @@ func TestFindZoneByFqdn(t *testing.T) {
 
 func TestCheckAuthoritativeNss(t *testing.T) {
 	for _, tt := range checkAuthoritativeNssTests {
-		ok, _ := checkAuthoritativeNss(tt.fqdn, tt.value, tt.ns)
+		ok, _ := checkAuthoritativeNss(tt.fqdn, tt.value, tt.ns, true)
 		if ok != tt.ok {
 			t.Errorf("%s: got %t; want %t", tt.fqdn, ok, tt.ok)
 		}
@@ -234,7 +234,7 @@ func TestCheckAuthoritativeNss(t *testing.T) {
 
 func TestCheckAuthoritativeNssErr(t *testing.T) {
 	for _, tt := range checkAuthoritativeNssTestsErr {
-		_, err := checkAuthoritativeNss(tt.fqdn, tt.value, tt.ns)
+		_, err := checkAuthoritativeNss(tt.fqdn, tt.value, tt.ns, true)
 		if err == nil {
 			t.Fatalf("#%s: expected %q (error); got <nil>", tt.fqdn, tt.error)
 		}
@@ -391,3 +391,219 @@ func Test_followCNAMEs(t *testing.T) {
 		})
 	}
 }
+
+func Test_delegatedChallenge(t *testing.T) {
+	const (
+		challengeFQDN = "_acme-challenge.example.com."
+		delegatedFQDN = "_acme-challenge.example.org."
+	)
+	dnsQuery = func(fqdn string, rtype uint16, nameservers []string, recursive bool) (in *dns.Msg, err error) {
+		msg := &dns.Msg{}
+		msg.Rcode = dns.RcodeSuccess
+		switch {
+		case fqdn == challengeFQDN && rtype == dns.TypeCNAME:
+			msg.Answer = []dns.RR{
+				&dns.CNAME{
+					Hdr:    dns.RR_Header{Name: fqdn},
+					Target: delegatedFQDN,
+				},
+			}
+		case fqdn == delegatedFQDN && rtype == dns.TypeTXT:
+			msg.Answer = []dns.RR{
+				&dns.TXT{
+					Hdr: dns.RR_Header{Name: fqdn},
+					Txt: []string{"token"},
+				},
+			}
+		case rtype == dns.TypeTXT:
+			msg.Rcode = dns.RcodeNameError
+		}
+		return msg, nil
+	}
+	defer func() {
+		// restore the mock
+		dnsQuery = DNSQuery
+	}()
+
+	fqdn, err := DNS01LookupFQDN("example.com", false)
+	if err != nil {
+		t.Fatalf("DNS01LookupFQDN() unexpected error: %v", err)
+	}
+	if fqdn != challengeFQDN {
+		t.Errorf("DNS01LookupFQDN() without following CNAMEs got = %q, want %q", fqdn, challengeFQDN)
+	}
+
+	fqdn, err = DNS01LookupFQDN("example.com", true)
+	if err != nil {
+		t.Fatalf("DNS01LookupFQDN() unexpected error: %v", err)
+	}
+	if fqdn != delegatedFQDN {
+		t.Errorf("DNS01LookupFQDN() following CNAMEs got = %q, want %q", fqdn, delegatedFQDN)
+	}
+
+	// The propagation check must look for the TXT record on the delegated
+	// name, regardless of which of the two names it is given.
+	for _, name := range []string{challengeFQDN, delegatedFQDN} {
+		ok, err := checkDNSPropagation(name, "token", []string{"8.8.8.8:53"}, false, true)
+		if err != nil {
+			t.Fatalf("checkDNSPropagation(%q) unexpected error: %v", name, err)
+		}
+		if !ok {
+			t.Errorf("checkDNSPropagation(%q) expected the delegated TXT record to be found", name)
+		}
+	}
+}
+
+func Test_checkDNSPropagationAuthoritative(t *testing.T) {
+	const (
+		challengeFQDN = "_acme-challenge.authoritative.example.net."
+		zone          = "example.net."
+		recursiveNS   = "10.0.0.1:53"
+		authNS        = "ns1.example.net.:53"
+	)
+	var txtQueriedNameservers []string
+	dnsQuery = func(fqdn string, rtype uint16, nameservers []string, recursive bool) (in *dns.Msg, err error) {
+		msg := &dns.Msg{}
+		msg.Rcode = dns.RcodeSuccess
+		switch {
+		case rtype == dns.TypeCNAME:
+		case rtype == dns.TypeSOA && fqdn == zone:
+			msg.Answer = []dns.RR{&dns.SOA{Hdr: dns.RR_Header{Name: zone}}}
+		case rtype == dns.TypeSOA:
+			msg.Rcode = dns.RcodeNameError
+		case rtype == dns.TypeNS && fqdn == zone:
+			msg.Answer = []dns.RR{&dns.NS{Hdr: dns.RR_Header{Name: zone}, Ns: "NS1.example.net."}}
+		case rtype == dns.TypeTXT && fqdn == challengeFQDN:
+			txtQueriedNameservers = append(txtQueriedNameservers, nameservers...)
+			// The recursive nameserver has cached the record from before it
+			// was updated, whereas the authoritative nameserver has the
+			// current record.
+			txt := "stale-token"
+			if reflect.DeepEqual(nameservers, []string{authNS}) {
+				txt = "token"
+			}
+			msg.Answer = []dns.RR{&dns.TXT{Hdr: dns.RR_Header{Name: fqdn}, Txt: []string{txt}}}
+		default:
+			return nil, fmt.Errorf("unexpected %s query for %q", dns.TypeToString[rtype], fqdn)
+		}
+		return msg, nil
+	}
+	defer func() {
+		// restore the mock
+		dnsQuery = DNSQuery
+	}()
+
+	ok, err := checkDNSPropagation(challengeFQDN, "token", []string{recursiveNS}, true, true)
+	if err != nil {
+		t.Fatalf("checkDNSPropagation() unexpected error: %v", err)
+	}
+	if !ok {
+		t.Errorf("checkDNSPropagation() expected the record to be found on the authoritative nameservers")
+	}
+	if !reflect.DeepEqual(txtQueriedNameservers, []string{authNS}) {
+		t.Errorf("checkDNSPropagation() queried TXT records from %v, want %v", txtQueriedNameservers, []string{authNS})
+	}
+
+	txtQueriedNameservers = nil
+	ok, err = checkDNSPropagation(challengeFQDN, "token", []string{recursiveNS}, false, true)
+	if err != nil {
+		t.Fatalf("checkDNSPropagation() unexpected error: %v", err)
+	}
+	if ok {
+		t.Errorf("checkDNSPropagation() expected the stale record on the recursive nameservers not to match")
+	}
+	if !reflect.DeepEqual(txtQueriedNameservers, []string{recursiveNS}) {
+		t.Errorf("checkDNSPropagation() queried TXT records from %v, want %v", txtQueriedNameservers, []string{recursiveNS})
+	}
+}
+
+func Test_checkDNSPropagationRequireAll(t *testing.T) {
+	const challengeFQDN = "_acme-challenge.example.net."
+	nameservers := []string{"10.0.0.1:53", "10.0.0.2:53", "10.0.0.3:53"}
+
+	tests := map[string]struct {
+		// propagated is the set of nameservers that return the record.
+		propagated map[string]bool
+		// failing is the set of nameservers that return an error.
+		failing    map[string]bool
+		requireAll bool
+		ok         bool
+		err        bool
+	}{
+		"require all: no nameservers have the record": {
+			requireAll: true,
+		},
+		"require all: some nameservers have the record": {
+			propagated: map[string]bool{"10.0.0.1:53": true, "10.0.0.3:53": true},
+			requireAll: true,
+		},
+		"require all: all nameservers have the record": {
+			propagated: map[string]bool{"10.0.0.1:53": true, "10.0.0.2:53": true, "10.0.0.3:53": true},
+			requireAll: true,
+			ok:         true,
+		},
+		"require all: a nameserver fails": {
+			propagated: map[string]bool{"10.0.0.1:53": true, "10.0.0.3:53": true},
+			failing:    map[string]bool{"10.0.0.2:53": true},
+			requireAll: true,
+			err:        true,
+		},
+		"require any: no nameservers have the record": {},
+		"require any: some nameservers have the record": {
+			propagated: map[string]bool{"10.0.0.3:53": true},
+			ok:         true,
+		},
+		"require any: all nameservers have the record": {
+			propagated: map[string]bool{"10.0.0.1:53": true, "10.0.0.2:53": true, "10.0.0.3:53": true},
+			ok:         true,
+		},
+		"require any: a nameserver fails but another has the record": {
+			propagated: map[string]bool{"10.0.0.2:53": true},
+			failing:    map[string]bool{"10.0.0.1:53": true},
+			ok:         true,
+		},
+		"require any: a nameserver fails and none have the record": {
+			failing: map[string]bool{"10.0.0.1:53": true},
+			err:     true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dnsQuery = func(fqdn string, rtype uint16, nameservers []string, recursive bool) (in *dns.Msg, err error) {
+				msg := &dns.Msg{}
+				msg.Rcode = dns.RcodeSuccess
+				switch {
+				case rtype == dns.TypeCNAME:
+				case rtype == dns.TypeTXT && fqdn == challengeFQDN:
+					if len(nameservers) != 1 {
+						return nil, fmt.Errorf("expected a single nameserver to be queried, got %v", nameservers)
+					}
+					if tc.failing[nameservers[0]] {
+						return nil, fmt.Errorf("read udp %s: i/o timeout", nameservers[0])
+					}
+					if !tc.propagated[nameservers[0]] {
+						msg.Rcode = dns.RcodeNameError
+						break
+					}
+					msg.Answer = []dns.RR{&dns.TXT{Hdr: dns.RR_Header{Name: fqdn}, Txt: []string{"token"}}}
+				default:
+					return nil, fmt.Errorf("unexpected %s query for %q", dns.TypeToString[rtype], fqdn)
+				}
+				return msg, nil
+			}
+			defer func() {
+				// restore the mock
+				dnsQuery = DNSQuery
+			}()
+
+			ok, err := checkDNSPropagation(challengeFQDN, "token", nameservers, false, tc.requireAll)
+			if (err != nil) != tc.err {
+				t.Fatalf("checkDNSPropagation() error = %v, expected error: %t", err, tc.err)
+			}
+			if ok != tc.ok {
+				t.Errorf("checkDNSPropagation() = %t, want %t", ok, tc.ok)
+			}
+		})
+	}
+}
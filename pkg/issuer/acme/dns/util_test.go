@@ -28,6 +28,7 @@ import (
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/clouddns"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/cloudflare"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/digitalocean"
+	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/exec"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/route53"
 	"github.com/cert-manager/cert-manager/pkg/issuer/acme/dns/util"
 	"github.com/cert-manager/cert-manager/test/unit/gen"
@@ -155,6 +156,10 @@ func newFakeDNSProviders() *fakeDNSProviders {
 			f.call("digitalocean", token, util.RecursiveNameservers)
 			return nil, nil
 		},
+		exec: func(command string, allowedCommands []string) (*exec.DNSProvider, error) {
+			f.call("exec", command, allowedCommands)
+			return exec.NewDNSProvider(command, allowedCommands)
+		},
 	}
 	return f
 }
@@ -36,8 +36,8 @@ import (
 	"github.com/cert-manager/cert-manager/internal/ingress"
 	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
 	v1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmacmelisters "github.com/cert-manager/cert-manager/pkg/client/listers/acme/v1"
 	"github.com/cert-manager/cert-manager/pkg/controller"
-	"github.com/cert-manager/cert-manager/pkg/issuer/acme/http/solver"
 	logf "github.com/cert-manager/cert-manager/pkg/logs"
 )
 
@@ -64,6 +64,7 @@ type Solver struct {
 	ingressLister        ingress.InternalIngressLister
 	ingressCreateUpdater ingress.InternalIngressCreateUpdater
 	httpRouteLister      gwapilisters.HTTPRouteLister
+	challengeLister      cmacmelisters.ChallengeLister
 
 	testReachability reachabilityTest
 	requiredPasses   int
@@ -88,6 +89,7 @@ func NewSolver(ctx *controller.Context) (*Solver, error) {
 		ingressLister:        ingressLister,
 		ingressCreateUpdater: ingressCreateUpdater,
 		httpRouteLister:      ctx.GWShared.Networking().V1alpha1().HTTPRoutes().Lister(),
+		challengeLister:      ctx.SharedInformerFactory.Acme().V1().Challenges().Lister(),
 		testReachability:     testReachability,
 		requiredPasses:       5,
 	}, nil
@@ -163,6 +165,11 @@ func (s *Solver) Check(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.
 		}
 	}
 
+	if s.HTTP01SolverSkipSelfCheck {
+		log.V(logf.DebugLevel).Info("HTTP01 self check is disabled, skipping reachability test")
+		return nil
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, HTTP01Timeout)
 	defer cancel()
 	url := s.buildChallengeUrl(ch)
@@ -184,13 +191,18 @@ func (s *Solver) Check(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.
 	return nil
 }
 
-// CleanUp will ensure the created service, ingress and pod are clean/deleted of any
-// cert-manager created data.
+// CleanUp will ensure the created service, ingress, HTTPRoute and pod are
+// clean/deleted of any cert-manager created data. HTTPRoutes left behind by
+// challenges that no longer exist are also deleted.
 func (s *Solver) CleanUp(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
 	var errs []error
 	errs = append(errs, s.cleanupPods(ctx, ch))
 	errs = append(errs, s.cleanupServices(ctx, ch))
 	errs = append(errs, s.cleanupIngresses(ctx, ch))
+	errs = append(errs, s.cleanupGatewayHTTPRoutes(ctx, ch))
+	errs = append(errs, s.cleanupOrphanedGatewayHTTPRoutes(ctx, ch.Namespace))
+	errs = append(errs, s.cleanupSharedPod(ctx, ch))
+	errs = append(errs, s.cleanupSharedService(ctx, ch))
 	return utilerrors.NewAggregate(errs)
 }
 
@@ -203,7 +215,7 @@ func (s *Solver) buildChallengeUrl(ch *cmacme.Challenge) *url.URL {
 	if k8snet.IsIPv6(net.ParseIP(url.Host)) {
 		url.Host = fmt.Sprintf("[%s]", url.Host)
 	}
-	url.Path = fmt.Sprintf("%s/%s", solver.HTTPChallengePath, ch.Spec.Token)
+	url.Path = challengePath(ch)
 
 	return url
 }
@@ -46,7 +46,9 @@ func TestCheck(t *testing.T) {
 		name             string
 		reachabilityTest reachabilityTest
 		challenge        *cmacme.Challenge
+		skipSelfCheck    bool
 		expectedErr      bool
+		expectedCalls    *int
 	}
 	tests := []testT{
 		{
@@ -63,6 +65,36 @@ func TestCheck(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			name: "should pass without running reachability test if self check is skipped",
+			reachabilityTest: func(context.Context, *url.URL, string, []string, string) error {
+				return fmt.Errorf("failed")
+			},
+			skipSelfCheck: true,
+			expectedErr:   false,
+			expectedCalls: new(int),
+		},
+		{
+			name: "should include the ingress path prefix in the self check URL",
+			reachabilityTest: func(_ context.Context, url *url.URL, _ string, _ []string, _ string) error {
+				if url.String() != "http://example.com/prefix/.well-known/acme-challenge/token" {
+					return fmt.Errorf("unexpected self check URL %q", url)
+				}
+				return nil
+			},
+			challenge: &cmacme.Challenge{
+				Spec: cmacme.ChallengeSpec{
+					DNSName: "example.com",
+					Token:   "token",
+					Solver: cmacme.ACMEChallengeSolver{
+						HTTP01: &cmacme.ACMEChallengeSolverHTTP01{
+							Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{PathPrefix: "/prefix"},
+						},
+					},
+				},
+			},
+			expectedErr: false,
+		},
 	}
 
 	for i := range tests {
@@ -73,8 +105,18 @@ func TestCheck(t *testing.T) {
 			if test.challenge == nil {
 				test.challenge = &cmacme.Challenge{}
 			}
+			if test.expectedCalls == nil {
+				test.expectedCalls = &requiredCallsForPass
+			}
 			s := Solver{
-				Context:          &controller.Context{RESTConfig: new(rest.Config)},
+				Context: &controller.Context{
+					RESTConfig: new(rest.Config),
+					ContextOptions: controller.ContextOptions{
+						ACMEOptions: controller.ACMEOptions{
+							HTTP01SolverSkipSelfCheck: test.skipSelfCheck,
+						},
+					},
+				},
 				testReachability: countReachabilityTestCalls(&calls, test.reachabilityTest),
 				requiredPasses:   requiredCallsForPass,
 			}
@@ -88,8 +130,8 @@ func TestCheck(t *testing.T) {
 				t.Errorf("Expected error from Check, but got none")
 				return
 			}
-			if !test.expectedErr && calls != requiredCallsForPass {
-				t.Errorf("Expected Wait to verify reachability test passes %d times, but only checked %d", requiredCallsForPass, calls)
+			if !test.expectedErr && calls != *test.expectedCalls {
+				t.Errorf("Expected Wait to verify reachability test passes %d times, but only checked %d", *test.expectedCalls, calls)
 				return
 			}
 		})
@@ -21,8 +21,10 @@ import (
 	"fmt"
 	"reflect"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/utils/pointer"
 	gwapi "sigs.k8s.io/gateway-api/apis/v1alpha1"
@@ -174,7 +176,87 @@ func generateHTTPRouteSpec(ch *cmacme.Challenge, svcName string) gwapi.HTTPRoute
 	}
 }
 
-func (s *Solver) cleanupGatewayHTTPRoutes(_ context.Context, _ *cmacme.Challenge) error {
-	// Unlike Ingress, we don't modify existing HTTPRoutes so there is nothing to do here.
-	return nil
+// cleanupGatewayHTTPRoutes deletes the HTTPRoutes created to solve the given
+// challenge. Unlike Ingress, existing HTTPRoutes are never modified, so any
+// HTTPRoute labelled for the challenge was created by cert-manager. Cleanup
+// is only considered complete once the HTTPRoutes are actually gone, as they
+// may be held in place by finalizers.
+func (s *Solver) cleanupGatewayHTTPRoutes(ctx context.Context, ch *cmacme.Challenge) error {
+	log := logf.FromContext(ctx, "cleanupGatewayHTTPRoutes")
+
+	if ch.Spec.Solver.HTTP01 == nil || ch.Spec.Solver.HTTP01.GatewayHTTPRoute == nil {
+		return nil
+	}
+
+	httpRoutes, err := s.httpRouteLister.HTTPRoutes(ch.Namespace).List(labels.Set(podLabels(ch)).AsSelector())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, httpRoute := range httpRoutes {
+		log := logf.WithRelatedResource(log, httpRoute)
+		if err := s.deleteGatewayHTTPRoute(ctx, httpRoute); err != nil {
+			log.V(logf.WarnLevel).Info("failed to delete HTTPRoute resource", "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		log.V(logf.InfoLevel).Info("successfully deleted HTTPRoute resource")
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// cleanupOrphanedGatewayHTTPRoutes deletes HTTPRoutes in the given namespace
+// that were created to solve a challenge which no longer exists. These are
+// normally garbage collected, but are otherwise left behind if the challenge
+// was deleted before its cleanup completed.
+func (s *Solver) cleanupOrphanedGatewayHTTPRoutes(ctx context.Context, namespace string) error {
+	log := logf.FromContext(ctx, "cleanupOrphanedGatewayHTTPRoutes")
+
+	selector := labels.Set{cmacme.SolverIdentificationLabelKey: "true"}.AsSelector()
+	httpRoutes, err := s.httpRouteLister.HTTPRoutes(namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, httpRoute := range httpRoutes {
+		ref := metav1.GetControllerOf(httpRoute)
+		if ref == nil || ref.Kind != challengeGvk.Kind || ref.APIVersion != challengeGvk.GroupVersion().String() {
+			continue
+		}
+		ch, err := s.challengeLister.Challenges(httpRoute.Namespace).Get(ref.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+			continue
+		}
+		if err == nil && ch.UID == ref.UID {
+			continue
+		}
+
+		log := logf.WithRelatedResource(log, httpRoute)
+		log.V(logf.InfoLevel).Info("deleting HTTPRoute resource for challenge that no longer exists", "challenge", ref.Name)
+		if err := s.deleteGatewayHTTPRoute(ctx, httpRoute); err != nil {
+			log.V(logf.WarnLevel).Info("failed to delete HTTPRoute resource", "error", err)
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// deleteGatewayHTTPRoute deletes the given HTTPRoute and returns an error if
+// it still exists afterwards.
+func (s *Solver) deleteGatewayHTTPRoute(ctx context.Context, httpRoute *gwapi.HTTPRoute) error {
+	client := s.GWClient.NetworkingV1alpha1().HTTPRoutes(httpRoute.Namespace)
+	err := client.Delete(ctx, httpRoute.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	_, err = client.Get(ctx, httpRoute.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return err
+	default:
+		return fmt.Errorf("HTTPRoute %s/%s has not been deleted yet", httpRoute.Namespace, httpRoute.Name)
+	}
 }
@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	coretesting "k8s.io/client-go/testing"
+	gwapi "sigs.k8s.io/gateway-api/apis/v1alpha1"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	"github.com/cert-manager/cert-manager/pkg/controller/test"
+)
+
+func gatewayTestChallenge(name, dnsName, token string) *cmacme.Challenge {
+	return &cmacme.Challenge{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: defaultTestNamespace,
+			UID:       types.UID(name + "-uid"),
+		},
+		Spec: cmacme.ChallengeSpec{
+			DNSName: dnsName,
+			Token:   token,
+			Solver: cmacme.ACMEChallengeSolver{
+				HTTP01: &cmacme.ACMEChallengeSolverHTTP01{
+					GatewayHTTPRoute: &cmacme.ACMEChallengeSolverHTTP01GatewayHTTPRoute{},
+				},
+			},
+		},
+	}
+}
+
+// gatewayTestHTTPRoute returns an HTTPRoute as created by the solver for the
+// given challenge.
+func gatewayTestHTTPRoute(name string, ch *cmacme.Challenge) *gwapi.HTTPRoute {
+	return &gwapi.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       ch.Namespace,
+			Labels:          podLabels(ch),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ch, challengeGvk)},
+		},
+		Spec: generateHTTPRouteSpec(ch, "fakeservice"),
+	}
+}
+
+func listHTTPRouteNames(t *testing.T, b *test.Builder) []string {
+	routes, err := b.GWClient.NetworkingV1alpha1().HTTPRoutes(defaultTestNamespace).List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	var names []string
+	for _, route := range routes.Items {
+		names = append(names, route.Name)
+	}
+	return names
+}
+
+func TestCleanupGatewayHTTPRoutes(t *testing.T) {
+	ch := gatewayTestChallenge("challenge", "example.com", "token")
+	other := gatewayTestChallenge("other-challenge", "other.example.com", "other-token")
+
+	b := &test.Builder{
+		T: t,
+		GWObjects: []runtime.Object{
+			gatewayTestHTTPRoute("route", ch),
+			gatewayTestHTTPRoute("other-route", other),
+		},
+	}
+	s, err := buildFakeSolver(b)
+	require.NoError(t, err)
+	defer b.Stop()
+
+	require.NoError(t, s.cleanupGatewayHTTPRoutes(context.TODO(), ch))
+	assert.Equal(t, []string{"other-route"}, listHTTPRouteNames(t, b), "only the HTTPRoute for the challenge should be deleted")
+}
+
+func TestCleanupGatewayHTTPRoutesNotYetDeleted(t *testing.T) {
+	ch := gatewayTestChallenge("challenge", "example.com", "token")
+
+	b := &test.Builder{
+		T:         t,
+		GWObjects: []runtime.Object{gatewayTestHTTPRoute("route", ch)},
+	}
+	s, err := buildFakeSolver(b)
+	require.NoError(t, err)
+	defer b.Stop()
+
+	// Simulate an HTTPRoute held in place by a finalizer by accepting the
+	// delete without removing the resource.
+	b.FakeGWClient().PrependReactor("delete", "httproutes", func(coretesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+
+	err = s.cleanupGatewayHTTPRoutes(context.TODO(), ch)
+	assert.EqualError(t, err, "HTTPRoute "+defaultTestNamespace+"/route has not been deleted yet")
+}
+
+func TestCleanupOrphanedGatewayHTTPRoutes(t *testing.T) {
+	existing := gatewayTestChallenge("existing-challenge", "example.com", "token")
+	deleted := gatewayTestChallenge("deleted-challenge", "deleted.example.com", "deleted-token")
+	recreated := gatewayTestChallenge("recreated-challenge", "recreated.example.com", "recreated-token")
+	recreatedRoute := gatewayTestHTTPRoute("recreated-route", recreated)
+	recreatedRoute.OwnerReferences[0].UID = "previous-uid"
+
+	userRoute := gatewayTestHTTPRoute("user-route", existing)
+	userRoute.OwnerReferences = nil
+
+	b := &test.Builder{
+		T:                  t,
+		CertManagerObjects: []runtime.Object{existing, recreated},
+		GWObjects: []runtime.Object{
+			gatewayTestHTTPRoute("existing-route", existing),
+			gatewayTestHTTPRoute("deleted-route", deleted),
+			recreatedRoute,
+			userRoute,
+		},
+	}
+	s, err := buildFakeSolver(b)
+	require.NoError(t, err)
+	defer b.Stop()
+
+	require.NoError(t, s.cleanupOrphanedGatewayHTTPRoutes(context.TODO(), defaultTestNamespace))
+	assert.ElementsMatch(t, []string{"existing-route", "user-route"}, listHTTPRouteNames(t, b),
+		"only HTTPRoutes owned by challenges that no longer exist should be deleted")
+}
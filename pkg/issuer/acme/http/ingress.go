@@ -162,7 +162,7 @@ func buildIngressResource(ch *cmacme.Challenge, svcName string) (*networkingv1.I
 		ingAnnotations[annotationIngressClass] = *http01IngressCfg.Class
 	}
 
-	ingPathToAdd := ingressPath(ch.Spec.Token, svcName)
+	ingPathToAdd := ingressPath(ch, svcName)
 
 	httpHost := ch.Spec.DNSName
 	// if we need to verify ownership of an IP the challenge should propagate on all hosts
@@ -233,7 +233,7 @@ func (s *Solver) addChallengePathToIngress(ctx context.Context, ch *cmacme.Chall
 		return nil, err
 	}
 
-	ingPathToAdd := ingressPath(ch.Spec.Token, svcName)
+	ingPathToAdd := ingressPath(ch, svcName)
 	// check for an existing Rule for the given domain on the ingress resource
 	for _, rule := range ing.Spec.Rules {
 		if rule.Host == ch.Spec.DNSName {
@@ -321,7 +321,7 @@ func (s *Solver) cleanupIngresses(ctx context.Context, ch *cmacme.Challenge) err
 	log = logf.WithRelatedResource(log, ing)
 
 	log.V(logf.DebugLevel).Info("attempting to clean up automatically added solver paths on ingress resource")
-	ingPathToDel := solverPathFn(ch.Spec.Token)
+	ingPathToDel := challengePath(ch)
 	var ingRules []networkingv1.IngressRule
 	for _, rule := range ing.Spec.Rules {
 		// always retain rules that are not for the same DNSName
@@ -365,9 +365,9 @@ func (s *Solver) cleanupIngresses(ctx context.Context, ch *cmacme.Challenge) err
 
 // ingressPath returns the ingress HTTPIngressPath object needed to solve this
 // challenge.
-func ingressPath(token, serviceName string) networkingv1.HTTPIngressPath {
+func ingressPath(ch *cmacme.Challenge, serviceName string) networkingv1.HTTPIngressPath {
 	return networkingv1.HTTPIngressPath{
-		Path:     solverPathFn(token),
+		Path:     challengePath(ch),
 		PathType: func() *networkingv1.PathType { s := networkingv1.PathTypeImplementationSpecific; return &s }(),
 		Backend: networkingv1.IngressBackend{
 			Service: &networkingv1.IngressServiceBackend{
@@ -380,6 +380,17 @@ func ingressPath(token, serviceName string) networkingv1.HTTPIngressPath {
 	}
 }
 
-var solverPathFn = func(token string) string {
-	return fmt.Sprintf("%s/%s", solver.HTTPChallengePath, token)
+// challengePath returns the path on which the given challenge is solved,
+// including the path prefix of the challenge's HTTP01 ingress solver, if any.
+func challengePath(ch *cmacme.Challenge) string {
+	return fmt.Sprintf("%s%s/%s", pathPrefix(ch), solver.HTTPChallengePath, ch.Spec.Token)
+}
+
+// pathPrefix returns the path prefix of the challenge's HTTP01 ingress
+// solver, or an empty string if there is none.
+func pathPrefix(ch *cmacme.Challenge) string {
+	if ch.Spec.Solver.HTTP01 != nil && ch.Spec.Solver.HTTP01.Ingress != nil {
+		return ch.Spec.Solver.HTTP01.Ingress.PathPrefix
+	}
+	return ""
 }
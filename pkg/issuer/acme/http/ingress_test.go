@@ -473,6 +473,26 @@ func TestEnsureIngress(t *testing.T) {
 				}
 			},
 		},
+		"should include the path prefix in the created ingress path": {
+			Challenge: &cmacme.Challenge{
+				Spec: cmacme.ChallengeSpec{
+					DNSName: "example.com",
+					Token:   "abcd",
+					Solver: cmacme.ACMEChallengeSolver{
+						HTTP01: &cmacme.ACMEChallengeSolverHTTP01{
+							Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{PathPrefix: "/prefix"},
+						},
+					},
+				},
+			},
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				ing := args[0].(*networkingv1.Ingress)
+				paths := ing.Spec.Rules[0].HTTP.Paths
+				if len(paths) != 1 || paths[0].Path != "/prefix/.well-known/acme-challenge/abcd" {
+					t.Errorf("expected a single ingress path with the prefix, got %+v", paths)
+				}
+			},
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
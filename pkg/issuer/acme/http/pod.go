@@ -22,6 +22,7 @@ import (
 	"hash/adler32"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
@@ -50,6 +51,12 @@ func podLabels(ch *cmacme.Challenge) map[string]string {
 func (s *Solver) ensurePod(ctx context.Context, ch *cmacme.Challenge) (*corev1.Pod, error) {
 	log := logf.FromContext(ctx).WithName("ensurePod")
 
+	if s.HTTP01SolverSharePods {
+		if group, ok := sharedSolverGroup(ch); ok {
+			return s.ensureSharedPod(logf.NewContext(ctx, log), ch, group)
+		}
+	}
+
 	log.V(logf.DebugLevel).Info("checking for existing HTTP01 solver pods")
 	existingPods, err := s.getPodsForChallenge(ctx, ch)
 	if err != nil {
@@ -73,6 +80,36 @@ func (s *Solver) ensurePod(ctx context.Context, ch *cmacme.Challenge) (*corev1.P
 	return s.createPod(ctx, ch)
 }
 
+// ensureSharedPod ensures that the solver pod shared by the given group of
+// challenges exists and that the given challenge is recorded as one of its
+// owners.
+func (s *Solver) ensureSharedPod(ctx context.Context, ch *cmacme.Challenge, group string) (*corev1.Pod, error) {
+	log := logf.FromContext(ctx)
+
+	pod, err := s.podLister.Pods(ch.Namespace).Get(sharedSolverName(group))
+	if apierrors.IsNotFound(err) {
+		log.V(logf.InfoLevel).Info("creating shared HTTP01 challenge solver pod")
+		return s.Client.CoreV1().Pods(ch.Namespace).Create(ctx, s.buildSharedPod(ch, group), metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log = logf.WithRelatedResource(log, pod)
+	if pod.DeletionTimestamp != nil {
+		return nil, fmt.Errorf("shared challenge solver pod %s/%s is being deleted. retrying challenge sync", pod.Namespace, pod.Name)
+	}
+	if isSharedBy(pod, ch) {
+		log.V(logf.DebugLevel).Info("found existing shared HTTP01 solver pod")
+		return pod, nil
+	}
+
+	log.V(logf.InfoLevel).Info("adding challenge to existing shared HTTP01 solver pod")
+	pod = pod.DeepCopy()
+	pod.OwnerReferences = append(pod.OwnerReferences, sharedOwnerRef(ch))
+	return s.Client.CoreV1().Pods(pod.Namespace).Update(ctx, pod, metav1.UpdateOptions{})
+}
+
 // getPodsForChallenge returns a list of pods that were created to solve
 // the given challenge
 func (s *Solver) getPodsForChallenge(ctx context.Context, ch *cmacme.Challenge) ([]*corev1.Pod, error) {
@@ -130,6 +167,39 @@ func (s *Solver) cleanupPods(ctx context.Context, ch *cmacme.Challenge) error {
 	return utilerrors.NewAggregate(errs)
 }
 
+// cleanupSharedPod removes the given challenge as an owner of its shared
+// solver pod, if it has one, and deletes the pod if no other challenges are
+// using it. This is performed regardless of whether sharing is currently
+// enabled so that pods are not left behind if the option is disabled.
+func (s *Solver) cleanupSharedPod(ctx context.Context, ch *cmacme.Challenge) error {
+	group, ok := sharedSolverGroup(ch)
+	if !ok {
+		return nil
+	}
+	pod, err := s.podLister.Pods(ch.Namespace).Get(sharedSolverName(group))
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !isSharedBy(pod, ch) {
+		return nil
+	}
+
+	log := logf.WithRelatedResource(logf.FromContext(ctx), pod)
+	log.V(logf.InfoLevel).Info("releasing shared pod resource")
+	return releaseShared(pod, ch, func(refs []metav1.OwnerReference) error {
+		pod := pod.DeepCopy()
+		pod.OwnerReferences = refs
+		_, err := s.Client.CoreV1().Pods(pod.Namespace).Update(ctx, pod, metav1.UpdateOptions{})
+		return err
+	}, func(opts metav1.DeleteOptions) error {
+		log.V(logf.InfoLevel).Info("deleting shared pod resource as it is no longer in use")
+		return s.Client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, opts)
+	})
+}
+
 // createPod will create a challenge solving pod for the given certificate,
 // domain, token and key.
 func (s *Solver) createPod(ctx context.Context, ch *cmacme.Challenge) (*corev1.Pod, error) {
@@ -142,8 +212,35 @@ func (s *Solver) createPod(ctx context.Context, ch *cmacme.Challenge) (*corev1.P
 // buildPod will build a challenge solving pod for the given certificate,
 // domain, token and key. It will not create it in the API server
 func (s *Solver) buildPod(ch *cmacme.Challenge) *corev1.Pod {
+	return s.applyPodTemplate(ch, s.buildDefaultPod(ch))
+}
+
+// buildSharedPod will build a challenge solving pod that can be shared by all
+// challenges in the given group. It responds to any token using the account
+// key thumbprint rather than the challenge's own token and key.
+func (s *Solver) buildSharedPod(ch *cmacme.Challenge, group string) *corev1.Pod {
+	// sharedSolverGroup has already verified the key contains the thumbprint.
+	thumbprint, _ := keyThumbprint(ch)
+
 	pod := s.buildDefaultPod(ch)
+	pod.GenerateName = ""
+	pod.Name = sharedSolverName(group)
+	pod.Labels = sharedPodLabels(group)
+	pod.OwnerReferences = []metav1.OwnerReference{sharedOwnerRef(ch)}
+	pod.Spec.Containers[0].Args = []string{
+		fmt.Sprintf("--listen-port=%d", acmeSolverListenPort),
+		fmt.Sprintf("--key-thumbprint=%s", thumbprint),
+	}
+	if prefix := pathPrefix(ch); prefix != "" {
+		pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, fmt.Sprintf("--path-prefix=%s", prefix))
+	}
+
+	return s.applyPodTemplate(ch, pod)
+}
 
+// applyPodTemplate overrides the defaults of the given pod with any values
+// set in the challenge's pod template.
+func (s *Solver) applyPodTemplate(ch *cmacme.Challenge, pod *corev1.Pod) *corev1.Pod {
 	// Override defaults if they have changed in the pod template.
 	if ch.Spec.Solver.HTTP01 != nil {
 		if ch.Spec.Solver.HTTP01.Ingress != nil {
@@ -158,6 +255,16 @@ func (s *Solver) buildPod(ch *cmacme.Challenge) *corev1.Pod {
 func (s *Solver) buildDefaultPod(ch *cmacme.Challenge) *corev1.Pod {
 	podLabels := podLabels(ch)
 
+	args := []string{
+		fmt.Sprintf("--listen-port=%d", acmeSolverListenPort),
+		fmt.Sprintf("--domain=%s", ch.Spec.DNSName),
+		fmt.Sprintf("--token=%s", ch.Spec.Token),
+		fmt.Sprintf("--key=%s", ch.Spec.Key),
+	}
+	if prefix := pathPrefix(ch); prefix != "" {
+		args = append(args, fmt.Sprintf("--path-prefix=%s", prefix))
+	}
+
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: "cm-acme-http-solver-",
@@ -180,12 +287,7 @@ func (s *Solver) buildDefaultPod(ch *cmacme.Challenge) *corev1.Pod {
 					Image:           s.Context.HTTP01SolverImage,
 					ImagePullPolicy: corev1.PullIfNotPresent,
 					// TODO: replace this with some kind of cmdline generator
-					Args: []string{
-						fmt.Sprintf("--listen-port=%d", acmeSolverListenPort),
-						fmt.Sprintf("--domain=%s", ch.Spec.DNSName),
-						fmt.Sprintf("--token=%s", ch.Spec.Token),
-						fmt.Sprintf("--key=%s", ch.Spec.Key),
-					},
+					Args: args,
 					Resources: corev1.ResourceRequirements{
 						Requests: corev1.ResourceList{
 							corev1.ResourceCPU:    s.ACMEOptions.HTTP01SolverResourceRequestCPU,
@@ -256,5 +358,31 @@ func (s *Solver) mergePodObjectMetaWithPodTemplate(pod *corev1.Pod, podTempl *cm
 		pod.Spec.ServiceAccountName = podTempl.Spec.ServiceAccountName
 	}
 
+	if podTempl.Spec.HostNetwork {
+		pod.Spec.HostNetwork = true
+	}
+
+	if podTempl.Spec.Resources != nil {
+		// Resources set in the template override the defaults individually,
+		// so that e.g. only the memory limit can be raised.
+		resources := &pod.Spec.Containers[0].Resources
+		resources.Requests = mergeResourceList(resources.Requests, podTempl.Spec.Resources.Requests)
+		resources.Limits = mergeResourceList(resources.Limits, podTempl.Spec.Resources.Limits)
+	}
+
 	return pod
 }
+
+func mergeResourceList(defaults, overrides corev1.ResourceList) corev1.ResourceList {
+	if len(overrides) == 0 {
+		return defaults
+	}
+	merged := make(corev1.ResourceList, len(defaults)+len(overrides))
+	for name, quantity := range defaults {
+		merged[name] = quantity
+	}
+	for name, quantity := range overrides {
+		merged[name] = quantity
+	}
+	return merged
+}
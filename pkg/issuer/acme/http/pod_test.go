@@ -22,6 +22,8 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
@@ -127,6 +129,77 @@ func TestEnsurePod(t *testing.T) {
 				}
 			},
 		},
+		"should create the pod with the scheduling constraints and host network from template": {
+			Challenge: &cmacme.Challenge{
+				Spec: cmacme.ChallengeSpec{
+					DNSName: "example.com",
+					Token:   "token",
+					Key:     "key",
+					Solver: cmacme.ACMEChallengeSolver{
+						HTTP01: &cmacme.ACMEChallengeSolverHTTP01{
+							Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{
+								PodTemplate: &cmacme.ACMEChallengeSolverHTTP01IngressPodTemplate{
+									Spec: cmacme.ACMEChallengeSolverHTTP01IngressPodSpec{
+										NodeSelector: map[string]string{
+											"egress": "public",
+										},
+										Tolerations: []corev1.Toleration{
+											{
+												Key:      "dedicated",
+												Operator: corev1.TolerationOpEqual,
+												Value:    "egress",
+												Effect:   corev1.TaintEffectNoSchedule,
+											},
+										},
+										HostNetwork: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			PreFn: func(t *testing.T, s *solverFixture) {
+				s.Builder.Sync()
+			},
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				if args[1] != nil {
+					t.Fatalf("unexpected error creating pod: %v", args[1])
+				}
+				resp := args[0].(*corev1.Pod)
+
+				pods, err := s.Solver.podLister.List(labels.NewSelector())
+				if err != nil {
+					t.Fatalf("error listing pods: %v", err)
+				}
+				if len(pods) != 1 {
+					t.Fatalf("expected exactly one pod to be created, got %d", len(pods))
+				}
+				created := pods[0]
+				if created.Name != resp.Name {
+					t.Errorf("expected created pod %q to be returned, got %q", created.Name, resp.Name)
+				}
+
+				expectedNodeSelector := map[string]string{"egress": "public"}
+				if !reflect.DeepEqual(created.Spec.NodeSelector, expectedNodeSelector) {
+					t.Errorf("unexpected nodeSelector, exp=%v got=%v", expectedNodeSelector, created.Spec.NodeSelector)
+				}
+				expectedTolerations := []corev1.Toleration{
+					{
+						Key:      "dedicated",
+						Operator: corev1.TolerationOpEqual,
+						Value:    "egress",
+						Effect:   corev1.TaintEffectNoSchedule,
+					},
+				}
+				if !reflect.DeepEqual(created.Spec.Tolerations, expectedTolerations) {
+					t.Errorf("unexpected tolerations, exp=%v got=%v", expectedTolerations, created.Spec.Tolerations)
+				}
+				if !created.Spec.HostNetwork {
+					t.Errorf("expected pod to use the host network")
+				}
+			},
+		},
 		"should clean up if multiple pods exist": {
 			Challenge: &cmacme.Challenge{
 				Spec: cmacme.ChallengeSpec{
@@ -352,6 +425,64 @@ func TestMergePodObjectMetaWithPodTemplate(t *testing.T) {
 				}
 			},
 		},
+		"should override default resources with those from template": {
+			Challenge: &cmacme.Challenge{
+				Spec: cmacme.ChallengeSpec{
+					DNSName: "example.com",
+					Solver: cmacme.ACMEChallengeSolver{
+						HTTP01: &cmacme.ACMEChallengeSolverHTTP01{
+							Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{
+								PodTemplate: &cmacme.ACMEChallengeSolverHTTP01IngressPodTemplate{
+									Spec: cmacme.ACMEChallengeSolverHTTP01IngressPodSpec{
+										Resources: &corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												corev1.ResourceCPU: resource.MustParse("50m"),
+											},
+											Limits: corev1.ResourceList{
+												corev1.ResourceMemory: resource.MustParse("128Mi"),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			PreFn: func(t *testing.T, s *solverFixture) {
+				s.Solver.ACMEOptions.HTTP01SolverResourceRequestCPU = resource.MustParse("10m")
+				s.Solver.ACMEOptions.HTTP01SolverResourceRequestMemory = resource.MustParse("64Mi")
+				s.Solver.ACMEOptions.HTTP01SolverResourceLimitsCPU = resource.MustParse("100m")
+				s.Solver.ACMEOptions.HTTP01SolverResourceLimitsMemory = resource.MustParse("64Mi")
+				s.testResources[createdPodKey] = corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("50m"),
+						corev1.ResourceMemory: resource.MustParse("64Mi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+				}
+
+				s.Builder.Sync()
+			},
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				expectedResources := s.testResources[createdPodKey].(corev1.ResourceRequirements)
+
+				resp, ok := args[0].(*corev1.Pod)
+				if !ok {
+					t.Errorf("expected pod to be returned, but got %v", args[0])
+					t.Fail()
+					return
+				}
+
+				if !apiequality.Semantic.DeepEqual(expectedResources, resp.Spec.Containers[0].Resources) {
+					t.Errorf("unexpected pod resources generated from merge\nexp=%s\ngot=%s",
+						expectedResources.String(), resp.Spec.Containers[0].Resources.String())
+				}
+			},
+		},
 		"should use default if nothing has changed in template": {
 			Challenge: &cmacme.Challenge{
 				Spec: cmacme.ChallengeSpec{
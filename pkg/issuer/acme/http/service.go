@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
@@ -34,6 +35,12 @@ import (
 func (s *Solver) ensureService(ctx context.Context, ch *cmacme.Challenge) (*corev1.Service, error) {
 	log := logf.FromContext(ctx).WithName("ensureService")
 
+	if s.HTTP01SolverSharePods {
+		if group, ok := sharedSolverGroup(ch); ok {
+			return s.ensureSharedService(logf.NewContext(ctx, log), ch, group)
+		}
+	}
+
 	log.V(logf.DebugLevel).Info("checking for existing HTTP01 solver services for challenge")
 	existingServices, err := s.getServicesForChallenge(ctx, ch)
 	if err != nil {
@@ -56,6 +63,40 @@ func (s *Solver) ensureService(ctx context.Context, ch *cmacme.Challenge) (*core
 	return s.createService(ctx, ch)
 }
 
+// ensureSharedService ensures that the solver service shared by the given
+// group of challenges exists and that the given challenge is recorded as one
+// of its owners.
+func (s *Solver) ensureSharedService(ctx context.Context, ch *cmacme.Challenge, group string) (*corev1.Service, error) {
+	log := logf.FromContext(ctx)
+
+	svc, err := s.serviceLister.Services(ch.Namespace).Get(sharedSolverName(group))
+	if apierrors.IsNotFound(err) {
+		log.V(logf.DebugLevel).Info("creating shared HTTP01 challenge solver service")
+		svc, err := buildSharedService(ch, group)
+		if err != nil {
+			return nil, err
+		}
+		return s.Client.CoreV1().Services(ch.Namespace).Create(ctx, svc, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log = logf.WithRelatedResource(log, svc)
+	if svc.DeletionTimestamp != nil {
+		return nil, fmt.Errorf("shared challenge solver service %s/%s is being deleted. retrying challenge sync", svc.Namespace, svc.Name)
+	}
+	if isSharedBy(svc, ch) {
+		log.V(logf.DebugLevel).Info("found existing shared HTTP01 solver service")
+		return svc, nil
+	}
+
+	log.V(logf.DebugLevel).Info("adding challenge to existing shared HTTP01 solver service")
+	svc = svc.DeepCopy()
+	svc.OwnerReferences = append(svc.OwnerReferences, sharedOwnerRef(ch))
+	return s.Client.CoreV1().Services(svc.Namespace).Update(ctx, svc, metav1.UpdateOptions{})
+}
+
 // getServicesForChallenge returns a list of services that were created to solve
 // http challenges for the given domain
 func (s *Solver) getServicesForChallenge(ctx context.Context, ch *cmacme.Challenge) ([]*corev1.Service, error) {
@@ -136,6 +177,21 @@ func buildService(ch *cmacme.Challenge) (*corev1.Service, error) {
 	return service, nil
 }
 
+// buildSharedService builds the service for the solver pod shared by the
+// given group of challenges.
+func buildSharedService(ch *cmacme.Challenge, group string) (*corev1.Service, error) {
+	service, err := buildService(ch)
+	if err != nil {
+		return nil, err
+	}
+	service.GenerateName = ""
+	service.Name = sharedSolverName(group)
+	service.Labels = sharedPodLabels(group)
+	service.OwnerReferences = []metav1.OwnerReference{sharedOwnerRef(ch)}
+	service.Spec.Selector = sharedPodLabels(group)
+	return service, nil
+}
+
 func (s *Solver) cleanupServices(ctx context.Context, ch *cmacme.Challenge) error {
 	log := logf.FromContext(ctx, "cleanupPods")
 
@@ -158,3 +214,35 @@ func (s *Solver) cleanupServices(ctx context.Context, ch *cmacme.Challenge) erro
 	}
 	return utilerrors.NewAggregate(errs)
 }
+
+// cleanupSharedService removes the given challenge as an owner of its shared
+// solver service, if it has one, and deletes the service if no other
+// challenges are using it.
+func (s *Solver) cleanupSharedService(ctx context.Context, ch *cmacme.Challenge) error {
+	group, ok := sharedSolverGroup(ch)
+	if !ok {
+		return nil
+	}
+	svc, err := s.serviceLister.Services(ch.Namespace).Get(sharedSolverName(group))
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !isSharedBy(svc, ch) {
+		return nil
+	}
+
+	log := logf.WithRelatedResource(logf.FromContext(ctx), svc)
+	log.V(logf.DebugLevel).Info("releasing shared service resource")
+	return releaseShared(svc, ch, func(refs []metav1.OwnerReference) error {
+		svc := svc.DeepCopy()
+		svc.OwnerReferences = refs
+		_, err := s.Client.CoreV1().Services(svc.Namespace).Update(ctx, svc, metav1.UpdateOptions{})
+		return err
+	}, func(opts metav1.DeleteOptions) error {
+		log.V(logf.DebugLevel).Info("deleting shared service resource as it is no longer in use")
+		return s.Client.CoreV1().Services(svc.Namespace).Delete(ctx, svc.Name, opts)
+	})
+}
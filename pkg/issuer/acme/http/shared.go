@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+)
+
+// Shared solver resources are used when the HTTP01SolverSharePods option is
+// enabled. Rather than creating a Pod and Service per challenge, all
+// challenges belonging to the same Order that use the same solver
+// configuration share a single Pod and Service with a deterministic name.
+// The solver Pod responds to any token with the key authorization for that
+// token, so only the Ingress or HTTPRoute path differs between challenges.
+//
+// Each challenge using a shared resource is recorded as a (non-controller)
+// owner of it. When a challenge is cleaned up it removes itself as an owner,
+// and the last challenge to be cleaned up deletes the resource.
+
+// sharedSolverGroup returns the identifier of the group of challenges that
+// may share a solver Pod and Service with the given challenge. It returns
+// false if the challenge cannot share a solver, either because it is not
+// owned by an Order or because its key is not a key authorization for its
+// token.
+func sharedSolverGroup(ch *cmacme.Challenge) (string, bool) {
+	order := metav1.GetControllerOf(ch)
+	if order == nil {
+		return "", false
+	}
+	thumbprint, ok := keyThumbprint(ch)
+	if !ok {
+		return "", false
+	}
+	solverConfig, err := json.Marshal(ch.Spec.Solver)
+	if err != nil {
+		return "", false
+	}
+
+	h := fnv.New64a()
+	// Writing to a hash.Hash never returns an error.
+	_, _ = h.Write([]byte(order.UID))
+	_, _ = h.Write([]byte(thumbprint))
+	_, _ = h.Write(solverConfig)
+	return fmt.Sprintf("%x", h.Sum64()), true
+}
+
+// keyThumbprint returns the ACME account key thumbprint contained in the key
+// authorization of the given challenge.
+func keyThumbprint(ch *cmacme.Challenge) (string, bool) {
+	prefix := ch.Spec.Token + "."
+	if ch.Spec.Token == "" || !strings.HasPrefix(ch.Spec.Key, prefix) {
+		return "", false
+	}
+	thumbprint := strings.TrimPrefix(ch.Spec.Key, prefix)
+	return thumbprint, thumbprint != ""
+}
+
+// sharedSolverName returns the name of the shared solver Pod and Service
+// for the given group.
+func sharedSolverName(group string) string {
+	return "cm-acme-http-solver-shared-" + group
+}
+
+func sharedPodLabels(group string) map[string]string {
+	return map[string]string{
+		cmacme.SolverIdentificationLabelKey: "true",
+		cmacme.SolverGroupLabelKey:          group,
+	}
+}
+
+// sharedOwnerRef returns an OwnerReference to the given challenge. Shared
+// resources have no single controller, so the reference is not marked as
+// one.
+func sharedOwnerRef(ch *cmacme.Challenge) metav1.OwnerReference {
+	ref := metav1.NewControllerRef(ch, challengeGvk)
+	ref.Controller = nil
+	return *ref
+}
+
+func isSharedBy(obj metav1.Object, ch *cmacme.Challenge) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == ch.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseShared removes the given challenge from the owners of a shared
+// solver resource using update. If the challenge is the last remaining
+// owner, the resource is deleted using del instead. The deletion is
+// conditional on the resource not having been modified, so that a challenge
+// that has just started sharing the resource causes a conflict rather than
+// having the resource deleted from under it.
+func releaseShared(obj metav1.Object, ch *cmacme.Challenge, update func([]metav1.OwnerReference) error, del func(metav1.DeleteOptions) error) error {
+	var remaining []metav1.OwnerReference
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID != ch.UID {
+			remaining = append(remaining, ref)
+		}
+	}
+	if len(remaining) > 0 {
+		return update(remaining)
+	}
+
+	uid, resourceVersion := obj.GetUID(), obj.GetResourceVersion()
+	return del(metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			UID:             &uid,
+			ResourceVersion: &resourceVersion,
+		},
+	})
+}
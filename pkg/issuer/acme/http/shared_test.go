@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	"github.com/cert-manager/cert-manager/pkg/controller/test"
+)
+
+func sharedTestChallenge(orderUID types.UID, name, dnsName, token string) *cmacme.Challenge {
+	order := &cmacme.Order{ObjectMeta: metav1.ObjectMeta{Name: "test-order", UID: orderUID}}
+	return &cmacme.Challenge{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       defaultTestNamespace,
+			UID:             types.UID(name + "-uid"),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(order, cmacme.SchemeGroupVersion.WithKind(cmacme.OrderKind))},
+		},
+		Spec: cmacme.ChallengeSpec{
+			DNSName: dnsName,
+			Token:   token,
+			Key:     token + ".thumbprint",
+			Solver: cmacme.ACMEChallengeSolver{
+				HTTP01: &cmacme.ACMEChallengeSolverHTTP01{
+					Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{},
+				},
+			},
+		},
+	}
+}
+
+func TestSharedSolverGroup(t *testing.T) {
+	chA := sharedTestChallenge("order-uid", "a", "a.example.com", "token-a")
+	chB := sharedTestChallenge("order-uid", "b", "b.example.com", "token-b")
+	groupA, ok := sharedSolverGroup(chA)
+	assert.True(t, ok)
+	groupB, ok := sharedSolverGroup(chB)
+	assert.True(t, ok)
+	assert.Equal(t, groupA, groupB, "challenges for the same order should share a solver")
+
+	otherOrder := sharedTestChallenge("other-order-uid", "c", "a.example.com", "token-a")
+	groupC, ok := sharedSolverGroup(otherOrder)
+	assert.True(t, ok)
+	assert.NotEqual(t, groupA, groupC, "challenges for different orders should not share a solver")
+
+	otherSolver := sharedTestChallenge("order-uid", "d", "d.example.com", "token-d")
+	otherSolver.Spec.Solver.HTTP01.Ingress.Class = strPtr("nginx")
+	groupD, ok := sharedSolverGroup(otherSolver)
+	assert.True(t, ok)
+	assert.NotEqual(t, groupA, groupD, "challenges using different solvers should not share a solver")
+
+	noOrder := sharedTestChallenge("order-uid", "e", "e.example.com", "token-e")
+	noOrder.OwnerReferences = nil
+	_, ok = sharedSolverGroup(noOrder)
+	assert.False(t, ok, "challenges not owned by an order cannot share a solver")
+
+	badKey := sharedTestChallenge("order-uid", "f", "f.example.com", "token-f")
+	badKey.Spec.Key = "key"
+	_, ok = sharedSolverGroup(badKey)
+	assert.False(t, ok, "challenges without a key authorization cannot share a solver")
+}
+
+func TestSolverPodPathPrefix(t *testing.T) {
+	b := &test.Builder{T: t}
+	s, err := buildFakeSolver(b)
+	require.NoError(t, err)
+	defer b.Stop()
+
+	ch := sharedTestChallenge("order-uid", "a", "a.example.com", "token-a")
+	ch.Spec.Solver.HTTP01.Ingress.PathPrefix = "/acme"
+	group, _ := sharedSolverGroup(ch)
+
+	assert.Equal(t, []string{
+		"--listen-port=8089",
+		"--domain=a.example.com",
+		"--token=token-a",
+		"--key=token-a.thumbprint",
+		"--path-prefix=/acme",
+	}, s.buildDefaultPod(ch).Spec.Containers[0].Args)
+	assert.Equal(t, []string{
+		"--listen-port=8089",
+		"--key-thumbprint=thumbprint",
+		"--path-prefix=/acme",
+	}, s.buildSharedPod(ch, group).Spec.Containers[0].Args)
+}
+
+func TestSharedSolverResources(t *testing.T) {
+	ctx := context.TODO()
+	b := &test.Builder{T: t}
+	s, err := buildFakeSolver(b)
+	require.NoError(t, err)
+	defer b.Stop()
+	s.HTTP01SolverSharePods = true
+
+	chA := sharedTestChallenge("order-uid", "a", "a.example.com", "token-a")
+	chB := sharedTestChallenge("order-uid", "b", "b.example.com", "token-b")
+	group, _ := sharedSolverGroup(chA)
+
+	for _, ch := range []*cmacme.Challenge{chA, chB} {
+		_, err := s.ensurePod(ctx, ch)
+		require.NoError(t, err)
+		svc, err := s.ensureService(ctx, ch)
+		require.NoError(t, err)
+		assert.Equal(t, sharedSolverName(group), svc.Name)
+		b.Sync()
+	}
+
+	pods, err := s.podLister.List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, pods, 1, "expected a single shared pod")
+	assert.Equal(t, sharedSolverName(group), pods[0].Name)
+	assert.Equal(t, []string{"--listen-port=8089", "--key-thumbprint=thumbprint"}, pods[0].Spec.Containers[0].Args)
+	assert.Len(t, pods[0].OwnerReferences, 2)
+	assert.True(t, isSharedBy(pods[0], chA))
+	assert.True(t, isSharedBy(pods[0], chB))
+
+	services, err := s.serviceLister.List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, services, 1, "expected a single shared service")
+	assert.Equal(t, sharedPodLabels(group), services[0].Spec.Selector)
+	assert.Len(t, services[0].OwnerReferences, 2)
+
+	// Cleaning up the first challenge must leave the resources in place for
+	// the second one.
+	require.NoError(t, s.cleanupSharedPod(ctx, chA))
+	require.NoError(t, s.cleanupSharedService(ctx, chA))
+	b.Sync()
+
+	pods, err = s.podLister.List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.False(t, isSharedBy(pods[0], chA))
+	assert.True(t, isSharedBy(pods[0], chB))
+	services, err = s.serviceLister.List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Len(t, services[0].OwnerReferences, 1)
+
+	// Cleaning up the last challenge deletes the resources.
+	require.NoError(t, s.cleanupSharedPod(ctx, chB))
+	require.NoError(t, s.cleanupSharedService(ctx, chB))
+	b.Sync()
+
+	pods, err = s.podLister.List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, pods, 0)
+	services, err = s.serviceLister.List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, services, 0)
+}
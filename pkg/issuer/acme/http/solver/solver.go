@@ -20,11 +20,15 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"regexp"
 	"strings"
 
 	"github.com/go-logr/logr"
 )
 
+// validToken matches the base64url alphabet used for ACME challenge tokens.
+var validToken = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
 type HTTP01Solver struct {
 	ListenPort int
 
@@ -32,6 +36,17 @@ type HTTP01Solver struct {
 	Token  string
 	Key    string
 
+	// KeyThumbprint is the thumbprint of the ACME account key. If set, the
+	// solver will respond to requests for any token and domain with the key
+	// authorization for that token, allowing a single solver to serve many
+	// challenges. Domain, Token and Key are ignored.
+	KeyThumbprint string
+
+	// PathPrefix is prepended to HTTPChallengePath when matching requests,
+	// for solvers that are reached through a reverse proxy under a path
+	// prefix.
+	PathPrefix string
+
 	http.Server
 }
 
@@ -40,10 +55,23 @@ func (h *HTTP01Solver) Listen(log logr.Logger) error {
 		"expected_domain", h.Domain,
 		"expected_token", h.Token,
 		"expected_key", h.Key,
+		"key_thumbprint", h.KeyThumbprint,
 		"listen_port", h.ListenPort,
+		"path_prefix", h.PathPrefix,
 	)
 
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	h.Server = http.Server{
+		Addr:    fmt.Sprintf(":%d", h.ListenPort),
+		Handler: h.handler(log),
+	}
+
+	return h.Server.ListenAndServe()
+}
+
+// handler returns the handler that responds to challenge requests.
+func (h *HTTP01Solver) handler(log logr.Logger) http.Handler {
+	challengePath := h.PathPrefix + HTTPChallengePath
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// extract vars from the request
 		host := strings.Split(r.Host, ":")[0]
 		basePath := path.Dir(r.URL.EscapedPath())
@@ -63,12 +91,26 @@ func (h *HTTP01Solver) Listen(log logr.Logger) error {
 		}
 		log.Info("validating request")
 		// verify the base path is correct
-		if basePath != HTTPChallengePath {
-			log.Info("invalid base_path", "expected_base_path", HTTPChallengePath)
+		if basePath != challengePath {
+			log.Info("invalid base_path", "expected_base_path", challengePath)
 			http.NotFound(w, r)
 			return
 		}
 
+		if h.KeyThumbprint != "" {
+			if !validToken.MatchString(token) {
+				log.Info("invalid token")
+				http.NotFound(w, r)
+				return
+			}
+
+			log.Info("got challenge request for shared solver, writing key authorization")
+			w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, "%s.%s", token, h.KeyThumbprint)
+			return
+		}
+
 		log.Info("comparing host", "expected_host", h.Domain)
 		if h.Domain != host {
 			log.Info("invalid host", "expected_host", h.Domain)
@@ -89,11 +131,4 @@ func (h *HTTP01Solver) Listen(log logr.Logger) error {
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprint(w, h.Key)
 	})
-
-	h.Server = http.Server{
-		Addr:    fmt.Sprintf(":%d", h.ListenPort),
-		Handler: handler,
-	}
-
-	return h.Server.ListenAndServe()
 }
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package solver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+)
+
+func TestHandler(t *testing.T) {
+	tests := map[string]struct {
+		solver *HTTP01Solver
+		path   string
+
+		expectedStatus int
+		expectedBody   string
+	}{
+		"responds to a request for the challenge token": {
+			solver:         &HTTP01Solver{Domain: "example.com", Token: "token", Key: "token.key"},
+			path:           "/.well-known/acme-challenge/token",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "token.key",
+		},
+		"responds to a request for the challenge token under the path prefix": {
+			solver:         &HTTP01Solver{Domain: "example.com", Token: "token", Key: "token.key", PathPrefix: "/acme"},
+			path:           "/acme/.well-known/acme-challenge/token",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "token.key",
+		},
+		"responds to a request for any token under the path prefix for a shared solver": {
+			solver:         &HTTP01Solver{KeyThumbprint: "thumbprint", PathPrefix: "/acme"},
+			path:           "/acme/.well-known/acme-challenge/token",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "token.thumbprint",
+		},
+		"does not respond to a request without the path prefix": {
+			solver:         &HTTP01Solver{Domain: "example.com", Token: "token", Key: "token.key", PathPrefix: "/acme"},
+			path:           "/.well-known/acme-challenge/token",
+			expectedStatus: http.StatusNotFound,
+		},
+		"does not respond to a request for another token": {
+			solver:         &HTTP01Solver{Domain: "example.com", Token: "token", Key: "token.key"},
+			path:           "/.well-known/acme-challenge/other",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com"+test.path, nil)
+			rec := httptest.NewRecorder()
+			test.solver.handler(logr.Discard()).ServeHTTP(rec, req)
+
+			resp := rec.Result()
+			if resp.StatusCode != test.expectedStatus {
+				t.Errorf("expected status %d, got %d", test.expectedStatus, resp.StatusCode)
+			}
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != test.expectedBody {
+				t.Errorf("expected body %q, got %q", test.expectedBody, string(body))
+			}
+		})
+	}
+}
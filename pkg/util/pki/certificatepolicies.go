@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// OIDExtensionCertificatePolicies is the OID of the Certificate Policies
+// extension defined in RFC 5280, section 4.2.1.4.
+var OIDExtensionCertificatePolicies = asn1.ObjectIdentifier{2, 5, 29, 32}
+
+// policyInformation is the PolicyInformation ASN.1 structure defined in RFC
+// 5280. Policy qualifiers are not supported.
+type policyInformation struct {
+	Policy asn1.ObjectIdentifier
+}
+
+// CertificatePoliciesForCertificate returns the certificate policy OIDs
+// required by the Certificate's CertificatePoliciesAnnotation, if any.
+func CertificatePoliciesForCertificate(crt *v1.Certificate) ([]asn1.ObjectIdentifier, error) {
+	value, ok := crt.Annotations[v1.CertificatePoliciesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	var policies []asn1.ObjectIdentifier
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		oid, err := ParseObjectIdentifier(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", v1.CertificatePoliciesAnnotation, err)
+		}
+		policies = append(policies, oid)
+	}
+	return policies, nil
+}
+
+// ParseObjectIdentifier parses an OID in dotted decimal notation, e.g.
+// "2.23.140.1.2.1".
+func ParseObjectIdentifier(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("%q is not a valid object identifier", s)
+	}
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%q is not a valid object identifier", s)
+		}
+		oid[i] = n
+	}
+	return oid, nil
+}
+
+// BuildCertificatePoliciesExtension returns a Certificate Policies extension
+// containing the given policy OIDs.
+func BuildCertificatePoliciesExtension(policies []asn1.ObjectIdentifier) (pkix.Extension, error) {
+	infos := make([]policyInformation, len(policies))
+	for i, policy := range policies {
+		infos[i] = policyInformation{Policy: policy}
+	}
+	value, err := asn1.Marshal(infos)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: OIDExtensionCertificatePolicies, Value: value}, nil
+}
+
+// certificatePoliciesExtensionsForCertificate returns the extensions to add
+// to the CSR or certificate template for the given Certificate in order to
+// request its required certificate policies, if any.
+func certificatePoliciesExtensionsForCertificate(crt *v1.Certificate) ([]pkix.Extension, error) {
+	policies, err := CertificatePoliciesForCertificate(crt)
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+	ext, err := BuildCertificatePoliciesExtension(policies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build certificate policies extension: %w", err)
+	}
+	return []pkix.Extension{ext}, nil
+}
+
+// MissingCertificatePolicies returns those of the required policy OIDs that
+// are not contained in the given policies.
+func MissingCertificatePolicies(required, policies []asn1.ObjectIdentifier) []asn1.ObjectIdentifier {
+	var missing []asn1.ObjectIdentifier
+	for _, r := range required {
+		found := false
+		for _, p := range policies {
+			if r.Equal(p) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// certificatePoliciesExtensions returns the Certificate Policies extensions
+// in the given extensions.
+func certificatePoliciesExtensions(extensions []pkix.Extension) []pkix.Extension {
+	var exts []pkix.Extension
+	for _, ext := range extensions {
+		if ext.Id.Equal(OIDExtensionCertificatePolicies) {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
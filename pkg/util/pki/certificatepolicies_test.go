@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+func TestCertificatePoliciesForCertificate(t *testing.T) {
+	withAnnotation := func(value string) *cmapi.Certificate {
+		return &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.CertificatePoliciesAnnotation: value}}}
+	}
+
+	policies, err := CertificatePoliciesForCertificate(withAnnotation("2.23.140.1.2.1, 1.3.6.1.4.1.44947.1.1.1"))
+	require.NoError(t, err)
+	assert.Equal(t, []asn1.ObjectIdentifier{{2, 23, 140, 1, 2, 1}, {1, 3, 6, 1, 4, 1, 44947, 1, 1, 1}}, policies)
+
+	policies, err = CertificatePoliciesForCertificate(&cmapi.Certificate{})
+	require.NoError(t, err)
+	assert.Nil(t, policies)
+
+	_, err = CertificatePoliciesForCertificate(withAnnotation("2.23.foo"))
+	assert.EqualError(t, err, `invalid cert-manager.io/certificate-policies annotation: "2.23.foo" is not a valid object identifier`)
+
+	_, err = CertificatePoliciesForCertificate(withAnnotation("2"))
+	assert.Error(t, err)
+}
+
+func TestMissingCertificatePolicies(t *testing.T) {
+	a := asn1.ObjectIdentifier{2, 23, 140, 1, 2, 1}
+	b := asn1.ObjectIdentifier{2, 23, 140, 1, 2, 2}
+
+	assert.Empty(t, MissingCertificatePolicies([]asn1.ObjectIdentifier{a}, []asn1.ObjectIdentifier{b, a}))
+	assert.Equal(t, []asn1.ObjectIdentifier{b}, MissingCertificatePolicies([]asn1.ObjectIdentifier{a, b}, []asn1.ObjectIdentifier{a}))
+	assert.Empty(t, MissingCertificatePolicies(nil, nil))
+}
+
+func TestGenerateTemplateFromCSRPEMCopiesCertificatePolicies(t *testing.T) {
+	pk, err := GenerateECPrivateKey(256)
+	require.NoError(t, err)
+
+	crt := &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.CertificatePoliciesAnnotation: "2.23.140.1.2.1"}},
+		Spec: cmapi.CertificateSpec{
+			CommonName: "example.org",
+			PrivateKey: &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm},
+		},
+	}
+	csr, err := GenerateCSR(crt)
+	require.NoError(t, err)
+	csrDER, err := EncodeCSR(csr, pk)
+	require.NoError(t, err)
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	template, err := GenerateTemplateFromCSRPEM(csrPEM, time.Hour, false)
+	require.NoError(t, err)
+	_, cert, err := SignCertificate(template, template, pk.Public(), pk)
+	require.NoError(t, err)
+	assert.Equal(t, []asn1.ObjectIdentifier{{2, 23, 140, 1, 2, 1}}, cert.PolicyIdentifiers)
+
+	crt.Annotations = nil
+	template, err = GenerateTemplate(crt)
+	require.NoError(t, err)
+	template.PublicKey = pk.Public()
+	_, cert, err = SignCertificate(template, template, pk.Public(), pk)
+	require.NoError(t, err)
+	assert.Empty(t, cert.PolicyIdentifiers)
+}
@@ -19,7 +19,11 @@ package pki
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
@@ -29,6 +33,7 @@ import (
 	"math/big"
 	"net"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -209,6 +214,16 @@ func GenerateCSR(crt *v1.Certificate) (*x509.CertificateRequest, error) {
 			return nil, err
 		}
 	}
+	mustStapleExtensions, err := ocspMustStapleExtensionsForCertificate(crt)
+	if err != nil {
+		return nil, err
+	}
+	extraExtensions = append(extraExtensions, mustStapleExtensions...)
+	policyExtensions, err := certificatePoliciesExtensionsForCertificate(crt)
+	if err != nil {
+		return nil, err
+	}
+	extraExtensions = append(extraExtensions, policyExtensions...)
 
 	return &x509.CertificateRequest{
 		// Version 0 is the only one defined in the PKCS#10 standard, RFC2986.
@@ -303,6 +318,16 @@ func GenerateTemplate(crt *v1.Certificate) (*x509.Certificate, error) {
 		return nil, err
 	}
 
+	extraExtensions, err := ocspMustStapleExtensionsForCertificate(crt)
+	if err != nil {
+		return nil, err
+	}
+	policyExtensions, err := certificatePoliciesExtensionsForCertificate(crt)
+	if err != nil {
+		return nil, err
+	}
+	extraExtensions = append(extraExtensions, policyExtensions...)
+
 	return &x509.Certificate{
 		// Version must be 2 according to RFC5280.
 		// A version value of 2 confusingly means version 3.
@@ -327,12 +352,13 @@ func GenerateTemplate(crt *v1.Certificate) (*x509.Certificate, error) {
 		NotBefore: time.Now(),
 		NotAfter:  time.Now().Add(certDuration),
 		// see http://golang.org/pkg/crypto/x509/#KeyUsage
-		KeyUsage:       keyUsages,
-		ExtKeyUsage:    extKeyUsages,
-		DNSNames:       dnsNames,
-		IPAddresses:    ipAddresses,
-		URIs:           uris,
-		EmailAddresses: crt.Spec.EmailAddresses,
+		KeyUsage:        keyUsages,
+		ExtKeyUsage:     extKeyUsages,
+		DNSNames:        dnsNames,
+		IPAddresses:     ipAddresses,
+		URIs:            uris,
+		EmailAddresses:  crt.Spec.EmailAddresses,
+		ExtraExtensions: extraExtensions,
 	}, nil
 }
 
@@ -396,9 +422,71 @@ func GenerateTemplateFromCSRPEMWithUsages(csrPEM []byte, duration time.Duration,
 		IPAddresses:    csr.IPAddresses,
 		EmailAddresses: csr.EmailAddresses,
 		URIs:           csr.URIs,
+		// Requested TLS Features, such as OCSP Must-Staple, and certificate
+		// policies are carried over to the issued certificate.
+		ExtraExtensions: append(tlsFeatureExtensions(csr.Extensions), certificatePoliciesExtensions(csr.Extensions)...),
 	}, nil
 }
 
+// LimitTemplateDuration reduces the NotAfter time of the given certificate
+// template so that it is valid for at most maxDuration after its NotBefore
+// time. It returns true if the template was modified.
+func LimitTemplateDuration(template *x509.Certificate, maxDuration time.Duration) bool {
+	if maxDuration <= 0 || template.NotAfter.Sub(template.NotBefore) <= maxDuration {
+		return false
+	}
+	template.NotAfter = template.NotBefore.Add(maxDuration)
+	return true
+}
+
+// LimitTemplateToIssuer reduces the NotAfter time of the given certificate
+// template so that it does not outlive the given issuer certificate. It
+// returns true if the template was modified.
+func LimitTemplateToIssuer(template *x509.Certificate, issuer *x509.Certificate) bool {
+	if !template.NotAfter.After(issuer.NotAfter) {
+		return false
+	}
+	template.NotAfter = issuer.NotAfter
+	return true
+}
+
+// AddTemplateSANs adds the given DNS names and IP addresses to the subject
+// alternative names of the given certificate template, skipping any that are
+// already present. An error is returned if an IP address cannot be parsed.
+func AddTemplateSANs(template *x509.Certificate, dnsNames, ipAddresses []string) error {
+	for _, dnsName := range dnsNames {
+		found := false
+		for _, existing := range template.DNSNames {
+			if strings.EqualFold(existing, dnsName) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			template.DNSNames = append(template.DNSNames, dnsName)
+		}
+	}
+
+	for _, ipAddress := range ipAddresses {
+		ip := net.ParseIP(ipAddress)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address: %q", ipAddress)
+		}
+		found := false
+		for _, existing := range template.IPAddresses {
+			if existing.Equal(ip) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		}
+	}
+
+	return nil
+}
+
 // SignCertificate returns a signed *x509.Certificate given a template
 // *x509.Certificate crt and an issuer.
 // publicKey is the public key of the signee, and signerKey is the private
@@ -551,3 +639,76 @@ func SignatureAlgorithm(crt *v1.Certificate) (x509.PublicKeyAlgorithm, x509.Sign
 	}
 	return pubKeyAlgo, sigAlgo, nil
 }
+
+// signatureAlgorithmsByName maps the names of the signature algorithms which
+// can be configured on an issuer to the x509 signature algorithm and the type
+// of key that they can be used with.
+var signatureAlgorithmsByName = map[string]struct {
+	pubKeyAlgo x509.PublicKeyAlgorithm
+	sigAlgo    x509.SignatureAlgorithm
+}{
+	"SHA256WithRSA":   {x509.RSA, x509.SHA256WithRSA},
+	"SHA384WithRSA":   {x509.RSA, x509.SHA384WithRSA},
+	"SHA512WithRSA":   {x509.RSA, x509.SHA512WithRSA},
+	"ECDSAWithSHA256": {x509.ECDSA, x509.ECDSAWithSHA256},
+	"ECDSAWithSHA384": {x509.ECDSA, x509.ECDSAWithSHA384},
+	"ECDSAWithSHA512": {x509.ECDSA, x509.ECDSAWithSHA512},
+	"PureEd25519":     {x509.Ed25519, x509.PureEd25519},
+}
+
+// SignatureAlgorithmNames returns the sorted names of the signature algorithms
+// which can be configured on an issuer.
+func SignatureAlgorithmNames() []string {
+	names := make([]string, 0, len(signatureAlgorithmsByName))
+	for name := range signatureAlgorithmsByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SignatureAlgorithmForKey returns the x509 signature algorithm with the given
+// name, as configured on an issuer. An error is returned if the name is not
+// known, or if the signature algorithm cannot be used to sign with the private
+// key corresponding to the given public key.
+func SignatureAlgorithmForKey(name string, publicKey crypto.PublicKey) (x509.SignatureAlgorithm, error) {
+	algo, ok := signatureAlgorithmsByName[name]
+	if !ok {
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported signature algorithm %q", name)
+	}
+
+	var pubKeyAlgo x509.PublicKeyAlgorithm
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		pubKeyAlgo = x509.RSA
+	case *ecdsa.PublicKey:
+		pubKeyAlgo = x509.ECDSA
+	case ed25519.PublicKey:
+		pubKeyAlgo = x509.Ed25519
+	default:
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("unrecognised public key type: %T", publicKey)
+	}
+
+	if pubKeyAlgo != algo.pubKeyAlgo {
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("signature algorithm %q requires a %s key, but the key is %s", name, algo.pubKeyAlgo, pubKeyAlgo)
+	}
+	return algo.sigAlgo, nil
+}
+
+// ValidatePublicKeyAlgorithm returns an error if certificates cannot be
+// issued for the given public key, i.e. if it is not an RSA key, an ECDSA key
+// on one of the P-256, P-384 or P-521 curves, or an Ed25519 key.
+func ValidatePublicKeyAlgorithm(publicKey crypto.PublicKey) error {
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+			return nil
+		}
+		return fmt.Errorf("unsupported ecdsa curve: %s", pub.Curve.Params().Name)
+	default:
+		return fmt.Errorf("unsupported public key type: %T", publicKey)
+	}
+}
@@ -19,16 +19,21 @@ package pki
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"math/big"
+	"net"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/cert-manager/cert-manager/pkg/util"
@@ -352,6 +357,59 @@ func TestSignatureAlgorithmForCertificate(t *testing.T) {
 	}
 }
 
+func TestSignatureAlgorithmForKey(t *testing.T) {
+	rsaKey, err := GenerateRSAPrivateKey(2048)
+	require.NoError(t, err)
+	ecKey, err := GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	edKey, err := GenerateEd25519PrivateKey()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		name      string
+		publicKey crypto.PublicKey
+
+		expectedSigAlgo x509.SignatureAlgorithm
+		expectErr       bool
+	}{
+		"SHA384WithRSA can be used with an RSA key": {
+			name:            "SHA384WithRSA",
+			publicKey:       rsaKey.Public(),
+			expectedSigAlgo: x509.SHA384WithRSA,
+		},
+		"ECDSAWithSHA512 can be used with an ECDSA key": {
+			name:            "ECDSAWithSHA512",
+			publicKey:       ecKey.Public(),
+			expectedSigAlgo: x509.ECDSAWithSHA512,
+		},
+		"PureEd25519 can be used with an Ed25519 key": {
+			name:            "PureEd25519",
+			publicKey:       edKey.Public(),
+			expectedSigAlgo: x509.PureEd25519,
+		},
+		"SHA384WithRSA cannot be used with an ECDSA key": {
+			name:            "SHA384WithRSA",
+			publicKey:       ecKey.Public(),
+			expectedSigAlgo: x509.UnknownSignatureAlgorithm,
+			expectErr:       true,
+		},
+		"unknown signature algorithms are rejected": {
+			name:            "MD5WithRSA",
+			publicKey:       rsaKey.Public(),
+			expectedSigAlgo: x509.UnknownSignatureAlgorithm,
+			expectErr:       true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sigAlgo, err := SignatureAlgorithmForKey(test.name, test.publicKey)
+			assert.Equal(t, test.expectErr, err != nil, "unexpected error: %v", err)
+			assert.Equal(t, test.expectedSigAlgo, sigAlgo)
+		})
+	}
+}
+
 func TestRemoveDuplicates(t *testing.T) {
 	type testT struct {
 		input  []string
@@ -413,6 +471,11 @@ func TestGenerateCSR(t *testing.T) {
 		},
 	}
 
+	mustStapleExtension, err := BuildOCSPMustStapleExtension()
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	tests := []struct {
 		name    string
 		crt     *cmapi.Certificate
@@ -463,6 +526,20 @@ func TestGenerateCSR(t *testing.T) {
 				ExtraExtensions:    defaultExtraExtensions,
 			},
 		},
+		{
+			name: "Generate CSR from certificate requesting OCSP must-staple",
+			crt: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.OCSPMustStapleAnnotation: "true"}},
+				Spec:       cmapi.CertificateSpec{CommonName: "example.org"},
+			},
+			want: &x509.CertificateRequest{
+				Version:            0,
+				SignatureAlgorithm: x509.SHA256WithRSA,
+				PublicKeyAlgorithm: x509.RSA,
+				Subject:            pkix.Name{CommonName: "example.org"},
+				ExtraExtensions:    append(defaultExtraExtensions, mustStapleExtension),
+			},
+		},
 		{
 			name:    "Error on generating CSR from certificate with no subject",
 			crt:     &cmapi.Certificate{Spec: cmapi.CertificateSpec{}},
@@ -570,6 +647,96 @@ func Test_buildKeyUsagesExtensionsForCertificate(t *testing.T) {
 	}
 }
 
+func TestLimitTemplateDuration(t *testing.T) {
+	notBefore := time.Now()
+	tests := map[string]struct {
+		duration    time.Duration
+		maxDuration time.Duration
+
+		expectedDuration time.Duration
+		expectedLimited  bool
+	}{
+		"duration under the max is not changed": {
+			duration:         time.Hour,
+			maxDuration:      time.Hour * 2,
+			expectedDuration: time.Hour,
+		},
+		"duration equal to the max is not changed": {
+			duration:         time.Hour,
+			maxDuration:      time.Hour,
+			expectedDuration: time.Hour,
+		},
+		"duration over the max is limited": {
+			duration:         time.Hour * 2,
+			maxDuration:      time.Hour,
+			expectedDuration: time.Hour,
+			expectedLimited:  true,
+		},
+		"a zero max does not limit the duration": {
+			duration:         time.Hour * 2,
+			expectedDuration: time.Hour * 2,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			template := &x509.Certificate{NotBefore: notBefore, NotAfter: notBefore.Add(test.duration)}
+			assert.Equal(t, test.expectedLimited, LimitTemplateDuration(template, test.maxDuration))
+			assert.Equal(t, notBefore, template.NotBefore)
+			assert.Equal(t, test.expectedDuration, template.NotAfter.Sub(template.NotBefore))
+		})
+	}
+}
+
+func TestLimitTemplateToIssuer(t *testing.T) {
+	notBefore := time.Now()
+	tests := map[string]struct {
+		duration       time.Duration
+		issuerDuration time.Duration
+
+		expectedDuration time.Duration
+		expectedLimited  bool
+	}{
+		"a certificate expiring before the issuer is not changed": {
+			duration:         time.Hour,
+			issuerDuration:   time.Hour * 2,
+			expectedDuration: time.Hour,
+		},
+		"a certificate expiring with the issuer is not changed": {
+			duration:         time.Hour,
+			issuerDuration:   time.Hour,
+			expectedDuration: time.Hour,
+		},
+		"a certificate outliving the issuer is limited": {
+			duration:         time.Hour * 2,
+			issuerDuration:   time.Hour,
+			expectedDuration: time.Hour,
+			expectedLimited:  true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			template := &x509.Certificate{NotBefore: notBefore, NotAfter: notBefore.Add(test.duration)}
+			issuer := &x509.Certificate{NotBefore: notBefore, NotAfter: notBefore.Add(test.issuerDuration)}
+			assert.Equal(t, test.expectedLimited, LimitTemplateToIssuer(template, issuer))
+			assert.Equal(t, notBefore, template.NotBefore)
+			assert.Equal(t, test.expectedDuration, template.NotAfter.Sub(template.NotBefore))
+		})
+	}
+}
+
+func TestAddTemplateSANs(t *testing.T) {
+	template := &x509.Certificate{
+		DNSNames:    []string{"example.com"},
+		IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+	}
+	err := AddTemplateSANs(template, []string{"EXAMPLE.com", "pod.internal"}, []string{"10.0.0.1", "::1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "pod.internal"}, template.DNSNames)
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("::1")}, template.IPAddresses)
+
+	assert.Error(t, AddTemplateSANs(template, nil, []string{"not-an-ip"}))
+}
+
 func TestSignCSRTemplate(t *testing.T) {
 	// We want to test the behavior of SignCSRTemplate in various contexts;
 	// for that, we construct a chain of four certificates:
@@ -762,3 +929,44 @@ func TestEncodeX509Chain(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePublicKeyAlgorithm(t *testing.T) {
+	mustPublicKey := func(key crypto.Signer, err error) crypto.PublicKey {
+		require.NoError(t, err)
+		return key.Public()
+	}
+
+	tests := map[string]struct {
+		publicKey crypto.PublicKey
+		expErr    string
+	}{
+		"RSA keys are supported": {
+			publicKey: mustPublicKey(GenerateRSAPrivateKey(2048)),
+		},
+		"ECDSA P-384 keys are supported": {
+			publicKey: mustPublicKey(GenerateECPrivateKey(ECCurve384)),
+		},
+		"Ed25519 keys are supported": {
+			publicKey: mustPublicKey(GenerateEd25519PrivateKey()),
+		},
+		"ECDSA P-224 keys are not supported": {
+			publicKey: mustPublicKey(ecdsa.GenerateKey(elliptic.P224(), rand.Reader)),
+			expErr:    "unsupported ecdsa curve: P-224",
+		},
+		"unknown key types are not supported": {
+			publicKey: "not a key",
+			expErr:    "unsupported public key type: string",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidatePublicKeyAlgorithm(test.publicKey)
+			if test.expErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+
+	v1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// OIDExtensionTLSFeature is the OID of the TLS Feature extension defined in
+// RFC 7633.
+var OIDExtensionTLSFeature = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}
+
+// tlsFeatureStatusRequest is the TLS Feature value for the status_request
+// extension, which is used to require OCSP stapling ("OCSP Must-Staple").
+const tlsFeatureStatusRequest = 5
+
+// OCSPMustStapleForCertificate returns whether the Certificate requests the
+// OCSP Must-Staple extension, and whether it has expressed a preference at
+// all by setting the OCSPMustStapleAnnotation.
+func OCSPMustStapleForCertificate(crt *v1.Certificate) (mustStaple, set bool) {
+	value, ok := crt.Annotations[v1.OCSPMustStapleAnnotation]
+	if !ok {
+		return false, false
+	}
+	return value == "true", true
+}
+
+// BuildOCSPMustStapleExtension returns a TLS Feature extension requiring OCSP
+// stapling.
+func BuildOCSPMustStapleExtension() (pkix.Extension, error) {
+	value, err := asn1.Marshal([]int{tlsFeatureStatusRequest})
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: OIDExtensionTLSFeature, Value: value}, nil
+}
+
+// ocspMustStapleExtensionsForCertificate returns the extensions to add to the
+// CSR or certificate template for the given Certificate in order to request
+// OCSP Must-Staple, if any.
+func ocspMustStapleExtensionsForCertificate(crt *v1.Certificate) ([]pkix.Extension, error) {
+	if mustStaple, _ := OCSPMustStapleForCertificate(crt); !mustStaple {
+		return nil, nil
+	}
+	ext, err := BuildOCSPMustStapleExtension()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OCSP must-staple extension: %w", err)
+	}
+	return []pkix.Extension{ext}, nil
+}
+
+// HasOCSPMustStaple returns whether the given extensions contain a TLS
+// Feature extension requiring OCSP stapling.
+func HasOCSPMustStaple(extensions []pkix.Extension) bool {
+	for _, ext := range extensions {
+		if !ext.Id.Equal(OIDExtensionTLSFeature) {
+			continue
+		}
+		var features []int
+		if _, err := asn1.Unmarshal(ext.Value, &features); err != nil {
+			return false
+		}
+		for _, feature := range features {
+			if feature == tlsFeatureStatusRequest {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tlsFeatureExtensions returns the TLS Feature extensions in the given
+// extensions.
+func tlsFeatureExtensions(extensions []pkix.Extension) []pkix.Extension {
+	var exts []pkix.Extension
+	for _, ext := range extensions {
+		if ext.Id.Equal(OIDExtensionTLSFeature) {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+func TestHasOCSPMustStaple(t *testing.T) {
+	mustStaple, err := BuildOCSPMustStapleExtension()
+	require.NoError(t, err)
+
+	assert.True(t, HasOCSPMustStaple([]pkix.Extension{mustStaple}))
+	assert.False(t, HasOCSPMustStaple(nil))
+	assert.False(t, HasOCSPMustStaple([]pkix.Extension{{Id: OIDExtensionKeyUsage, Value: []byte{0x03, 0x02, 0x05, 0xa0}}}))
+	// A TLS Feature extension that does not contain status_request.
+	assert.False(t, HasOCSPMustStaple([]pkix.Extension{{Id: OIDExtensionTLSFeature, Value: []byte{0x30, 0x03, 0x02, 0x01, 0x11}}}))
+	assert.False(t, HasOCSPMustStaple([]pkix.Extension{{Id: OIDExtensionTLSFeature, Value: []byte{0xff}}}))
+}
+
+func TestOCSPMustStapleForCertificate(t *testing.T) {
+	withAnnotation := func(value string) *cmapi.Certificate {
+		return &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.OCSPMustStapleAnnotation: value}}}
+	}
+
+	mustStaple, set := OCSPMustStapleForCertificate(withAnnotation("true"))
+	assert.True(t, mustStaple)
+	assert.True(t, set)
+
+	mustStaple, set = OCSPMustStapleForCertificate(withAnnotation("false"))
+	assert.False(t, mustStaple)
+	assert.True(t, set)
+
+	mustStaple, set = OCSPMustStapleForCertificate(&cmapi.Certificate{})
+	assert.False(t, mustStaple)
+	assert.False(t, set)
+}
+
+func TestGenerateTemplateFromCSRPEMCopiesOCSPMustStaple(t *testing.T) {
+	pk, err := GenerateECPrivateKey(256)
+	require.NoError(t, err)
+
+	crt := &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.OCSPMustStapleAnnotation: "true"}},
+		Spec: cmapi.CertificateSpec{
+			CommonName: "example.org",
+			PrivateKey: &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm},
+		},
+	}
+	csr, err := GenerateCSR(crt)
+	require.NoError(t, err)
+	csrDER, err := EncodeCSR(csr, pk)
+	require.NoError(t, err)
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	template, err := GenerateTemplateFromCSRPEM(csrPEM, time.Hour, false)
+	require.NoError(t, err)
+	_, cert, err := SignCertificate(template, template, pk.Public(), pk)
+	require.NoError(t, err)
+	assert.True(t, HasOCSPMustStaple(cert.Extensions), "expected the issued certificate to require OCSP stapling")
+
+	crt.Annotations = nil
+	template, err = GenerateTemplate(crt)
+	require.NoError(t, err)
+	template.PublicKey = pk.Public()
+	_, cert, err = SignCertificate(template, template, pk.Public(), pk)
+	require.NoError(t, err)
+	assert.False(t, HasOCSPMustStaple(cert.Extensions))
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+
+	v1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// OIDExtensionSCTList is the OID of the extension holding the Signed
+// Certificate Timestamps embedded in a certificate, defined in RFC 6962.
+var OIDExtensionSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// EmbeddedSCTsForCertificate returns whether the Certificate expects its
+// issued certificate to contain embedded Signed Certificate Timestamps, and
+// whether it has expressed an expectation at all by setting the
+// EmbeddedSCTsAnnotation.
+func EmbeddedSCTsForCertificate(crt *v1.Certificate) (embedded, set bool) {
+	value, ok := crt.Annotations[v1.EmbeddedSCTsAnnotation]
+	if !ok {
+		return false, false
+	}
+	return value == "true", true
+}
+
+// HasEmbeddedSCTs returns true if the given certificate extensions contain a
+// Signed Certificate Timestamp list holding at least one timestamp. A list
+// that cannot be parsed is treated as containing no timestamps.
+func HasEmbeddedSCTs(extensions []pkix.Extension) bool {
+	for _, ext := range extensions {
+		if !ext.Id.Equal(OIDExtensionSCTList) {
+			continue
+		}
+		scts, err := parseSCTList(ext.Value)
+		if err != nil {
+			return false
+		}
+		return len(scts) > 0
+	}
+	return false
+}
+
+// parseSCTList parses the value of a Signed Certificate Timestamp list
+// extension, which is a DER encoded OCTET STRING containing the TLS encoded
+// SignedCertificateTimestampList defined in section 3.3 of RFC 6962. The
+// serialized timestamps are returned without being parsed themselves.
+func parseSCTList(value []byte) ([][]byte, error) {
+	var list []byte
+	if rest, err := asn1.Unmarshal(value, &list); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after SCT list")
+	}
+
+	list, err := readUint16Prefixed(list, true)
+	if err != nil {
+		return nil, err
+	}
+	var scts [][]byte
+	for len(list) > 0 {
+		sct, err := readUint16Prefixed(list, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(sct) == 0 {
+			return nil, errors.New("empty SCT in SCT list")
+		}
+		scts = append(scts, sct)
+		list = list[2+len(sct):]
+	}
+	return scts, nil
+}
+
+// readUint16Prefixed returns the data following the big-endian uint16 length
+// prefix at the start of b. If whole is true, the data must make up the rest
+// of b.
+func readUint16Prefixed(b []byte, whole bool) ([]byte, error) {
+	if len(b) < 2 {
+		return nil, errors.New("truncated SCT list")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	b = b[2:]
+	if len(b) < n || (whole && len(b) != n) {
+		return nil, errors.New("invalid length in SCT list")
+	}
+	return b[:n], nil
+}
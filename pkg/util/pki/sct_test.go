@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// sctListExtension returns a Signed Certificate Timestamp list extension
+// containing the given serialized timestamps.
+func sctListExtension(t *testing.T, scts ...[]byte) pkix.Extension {
+	var list []byte
+	for _, sct := range scts {
+		list = append(list, byte(len(sct)>>8), byte(len(sct)))
+		list = append(list, sct...)
+	}
+	list = append([]byte{byte(len(list) >> 8), byte(len(list))}, list...)
+	value, err := asn1.Marshal(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkix.Extension{Id: OIDExtensionSCTList, Value: value}
+}
+
+func TestHasEmbeddedSCTs(t *testing.T) {
+	tests := map[string]struct {
+		extensions []pkix.Extension
+		expected   bool
+	}{
+		"no extensions": {
+			expected: false,
+		},
+		"an unrelated extension": {
+			extensions: []pkix.Extension{{Id: OIDExtensionKeyUsage, Value: []byte{0x03, 0x02, 0x05, 0xa0}}},
+			expected:   false,
+		},
+		"an SCT list with one timestamp": {
+			extensions: []pkix.Extension{sctListExtension(t, []byte("sct-1"))},
+			expected:   true,
+		},
+		"an SCT list with two timestamps": {
+			extensions: []pkix.Extension{sctListExtension(t, []byte("sct-1"), []byte("sct-2"))},
+			expected:   true,
+		},
+		"an empty SCT list": {
+			extensions: []pkix.Extension{sctListExtension(t)},
+			expected:   false,
+		},
+		"an SCT list with an empty timestamp": {
+			extensions: []pkix.Extension{sctListExtension(t, []byte{})},
+			expected:   false,
+		},
+		"an SCT list that is not an OCTET STRING": {
+			extensions: []pkix.Extension{{Id: OIDExtensionSCTList, Value: []byte{0xff}}},
+			expected:   false,
+		},
+		"an SCT list with a truncated timestamp": {
+			// The list claims to hold 4 bytes, of which the timestamp claims
+			// to hold 5.
+			extensions: []pkix.Extension{{Id: OIDExtensionSCTList, Value: []byte{0x04, 0x06, 0x00, 0x04, 0x00, 0x05, 0x01, 0x02}}},
+			expected:   false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, HasEmbeddedSCTs(test.extensions))
+		})
+	}
+}
+
+func TestEmbeddedSCTsForCertificate(t *testing.T) {
+	withAnnotation := func(value string) *cmapi.Certificate {
+		return &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.EmbeddedSCTsAnnotation: value}}}
+	}
+
+	embedded, set := EmbeddedSCTsForCertificate(withAnnotation("true"))
+	assert.True(t, embedded)
+	assert.True(t, set)
+
+	embedded, set = EmbeddedSCTsForCertificate(withAnnotation("false"))
+	assert.False(t, embedded)
+	assert.True(t, set)
+
+	embedded, set = EmbeddedSCTsForCertificate(&cmapi.Certificate{})
+	assert.False(t, embedded)
+	assert.False(t, set)
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package predicate
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	"github.com/cert-manager/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 )
 
@@ -44,3 +46,32 @@ func CertificateNextPrivateKeySecretName(name string) Func {
 		return *crt.Status.NextPrivateKeySecretName == name
 	}
 }
+
+// CertificateIssuerRef returns a predicate that used to filter Certificates
+// to only those with a 'spec.issuerRef' referencing the given Issuer or
+// ClusterIssuer. It does not filter on namespace, which must be done
+// separately when filtering for an Issuer.
+func CertificateIssuerRef(issuer runtime.Object) Func {
+	var kind string
+	switch issuer.(type) {
+	case *cmapi.Issuer:
+		kind = cmapi.IssuerKind
+	case *cmapi.ClusterIssuer:
+		kind = cmapi.ClusterIssuerKind
+	default:
+		return func(runtime.Object) bool { return false }
+	}
+	name := issuer.(metav1.Object).GetName()
+
+	return func(obj runtime.Object) bool {
+		ref := obj.(*cmapi.Certificate).Spec.IssuerRef
+		if ref.Group != "" && ref.Group != certmanager.GroupName {
+			return false
+		}
+		refKind := ref.Kind
+		if refKind == "" {
+			refKind = cmapi.IssuerKind
+		}
+		return refKind == kind && ref.Name == name
+	}
+}
@@ -19,9 +19,12 @@ package predicate
 import (
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/pointer"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 )
 
 func TestCertificateSecretName(t *testing.T) {
@@ -92,3 +95,62 @@ func TestCertificateNextPrivateKeySecretName(t *testing.T) {
 		})
 	}
 }
+
+func TestCertificateIssuerRef(t *testing.T) {
+	certWithIssuerRef := func(ref cmmeta.ObjectReference) *cmapi.Certificate {
+		return &cmapi.Certificate{
+			Spec: cmapi.CertificateSpec{IssuerRef: ref},
+		}
+	}
+	issuer := &cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{Name: "abc"}}
+	clusterIssuer := &cmapi.ClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "abc"}}
+	tests := map[string]struct {
+		issuer   runtime.Object
+		cert     *cmapi.Certificate
+		expected bool
+	}{
+		"returns true if Issuer name matches and kind is empty": {
+			issuer:   issuer,
+			cert:     certWithIssuerRef(cmmeta.ObjectReference{Name: "abc"}),
+			expected: true,
+		},
+		"returns true if Issuer name, kind and group match": {
+			issuer:   issuer,
+			cert:     certWithIssuerRef(cmmeta.ObjectReference{Name: "abc", Kind: "Issuer", Group: "cert-manager.io"}),
+			expected: true,
+		},
+		"returns false if Issuer name does not match": {
+			issuer:   issuer,
+			cert:     certWithIssuerRef(cmmeta.ObjectReference{Name: "abcd"}),
+			expected: false,
+		},
+		"returns false if Issuer is referenced as a ClusterIssuer": {
+			issuer:   issuer,
+			cert:     certWithIssuerRef(cmmeta.ObjectReference{Name: "abc", Kind: "ClusterIssuer"}),
+			expected: false,
+		},
+		"returns true if ClusterIssuer name and kind match": {
+			issuer:   clusterIssuer,
+			cert:     certWithIssuerRef(cmmeta.ObjectReference{Name: "abc", Kind: "ClusterIssuer"}),
+			expected: true,
+		},
+		"returns false if ClusterIssuer is referenced with an empty kind": {
+			issuer:   clusterIssuer,
+			cert:     certWithIssuerRef(cmmeta.ObjectReference{Name: "abc"}),
+			expected: false,
+		},
+		"returns false if the reference is for an external issuer": {
+			issuer:   issuer,
+			cert:     certWithIssuerRef(cmmeta.ObjectReference{Name: "abc", Kind: "Issuer", Group: "example.com"}),
+			expected: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := CertificateIssuerRef(test.issuer)(test.cert)
+			if got != test.expected {
+				t.Errorf("unexpected response: got=%t, exp=%t", got, test.expected)
+			}
+		})
+	}
+}
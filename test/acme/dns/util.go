@@ -115,7 +115,7 @@ func closingStopCh(t time.Duration) <-chan struct{} {
 
 func (f *fixture) recordHasPropagatedCheck(fqdn, value string) func() (bool, error) {
 	return func() (bool, error) {
-		return util.PreCheckDNS(fqdn, value, []string{f.testDNSServer}, *f.useAuthoritative)
+		return util.PreCheckDNS(fqdn, value, []string{f.testDNSServer}, *f.useAuthoritative, true)
 	}
 }
 
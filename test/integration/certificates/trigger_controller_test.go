@@ -35,6 +35,7 @@ import (
 	cmclient "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
 	controllerpkg "github.com/cert-manager/cert-manager/pkg/controller"
 	"github.com/cert-manager/cert-manager/pkg/controller/certificates/trigger"
+	issuerfake "github.com/cert-manager/cert-manager/pkg/issuer/fake"
 	logf "github.com/cert-manager/cert-manager/pkg/logs"
 	"github.com/cert-manager/cert-manager/pkg/metrics"
 	"github.com/cert-manager/cert-manager/pkg/util/pki"
@@ -65,8 +66,14 @@ func TestTriggerController(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	shouldReissue := policies.NewTriggerPolicyChain(fakeClock).Evaluate
-	ctrl, queue, mustSync := trigger.NewController(logf.Log, cmCl, factory, cmFactory, framework.NewEventRecorder(t), fakeClock, shouldReissue)
+	// The referenced issuer doesn't need to exist for the purposes of this test.
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(cmmeta.ObjectReference, string) (cmapi.GenericIssuer, error) {
+			return &cmapi.Issuer{}, nil
+		},
+	}
+	shouldReissue := policies.NewTriggerPolicyChain(fakeClock, helper, policies.TriggerPolicyOptions{}).Evaluate
+	ctrl, queue, mustSync := trigger.NewController(logf.Log, cmCl, factory, cmFactory, framework.NewEventRecorder(t), fakeClock, shouldReissue, policies.TriggerPolicyOptions{}, false)
 	c := controllerpkg.NewController(
 		ctx,
 		"trigger_test",
@@ -123,7 +130,10 @@ func TestTriggerController_RenewNearExpiry(t *testing.T) {
 	// Only use the 'current certificate nearing expiry' policy chain during the
 	// test as we want to test the very specific cases of triggering/not
 	// triggering depending on whether a renewal is required.
-	shoudReissue := policies.Chain{policies.CurrentCertificateNearingExpiry(fakeClock)}.Evaluate
+	shoudReissue := policies.Chain{{
+		Name: policies.CurrentCertificateNearingExpiryPolicy,
+		Func: policies.CurrentCertificateNearingExpiry(fakeClock, policies.TriggerPolicyOptions{}),
+	}}.Evaluate
 	// Build, instantiate and run the trigger controller.
 	kubeClient, factory, cmCl, cmFactory := framework.NewClients(t, config)
 
@@ -178,7 +188,7 @@ func TestTriggerController_RenewNearExpiry(t *testing.T) {
 	}
 
 	// Start the trigger controller
-	ctrl, queue, mustSync := trigger.NewController(logf.Log, cmCl, factory, cmFactory, framework.NewEventRecorder(t), fakeClock, shoudReissue)
+	ctrl, queue, mustSync := trigger.NewController(logf.Log, cmCl, factory, cmFactory, framework.NewEventRecorder(t), fakeClock, shoudReissue, policies.TriggerPolicyOptions{}, false)
 	c := controllerpkg.NewController(
 		logf.NewContext(ctx, logf.Log, "trigger_controller_RenewNearExpiry"),
 		"trigger_test",
@@ -73,7 +73,7 @@ type cryptoBundle struct {
 }
 
 // MustCreateCryptoBundle creates a cryptoBundle to be used with tests or fails.
-func MustCreateCryptoBundle(t *testing.T, crt *cmapi.Certificate, fixedClock *fakeclock.FakeClock) cryptoBundle {
+func MustCreateCryptoBundle(t testing.TB, crt *cmapi.Certificate, fixedClock *fakeclock.FakeClock) cryptoBundle {
 	c, err := createCryptoBundle(crt, fixedClock)
 	if err != nil {
 		t.Fatalf("error generating crypto bundle: %v", err)
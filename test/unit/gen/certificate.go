@@ -104,6 +104,12 @@ func SetCertificateKeyEncoding(keyEncoding v1.PrivateKeyEncoding) CertificateMod
 	}
 }
 
+func SetCertificateKeyRotationPolicy(rotationPolicy v1.PrivateKeyRotationPolicy) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.PrivateKey.RotationPolicy = rotationPolicy
+	}
+}
+
 func SetCertificateSecretName(secretName string) CertificateModifier {
 	return func(crt *v1.Certificate) {
 		crt.Spec.SecretName = secretName
@@ -120,6 +126,12 @@ func SetCertificateSecretTemplate(annotations, labels map[string]string) Certifi
 	}
 }
 
+func SetCertificateKeystore(keystores *v1.CertificateKeystores) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.Keystores = keystores
+	}
+}
+
 func SetCertificateDuration(duration time.Duration) CertificateModifier {
 	return func(crt *v1.Certificate) {
 		crt.Spec.Duration = &metav1.Duration{Duration: duration}
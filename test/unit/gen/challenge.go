@@ -17,6 +17,8 @@ limitations under the License.
 package gen
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 )
@@ -107,3 +109,9 @@ func SetChallengeProcessing(b bool) ChallengeModifier {
 		ch.Status.Processing = b
 	}
 }
+
+func SetChallengeProcessingStartTime(t metav1.Time) ChallengeModifier {
+	return func(ch *cmacme.Challenge) {
+		ch.Status.ProcessingStartTime = &t
+	}
+}
@@ -346,6 +346,18 @@ func AddIssuerCondition(c v1.IssuerCondition) IssuerModifier {
 	}
 }
 
+func AddIssuerAnnotations(annotations map[string]string) IssuerModifier {
+	return func(iss v1.GenericIssuer) {
+		if iss.GetObjectMeta().Annotations == nil {
+			iss.GetObjectMeta().Annotations = make(map[string]string)
+		}
+
+		for k, v := range annotations {
+			iss.GetObjectMeta().Annotations[k] = v
+		}
+	}
+}
+
 func SetIssuerNamespace(namespace string) IssuerModifier {
 	return func(iss v1.GenericIssuer) {
 		iss.GetObjectMeta().Namespace = namespace
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policyinput builds consistent certificate policy Inputs from gen
+// fixtures, so that policy tests do not have to construct the Secret and
+// CertificateRequests of a Certificate by hand.
+package policyinput
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	"github.com/cert-manager/cert-manager/internal/controller/certificates/policies"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	testcrypto "github.com/cert-manager/cert-manager/test/unit/crypto"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+)
+
+// MismatchedDNSName is the DNS name added to the CSR of the current
+// CertificateRequest by WithMismatchedRequest.
+const MismatchedDNSName = "mismatched.example.com"
+
+// Option configures the Input built by MustBuild.
+type Option func(*options)
+
+type options struct {
+	clock             *fakeclock.FakeClock
+	validSecret       bool
+	expiredCert       bool
+	mismatchedRequest bool
+}
+
+// WithClock sets the clock used to determine the validity period of the
+// stored certificate. It should be the clock that the policies under test
+// are evaluated with. If not set, a fake clock set to the current time is
+// used.
+func WithClock(clock *fakeclock.FakeClock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithValidSecret populates the Input with a Secret containing a private key
+// and certificate that are up to date for the Certificate, along with the
+// ready CertificateRequest that the certificate was issued for.
+func WithValidSecret() Option {
+	return func(o *options) {
+		o.validSecret = true
+	}
+}
+
+// WithExpiredCert is like WithValidSecret, but the stored certificate expired
+// an hour before the current time.
+func WithExpiredCert() Option {
+	return func(o *options) {
+		o.validSecret = true
+		o.expiredCert = true
+	}
+}
+
+// WithMismatchedRequest is like WithValidSecret, but the CSR of the current
+// CertificateRequest also requests MismatchedDNSName, so is no longer valid
+// for the Certificate's spec. The CSR uses the same private key as the
+// stored certificate.
+func WithMismatchedRequest() Option {
+	return func(o *options) {
+		o.validSecret = true
+		o.mismatchedRequest = true
+	}
+}
+
+// MustBuild returns an Input for the given Certificate configured by opts.
+// Without options, the Input only contains a copy of the Certificate, as for
+// a Certificate whose Secret does not exist yet. The given Certificate is not
+// modified; when a Secret is added, the copy in the Input has its
+// status.revision set to 1 if not already set, so that the current
+// CertificateRequest is for its current revision.
+func MustBuild(t *testing.T, crt *cmapi.Certificate, opts ...Option) policies.Input {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.clock == nil {
+		o.clock = fakeclock.NewFakeClock(time.Now())
+	}
+
+	crt = crt.DeepCopy()
+	input := policies.Input{
+		Certificate:       crt,
+		IsInitialIssuance: true,
+	}
+	if !o.validSecret {
+		return input
+	}
+
+	if crt.Status.Revision == nil {
+		revision := 1
+		crt.Status.Revision = &revision
+	}
+
+	bundle := testcrypto.MustCreateCryptoBundle(t, crt, o.clock)
+	certBytes := bundle.CertBytes
+	if o.expiredCert {
+		now := o.clock.Now()
+		certBytes = testcrypto.MustCreateCertWithNotBeforeAfter(t, bundle.PrivateKeyBytes, crt, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	}
+
+	request := gen.CertificateRequestFrom(bundle.CertificateRequestReady,
+		gen.SetCertificateRequestCertificate(certBytes),
+	)
+	if o.mismatchedRequest {
+		mismatched := gen.CertificateFrom(crt,
+			gen.SetCertificateDNSNames(append(append([]string{}, crt.Spec.DNSNames...), MismatchedDNSName)...),
+		)
+		request = gen.CertificateRequestFrom(request,
+			gen.SetCertificateRequestCSR(testcrypto.MustGenerateCSRImpl(t, bundle.PrivateKeyBytes, mismatched)),
+		)
+	}
+
+	input.IsInitialIssuance = false
+	input.CurrentRevisionRequest = request
+	input.Secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: crt.Namespace,
+			Name:      crt.Spec.SecretName,
+			Annotations: map[string]string{
+				cmapi.CertificateNameKey:       crt.Name,
+				cmapi.IssuerNameAnnotationKey:  crt.Spec.IssuerRef.Name,
+				cmapi.IssuerKindAnnotationKey:  crt.Spec.IssuerRef.Kind,
+				cmapi.IssuerGroupAnnotationKey: crt.Spec.IssuerRef.Group,
+			},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSPrivateKeyKey: bundle.PrivateKeyBytes,
+			corev1.TLSCertKey:       certBytes,
+		},
+	}
+	return input
+}
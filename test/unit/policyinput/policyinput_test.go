@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyinput
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	"github.com/cert-manager/cert-manager/internal/controller/certificates/policies"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	issuerfake "github.com/cert-manager/cert-manager/pkg/issuer/fake"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	"github.com/cert-manager/cert-manager/test/unit/gen"
+)
+
+func testCertificate() *cmapi.Certificate {
+	return gen.Certificate("test",
+		gen.SetCertificateNamespace("testns"),
+		gen.SetCertificateCommonName("example.com"),
+		gen.SetCertificateDNSNames("example.com"),
+		gen.SetCertificateSecretName("test-tls"),
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "testissuer", Kind: "Issuer", Group: "cert-manager.io"}),
+	)
+}
+
+// Test_MustBuild evaluates the default trigger policy chain against each kind
+// of Input, to check that the Inputs are consistent and only violate the
+// policies they are intended to.
+func Test_MustBuild(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	helper := &issuerfake.Helper{
+		GetGenericIssuerFunc: func(cmmeta.ObjectReference, string) (cmapi.GenericIssuer, error) {
+			return &cmapi.Issuer{}, nil
+		},
+	}
+	chain := policies.NewTriggerPolicyChain(clock, helper, policies.TriggerPolicyOptions{})
+
+	tests := map[string]struct {
+		opts []Option
+
+		reason  string
+		reissue bool
+	}{
+		"without options the Secret does not exist": {
+			reason:  policies.DoesNotExist,
+			reissue: true,
+		},
+		"a valid Secret does not violate any policy": {
+			opts: []Option{WithValidSecret()},
+		},
+		"an expired certificate is reissued": {
+			opts:    []Option{WithExpiredCert()},
+			reason:  policies.Expired,
+			reissue: true,
+		},
+		"a mismatched request causes the certificate to be reissued": {
+			opts:    []Option{WithMismatchedRequest()},
+			reason:  policies.RequestChanged,
+			reissue: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := MustBuild(t, testCertificate(), append(tc.opts, WithClock(clock))...)
+			reason, message, reissue := chain.Evaluate(input)
+			assert.Equal(t, tc.reason, reason, message)
+			assert.Equal(t, tc.reissue, reissue, message)
+		})
+	}
+}
+
+func Test_MustBuild_ValidSecret(t *testing.T) {
+	crt := testCertificate()
+	input := MustBuild(t, crt, WithValidSecret())
+
+	assert.Nil(t, crt.Status.Revision, "expected the given Certificate not to be modified")
+	require.NotNil(t, input.Certificate.Status.Revision)
+	assert.Equal(t, 1, *input.Certificate.Status.Revision)
+	assert.False(t, input.IsInitialIssuance)
+
+	require.NotNil(t, input.Secret)
+	assert.Equal(t, "testns", input.Secret.Namespace)
+	assert.Equal(t, "test-tls", input.Secret.Name)
+	assert.Equal(t, "testissuer", input.Secret.Annotations[cmapi.IssuerNameAnnotationKey])
+
+	require.NotNil(t, input.CurrentRevisionRequest)
+	assert.Equal(t, "1", input.CurrentRevisionRequest.Annotations[cmapi.CertificateRequestRevisionAnnotationKey])
+	assert.Equal(t, input.Secret.Data[corev1.TLSCertKey], input.CurrentRevisionRequest.Status.Certificate)
+}
+
+func Test_MustBuild_ExpiredCert(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	input := MustBuild(t, testCertificate(), WithExpiredCert(), WithClock(clock))
+
+	cert, err := pki.DecodeX509CertificateBytes(input.Secret.Data[corev1.TLSCertKey])
+	require.NoError(t, err)
+	assert.True(t, cert.NotAfter.Before(clock.Now()), "expected certificate to have expired, but it expires at %s", cert.NotAfter)
+}
+
+func Test_MustBuild_MismatchedRequest(t *testing.T) {
+	input := MustBuild(t, testCertificate(), WithMismatchedRequest())
+
+	csr, err := pki.DecodeX509CertificateRequestBytes(input.CurrentRevisionRequest.Spec.Request)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com", MismatchedDNSName}, csr.DNSNames)
+
+	cert, err := pki.DecodeX509CertificateBytes(input.Secret.Data[corev1.TLSCertKey])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, cert.DNSNames)
+}